@@ -0,0 +1,285 @@
+// Package anagram builds an index of anagram sets over a word dictionary
+// and answers queries against it.
+package anagram
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const batchSize = 1024
+
+// Set is a group of words that are anagrams of each other.
+// Key is the first word of the set met in the dictionary.
+type Set struct {
+	Key   string   `json:"key"`
+	Words []string `json:"words"`
+}
+
+// Index stores dictionary words grouped by their sorted letters.
+// After Build returns the index is read-only and safe for concurrent use.
+type Index struct {
+	opts   Options
+	shards []*shard
+	words  int
+}
+
+type shard struct {
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+type group struct {
+	letters  []rune
+	first    string
+	firstSeq uint64
+	words    []string
+}
+
+type entry struct {
+	seq  uint64
+	word string
+}
+
+// Build reads a dictionary from r, one or more words per line,
+// and builds the index concurrently across opts.Shards shards.
+func Build(ctx context.Context, r io.Reader, opts Options) (*Index, error) {
+	if opts.Shards < 1 {
+		opts.Shards = runtime.GOMAXPROCS(0)
+	}
+
+	idx := &Index{
+		opts:   opts,
+		shards: make([]*shard, opts.Shards),
+	}
+	for i := range idx.shards {
+		idx.shards[i] = &shard{groups: make(map[string]*group)}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan []entry, opts.Shards)
+	var total atomic.Int64
+	var wg sync.WaitGroup
+
+	for range opts.Shards {
+		wg.Go(func() {
+			for batch := range batches {
+				total.Add(int64(idx.insert(batch)))
+			}
+		})
+	}
+
+	err := readBatches(ctx, r, batches)
+	close(batches)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+
+	idx.words = int(total.Load())
+	idx.finalize()
+
+	return idx, nil
+}
+
+// FromWords builds an index over an in-memory list of words.
+func FromWords(words []string, opts Options) *Index {
+	idx, _ := Build(context.Background(), strings.NewReader(strings.Join(words, "\n")), opts)
+	return idx
+}
+
+// readBatches streams words from r and sends them to batches in groups of batchSize.
+func readBatches(ctx context.Context, r io.Reader, batches chan<- []entry) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var seq uint64
+	batch := make([]entry, 0, batchSize)
+
+	send := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case batches <- batch:
+			batch = make([]entry, 0, batchSize)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for s.Scan() {
+		for _, w := range strings.Fields(s.Text()) {
+			batch = append(batch, entry{seq: seq, word: w})
+			seq++
+			if len(batch) == batchSize {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("failed to read dictionary: %w", err)
+	}
+
+	if len(batch) > 0 {
+		return send()
+	}
+
+	return nil
+}
+
+// insert normalizes a batch of words and adds them to their shards.
+// It returns the number of words added.
+func (idx *Index) insert(batch []entry) int {
+	n := 0
+	for _, e := range batch {
+		word := idx.opts.normalizeWord(e.word)
+		if word == "" {
+			continue
+		}
+		k := key(word)
+
+		sh := idx.shardFor(k)
+		sh.mu.Lock()
+		g, ok := sh.groups[k]
+		if !ok {
+			g = &group{letters: []rune(k), first: word, firstSeq: e.seq}
+			sh.groups[k] = g
+		}
+		if e.seq < g.firstSeq {
+			g.first, g.firstSeq = word, e.seq
+		}
+		g.words = append(g.words, word)
+		sh.mu.Unlock()
+		n++
+	}
+	return n
+}
+
+// finalize sorts the words of every group and drops duplicates.
+func (idx *Index) finalize() {
+	var wg sync.WaitGroup
+	for _, sh := range idx.shards {
+		wg.Go(func() {
+			for _, g := range sh.groups {
+				slices.Sort(g.words)
+				g.words = slices.Compact(g.words)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func (idx *Index) shardFor(k string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(k))
+	return idx.shards[h.Sum32()%uint32(len(idx.shards))]
+}
+
+// Len returns the number of words read into the index, duplicates included.
+func (idx *Index) Len() int {
+	return idx.words
+}
+
+// Anagrams returns all dictionary words that are anagrams of word,
+// including word itself if it is in the dictionary.
+func (idx *Index) Anagrams(word string) []string {
+	k := key(idx.opts.normalizeWord(word))
+	g, ok := idx.shardFor(k).groups[k]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(g.words)
+}
+
+// SubAnagrams returns dictionary words of at least minLen letters that can be
+// made from a subset of the letters of word. Longer words go first.
+func (idx *Index) SubAnagrams(word string, minLen int) []string {
+	letters := []rune(key(idx.opts.normalizeWord(word)))
+	if len(letters) == 0 {
+		return nil
+	}
+
+	var mu sync.Mutex
+	var res []string
+	var wg sync.WaitGroup
+
+	for _, sh := range idx.shards {
+		wg.Go(func() {
+			var found []string
+			for _, g := range sh.groups {
+				if len(g.letters) < minLen || !containsLetters(letters, g.letters) {
+					continue
+				}
+				found = append(found, g.words...)
+			}
+			mu.Lock()
+			res = append(res, found...)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	slices.SortFunc(res, func(a, b string) int {
+		if la, lb := len([]rune(a)), len([]rune(b)); la != lb {
+			return lb - la
+		}
+		return strings.Compare(a, b)
+	})
+
+	return res
+}
+
+// Top returns the n biggest anagram sets. Sets of a single word are skipped.
+// If n <= 0 all sets are returned.
+func (idx *Index) Top(n int) []Set {
+	sets := idx.sets()
+	slices.SortFunc(sets, func(a, b Set) int {
+		if len(a.Words) != len(b.Words) {
+			return len(b.Words) - len(a.Words)
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	if n > 0 && n < len(sets) {
+		sets = sets[:n]
+	}
+
+	return sets
+}
+
+// Groups returns every anagram set keyed by its first met word.
+func (idx *Index) Groups() map[string][]string {
+	res := make(map[string][]string)
+	for _, s := range idx.sets() {
+		res[s.Key] = s.Words
+	}
+	return res
+}
+
+func (idx *Index) sets() []Set {
+	var sets []Set
+	for _, sh := range idx.shards {
+		for _, g := range sh.groups {
+			if len(g.words) < 2 {
+				continue
+			}
+			sets = append(sets, Set{Key: g.first, Words: slices.Clone(g.words)})
+		}
+	}
+	return sets
+}
@@ -0,0 +1,80 @@
+package anagram
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golovanevvs/wbtech-school-go/L2/internal/leakcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dict = []string{"пятка", "пятак", "тяпка", "листок", "слиток", "столик", "стол", "кот", "ток", "ёлка", "елка"}
+
+func TestGroups(t *testing.T) {
+	idx := FromWords(dict, Options{Shards: 4})
+
+	expected := map[string][]string{
+		"пятка":  {"пятак", "пятка", "тяпка"},
+		"листок": {"листок", "слиток", "столик"},
+		"кот":    {"кот", "ток"},
+	}
+
+	assert.Equal(t, expected, idx.Groups())
+	assert.Equal(t, len(dict), idx.Len())
+}
+
+func TestAnagrams(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		word     string
+		expected []string
+	}{
+		{name: "1.pos", word: "ТЯПКА", expected: []string{"пятак", "пятка", "тяпка"}},
+		{name: "2.pos", word: "стол", expected: []string{"стол"}},
+		{name: "3.neg", word: "дом", expected: nil},
+		{name: "4.pos", word: "ёлка", expected: []string{"ёлка"}},
+		{name: "5.pos", opts: Options{FoldYo: true}, word: "ёлка", expected: []string{"елка"}},
+		// decomposed "ё" (е + combining diaeresis) must match the composed one
+		{name: "6.pos", word: "ёлка", expected: []string{"ёлка"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := FromWords(dict, tt.opts)
+			assert.Equal(t, tt.expected, idx.Anagrams(tt.word))
+		})
+	}
+}
+
+func TestSubAnagrams(t *testing.T) {
+	leakcheck.Check(t)
+
+	idx := FromWords(dict, Options{Shards: 3})
+
+	assert.Equal(t, []string{"листок", "слиток", "столик", "стол", "кот", "ток"}, idx.SubAnagrams("листок", 3))
+	assert.Equal(t, []string{"стол"}, idx.SubAnagrams("листок", 4)[3:])
+	assert.Nil(t, idx.SubAnagrams("", 1))
+}
+
+func TestTop(t *testing.T) {
+	idx := FromWords(dict, Options{})
+
+	top := idx.Top(2)
+	require.Len(t, top, 2)
+	assert.Equal(t, Set{Key: "листок", Words: []string{"листок", "слиток", "столик"}}, top[0])
+	assert.Equal(t, Set{Key: "пятка", Words: []string{"пятак", "пятка", "тяпка"}}, top[1])
+	assert.Len(t, idx.Top(0), 3)
+}
+
+func TestBuildCanceled(t *testing.T) {
+	leakcheck.Check(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Build(ctx, strings.NewReader(strings.Repeat("слово\n", 10*batchSize)), Options{Shards: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,57 @@
+package anagram
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Options controls how dictionary words and queries are normalized.
+type Options struct {
+	// FoldYo treats "ё" as "е", so "ёлка" and "елка" are the same word.
+	FoldYo bool
+	// Shards is the number of index shards built in parallel (at least 1).
+	Shards int
+}
+
+var yoReplacer = strings.NewReplacer("ё", "е")
+
+// normalizeWord brings a word to the canonical form stored in the index:
+// NFC-composed, lower-cased and optionally with ё folded to е.
+func (o Options) normalizeWord(s string) string {
+	s = strings.ToLower(norm.NFC.String(strings.TrimSpace(s)))
+	if o.FoldYo {
+		s = yoReplacer.Replace(s)
+	}
+	return s
+}
+
+// key returns the sorted letters of an already normalized word.
+// All anagrams of a word share the same key.
+func key(word string) string {
+	runes := []rune(word)
+	slices.Sort(runes)
+	return string(runes)
+}
+
+// containsLetters reports whether the sorted letter multiset sub
+// is contained in the sorted letter multiset set.
+func containsLetters(set, sub []rune) bool {
+	if len(sub) > len(set) {
+		return false
+	}
+
+	i := 0
+	for _, r := range sub {
+		for i < len(set) && set[i] < r {
+			i++
+		}
+		if i == len(set) || set[i] != r {
+			return false
+		}
+		i++
+	}
+
+	return true
+}
@@ -1,50 +1,94 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"sort"
+	"io"
+	"os"
 	"strings"
+
+	"github.com/golovanevvs/wbtech-school-go/L2/L2.11/anagram"
+	"github.com/spf13/cobra"
 )
 
+type options struct {
+	FoldYo bool
+	Shards int
+	Word   string
+	Sub    string
+	MinLen int
+	Top    int
+	Serve  string
+}
+
 func main() {
-	input := []string{"пятка", "пятак", "тяпка", "листок", "слиток", "столик", "стол"}
-	res := getAnagrams(input)
-	fmt.Printf("Input: %v\n", input)
-	fmt.Println("Result:")
-	for k, v := range res {
-		fmt.Printf("%q: %v\n", k, v)
+	opts := &options{}
+
+	rootCmd := &cobra.Command{
+		Use:   "anagrams [dictionary]",
+		Short: "anagrams - anagram sets finder",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Context(), args, opts)
+		},
 	}
-}
 
-func normalize(s string) string {
-	runes := []rune(strings.ToLower(s))
-	sort.Slice(runes, func(i, j int) bool {
-		return runes[i] < runes[j]
-	})
-	return string(runes)
-}
+	rootCmd.Flags().BoolVarP(&opts.FoldYo, "fold-yo", "e", false, "treat ё as е")
+	rootCmd.Flags().IntVar(&opts.Shards, "shards", 0, "number of index shards (default GOMAXPROCS)")
+	rootCmd.Flags().StringVarP(&opts.Word, "word", "w", "", "print anagrams of the word")
+	rootCmd.Flags().StringVarP(&opts.Sub, "sub", "s", "", "print words made of a subset of the word letters")
+	rootCmd.Flags().IntVar(&opts.MinLen, "min-len", 2, "minimal word length for --sub")
+	rootCmd.Flags().IntVarP(&opts.Top, "top", "t", 0, "print N biggest anagram sets")
+	rootCmd.Flags().StringVar(&opts.Serve, "serve", "", "serve queries over HTTP on the address, e.g. :8080")
 
-func getAnagrams(words []string) map[string][]string {
-	groups := make(map[string][]string)
-	firstWord := make(map[string]string)
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
 
-	for _, w := range words {
-		key := normalize(w)
-		groups[key] = append(groups[key], strings.ToLower(w))
-		if _, ok := firstWord[key]; !ok {
-			firstWord[key] = strings.ToLower(w)
+func run(ctx context.Context, args []string, opts *options) error {
+	var reader io.Reader
+	switch {
+	case len(args) > 0:
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
 		}
+		defer f.Close()
+		reader = f
+	default:
+		// without a dictionary fall back to the example from the task
+		input := []string{"пятка", "пятак", "тяпка", "листок", "слиток", "столик", "стол"}
+		fmt.Printf("Input: %v\n", input)
+		reader = strings.NewReader(strings.Join(input, "\n"))
+	}
+
+	idx, err := anagram.Build(ctx, reader, anagram.Options{FoldYo: opts.FoldYo, Shards: opts.Shards})
+	if err != nil {
+		return err
 	}
 
-	result := make(map[string][]string)
+	switch {
+	case opts.Serve != "":
+		return serve(opts.Serve, idx, opts.MinLen)
+
+	case opts.Word != "":
+		fmt.Println(strings.Join(idx.Anagrams(opts.Word), "\n"))
+
+	case opts.Sub != "":
+		fmt.Println(strings.Join(idx.SubAnagrams(opts.Sub, opts.MinLen), "\n"))
+
+	case opts.Top > 0:
+		for _, s := range idx.Top(opts.Top) {
+			fmt.Printf("%d %q: %v\n", len(s.Words), s.Key, s.Words)
+		}
 
-	for key, group := range groups {
-		if len(group) < 2 {
-			continue
+	default:
+		fmt.Println("Result:")
+		for k, v := range idx.Groups() {
+			fmt.Printf("%q: %v\n", k, v)
 		}
-		sort.Strings(group)
-		result[firstWord[key]] = group
 	}
 
-	return result
+	return nil
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/golovanevvs/wbtech-school-go/L2/L2.11/anagram"
+)
+
+type server struct {
+	idx    *anagram.Index
+	minLen int
+}
+
+func serve(addr string, idx *anagram.Index, minLen int) error {
+	s := &server{idx: idx, minLen: minLen}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /anagrams", s.anagrams)
+	mux.HandleFunc("GET /subanagrams", s.subAnagrams)
+	mux.HandleFunc("GET /top", s.top)
+
+	fmt.Printf("Serving %d words on %s\n", idx.Len(), addr)
+
+	return http.ListenAndServe(addr, mux)
+}
+
+func (s *server) anagrams(w http.ResponseWriter, r *http.Request) {
+	word := r.URL.Query().Get("word")
+	if word == "" {
+		writeError(w, http.StatusBadRequest, "word is required")
+		return
+	}
+
+	writeJSON(w, map[string]any{"word": word, "anagrams": s.idx.Anagrams(word)})
+}
+
+func (s *server) subAnagrams(w http.ResponseWriter, r *http.Request) {
+	word := r.URL.Query().Get("word")
+	if word == "" {
+		writeError(w, http.StatusBadRequest, "word is required")
+		return
+	}
+
+	minLen := s.minLen
+	if v := r.URL.Query().Get("min_len"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid min_len")
+			return
+		}
+		minLen = n
+	}
+
+	writeJSON(w, map[string]any{"word": word, "words": s.idx.SubAnagrams(word, minLen)})
+}
+
+func (s *server) top(w http.ResponseWriter, r *http.Request) {
+	n := 10
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		n, err = strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid n")
+			return
+		}
+	}
+
+	writeJSON(w, map[string]any{"sets": s.idx.Top(n)})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
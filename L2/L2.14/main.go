@@ -17,10 +17,18 @@ func or(channels ...<-chan interface{}) <-chan interface{} {
 		switch len(channels) {
 		case 1:
 			<-channels[0]
+		case 2:
+			select {
+			case <-channels[0]:
+			case <-channels[1]:
+			}
 		default:
+			// orDone is passed down so that nested goroutines exit
+			// as soon as this one does
 			select {
 			case <-channels[0]:
-			case <-or(channels[1:]...):
+			case <-channels[1]:
+			case <-or(append(channels[2:len(channels):len(channels)], orDone)...):
 			}
 		}
 	}()
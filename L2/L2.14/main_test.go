@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L2/internal/leakcheck"
+)
+
+func TestOr(t *testing.T) {
+	t.Run("first channel fires", func(t *testing.T) {
+		leakcheck.Check(t, leakcheck.Timeout(100*time.Millisecond))
+
+		fired := make(chan interface{})
+		never := make(chan interface{})
+		close(fired)
+
+		<-or(fired, never, never, never, never)
+	})
+
+	t.Run("last channel fires", func(t *testing.T) {
+		leakcheck.Check(t, leakcheck.Timeout(100*time.Millisecond))
+
+		fired := make(chan interface{})
+		never := make(chan interface{})
+		close(fired)
+
+		<-or(never, never, never, never, fired)
+	})
+
+	t.Run("no channels", func(t *testing.T) {
+		leakcheck.Check(t)
+
+		<-or()
+	})
+}
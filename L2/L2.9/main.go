@@ -2,85 +2,112 @@ package main
 
 import (
 	"bufio"
-	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
-	"unicode"
+
+	"github.com/golovanevvs/wbtech-school-go/L2/L2.9/rle"
+	"github.com/spf13/cobra"
 )
 
 func main() {
+	var output string
+
+	rootCmd := &cobra.Command{
+		Use:   "unpack",
+		Short: "unpack - run-length string codec",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return interactive()
+		},
+	}
+
+	packCmd := &cobra.Command{
+		Use:   "pack [file]",
+		Short: "pack file content",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return convert(args, output, func(w io.Writer, r io.Reader) error {
+				enc := rle.NewEncoder(w)
+				if _, err := io.Copy(enc, r); err != nil {
+					return err
+				}
+				return enc.Close()
+			})
+		},
+	}
+
+	unpackCmd := &cobra.Command{
+		Use:   "unpack [file]",
+		Short: "unpack file content",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return convert(args, output, func(w io.Writer, r io.Reader) error {
+				_, err := io.Copy(w, rle.NewDecoder(r))
+				return err
+			})
+		},
+	}
+
+	for _, cmd := range []*cobra.Command{packCmd, unpackCmd} {
+		cmd.Flags().StringVarP(&output, "output", "o", "", "output file (default STDOUT)")
+		rootCmd.AddCommand(cmd)
+	}
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func interactive() error {
 	fmt.Println("Enter the string:")
 	in := bufio.NewReader(os.Stdin)
 	str, err := in.ReadString('\n')
 	if err != nil {
-		fmt.Printf("Input error: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("input error: %w", err)
 	}
 
 	str = strings.TrimSpace(str)
 
 	unpackingString, err := unpackString(str)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return err
 	}
 	fmt.Printf("Unpacking string: %s\n", unpackingString)
+
+	return nil
 }
 
-func unpackString(str string) (string, error) {
-	rs := []rune(str)
-
-	var res strings.Builder
-	var r rune
-
-	for i := 0; i < len(rs); i++ {
-		switch {
-		case rs[i] == '\\':
-			if i == len(rs)-1 {
-				return "", errors.New("invalid string")
-			}
-			if r != 0 {
-				res.WriteRune(r)
-			}
-			i++
-			r = rs[i]
-
-		case !unicode.IsDigit(rs[i]):
-			if r != 0 {
-				res.WriteRune(r)
-			}
-			r = rs[i]
-
-		case unicode.IsDigit(rs[i]):
-			if r == 0 {
-				return "", errors.New("invalid string")
-			}
-
-			numStr := string(rs[i])
-			j := i + 1
-			for j < len(rs) && unicode.IsDigit(rs[j]) {
-				numStr += string(rs[j])
-				j++
-			}
-
-			count := 0
-			for _, d := range numStr {
-				count = count*10 + int(d-'0')
-			}
-
-			for range count {
-				res.WriteRune(r)
-			}
-
-			r = 0
-			i = j - 1
+// convert runs fn over the input file (or STDIN) and the output file (or STDOUT).
+func convert(args []string, output string, fn func(w io.Writer, r io.Reader) error) error {
+	var reader io.Reader = os.Stdin
+	if len(args) > 0 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
 		}
+		defer f.Close()
+		reader = f
 	}
 
-	if r != 0 {
-		res.WriteRune(r)
+	var writer io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		defer f.Close()
+		writer = f
+	}
+
+	bw := bufio.NewWriter(writer)
+	if err := fn(bw, reader); err != nil {
+		return err
 	}
 
-	return res.String(), nil
+	return bw.Flush()
+}
+
+func unpackString(str string) (string, error) {
+	return rle.Unpack(str)
 }
@@ -0,0 +1,161 @@
+package rle
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+const decodeChunk = 4096
+
+// Decoder unpacks data read from the underlying reader.
+// Runs are expanded lazily, so a large count does not allocate its whole output.
+type Decoder struct {
+	r      *bufio.Reader
+	buf    []byte
+	run    rune
+	count  int
+	cur    rune
+	hasCur bool
+	err    error
+}
+
+// NewDecoder returns a Decoder reading packed data from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Read fills p with unpacked data. It returns ErrInvalidString
+// once the malformed part of the input is reached.
+func (d *Decoder) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		switch {
+		case len(d.buf) > 0:
+			c := copy(p[n:], d.buf)
+			d.buf = d.buf[c:]
+			n += c
+
+		case d.count > 0:
+			d.expand()
+
+		case d.err != nil:
+			if n > 0 {
+				return n, nil
+			}
+			return 0, d.err
+
+		default:
+			d.err = d.next()
+		}
+	}
+
+	return n, nil
+}
+
+// expand moves up to decodeChunk bytes of the current run into buf.
+func (d *Decoder) expand() {
+	size := utf8.RuneLen(d.run)
+	if size < 0 {
+		d.run, size = utf8.RuneError, utf8.RuneLen(utf8.RuneError)
+	}
+
+	k := min(d.count, max(decodeChunk/size, 1))
+	buf := make([]byte, 0, k*size)
+	for range k {
+		buf = utf8.AppendRune(buf, d.run)
+	}
+
+	d.buf = buf
+	d.count -= k
+}
+
+// next parses the input until the next run is known.
+// It returns io.EOF when the input is exhausted.
+func (d *Decoder) next() error {
+	for {
+		r, _, err := d.r.ReadRune()
+		if err == io.EOF {
+			if d.hasCur {
+				d.emit(1)
+				return nil
+			}
+			return io.EOF
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case r == escape:
+			escaped, _, err := d.r.ReadRune()
+			if err == io.EOF {
+				return ErrInvalidString
+			}
+			if err != nil {
+				return err
+			}
+			if d.push(escaped) {
+				return nil
+			}
+
+		case isDigit(r):
+			if !d.hasCur {
+				return ErrInvalidString
+			}
+			count, err := d.readCount(r)
+			if err != nil {
+				return err
+			}
+			d.emit(count)
+			if count > 0 {
+				return nil
+			}
+
+		default:
+			if d.push(r) {
+				return nil
+			}
+		}
+	}
+}
+
+// emit schedules the current character to be written count times.
+func (d *Decoder) emit(count int) {
+	d.run, d.count = d.cur, count
+	d.hasCur = false
+}
+
+// push makes r the current character. The previous one, if any,
+// is scheduled once and push reports true.
+func (d *Decoder) push(r rune) bool {
+	prev, had := d.cur, d.hasCur
+	d.cur, d.hasCur = r, true
+	if had {
+		d.run, d.count = prev, 1
+	}
+	return had
+}
+
+// readCount reads a decimal count starting with the digit first.
+func (d *Decoder) readCount(first rune) (int, error) {
+	count := int(first - '0')
+	for {
+		r, _, err := d.r.ReadRune()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if !isDigit(r) {
+			d.r.UnreadRune()
+			return count, nil
+		}
+
+		count = count*10 + int(r-'0')
+		if count > maxCount {
+			return 0, ErrInvalidString
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rle
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Encoder packs text written to it and writes the result to the underlying writer.
+// A run may continue across Write calls, so Close must be called to flush the last one.
+type Encoder struct {
+	w       *bufio.Writer
+	partial []byte
+	run     rune
+	count   int
+	err     error
+}
+
+// NewEncoder returns an Encoder writing packed data to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Write packs p. UTF-8 sequences split between calls are joined.
+func (e *Encoder) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	data := p
+	if len(e.partial) > 0 {
+		data = append(e.partial, p...)
+		e.partial = nil
+	}
+
+	for len(data) > 0 {
+		if !utf8.FullRune(data) {
+			e.partial = append([]byte(nil), data...)
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		e.add(r)
+	}
+
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	return len(p), nil
+}
+
+// Close flushes the pending run and buffered output.
+// It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	for len(e.partial) > 0 {
+		r, size := utf8.DecodeRune(e.partial)
+		e.partial = e.partial[size:]
+		e.add(r)
+	}
+
+	e.flushRun()
+	if e.err != nil {
+		return e.err
+	}
+
+	e.err = e.w.Flush()
+
+	return e.err
+}
+
+func (e *Encoder) add(r rune) {
+	if e.count > 0 && r == e.run && e.count < maxCount {
+		e.count++
+		return
+	}
+	e.flushRun()
+	e.run, e.count = r, 1
+}
+
+func (e *Encoder) flushRun() {
+	if e.count == 0 || e.err != nil {
+		return
+	}
+
+	if needsEscape(e.run) {
+		e.w.WriteRune(escape)
+	}
+	e.w.WriteRune(e.run)
+	if e.count > 1 {
+		e.w.WriteString(strconv.Itoa(e.count))
+	}
+
+	e.count = 0
+	// bufio.Writer keeps the first error, so checking it once per run is enough
+	_, e.err = e.w.Write(nil)
+}
@@ -0,0 +1,52 @@
+// Package rle implements the run-length string codec from the L2.9 task.
+//
+// A packed string is a sequence of characters, each optionally followed by
+// a decimal repeat count: "a4bc2" unpacks to "aaaabcc". A count of 0 drops
+// the character. Digits and backslashes are written as "\4" and "\\" so that
+// they are treated as characters, not counts: "\45" unpacks to "44444".
+// Only ASCII digits are counts, any other Unicode character is literal.
+package rle
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrInvalidString is returned when the input is not a valid packed string:
+// it starts with a count, ends with a lone backslash or has a count that
+// does not fit in an int32.
+var ErrInvalidString = errors.New("invalid string")
+
+const escape = '\\'
+
+// maxCount is the largest repeat count accepted by the decoder and
+// written by the encoder. Longer runs are split.
+const maxCount = 1<<31 - 1
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func needsEscape(r rune) bool {
+	return r == escape || isDigit(r)
+}
+
+// Pack returns the packed form of s. For any valid UTF-8 string
+// Unpack(Pack(s)) == s.
+func Pack(s string) string {
+	var sb strings.Builder
+	enc := NewEncoder(&sb)
+	io.WriteString(enc, s)
+	enc.Close()
+	return sb.String()
+}
+
+// Unpack expands a packed string.
+func Unpack(s string) (string, error) {
+	var sb strings.Builder
+	if _, err := io.Copy(&sb, NewDecoder(strings.NewReader(s))); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,167 @@
+package rle
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"testing/quick"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "1.pos", input: "aaaabccddddde", expected: "a4bc2d5e"},
+		{name: "2.pos", input: "abcd", expected: "abcd"},
+		{name: "3.pos", input: "", expected: ""},
+		{name: "4.pos", input: "qwe45", expected: "qwe\\4\\5"},
+		{name: "5.pos", input: "qwe44444", expected: "qwe\\45"},
+		{name: "6.pos", input: "aaaaaaaaaabb", expected: "a10b2"},
+		{name: "7.pos", input: "Гооооолоооовааанёёв", expected: "Го5ло4ва3нё2в"},
+		{name: "8.pos", input: "\\\\\\", expected: "\\\\3"},
+		{name: "9.pos", input: "٣٣", expected: "٣2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Pack(tt.input))
+		})
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{name: "1.pos", input: "a4bc2d5e", expected: "aaaabccddddde"},
+		{name: "2.neg", input: "45", err: true},
+		{name: "3.neg", input: "abc\\", err: true},
+		{name: "4.pos", input: "a0b", expected: "b"},
+		{name: "5.pos", input: "a1b1", expected: "ab"},
+		{name: "6.pos", input: "\x00\x003", expected: "\x00\x00\x00\x00"},
+		{name: "7.neg", input: "a99999999999", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Unpack(tt.input)
+			if tt.err {
+				assert.ErrorIs(t, err, ErrInvalidString)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRoundTripQuick(t *testing.T) {
+	roundTrip := func(s string) bool {
+		res, err := Unpack(Pack(s))
+		return err == nil && res == s
+	}
+
+	require.NoError(t, quick.Check(roundTrip, &quick.Config{MaxCount: 5000}))
+}
+
+func TestRoundTripRuns(t *testing.T) {
+	// runs are what quick.Check rarely generates, so build them explicitly
+	runs := func(chars []rune, counts []uint8) bool {
+		var sb strings.Builder
+		for i, r := range chars {
+			if !utf8.ValidRune(r) {
+				r = '\\'
+			}
+			n := 1
+			if i < len(counts) {
+				n += int(counts[i])
+			}
+			sb.WriteString(strings.Repeat(string(r), n))
+		}
+		s := sb.String()
+
+		res, err := Unpack(Pack(s))
+		return err == nil && res == s
+	}
+
+	require.NoError(t, quick.Check(runs, nil))
+}
+
+func TestStreaming(t *testing.T) {
+	input := strings.Repeat("ёё11\\\\zz", 1000) + strings.Repeat("я", 10000)
+
+	var packed bytes.Buffer
+	enc := NewEncoder(&packed)
+	// write one byte at a time to split multi-byte runes and runs between calls
+	for i := range len(input) {
+		_, err := enc.Write([]byte{input[i]})
+		require.NoError(t, err)
+	}
+	require.NoError(t, enc.Close())
+	assert.Equal(t, Pack(input), packed.String())
+
+	out, err := io.ReadAll(iotest.OneByteReader(NewDecoder(iotest.HalfReader(&packed))))
+	require.NoError(t, err)
+	assert.Equal(t, input, string(out))
+}
+
+func TestDecoderLargeCount(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a2000000000b"))
+
+	buf := make([]byte, 10)
+	n, err := io.ReadFull(dec, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "aaaaaaaaaa", string(buf[:n]))
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, s := range []string{"", "aaaabccddddde", "qwe45", "\\\\", "Гооооолоооовааанёёв", "a\x00\x00"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) {
+			t.Skip()
+		}
+		res, err := Unpack(Pack(s))
+		if err != nil {
+			t.Fatalf("Unpack(Pack(%q)) error: %v", s, err)
+		}
+		if res != s {
+			t.Fatalf("Unpack(Pack(%q)) = %q", s, res)
+		}
+	})
+}
+
+func FuzzUnpack(f *testing.F) {
+	for _, s := range []string{"a4bc2d5e", "45", "abc\\", "a0b", "\\45", "a10b2"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		// counts can be huge, read a bounded prefix only
+		out, err := io.ReadAll(io.LimitReader(NewDecoder(strings.NewReader(s)), 1<<16))
+		if err != nil {
+			return
+		}
+		if !utf8.ValidString(s) || len(out) == 1<<16 {
+			return
+		}
+		// a valid packed string must survive another pack/unpack cycle
+		res, err := Unpack(Pack(string(out)))
+		if err != nil || res != string(out) {
+			t.Fatalf("round trip of Unpack(%q) = %q, %v", s, res, err)
+		}
+	})
+}
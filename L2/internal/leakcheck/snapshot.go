@@ -0,0 +1,114 @@
+package leakcheck
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Goroutine is a single goroutine parsed from runtime.Stack output.
+type Goroutine struct {
+	ID      uint64
+	State   string
+	TopFunc string
+	Funcs   []string
+	Stack   string
+}
+
+// Snapshot is the list of goroutines running at some moment.
+type Snapshot []Goroutine
+
+// Take returns a snapshot of all running goroutines.
+func Take() Snapshot {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var s Snapshot
+	for block := range strings.SplitSeq(string(buf), "\n\n") {
+		if g, ok := parseGoroutine(block); ok {
+			s = append(s, g)
+		}
+	}
+
+	return s
+}
+
+func (s Snapshot) byID(id uint64) (Goroutine, bool) {
+	for _, g := range s {
+		if g.ID == id {
+			return g, true
+		}
+	}
+	return Goroutine{}, false
+}
+
+// parseGoroutine parses a block like:
+//
+//	goroutine 7 [chan receive]:
+//	main.worker(0xc000010000)
+//		/src/main.go:12 +0x25
+//	created by main.main in goroutine 1
+//		/src/main.go:8 +0x3c
+func parseGoroutine(block string) (Goroutine, bool) {
+	lines := strings.Split(strings.TrimSpace(block), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "goroutine ") {
+		return Goroutine{}, false
+	}
+
+	header := strings.TrimPrefix(lines[0], "goroutine ")
+	idStr, state, _ := strings.Cut(header, " ")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return Goroutine{}, false
+	}
+
+	g := Goroutine{
+		ID:    id,
+		State: strings.TrimSuffix(strings.TrimPrefix(state, "["), "]:"),
+		Stack: strings.Join(lines[1:], "\n"),
+	}
+
+	// function lines alternate with indented file:line lines
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		g.Funcs = append(g.Funcs, funcName(line))
+	}
+	if len(g.Funcs) > 0 {
+		g.TopFunc = g.Funcs[0]
+	}
+
+	return g, true
+}
+
+// funcName extracts the function name from a stack line like
+// "main.worker(0xc000010000)" or "created by main.main in goroutine 1".
+func funcName(line string) string {
+	if rest, ok := strings.CutPrefix(line, "created by "); ok {
+		name, _, _ := strings.Cut(rest, " in goroutine ")
+		return name
+	}
+	if i := strings.LastIndex(line, "("); i > 0 {
+		return line[:i]
+	}
+	return line
+}
+
+func (g Goroutine) matches(names []string) bool {
+	for _, fn := range g.Funcs {
+		for _, name := range names {
+			if strings.Contains(fn, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
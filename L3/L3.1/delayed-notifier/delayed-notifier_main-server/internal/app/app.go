@@ -12,6 +12,8 @@ type App struct {
 	lg   *zlog.Zerolog
 	deps *dependencies
 	rm   *resourceManager
+	// pollDone is closed when the Telegram polling stops
+	pollDone chan struct{}
 }
 
 func New(env string) (*App, error) {
@@ -6,10 +6,18 @@ import (
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgEmail"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgLogger"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRabbitmq"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRetry"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgSMS"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgWebhook"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/addNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/consumeNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/guardNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/scheduleNoticeService"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport"
 	"github.com/wb-go/wbf/config"
 )
@@ -19,8 +27,16 @@ type Config struct {
 	rs *pkgRetry.Config
 	rd *pkgRedis.Config
 	rb *pkgRabbitmq.Config
+	pg *pkgPostgres.Config
+	st *repository.Config
 	tg *pkgTelegram.Config
 	em *pkgEmail.Config
+	wh *pkgWebhook.Config
+	sm *pkgSMS.Config
+	ad *addNoticeService.Config
+	sc *scheduleNoticeService.Config
+	cs *consumeNoticeService.Config
+	gd *guardNoticeService.Config
 	tr *transport.Config
 }
 
@@ -34,6 +50,7 @@ func newConfig(env string) (*Config, error) {
 			"providers/email/.env",
 			"providers/grafana/.env",
 			"providers/loki/.env",
+			"providers/postgres/.env",
 			"providers/promtail/.env",
 			"providers/rabbitmq/.env",
 			"providers/redis/.env",
@@ -48,6 +65,7 @@ func newConfig(env string) (*Config, error) {
 	if err := cfg.LoadConfigFiles(
 		"providers/app/config.yaml",
 		"providers/logger/config.yaml",
+		"providers/postgres/config.yaml",
 		"providers/rabbitmq/config.yaml",
 		"providers/redis/config.yaml",
 	); err != nil {
@@ -64,8 +82,16 @@ func newConfig(env string) (*Config, error) {
 		rs: pkgRetry.NewConfig(cfg),
 		rd: pkgRedis.NewConfig(cfg),
 		rb: pkgRabbitmq.NewConfig(cfg),
+		pg: pkgPostgres.NewConfig(cfg),
+		st: repository.NewConfig(cfg),
 		tg: pkgTelegram.NewConfig(cfg),
 		em: pkgEmail.NewConfig(cfg),
+		wh: pkgWebhook.NewConfig(cfg),
+		sm: pkgSMS.NewConfig(cfg),
+		ad: addNoticeService.NewConfig(cfg),
+		sc: scheduleNoticeService.NewConfig(cfg),
+		cs: consumeNoticeService.NewConfig(cfg),
+		gd: guardNoticeService.NewConfig(cfg),
 		tr: transport.NewConfig(cfg, env),
 	}, nil
 }
@@ -87,6 +113,22 @@ func (a *Config) String() string {
 
 %s %s
 
+%s %s
+
+%s %s
+
+%s %s
+
+%s %s
+
+%s %s
+
+%s %s
+
+%s %s
+
+%s %s
+
 %s %s
 `,
 		pkgConst.Config,
@@ -94,8 +136,16 @@ func (a *Config) String() string {
 		pkgConst.Retry, a.rs.String(),
 		pkgConst.Redis, a.rd.String(),
 		pkgConst.RabbitMQ, a.rb.String(),
+		pkgConst.Postgres, a.pg.String(),
+		pkgConst.Storage, a.st.String(),
 		pkgConst.Telegram, a.tg.String(),
 		pkgConst.EMail, a.em.String(),
+		pkgConst.Webhook, a.wh.String(),
+		pkgConst.SMS, a.sm.String(),
+		pkgConst.Batch, a.ad.String(),
+		pkgConst.Scheduler, a.sc.String(),
+		pkgConst.Consumer, a.cs.String(),
+		pkgConst.Guard, a.gd.String(),
 		pkgConst.Transport, a.tr.String(),
 	)
 }
@@ -1,13 +1,18 @@
 package app
 
 import (
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgEmail"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRabbitmq"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRetry"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgSMS"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgWebhook"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/provider"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport"
@@ -18,9 +23,11 @@ import (
 type dependencies struct {
 	rs *pkgRetry.Retry
 	rd *pkgRedis.Client
+	pg *pkgPostgres.Postgres
 	rb *pkgRabbitmq.Client
 	tg *pkgTelegram.Client
 	em *pkgEmail.Client
+	pv *provider.Registry
 	rp *repository.Repository
 	sv *service.Service
 	tr *transport.Transport
@@ -90,6 +97,39 @@ func (b *dependencyBuilder) initRedis() error {
 	return nil
 }
 
+// initPostgres connects to Postgres if the notices are stored there
+func (b *dependencyBuilder) initPostgres() error {
+	switch b.cfg.st.Storage {
+	case repository.StorageRedis:
+		return nil
+	case repository.StoragePostgres:
+	default:
+		return pkgErrors.Wrapf(pkgErrors.ErrUnknownStorage, "storage: %s", b.cfg.st.Storage)
+	}
+
+	var pg *pkgPostgres.Postgres
+	var err error
+	fn := func() error {
+		pg, err = pkgPostgres.New(b.cfg.pg)
+		if err != nil {
+			b.lg.Warn().Err(err).Int("port", b.cfg.pg.Master.Port).Msgf("%s failed to initialize Postgres", pkgConst.Warn)
+			return err
+		}
+		return nil
+	}
+	if err := retry.Do(fn, retry.Strategy(*b.deps.rs)); err != nil {
+		return pkgErrors.Wrapf(err, "initialize Postgres, port: %d, attempts: %d", b.cfg.pg.Master.Port, b.cfg.rs.Attempts)
+	}
+
+	b.lg.Debug().Msgf("%s Postgres has been initialized", pkgConst.Info)
+	b.deps.pg = pg
+	b.rm.addResource(resource{
+		name:      "Postgres client",
+		closeFunc: func() error { return b.deps.pg.Close() },
+	})
+	return nil
+}
+
 func (b *dependencyBuilder) initRabbitMQ() error {
 	var rb *pkgRabbitmq.Client
 	var err error
@@ -135,7 +175,7 @@ func (b *dependencyBuilder) initEmail() error {
 }
 
 func (b *dependencyBuilder) initRepository() error {
-	rp, err := repository.New(b.deps.rd)
+	rp, err := repository.New(b.deps.rd, b.deps.pg)
 	if err != nil {
 		return pkgErrors.Wrap(err, "initialize repository")
 	}
@@ -144,8 +184,41 @@ func (b *dependencyBuilder) initRepository() error {
 	return nil
 }
 
+// initProviders registers the channel providers. Webhook and SMS channels
+// are available only if configured
+func (b *dependencyBuilder) initProviders() error {
+	pv := provider.NewRegistry()
+	pv.Register(model.ChannelTelegram, provider.NewTelegram(b.deps.tg, b.deps.rp))
+	pv.Register(model.ChannelEmail, provider.NewEmail(b.deps.em))
+
+	if b.cfg.wh.Secret != "" {
+		wh, err := pkgWebhook.New(b.cfg.wh)
+		if err != nil {
+			return pkgErrors.Wrap(err, "initialize webhook client")
+		}
+		pv.Register(model.ChannelWebhook, provider.NewWebhook(wh))
+	} else {
+		b.lg.Warn().Msgf("%s webhook secret is not set, webhook channel is disabled", pkgConst.Warn)
+	}
+
+	if b.cfg.sm.URL != "" {
+		sm, err := pkgSMS.New(b.cfg.sm)
+		if err != nil {
+			return pkgErrors.Wrap(err, "initialize sms client")
+		}
+		pv.Register(model.ChannelSMS, provider.NewSMS(sm))
+	} else {
+		b.lg.Warn().Msgf("%s sms gateway url is not set, sms channel is disabled", pkgConst.Warn)
+	}
+
+	model.SetChannelRegistry(pv)
+	b.lg.Debug().Any("channels", pv.Types()).Msgf("%s channel providers have been registered", pkgConst.Info)
+	b.deps.pv = pv
+	return nil
+}
+
 func (b *dependencyBuilder) initService() {
-	sv := service.New(b.deps.rs, b.deps.rp, b.deps.rb, b.deps.tg, b.deps.em)
+	sv := service.New(b.deps.rs, b.cfg.ad, b.cfg.sc, b.cfg.cs, b.cfg.gd, b.cfg.tg, b.deps.rp, b.deps.rb, b.deps.tg, b.deps.pv)
 	b.lg.Debug().Msgf("%s service has been initialized", pkgConst.Info)
 	b.deps.sv = sv
 }
@@ -163,6 +236,9 @@ func (b *dependencyBuilder) build() (*dependencies, *resourceManager, error) {
 	if err := b.initRedis(); err != nil {
 		return nil, b.rm, err
 	}
+	if err := b.initPostgres(); err != nil {
+		return nil, b.rm, err
+	}
 	if err := b.initRabbitMQ(); err != nil {
 		return nil, b.rm, err
 	}
@@ -175,6 +251,9 @@ func (b *dependencyBuilder) build() (*dependencies, *resourceManager, error) {
 	if err := b.initRepository(); err != nil {
 		return nil, b.rm, err
 	}
+	if err := b.initProviders(); err != nil {
+		return nil, b.rm, err
+	}
 	b.initService()
 	b.initTransport()
 	return b.deps, b.rm, nil
@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
 )
 
-func (a *App) Run(cancel context.CancelFunc) error {
-	ctx := context.Background()
-
+func (a *App) Run(ctx context.Context, cancel context.CancelFunc) error {
 	a.deps.tr.HTTP.RunServer(cancel)
 	time.Sleep(500 * time.Millisecond)
 
@@ -18,13 +18,29 @@ func (a *App) Run(cancel context.CancelFunc) error {
 		return pkgErrors.Wrap(err, "failed to start http server")
 	}
 
-	if err := a.deps.tg.SetWebhook(fmt.Sprintf("%s/telegram/webhook", a.cfg.tr.TrHTTP.PublicHost)); err != nil {
-		return pkgErrors.Wrap(err, "failed to set telegram webhook")
+	switch a.cfg.tg.Mode {
+	case pkgTelegram.ModeWebhook:
+		if err := a.deps.tg.SetWebhook(fmt.Sprintf("%s/telegram/webhook", a.cfg.tr.TrHTTP.PublicHost)); err != nil {
+			return pkgErrors.Wrap(err, "failed to set telegram webhook")
+		}
+	case pkgTelegram.ModePolling:
+		a.pollDone = make(chan struct{})
+		go func() {
+			defer close(a.pollDone)
+			if err := a.deps.sv.TelegramPollService.RunPolling(ctx); err != nil {
+				a.lg.Error().Err(err).Msgf("%s telegram polling failed", pkgConst.Error)
+				cancel()
+			}
+		}()
+	default:
+		return pkgErrors.Wrapf(pkgErrors.ErrUnknownTelegramMode, "mode: %s", a.cfg.tg.Mode)
 	}
 
 	if err := a.deps.sv.ConsumeNoticeService.Consume(ctx); err != nil {
 		return pkgErrors.Wrap(err, "failed to start consumer")
 	}
 
+	go a.deps.sv.ScheduleNoticeService.RunScheduler(ctx)
+
 	return nil
 }
@@ -26,6 +26,11 @@ func (a *App) GracefullShutdown(ctx context.Context, cancel context.CancelFunc)
 		lg.Error().Err(err).Msgf("%s failed to shutdown http server", pkgConst.Error)
 	}
 
+	// the update being handled is handled to the end before Redis is closed
+	if a.pollDone != nil {
+		<-a.pollDone
+	}
+
 	if err := a.rm.closeAll(); err != nil {
 		lg.Error().Err(err).Msgf("%s failed to close resources", pkgConst.Error)
 	}
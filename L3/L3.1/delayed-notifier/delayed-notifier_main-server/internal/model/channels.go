@@ -3,6 +3,8 @@ package model
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
 )
 
 var (
@@ -14,7 +16,7 @@ var (
 type Channels []ChannelInfo
 
 type ChannelInfo struct {
-	Type  ChannelType `json:"type" validate:"required,oneof=email telegram" binding:"required,oneof=email telegram"`
+	Type  ChannelType `json:"type" validate:"required" binding:"required"`
 	Value string      `json:"value" validate:"required" binding:"required"`
 }
 
@@ -23,26 +25,70 @@ type ChannelType string
 const (
 	ChannelEmail    ChannelType = "email"
 	ChannelTelegram ChannelType = "telegram"
+	ChannelWebhook  ChannelType = "webhook"
+	ChannelSMS      ChannelType = "sms"
 )
 
+// ChannelRegistry knows the channel types that have a provider
+// and validates their recipients
+type ChannelRegistry interface {
+	Supports(t ChannelType) bool
+	ValidateRecipient(t ChannelType, recipient string) error
+}
+
+// builtinChannels is the registry used until the providers are registered
+type builtinChannels struct{}
+
+func (builtinChannels) Supports(t ChannelType) bool {
+	return t == ChannelEmail || t == ChannelTelegram
+}
+
+func (builtinChannels) ValidateRecipient(ChannelType, string) error {
+	return nil
+}
+
+var (
+	channelsMu sync.RWMutex
+	channels   ChannelRegistry = builtinChannels{}
+)
+
+// SetChannelRegistry sets the registry asked by channel validation
+func SetChannelRegistry(r ChannelRegistry) {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	channels = r
+}
+
+func channelRegistry() ChannelRegistry {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	return channels
+}
+
 func (t ChannelType) IsValid() bool {
-	switch t {
-	case ChannelEmail, ChannelTelegram:
-		return true
-	}
-	return false
+	return channelRegistry().Supports(t)
 }
 
+// Validate checks that all channel types have a provider
+// and at least one channel has a recipient
 func (c Channels) Validate() error {
 	if len(c) == 0 {
 		return ErrEmptyChannels
 	}
 
+	registry := channelRegistry()
 	validCount := 0
 	for _, ch := range c {
-		if ch.Type.IsValid() && ch.Value != "" {
-			validCount++
+		if !registry.Supports(ch.Type) {
+			return fmt.Errorf("%w: %s", ErrInvalidTypeBase, ch.Type)
+		}
+		if ch.Value == "" {
+			continue
+		}
+		if err := registry.ValidateRecipient(ch.Type, ch.Value); err != nil {
+			return fmt.Errorf("invalid %s recipient %q: %w", ch.Type, ch.Value, err)
 		}
+		validCount++
 	}
 
 	if validCount == 0 {
@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+	// DeliverySkipped is the status of a duplicate the recipient already got
+	DeliverySkipped DeliveryStatus = "skipped"
+	// DeliveryDeferred is the status of a delivery over the rate limit of the recipient
+	DeliveryDeferred DeliveryStatus = "deferred"
+)
+
+// Sendable reports whether the delivery is still to be sent
+func (s DeliveryStatus) Sendable() bool {
+	return s == DeliveryPending || s == DeliveryFailed
+}
+
+// Delivery is the delivery record of a notice to one channel
+type Delivery struct {
+	Channel           ChannelType    `json:"channel"`
+	Recipient         string         `json:"recipient"`
+	Status            DeliveryStatus `json:"status"`
+	Attempts          int            `json:"attempts"`
+	LastError         string         `json:"last_error,omitempty"`
+	ProviderMessageID string         `json:"provider_message_id,omitempty"`
+	LastAttemptAt     *time.Time     `json:"last_attempt_at,omitempty"`
+	DeliveredAt       *time.Time     `json:"delivered_at,omitempty"`
+}
+
+type Deliveries []Delivery
+
+// Deferred reports whether some delivery is deferred
+func (d Deliveries) Deferred() bool {
+	for _, dl := range d {
+		if dl.Status == DeliveryDeferred {
+			return true
+		}
+	}
+	return false
+}
+
+// Status derives the notice status from the delivery records:
+// sent if every channel got the notice, failed if none did.
+// A skipped duplicate counts as delivered, skipped if all are duplicates
+func (d Deliveries) Status() Status {
+	sent, skipped := 0, 0
+	for _, dl := range d {
+		switch dl.Status {
+		case DeliverySent:
+			sent++
+		case DeliverySkipped:
+			skipped++
+		}
+	}
+
+	switch {
+	case len(d) > 0 && skipped == len(d):
+		return StatusSkipped
+	case len(d) > 0 && sent+skipped == len(d):
+		return StatusSent
+	case sent+skipped > 0:
+		return StatusPartiallySent
+	default:
+		return StatusFailed
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveriesStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		deliveries Deliveries
+		want       Status
+	}{
+		{"all sent", Deliveries{{Status: DeliverySent}, {Status: DeliverySent}}, StatusSent},
+		{"some sent", Deliveries{{Status: DeliverySent}, {Status: DeliveryFailed}}, StatusPartiallySent},
+		{"none sent", Deliveries{{Status: DeliveryFailed}, {Status: DeliveryFailed}}, StatusFailed},
+		{"no deliveries", nil, StatusFailed},
+		{"sent and skipped", Deliveries{{Status: DeliverySent}, {Status: DeliverySkipped}}, StatusSent},
+		{"all skipped", Deliveries{{Status: DeliverySkipped}, {Status: DeliverySkipped}}, StatusSkipped},
+		{"skipped and failed", Deliveries{{Status: DeliverySkipped}, {Status: DeliveryFailed}}, StatusPartiallySent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.deliveries.Status())
+		})
+	}
+}
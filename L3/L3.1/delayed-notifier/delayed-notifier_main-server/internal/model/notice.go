@@ -1,19 +1,33 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
 type Notice struct {
-	ID        int        `json:"id"`
-	UserID    int        `json:"user_id" validate:"required"`
-	Message   string     `json:"message" validate:"required"`
-	Channels  Channels   `json:"channels"`
-	CreatedAt time.Time  `json:"created_at"`
-	SentAt    *time.Time `json:"sent_at,omitempty" validate:"omitempty,gtfield=CreatedAt"`
-	Status    Status     `json:"status"`
+	ID         int          `json:"id"`
+	UserID     int          `json:"user_id" validate:"required"`
+	Message    string       `json:"message" validate:"required_without=Template"`
+	Template   *TemplateRef `json:"template,omitempty"`
+	Channels   Channels     `json:"channels"`
+	CreatedAt  time.Time    `json:"created_at"`
+	SentAt     *time.Time   `json:"sent_at,omitempty" validate:"omitempty,gtfield=CreatedAt"`
+	Status     Status       `json:"status"`
+	Deliveries Deliveries   `json:"deliveries,omitempty"`
+	Recurrence *Recurrence  `json:"recurrence,omitempty"`
+	// Occurrence is the number of the scheduled occurrence of a recurring notice starting with 1
+	Occurrence int `json:"occurrence,omitempty"`
+	// Previous is the outcome of the previous occurrence of a recurring notice
+	Previous *OccurrenceResult `json:"previous,omitempty"`
+	// DeferredUntil is the time the occurrence was deferred to by quiet hours or rate limits
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`
+	// Version is increased by every change of a scheduled notice
+	Version int `json:"version"`
 }
 
 func (n *Notice) Validate() error {
@@ -31,5 +45,114 @@ func (n *Notice) Validate() error {
 		return err
 	}
 
+	if n.Recurrence != nil {
+		if err := n.Recurrence.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// Missed reports whether the scheduled occurrence of a recurring notice with SkipMissed
+// is missed: the occurrence following it is due at now as well. Without SkipMissed
+// the missed occurrences are sent late one by one, so none of them is missed
+func (n *Notice) Missed(now time.Time) bool {
+	if n.Recurrence == nil || !n.Recurrence.SkipMissed || n.SentAt == nil {
+		return false
+	}
+	next, ok := n.Recurrence.Next(n.Occurrence, *n.SentAt)
+	return ok && !next.After(now)
+}
+
+// Advance moves a recurring notice to its next occurrence and keeps
+// the outcome of the current one in Previous. With SkipMissed the occurrences
+// already missed at now are skipped. It returns false if the series is over
+func (n *Notice) Advance(now time.Time) bool {
+	if n.Recurrence == nil || n.SentAt == nil {
+		return false
+	}
+
+	occurrence, at := n.Occurrence, *n.SentAt
+	for {
+		next, ok := n.Recurrence.Next(occurrence, at)
+		if !ok {
+			return false
+		}
+		occurrence, at = occurrence+1, next
+
+		if !n.Recurrence.SkipMissed {
+			break
+		}
+		if following, ok := n.Recurrence.Next(occurrence, at); !ok || following.After(now) {
+			break
+		}
+	}
+
+	n.Previous = &OccurrenceResult{
+		Occurrence: n.Occurrence,
+		SentAt:     *n.SentAt,
+		Status:     n.Status,
+		Deliveries: n.Deliveries,
+	}
+	n.Occurrence = occurrence
+	n.SentAt = &at
+	n.Status = StatusScheduled
+	n.Deliveries = nil
+	n.DeferredUntil = nil
+
+	return true
+}
+
+// DueAt returns the time the notice is scheduled at
+func (n *Notice) DueAt() time.Time {
+	if n.DeferredUntil != nil {
+		return *n.DeferredUntil
+	}
+	if n.SentAt != nil {
+		return *n.SentAt
+	}
+	return time.Time{}
+}
+
+// PrepareDeliveries makes a delivery record per channel of the notice,
+// the records kept from a previous attempt stay as they are
+func (n *Notice) PrepareDeliveries() {
+	deliveries := make(Deliveries, 0, len(n.Channels))
+	for _, ch := range n.Channels {
+		if ch.Value == "" {
+			continue
+		}
+		deliveries = append(deliveries, n.Deliveries.find(ch))
+	}
+	n.Deliveries = deliveries
+}
+
+// find returns the delivery record of the channel or a new pending one
+func (d Deliveries) find(ch ChannelInfo) Delivery {
+	for _, dl := range d {
+		if dl.Channel == ch.Type && dl.Recipient == ch.Value {
+			return dl
+		}
+	}
+	return Delivery{
+		Channel:   ch.Type,
+		Recipient: ch.Value,
+		Status:    DeliveryPending,
+	}
+}
+
+// ContentHash returns the hash of the message or template of the notice
+func (n *Notice) ContentHash() string {
+	return contentHash(struct {
+		Message  string       `json:"message"`
+		Template *TemplateRef `json:"template"`
+	}{n.Message, n.Template})
+}
+
+// contentHash returns the hex SHA-256 of the JSON of v
+func contentHash(v any) string {
+	b, _ := json.Marshal(v)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
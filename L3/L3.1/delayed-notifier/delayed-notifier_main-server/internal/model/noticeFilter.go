@@ -0,0 +1,144 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("limit must be between 1 and 100")
+	ErrInvalidOffset = errors.New("offset cannot be negative")
+	ErrInvalidRange  = errors.New("from must be before to")
+	ErrInvalidOrder  = errors.New("invalid order")
+)
+
+// NoticeOrder is the order of the notices of a page
+type NoticeOrder string
+
+const (
+	// OrderSentAtDesc orders by sent_at descending, notices without sent_at go last
+	OrderSentAtDesc NoticeOrder = ""
+	// OrderDueAtAsc orders by the due time ascending, notices without it go last
+	OrderDueAtAsc NoticeOrder = "due_at_asc"
+)
+
+// NoticeFilter selects a page of the notices of a user
+type NoticeFilter struct {
+	UserID   int
+	Statuses []Status
+	// From and To limit sent_at: From inclusive, To exclusive
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+	Order  NoticeOrder
+}
+
+// NoticePage is a page of the notices of a user in the order of the filter
+type NoticePage struct {
+	Notices []Notice `json:"notices"`
+	Total   int      `json:"total"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+}
+
+// Validate sets the default limit and checks the filter
+func (f *NoticeFilter) Validate() error {
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return ErrInvalidLimit
+	}
+	if f.Offset < 0 {
+		return ErrInvalidOffset
+	}
+	for _, s := range f.Statuses {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return ErrInvalidRange
+	}
+	if f.Order != OrderSentAtDesc && f.Order != OrderDueAtAsc {
+		return ErrInvalidOrder
+	}
+	return nil
+}
+
+// Match reports whether the notice passes the filter
+func (f *NoticeFilter) Match(n *Notice) bool {
+	if n.UserID != f.UserID {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, n.Status) {
+		return false
+	}
+	if f.From != nil && (n.SentAt == nil || n.SentAt.Before(*f.From)) {
+		return false
+	}
+	if f.To != nil && (n.SentAt == nil || !n.SentAt.Before(*f.To)) {
+		return false
+	}
+	return true
+}
+
+// Page sorts the notices passing the filter and cuts the page of the filter
+func (f *NoticeFilter) Page(notices []Notice) NoticePage {
+	matched := make([]Notice, 0, len(notices))
+	for i := range notices {
+		if f.Match(&notices[i]) {
+			matched = append(matched, notices[i])
+		}
+	}
+
+	if f.Order == OrderDueAtAsc {
+		slices.SortFunc(matched, compareDueAtAsc)
+	} else {
+		slices.SortFunc(matched, compareSentAtDesc)
+	}
+
+	page := NoticePage{Notices: []Notice{}, Total: len(matched), Limit: f.Limit, Offset: f.Offset}
+	if f.Offset < len(matched) {
+		page.Notices = matched[f.Offset:min(f.Offset+f.Limit, len(matched))]
+	}
+	return page
+}
+
+func compareSentAtDesc(a, b Notice) int {
+	switch {
+	case a.SentAt == nil && b.SentAt == nil:
+	case a.SentAt == nil:
+		return 1
+	case b.SentAt == nil:
+		return -1
+	default:
+		if c := b.SentAt.Compare(*a.SentAt); c != 0 {
+			return c
+		}
+	}
+	return b.ID - a.ID
+}
+
+func compareDueAtAsc(a, b Notice) int {
+	aDue, bDue := a.DueAt(), b.DueAt()
+	switch {
+	case aDue.IsZero() && bDue.IsZero():
+	case aDue.IsZero():
+		return 1
+	case bDue.IsZero():
+		return -1
+	default:
+		if c := aDue.Compare(bDue); c != 0 {
+			return c
+		}
+	}
+	return a.ID - b.ID
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoticeFilter_Validate(t *testing.T) {
+	f := NoticeFilter{UserID: 1}
+	require.NoError(t, f.Validate())
+	require.Equal(t, DefaultPageLimit, f.Limit)
+
+	now := time.Now()
+	tests := []struct {
+		name   string
+		filter NoticeFilter
+		err    error
+	}{
+		{"limit too large", NoticeFilter{Limit: MaxPageLimit + 1}, ErrInvalidLimit},
+		{"negative offset", NoticeFilter{Offset: -1}, ErrInvalidOffset},
+		{"empty range", NoticeFilter{From: &now, To: &now}, ErrInvalidRange},
+		{"invalid status", NoticeFilter{Statuses: []Status{"unknown"}}, nil},
+		{"invalid order", NoticeFilter{Order: "unknown"}, ErrInvalidOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			require.Error(t, err)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNoticeFilter_PageDueAtAsc(t *testing.T) {
+	at := func(h int) *time.Time {
+		tm := time.Date(2026, 1, 1, h, 0, 0, 0, time.UTC)
+		return &tm
+	}
+	notices := []Notice{
+		{ID: 1, UserID: 1, SentAt: at(3)},
+		{ID: 2, UserID: 1, SentAt: at(1), DeferredUntil: at(4)},
+		{ID: 3, UserID: 1},
+		{ID: 4, UserID: 1, SentAt: at(2)},
+		{ID: 5, UserID: 1, SentAt: at(2)},
+		{ID: 6, UserID: 2, SentAt: at(1)},
+	}
+
+	f := NoticeFilter{UserID: 1, Order: OrderDueAtAsc, Limit: 3}
+	page := f.Page(notices)
+	require.Equal(t, 5, page.Total)
+	ids := make([]int, len(page.Notices))
+	for i, n := range page.Notices {
+		ids[i] = n.ID
+	}
+	require.Equal(t, []int{4, 5, 1}, ids)
+
+	f.Offset = 3
+	page = f.Page(notices)
+	require.Len(t, page.Notices, 2)
+	require.Equal(t, 2, page.Notices[0].ID)
+	require.Equal(t, 3, page.Notices[1].ID)
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrEmptyPatch      = errors.New("patch must change sent_at, message or channels")
+	ErrEmptyMessage    = errors.New("message cannot be empty")
+	ErrRecurringSentAt = errors.New("sent_at of a recurring notice cannot be changed")
+	ErrSentAtInThePast = errors.New("sent_at must be in the future")
+)
+
+// PatchNotice changes a scheduled notice, nil fields are kept
+type PatchNotice struct {
+	SentAt *time.Time `json:"sent_at"`
+	// Message replaces the message or template of the notice
+	Message  *string  `json:"message"`
+	Channels Channels `json:"channels"`
+}
+
+func (p *PatchNotice) Validate() error {
+	if p.SentAt == nil && p.Message == nil && p.Channels == nil {
+		return ErrEmptyPatch
+	}
+	if p.Message != nil && *p.Message == "" {
+		return ErrEmptyMessage
+	}
+	if p.Channels != nil {
+		if err := p.Channels.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply changes the notice and moves it to the next version,
+// so that copies of the previous version published already are not sent
+func (p *PatchNotice) Apply(n *Notice, now time.Time) error {
+	if p.SentAt != nil {
+		if n.Recurrence != nil {
+			return ErrRecurringSentAt
+		}
+		if !p.SentAt.After(now) {
+			return ErrSentAtInThePast
+		}
+		sentAt := *p.SentAt
+		n.SentAt = &sentAt
+		n.DeferredUntil = nil
+	}
+	if p.Message != nil {
+		n.Message = *p.Message
+		n.Template = nil
+	}
+	if p.Channels != nil {
+		n.Channels = p.Channels
+		// drops the deliveries of the removed channels kept by a deferred notice
+		if n.Deliveries != nil {
+			n.PrepareDeliveries()
+		}
+	}
+	n.Version++
+	return nil
+}
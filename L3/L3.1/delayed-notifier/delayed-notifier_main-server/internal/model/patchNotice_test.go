@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchNotice_Apply(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	sentAt := now.Add(time.Hour)
+	deferredUntil := now.Add(2 * time.Hour)
+	newNotice := func() *Notice {
+		return &Notice{
+			Message:       "old",
+			Template:      &TemplateRef{Name: "reminder"},
+			Channels:      Channels{{Type: ChannelEmail, Value: "user@example.com"}, {Type: ChannelTelegram, Value: "user"}},
+			SentAt:        &sentAt,
+			DeferredUntil: &deferredUntil,
+			Deliveries:    Deliveries{{Channel: ChannelEmail, Recipient: "user@example.com", Status: DeliverySent}, {Channel: ChannelTelegram, Recipient: "user", Status: DeliveryDeferred}},
+		}
+	}
+
+	message := "new"
+	later := now.Add(3 * time.Hour)
+	n := newNotice()
+	require.NoError(t, (&PatchNotice{SentAt: &later, Message: &message}).Apply(n, now))
+	require.Equal(t, 1, n.Version)
+	require.Equal(t, later, *n.SentAt)
+	require.Nil(t, n.DeferredUntil, "the new sent_at replaces the deferral")
+	require.Equal(t, later, n.DueAt())
+	require.Equal(t, "new", n.Message)
+	require.Nil(t, n.Template)
+
+	n = newNotice()
+	require.NoError(t, (&PatchNotice{Channels: Channels{{Type: ChannelTelegram, Value: "user"}}}).Apply(n, now))
+	require.Equal(t, Deliveries{{Channel: ChannelTelegram, Recipient: "user", Status: DeliveryDeferred}}, n.Deliveries)
+	require.Equal(t, deferredUntil, n.DueAt())
+
+	earlier := now.Add(-time.Minute)
+	require.ErrorIs(t, (&PatchNotice{SentAt: &earlier}).Apply(newNotice(), now), ErrSentAtInThePast)
+
+	recurring := newNotice()
+	recurring.Recurrence = &Recurrence{Cron: "@daily"}
+	require.ErrorIs(t, (&PatchNotice{SentAt: &later}).Apply(recurring, now), ErrRecurringSentAt)
+}
+
+func TestPatchNotice_Validate(t *testing.T) {
+	empty := ""
+	require.ErrorIs(t, (&PatchNotice{}).Validate(), ErrEmptyPatch)
+	require.ErrorIs(t, (&PatchNotice{Message: &empty}).Validate(), ErrEmptyMessage)
+	require.Error(t, (&PatchNotice{Channels: Channels{{Type: "pigeon", Value: "x"}}}).Validate())
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrEmptyQuietHours = errors.New("quiet hours start and end must differ")
+
+// Preferences are the delivery preferences of a user
+type Preferences struct {
+	UserID int `json:"user_id"`
+	// Timezone is an IANA time zone of the user's local times, UTC if empty
+	Timezone   string      `json:"timezone,omitempty"`
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// QuietHours is a daily window in which notices are deferred to its end.
+// The window spans midnight if End is before Start
+type QuietHours struct {
+	// Start and End are local times HH:MM
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+	// Timezone is an IANA time zone, UTC if empty
+	Timezone string `json:"timezone,omitempty"`
+}
+
+func (p *Preferences) Validate() error {
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	if p.QuietHours != nil {
+		return p.QuietHours.Validate()
+	}
+	return nil
+}
+
+// Location returns the time zone of the user, UTC if it is not set or invalid
+func (p *Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (q *QuietHours) Validate() error {
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+	if start == end {
+		return ErrEmptyQuietHours
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("invalid quiet hours timezone: %w", err)
+	}
+	return nil
+}
+
+// Until returns the end of the window if t is in the quiet hours
+func (q *QuietHours) Until(t time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := t.In(loc)
+	y, m, d := local.Date()
+	at := func(day int, clock time.Duration) time.Time {
+		return time.Date(y, m, day, int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, loc)
+	}
+
+	now := local.Sub(at(d, 0))
+	switch {
+	case start < end && now >= start && now < end:
+		return at(d, end), true
+	case start > end && now >= start:
+		return at(d+1, end), true
+	case start > end && now < end:
+		return at(d, end), true
+	}
+	return time.Time{}, false
+}
+
+// parseClock parses HH:MM into the time since midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHours_Until(t *testing.T) {
+	moscow := mustLocation(t, "Europe/Moscow")
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 0, 0, moscow)
+	}
+
+	tests := []struct {
+		name      string
+		q         QuietHours
+		now       time.Time
+		want      time.Time
+		wantQuiet bool
+	}{
+		{"same day inside", QuietHours{Start: "13:00", End: "14:00", Timezone: "Europe/Moscow"}, at(10, 13, 30), at(10, 14, 0), true},
+		{"same day end is not quiet", QuietHours{Start: "13:00", End: "14:00", Timezone: "Europe/Moscow"}, at(10, 14, 0), time.Time{}, false},
+		{"overnight before midnight", QuietHours{Start: "22:00", End: "08:00", Timezone: "Europe/Moscow"}, at(10, 23, 0), at(11, 8, 0), true},
+		{"overnight after midnight", QuietHours{Start: "22:00", End: "08:00", Timezone: "Europe/Moscow"}, at(11, 7, 59), at(11, 8, 0), true},
+		{"overnight outside", QuietHours{Start: "22:00", End: "08:00", Timezone: "Europe/Moscow"}, at(10, 12, 0), time.Time{}, false},
+		// 20:00 UTC is 23:00 in Moscow
+		{"timezone of the user", QuietHours{Start: "22:00", End: "08:00", Timezone: "Europe/Moscow"}, time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC), at(11, 8, 0), true},
+		{"utc by default", QuietHours{Start: "22:00", End: "08:00"}, time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.q.Validate())
+			until, quiet := tt.q.Until(tt.now)
+			require.Equal(t, tt.wantQuiet, quiet)
+			require.True(t, tt.want.Equal(until), "want %v, got %v", tt.want, until)
+		})
+	}
+}
+
+func TestQuietHours_Validate(t *testing.T) {
+	require.Error(t, (&QuietHours{Start: "25:00", End: "08:00"}).Validate())
+	require.Error(t, (&QuietHours{Start: "22:00", End: "8"}).Validate())
+	require.ErrorIs(t, (&QuietHours{Start: "22:00", End: "22:00"}).Validate(), ErrEmptyQuietHours)
+	require.Error(t, (&QuietHours{Start: "22:00", End: "08:00", Timezone: "Mars/Olympus"}).Validate())
+}
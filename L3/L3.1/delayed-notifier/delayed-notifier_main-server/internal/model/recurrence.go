@@ -0,0 +1,130 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/teambition/rrule-go"
+)
+
+var (
+	ErrRecurrenceRule  = errors.New("recurrence must have exactly one of cron and rrule")
+	ErrRecurrenceCount = errors.New("recurrence count cannot be negative")
+	ErrNoOccurrences   = errors.New("recurrence has no occurrences")
+)
+
+// Recurrence repeats a notice by a cron expression or an RRULE (RFC 5545)
+type Recurrence struct {
+	// Cron is a standard 5-field expression or a descriptor like @daily
+	Cron string `json:"cron,omitempty"`
+	// RRule is a rule without DTSTART, e.g. FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0;BYSECOND=0
+	RRule string `json:"rrule,omitempty"`
+	// Timezone is an IANA name the rule is evaluated in, UTC by default
+	Timezone string `json:"timezone,omitempty"`
+	// Until is the time after which there are no occurrences
+	Until *time.Time `json:"until,omitempty"`
+	// Count is the number of occurrences, unlimited if zero
+	Count int `json:"count,omitempty"`
+	// SkipMissed skips occurrences that are missed because a later one is already due,
+	// e.g. after downtime, instead of sending all of them late
+	SkipMissed bool `json:"skip_missed,omitempty"`
+	// Start is the time the series starts from, DTSTART of the RRULE
+	Start time.Time `json:"start"`
+}
+
+// OccurrenceResult is the outcome of a past occurrence of a recurring notice
+type OccurrenceResult struct {
+	Occurrence int        `json:"occurrence"`
+	SentAt     time.Time  `json:"sent_at"`
+	Status     Status     `json:"status"`
+	Deliveries Deliveries `json:"deliveries,omitempty"`
+}
+
+func (r *Recurrence) Validate() error {
+	if (r.Cron == "") == (r.RRule == "") {
+		return ErrRecurrenceRule
+	}
+	if r.Count < 0 {
+		return ErrRecurrenceCount
+	}
+	_, err := r.schedule()
+	return err
+}
+
+func (r *Recurrence) location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", r.Timezone, err)
+	}
+	return loc, nil
+}
+
+// schedule returns a function that returns the first occurrence after t
+// or the zero time if there is none
+func (r *Recurrence) schedule() (func(t time.Time) time.Time, error) {
+	loc, err := r.location()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Cron != "" {
+		sched, err := cron.ParseStandard(r.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron %q: %w", r.Cron, err)
+		}
+		return func(t time.Time) time.Time {
+			return sched.Next(t.In(loc))
+		}, nil
+	}
+
+	opt, err := rrule.StrToROption(r.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rrule %q: %w", r.RRule, err)
+	}
+	opt.Dtstart = r.Start.In(loc).Truncate(time.Second)
+	rule, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rrule %q: %w", r.RRule, err)
+	}
+	return func(t time.Time) time.Time {
+		return rule.After(t, false)
+	}, nil
+}
+
+// First returns the first occurrence at or after Start
+func (r *Recurrence) First() (time.Time, error) {
+	next, err := r.schedule()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	first := next(r.Start.Add(-time.Second))
+	if first.IsZero() || (r.Until != nil && first.After(*r.Until)) {
+		return time.Time{}, ErrNoOccurrences
+	}
+	return first, nil
+}
+
+// Next returns the occurrence following the occurrence number occurrence at t.
+// It returns false when the series is over
+func (r *Recurrence) Next(occurrence int, t time.Time) (time.Time, bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+
+	next, err := r.schedule()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	at := next(t)
+	if at.IsZero() || (r.Until != nil && at.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return at, true
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestRecurrence_Cron(t *testing.T) {
+	moscow := mustLocation(t, "Europe/Moscow")
+	r := &Recurrence{
+		Cron:     "0 9 * * 1-5",
+		Timezone: "Europe/Moscow",
+		// Friday
+		Start: time.Date(2025, 1, 10, 10, 0, 0, 0, moscow),
+	}
+	require.NoError(t, r.Validate())
+
+	first, err := r.First()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 13, 9, 0, 0, 0, moscow), first, "first occurrence is on Monday")
+
+	next, ok := r.Next(1, first)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2025, 1, 14, 9, 0, 0, 0, moscow), next)
+	require.Equal(t, 6, next.UTC().Hour())
+}
+
+func TestRecurrence_RRule(t *testing.T) {
+	r := &Recurrence{
+		RRule: "FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0;BYSECOND=0",
+		Count: 2,
+		Start: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, r.Validate())
+
+	first, err := r.First()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC), first)
+
+	next, ok := r.Next(1, first)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), next)
+
+	_, ok = r.Next(2, next)
+	require.False(t, ok, "series is over after count occurrences")
+}
+
+func TestRecurrence_Until(t *testing.T) {
+	until := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	r := &Recurrence{Cron: "@daily", Until: &until, Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	first, err := r.First()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), first, "start matching the rule is the first occurrence")
+
+	next, ok := r.Next(1, first)
+	require.True(t, ok)
+	_, ok = r.Next(2, next)
+	require.False(t, ok, "no occurrences after until")
+}
+
+func TestRecurrence_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Recurrence
+	}{
+		{"no rule", Recurrence{}},
+		{"both rules", Recurrence{Cron: "@daily", RRule: "FREQ=DAILY"}},
+		{"invalid cron", Recurrence{Cron: "61 * * * *"}},
+		{"invalid rrule", Recurrence{RRule: "FREQ=SOMETIMES"}},
+		{"invalid timezone", Recurrence{Cron: "@daily", Timezone: "Mars/Olympus"}},
+		{"negative count", Recurrence{Cron: "@daily", Count: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.r.Validate())
+		})
+	}
+}
+
+func TestNotice_Advance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	newNotice := func(skipMissed bool) *Notice {
+		sentAt := start
+		return &Notice{
+			SentAt:     &sentAt,
+			Status:     StatusSent,
+			Deliveries: Deliveries{{Channel: ChannelEmail, Status: DeliverySent}},
+			Recurrence: &Recurrence{Cron: "0 9 * * *", SkipMissed: skipMissed, Count: 10, Start: start},
+			Occurrence: 1,
+		}
+	}
+	// three days of downtime after the first occurrence
+	now := start.Add(72*time.Hour + time.Hour)
+
+	t.Run("catch up", func(t *testing.T) {
+		n := newNotice(false)
+		require.True(t, n.Advance(now))
+		require.Equal(t, 2, n.Occurrence)
+		require.Equal(t, start.Add(24*time.Hour), *n.SentAt)
+		require.Equal(t, StatusScheduled, n.Status)
+		require.Nil(t, n.Deliveries)
+		require.Equal(t, &OccurrenceResult{Occurrence: 1, SentAt: start, Status: StatusSent,
+			Deliveries: Deliveries{{Channel: ChannelEmail, Status: DeliverySent}}}, n.Previous)
+		require.False(t, n.Missed(now), "the occurrence caught up with is sent late")
+	})
+
+	t.Run("skip missed", func(t *testing.T) {
+		n := newNotice(true)
+		require.True(t, n.Missed(now))
+		require.True(t, n.Advance(now))
+		require.Equal(t, 4, n.Occurrence, "only the latest due occurrence is kept")
+		require.Equal(t, start.Add(72*time.Hour), *n.SentAt)
+		require.False(t, n.Missed(now))
+	})
+
+	t.Run("series over", func(t *testing.T) {
+		n := newNotice(false)
+		n.Occurrence = 10
+		require.False(t, n.Advance(now))
+		require.Equal(t, StatusSent, n.Status)
+		require.Nil(t, n.Previous)
+	})
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEmptyBatch    = errors.New("batch cannot be empty")
+	ErrTooLargeBatch = errors.New("too many notices in batch")
+)
+
+// ReqBatch creates several notices at once
+type ReqBatch struct {
+	Notices []ReqNotice `json:"notices" binding:"required"`
+}
+
+func (b *ReqBatch) Validate(maxSize int) error {
+	if len(b.Notices) == 0 {
+		return ErrEmptyBatch
+	}
+	if len(b.Notices) > maxSize {
+		return fmt.Errorf("%w: %d, max %d", ErrTooLargeBatch, len(b.Notices), maxSize)
+	}
+	return nil
+}
+
+// BatchResult is the result of one notice of a batch
+type BatchResult struct {
+	Index int    `json:"index"`
+	ID    int    `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
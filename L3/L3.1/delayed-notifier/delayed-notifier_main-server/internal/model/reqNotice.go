@@ -1,16 +1,39 @@
 package model
 
 import (
+	"errors"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
+var (
+	ErrEmptySentAt = errors.New("sent_at cannot be empty")
+	// ErrInvalidUserID is returned for a user ID of the HTTP API that is not positive,
+	// the negative user IDs own the notices created with the Telegram bot
+	ErrInvalidUserID = errors.New("user_id must be a positive integer")
+)
+
+// CheckAPIUserID checks a user ID received by the HTTP API
+func CheckAPIUserID(userID int) error {
+	if userID <= 0 {
+		return ErrInvalidUserID
+	}
+	return nil
+}
+
 type ReqNotice struct {
-	UserID   int        `json:"user_id" binding:"required,numeric"`
-	Message  string     `json:"message" binding:"required"`
-	Channels Channels   `json:"channels" binding:"required"`
-	SentAt   *time.Time `json:"sent_at" binding:"required"`
+	UserID  int    `json:"user_id" binding:"required,numeric"`
+	Message string `json:"message" binding:"required_without=Template"`
+	// Template renders the message per channel instead of Message
+	Template *TemplateRef `json:"template"`
+	Channels Channels     `json:"channels" binding:"required"`
+	// SentAt is required for a single notice, a recurring one
+	// starts at it or now if it is not set
+	SentAt     *time.Time  `json:"sent_at"`
+	Recurrence *Recurrence `json:"recurrence"`
+	// IdempotencyKey is the Idempotency-Key header of the request
+	IdempotencyKey string `json:"-"`
 }
 
 func (n *ReqNotice) Validate() error {
@@ -24,5 +47,16 @@ func (n *ReqNotice) Validate() error {
 		return err
 	}
 
+	if n.Recurrence == nil && n.SentAt == nil {
+		return ErrEmptySentAt
+	}
+
 	return nil
 }
+
+// ContentHash returns the hash of the request without its idempotency key
+func (n *ReqNotice) ContentHash() string {
+	req := *n
+	req.IdempotencyKey = ""
+	return contentHash(req)
+}
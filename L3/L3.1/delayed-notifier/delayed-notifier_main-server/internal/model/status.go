@@ -7,18 +7,31 @@ import (
 type Status string
 
 const (
-	StatusScheduled Status = "scheduled"
-	StatusPending   Status = "pending"
-	StatusSent      Status = "sent"
-	StatusFailed    Status = "failed"
-	StatusDeleted   Status = "deleted"
+	StatusScheduled     Status = "scheduled"
+	StatusPending       Status = "pending"
+	StatusSent          Status = "sent"
+	StatusPartiallySent Status = "partially_sent"
+	StatusFailed        Status = "failed"
+	StatusDeleted       Status = "deleted"
+	// StatusSkipped is the status of a skipped occurrence of a recurring notice
+	// or a notice every recipient of which already got it
+	StatusSkipped Status = "skipped"
 )
 
 func (s Status) Validate() error {
 	switch s {
-	case StatusScheduled, StatusPending, StatusSent, StatusFailed, StatusDeleted:
+	case StatusScheduled, StatusPending, StatusSent, StatusPartiallySent, StatusFailed, StatusDeleted, StatusSkipped:
 	default:
 		return errors.New("invalid status: " + string(s))
 	}
 	return nil
 }
+
+// IsFinal reports whether the notice was processed and will not be sent again
+func (s Status) IsFinal() bool {
+	switch s {
+	case StatusSent, StatusPartiallySent, StatusFailed, StatusSkipped:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultEmailSubject is the subject of e-mails without a template subject
+const DefaultEmailSubject = "delayed-notifier"
+
+var (
+	ErrInvalidTemplateName = errors.New("template name must be 1-64 letters, digits, '.', '_' or '-'")
+	ErrEmptyTemplate       = errors.New("template must have a telegram, email or text variant")
+	ErrEmptyTelegramText   = errors.New("telegram template text cannot be empty")
+	ErrEmptyEmailBody      = errors.New("email template must have an html or text body")
+)
+
+var templateNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// ParseMode is the Telegram formatting of a message
+type ParseMode string
+
+const (
+	ParseModeText       ParseMode = ""
+	ParseModeMarkdownV2 ParseMode = "MarkdownV2"
+	ParseModeHTML       ParseMode = "HTML"
+)
+
+func (m ParseMode) IsValid() bool {
+	switch m {
+	case ParseModeText, ParseModeMarkdownV2, ParseModeHTML:
+		return true
+	}
+	return false
+}
+
+// Template is a named message with a variant per channel
+type Template struct {
+	Name     string            `json:"name"`
+	Telegram *TelegramTemplate `json:"telegram,omitempty"`
+	Email    *EmailTemplate    `json:"email,omitempty"`
+	// Text is the plain text variant for the other channels (webhook, sms)
+	Text      string    `json:"text,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TelegramTemplate struct {
+	Text      string    `json:"text"`
+	ParseMode ParseMode `json:"parse_mode,omitempty"`
+}
+
+type EmailTemplate struct {
+	// Subject is DefaultEmailSubject if empty
+	Subject string `json:"subject,omitempty"`
+	HTML    string `json:"html,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// TemplateRef references a template from a notice
+type TemplateRef struct {
+	Name string         `json:"name" binding:"required"`
+	Vars map[string]any `json:"vars,omitempty"`
+}
+
+// RenderedMessage is a notice message rendered for each channel
+type RenderedMessage struct {
+	Telegram *TelegramMessage `json:"telegram,omitempty"`
+	Email    *EmailMessage    `json:"email,omitempty"`
+	Text     string           `json:"text,omitempty"`
+}
+
+type TelegramMessage struct {
+	Text      string    `json:"text"`
+	ParseMode ParseMode `json:"parse_mode,omitempty"`
+}
+
+type EmailMessage struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// PlainMessage is the message of a notice without a template
+func PlainMessage(message string) *RenderedMessage {
+	return &RenderedMessage{
+		Telegram: &TelegramMessage{Text: message},
+		Email:    &EmailMessage{Subject: DefaultEmailSubject, Text: message},
+		Text:     message,
+	}
+}
+
+func (t *Template) Validate() error {
+	if !templateNameRe.MatchString(t.Name) {
+		return ErrInvalidTemplateName
+	}
+	if t.Telegram == nil && t.Email == nil && t.Text == "" {
+		return ErrEmptyTemplate
+	}
+	if t.Telegram != nil {
+		if t.Telegram.Text == "" {
+			return ErrEmptyTelegramText
+		}
+		if !t.Telegram.ParseMode.IsValid() {
+			return fmt.Errorf("invalid telegram parse mode %q", t.Telegram.ParseMode)
+		}
+	}
+	if t.Email != nil && t.Email.HTML == "" && t.Email.Text == "" {
+		return ErrEmptyEmailBody
+	}
+	return nil
+}
+
+// Supports reports whether the template has a variant for the channel type
+func (t *Template) Supports(channel ChannelType) bool {
+	switch channel {
+	case ChannelTelegram:
+		return t.Telegram != nil
+	case ChannelEmail:
+		return t.Email != nil
+	}
+	return t.Text != ""
+}
@@ -21,8 +21,16 @@ var (
 	Logger    = emoji.Memo
 	EMail     = emoji.EMail
 	Telegram  = emoji.MobilePhoneWithArrow
+	Webhook   = emoji.Link
+	SMS       = emoji.MobilePhone
 	RabbitMQ  = emoji.RabbitFace
 	Redis     = emoji.FloppyDisk
+	Postgres  = emoji.Elephant
+	Storage   = emoji.FileCabinet
 	Retry     = emoji.RecyclingSymbol
+	Scheduler = emoji.AlarmClock
+	Consumer  = emoji.InboxTray
+	Guard     = emoji.Shield
+	Batch     = emoji.Package
 	Transport = emoji.Bus
 )
@@ -3,7 +3,10 @@ package pkgEmail
 import (
 	"crypto/tls"
 	"fmt"
+	"math/rand/v2"
+	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
@@ -32,29 +35,63 @@ func New(cfg *Config) (*Client, error) {
 	}, nil
 }
 
+// Message is an e-mail with a text body, an HTML body or both as alternatives
+type Message struct {
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []string
+}
+
 func (c *Client) SendEmail(to []string, subject, body string, isHTML bool, attachments ...string) error {
+	msg := Message{Subject: subject, Attachments: attachments}
+	if isHTML {
+		msg.HTML = body
+	} else {
+		msg.Text = body
+	}
+	_, err := c.Send(to, msg)
+	return err
+}
+
+// Send sends the e-mail and returns the Message-ID it was sent with
+func (c *Client) Send(to []string, m Message) (messageID string, err error) {
+	messageID = newMessageID(c.cfg.From)
 
 	msg := gomail.NewMessage()
 	c.lock.Lock()
+	msg.SetHeader("Message-ID", messageID)
 	msg.SetHeader("From", c.cfg.From)
 	msg.SetHeader("To", to...)
-	msg.SetHeader("Subject", subject)
+	msg.SetHeader("Subject", m.Subject)
 
-	if isHTML {
-		msg.SetBody("text/html", body)
-	} else {
-		msg.SetBody("text/plain", body)
+	switch {
+	case m.Text != "" && m.HTML != "":
+		msg.SetBody("text/plain", m.Text)
+		msg.AddAlternative("text/html", m.HTML)
+	case m.HTML != "":
+		msg.SetBody("text/html", m.HTML)
+	default:
+		msg.SetBody("text/plain", m.Text)
 	}
 
-	for _, a := range attachments {
+	for _, a := range m.Attachments {
 		msg.Attach(a)
 	}
 	c.lock.Unlock()
 
 	dialer := newDialer(c.cfg.SMTPHost, c.cfg.SMTPPort, c.cfg.Username, c.cfg.Password)
 	if err := dialer.DialAndSend(msg); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+		return "", fmt.Errorf("failed to send email: %w", err)
 	}
 
-	return nil
+	return messageID, nil
+}
+
+func newMessageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+	return fmt.Sprintf("<%d.%d@%s>", time.Now().UnixNano(), rand.Int64(), domain)
 }
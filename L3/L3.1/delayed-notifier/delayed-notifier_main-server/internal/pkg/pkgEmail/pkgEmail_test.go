@@ -2,6 +2,7 @@ package pkgEmail
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -99,3 +100,32 @@ func TestClient_SendEmail_Failure(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "smtp error")
 }
+
+func TestClient_Send_MessageID(t *testing.T) {
+	cfg := &Config{
+		SMTPHost: "smtp.example.com",
+		SMTPPort: 587,
+		Username: "user",
+		Password: "pass",
+		From:     "from@example.com",
+	}
+
+	client, _ := New(cfg)
+
+	var header []string
+	origNewDialer := newDialer
+	newDialer = func(_ string, _ int, _ string, _ string) Dialer {
+		return &mockDialer{
+			sendFunc: func(msgs ...*gomail.Message) error {
+				header = msgs[0].GetHeader("Message-ID")
+				return nil
+			},
+		}
+	}
+	defer func() { newDialer = origNewDialer }()
+
+	messageID, err := client.Send([]string{"to@example.com"}, Message{Subject: "subject", Text: "body", HTML: "<b>body</b>"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{messageID}, header)
+	assert.True(t, strings.HasSuffix(messageID, "@example.com>"))
+}
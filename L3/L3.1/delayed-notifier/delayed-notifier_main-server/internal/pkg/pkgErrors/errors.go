@@ -5,11 +5,26 @@ import (
 )
 
 var (
-	ErrContentTypeAJ  = errors.New("content type must be application/json")
-	ErrEmptyUserID    = errors.New("user_id must not be empty")
-	ErrEmptyID        = errors.New("id must not be empty")
-	ErrEmptyTitle     = errors.New("title must not be empty")
-	ErrEmptyDate      = errors.New("date must not be empty")
-	ErrUserNotFound   = errors.New("user id not found")
-	ErrNoticeNotFound = errors.New("notice not found")
+	ErrContentTypeAJ       = errors.New("content type must be application/json")
+	ErrEmptyUserID         = errors.New("user_id must not be empty")
+	ErrEmptyID             = errors.New("id must not be empty")
+	ErrEmptyTitle          = errors.New("title must not be empty")
+	ErrEmptyDate           = errors.New("date must not be empty")
+	ErrUserNotFound        = errors.New("user id not found")
+	ErrNoticeNotFound      = errors.New("notice not found")
+	ErrNotRecurring        = errors.New("notice is not recurring")
+	ErrTemplateNotFound    = errors.New("template not found")
+	ErrInvalidTemplate     = errors.New("invalid template")
+	ErrTemplateVars        = errors.New("template variables do not match the template")
+	ErrInvalidFilter       = errors.New("invalid notice filter")
+	ErrUnknownStorage      = errors.New("storage must be redis or postgres")
+	ErrPreferencesNotFound = errors.New("preferences not found")
+	ErrInvalidPreferences  = errors.New("invalid preferences")
+	ErrIdempotencyConflict = errors.New("idempotency key is already used with another request")
+	ErrIdempotencyPending  = errors.New("request with the idempotency key is in progress")
+	ErrNoticeNotScheduled  = errors.New("notice is not scheduled")
+	ErrNoticeConflict      = errors.New("notice was changed concurrently")
+	ErrInvalidPatch        = errors.New("invalid notice patch")
+	ErrInvalidBatch        = errors.New("invalid notice batch")
+	ErrUnknownTelegramMode = errors.New("telegram mode must be webhook or polling")
 )
@@ -1,30 +1,64 @@
 package pkgPostgres
 
-// func New(rd *pkgRedis.Client) (*Repository, error) {
-// masterDSN := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-// 	cfg.Postgres.Master.Host, cfg.Postgres.Master.Port, cfg.Postgres.Master.User, cfg.Postgres.Master.Password, cfg.Postgres.Master.DBName)
-
-// slave1DSN := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-// 	cfg.Postgres.Slave1.Host, cfg.Postgres.Slave1.Port, cfg.Postgres.Slave1.User, cfg.Postgres.Slave1.Password, cfg.Postgres.Slave1.DBName)
-
-// slave2DSN := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-// 	cfg.Postgres.Slave2.Host, cfg.Postgres.Slave2.Port, cfg.Postgres.Slave2.User, cfg.Postgres.Slave2.Password, cfg.Postgres.Slave2.DBName)
-
-// opts := &dbpg.Options{
-// 	MaxOpenConns:    cfg.Postgres.MaxOpenConns,
-// 	MaxIdleConns:    cfg.Postgres.MaxIdleConns,
-// 	ConnMaxLifetime: cfg.Postgres.ConnMaxLifetime,
-// }
-
-// db, err := dbpg.New(masterDSN, []string{slave1DSN, slave2DSN}, opts)
-// if err != nil {
-// 	return nil, fmt.Errorf("failed to create db: %w", err)
-// }
-
-// return &Repository{
-// 	Postgres: db,
-// }, nil
-// return &Repository{
-// postgres: postgres.New(),
-// }, nil
-// }
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wb-go/wbf/dbpg"
+)
+
+type Postgres struct {
+	DB *dbpg.DB
+}
+
+func (c dsnConfig) dsn() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		c.Host, c.Port, c.User, c.Password, c.DBName)
+}
+
+// New connects to the master and to the slaves with a port set,
+// reads are balanced between the slaves
+func New(cfg *Config) (*Postgres, error) {
+	var slaveDSNs []string
+	for _, slave := range []*dsnConfig{cfg.Slave1, cfg.Slave2} {
+		if slave != nil && slave.Port != 0 {
+			slaveDSNs = append(slaveDSNs, slave.dsn())
+		}
+	}
+
+	opts := &dbpg.Options{
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+	}
+
+	db, err := dbpg.New(cfg.Master.dsn(), slaveDSNs, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DB instance: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.Master.PingContext(ctx); err != nil {
+		return nil, errors.Join(fmt.Errorf("postgres ping failed: %w", err), closeDB(db))
+	}
+
+	return &Postgres{
+		DB: db,
+	}, nil
+}
+
+func (p *Postgres) Close() error {
+	return closeDB(p.DB)
+}
+
+func closeDB(db *dbpg.DB) error {
+	errs := []error{db.Master.Close()}
+	for _, slave := range db.Slaves {
+		errs = append(errs, slave.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -1,4 +1,4 @@
-// Package rabbitmq provides a wrapper for RabbitMQ with separate channels for publishing and consuming, DLX/DLQ, TTL, and worker-based DLQ consumer.
+// Package rabbitmq provides a wrapper for RabbitMQ with separate channels for publishing and consuming, DLX/DLQ, and worker-based DLQ consumer.
 package pkgRabbitmq
 
 import (
@@ -168,52 +168,32 @@ func (c *Client) Publish(msg Message) error {
 	)
 }
 
-// PublishStructWithTTL automatically serializes the structure to JSON and publishes the message.
-func (c *Client) PublishStructWithTTL(data interface{}, ttl time.Duration) error {
+// PublishReady serializes the structure to JSON and publishes it to the DLX,
+// so that it is routed straight to the DLQ and consumed without delay.
+// Delays are kept outside of RabbitMQ: it expires messages only at the head of a queue,
+// so a message with a long TTL would hold back the ones queued after it.
+func (c *Client) PublishReady(data interface{}) error {
 	if err := c.ensurePubChannel(); err != nil {
 		return fmt.Errorf("failed to ensure publish channel: %w", err)
 	}
 
-	if ttl <= 0 {
-		return fmt.Errorf("invalid TTL: must be positive, got %v", ttl)
-	}
-
 	body, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal struct: %w", err)
 	}
 
-	queueName := fmt.Sprintf("msg_%d", time.Now().UnixNano())
-
-	tempQueue, err := c.DeclareTempQueue(queueName, ttl)
-	if err != nil {
-		return fmt.Errorf("failed to declare temp queue: %w", err)
-	}
-
-	if err := c.pubChannel.QueueBind(
-		tempQueue,
-		tempQueue,
-		c.config.Exchange,
-		false,
-		nil,
-	); err != nil {
-		return fmt.Errorf("failed to bind temp queue %q: %w", tempQueue, err)
-	}
-
-	expiration := fmt.Sprintf("%d", ttl.Milliseconds())
-	err = c.pubChannel.Publish(
-		c.config.Exchange,
-		tempQueue,
+	if err := c.pubChannel.Publish(
+		c.config.DLX,
+		"",
 		false,
 		false,
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-			Expiration:  expiration,
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
 		},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to publish message to temp queue %q: %w", tempQueue, err)
+	); err != nil {
+		return fmt.Errorf("failed to publish message to DLX %q: %w", c.config.DLX, err)
 	}
 
 	return nil
@@ -233,39 +213,6 @@ func (c *Client) ensurePubChannel() error {
 	return nil
 }
 
-// DeclareTempQueue creates a temporary queue with a specific TTL and auto-delete flag.
-func (c *Client) DeclareTempQueue(name string, ttl time.Duration) (string, error) {
-	ms := ttl.Milliseconds()
-	if ms <= 0 {
-		return "", fmt.Errorf("invalid TTL: must be positive, got %d ms", ms)
-	}
-
-	exp := ms + 5000
-	if exp <= 0 {
-		return "", fmt.Errorf("invalid x-expires: overflow or negative value (%d)", exp)
-	}
-
-	args := amqp.Table{
-		"x-message-ttl":          int64(ms),
-		"x-expires":              int64(exp),
-		"x-dead-letter-exchange": c.config.DLX,
-	}
-
-	q, err := c.pubChannel.QueueDeclare(
-		name,
-		false,
-		true,
-		false,
-		false,
-		args,
-	)
-	if err != nil {
-		return "", fmt.Errorf("failed to declare temp queue %q: %w", name, err)
-	}
-
-	return q.Name, nil
-}
-
 // Ack acknowledges a message.
 func (c *Client) Ack(msg amqp.Delivery) error {
 	return msg.Ack(false)
@@ -64,6 +64,42 @@ func (c *Client) SetWithID(ctx context.Context, prefix string, value interface{}
 	return key, nil
 }
 
+// ReserveIDs reserves n sequential IDs of the prefix, the ones SetWithID uses,
+// and returns the first of them
+func (c *Client) ReserveIDs(ctx context.Context, prefix string, n int) (int, error) {
+	last, err := c.rdb.IncrBy(ctx, prefix+":next_id", int64(n)).Result()
+	if err != nil {
+		return 0, pkgErrors.Wrap(err, "reserve IDs in Redis")
+	}
+	return int(last) - n + 1, nil
+}
+
+// SetIndexedTx sets the keys and adds the members to the sorted sets of index
+// in one MULTI transaction, so either all of them are written or none
+func (c *Client) SetIndexedTx(ctx context.Context, values map[string]interface{}, index map[string]map[string]float64, ttl ...time.Duration) error {
+	t := c.ttl
+	if len(ttl) > 0 {
+		t = ttl[0]
+	}
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, t)
+		}
+		for key, members := range index {
+			zs := make([]redis.Z, 0, len(members))
+			for member, score := range members {
+				zs = append(zs, redis.Z{Score: score, Member: member})
+			}
+			pipe.ZAdd(ctx, key, zs...)
+		}
+		return nil
+	})
+	if err != nil {
+		return pkgErrors.Wrap(err, "set data to Redis in transaction")
+	}
+	return nil
+}
+
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
 	val, err := c.rdb.Get(ctx, key).Result()
 	if errors.Is(err, redis.Nil) {
@@ -87,6 +123,48 @@ func (c *Client) Del(ctx context.Context, keys ...string) error {
 	return nil
 }
 
+// DelIndexedTx deletes the keys and removes the members from the sorted sets of index
+// in one MULTI transaction. Like Del, it returns ErrNoticeNotFound if none of the keys existed
+func (c *Client) DelIndexedTx(ctx context.Context, keys []string, index map[string][]string) error {
+	var del *redis.IntCmd
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		del = pipe.Del(ctx, keys...)
+		for key, members := range index {
+			args := make([]interface{}, len(members))
+			for i, m := range members {
+				args[i] = m
+			}
+			pipe.ZRem(ctx, key, args...)
+		}
+		return nil
+	})
+	if err != nil {
+		return pkgErrors.Wrap(err, "delete data from Redis in transaction")
+	}
+	if del.Val() == 0 {
+		return pkgErrors.Wrap(pkgErrors.ErrNoticeNotFound, "delete data from Redis in transaction")
+	}
+	return nil
+}
+
+// MGet returns the values of the existing keys, the missing keys are left out
+func (c *Client) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	if len(keys) == 0 {
+		return map[string]string{}, nil
+	}
+	vals, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "get data from Redis")
+	}
+	values := make(map[string]string, len(keys))
+	for i, val := range vals {
+		if s, ok := val.(string); ok {
+			values[keys[i]] = s
+		}
+	}
+	return values, nil
+}
+
 func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
 	n, err := c.rdb.Exists(ctx, key).Result()
 	return n > 0, err
@@ -107,3 +185,245 @@ func (c *Client) HGet(ctx context.Context, key, field string) (string, error) {
 	}
 	return val, err
 }
+
+func (c *Client) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	return c.rdb.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZAddMany adds the members with their scores in one command
+func (c *Client) ZAddMany(ctx context.Context, key string, members map[string]float64) error {
+	zs := make([]redis.Z, 0, len(members))
+	for member, score := range members {
+		zs = append(zs, redis.Z{Score: score, Member: member})
+	}
+	return c.rdb.ZAdd(ctx, key, zs...).Err()
+}
+
+// ZRangeAfter returns up to limit members of the sorted set with score greater than after
+// by ascending score, so that paging by the last score does not depend on the ranks
+// of members removed in the meantime
+func (c *Client) ZRangeAfter(ctx context.Context, key string, after float64, limit int) ([]string, error) {
+	members, err := c.rdb.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min:   fmt.Sprintf("(%v", after),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "get sorted set range from Redis")
+	}
+	return members, nil
+}
+
+func (c *Client) ZRem(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return c.rdb.ZRem(ctx, key, args...).Err()
+}
+
+// claimByScoreScript moves up to ARGV[3] members with score <= ARGV[1] to score ARGV[2]
+// and returns them, so that concurrent callers never claim the same member twice
+var claimByScoreScript = redis.NewScript(`
+local members = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, member in ipairs(members) do
+	redis.call('ZADD', KEYS[1], ARGV[2], member)
+end
+return members
+`)
+
+// ClaimByScore atomically returns up to limit members of the sorted set with score <= maxScore
+// and moves them to newScore. A member that is not removed by the caller
+// becomes claimable again once newScore is reached
+func (c *Client) ClaimByScore(ctx context.Context, key string, maxScore, newScore float64, limit int) ([]string, error) {
+	members, err := claimByScoreScript.Run(ctx, c.rdb, []string{key}, maxScore, newScore, limit).StringSlice()
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "claim members by score in Redis")
+	}
+	return members, nil
+}
+
+// zremIfScoreScript removes member ARGV[1] only if its score is still ARGV[2]
+var zremIfScoreScript = redis.NewScript(`
+if tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1])) == tonumber(ARGV[2]) then
+	return redis.call('ZREM', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// ZRemIfScore removes the member of the sorted set only if it still has the score,
+// so that a member added again with another score in the meantime is kept
+func (c *Client) ZRemIfScore(ctx context.Context, key, member string, score float64) error {
+	if err := zremIfScoreScript.Run(ctx, c.rdb, []string{key}, member, score).Err(); err != nil {
+		return pkgErrors.Wrap(err, "remove member by score in Redis")
+	}
+	return nil
+}
+
+func (c *Client) SAdd(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return c.rdb.SAdd(ctx, key, args...).Err()
+}
+
+func (c *Client) SRem(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return c.rdb.SRem(ctx, key, args...).Err()
+}
+
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.rdb.SMembers(ctx, key).Result()
+}
+
+// Scan returns all keys matching the pattern iterating with SCAN
+func (c *Client) Scan(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := c.rdb.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, pkgErrors.Wrap(err, "scan keys in Redis")
+	}
+	return keys, nil
+}
+
+// setNXGetScript sets KEYS[1] to ARGV[1] with TTL ARGV[2] ms if it does not exist,
+// otherwise returns its value
+var setNXGetScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value then
+	return {0, value}
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return {1, ARGV[1]}
+`)
+
+// SetNXGet sets the key if it does not exist. It returns false
+// and the current value if the key exists
+func (c *Client) SetNXGet(ctx context.Context, key, value string, ttl time.Duration) (string, bool, error) {
+	res, err := setNXGetScript.Run(ctx, c.rdb, []string{key}, value, ttl.Milliseconds()).Slice()
+	if err != nil {
+		return "", false, pkgErrors.Wrap(err, "set data to Redis if not exists")
+	}
+	set, _ := res[0].(int64)
+	current, _ := res[1].(string)
+	return current, set == 1, nil
+}
+
+// delIfValueScript deletes KEYS[1] only if its value is still ARGV[1]
+var delIfValueScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// DelIfValue deletes the key only if it still has the value,
+// so that a key set again by another owner is kept
+func (c *Client) DelIfValue(ctx context.Context, key, value string) error {
+	if err := delIfValueScript.Run(ctx, c.rdb, []string{key}, value).Err(); err != nil {
+		return pkgErrors.Wrap(err, "delete data from Redis by value")
+	}
+	return nil
+}
+
+// setIfFieldsScript sets KEYS[1] to ARGV[1] with TTL ARGV[2] ms only if its current value
+// is a JSON object with the fields ARGV[3], ARGV[5]... equal to ARGV[4], ARGV[6]...
+var setIfFieldsScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return 0
+end
+local doc = cjson.decode(current)
+for i = 3, #ARGV, 2 do
+	if tostring(doc[ARGV[i]]) ~= ARGV[i + 1] then
+		return 0
+	end
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// SetIfFields sets the JSON value of the key only if the current value has the fields
+// with the string values. It returns false if the key does not exist or a field differs
+func (c *Client) SetIfFields(ctx context.Context, key string, value []byte, fields map[string]string, ttl ...time.Duration) (bool, error) {
+	t := c.ttl
+	if len(ttl) > 0 {
+		t = ttl[0]
+	}
+	args := []any{value, t.Milliseconds()}
+	for name, v := range fields {
+		args = append(args, name, v)
+	}
+	set, err := setIfFieldsScript.Run(ctx, c.rdb, []string{key}, args...).Int64()
+	if err != nil {
+		return false, pkgErrors.Wrap(err, "set data to Redis if fields match")
+	}
+	return set == 1, nil
+}
+
+// hGetOrIncrScript returns the field ARGV[1] of the hash KEYS[1], the field that
+// does not exist is set to the next value of the counter KEYS[2] first
+var hGetOrIncrScript = redis.NewScript(`
+local value = redis.call('HGET', KEYS[1], ARGV[1])
+if value then
+	return tonumber(value)
+end
+local next = redis.call('INCR', KEYS[2])
+redis.call('HSET', KEYS[1], ARGV[1], next)
+return next
+`)
+
+// HGetOrIncr returns the integer value of the hash field. The field that does not exist
+// is set to the next value of the counter, so every field gets its own value
+func (c *Client) HGetOrIncr(ctx context.Context, key, field, counterKey string) (int64, error) {
+	value, err := hGetOrIncrScript.Run(ctx, c.rdb, []string{key, counterKey}, field).Int64()
+	if err != nil {
+		return 0, pkgErrors.Wrap(err, "get or increment hash field in Redis")
+	}
+	return value, nil
+}
+
+// takeTokenScript takes a token from the bucket KEYS[1] of ARGV[1] tokens
+// refilled in ARGV[2] ms at ARGV[3] ms. It returns 0 if the token is taken,
+// otherwise the time in ms until a token is available
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+local rate = capacity / interval
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], interval)
+return wait
+`)
+
+// TakeToken takes a token from the token bucket of capacity tokens refilled
+// evenly over interval. It returns 0 if the token is taken, otherwise
+// the time until a token is available
+func (c *Client) TakeToken(ctx context.Context, key string, capacity int, interval time.Duration, now time.Time) (time.Duration, error) {
+	wait, err := takeTokenScript.Run(ctx, c.rdb, []string{key}, capacity, interval.Milliseconds(), now.UnixMilli()).Int64()
+	if err != nil {
+		return 0, pkgErrors.Wrap(err, "take token from Redis")
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
@@ -0,0 +1,42 @@
+package pkgSMS
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type Config struct {
+	URL     string
+	Token   string
+	From    string
+	Timeout time.Duration
+}
+
+func NewConfig(cfg *config.Config) *Config {
+	timeout := cfg.GetDuration("sms.timeout")
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Config{
+		URL:     cfg.GetString("sms.url"),
+		Token:   cfg.GetString("sms.token"),
+		From:    cfg.GetString("sms.from"),
+		Timeout: timeout,
+	}
+}
+
+func (c Config) String() string {
+	var token string
+	if len(c.Token) > 0 {
+		token = "***hidden***"
+	}
+	return fmt.Sprintf(`sms:
+  %s: %s, %s: %s
+  %s: %s, %s: %v`,
+		"url", c.URL, "token", token,
+		"from", c.From, "timeout", c.Timeout)
+}
@@ -0,0 +1,98 @@
+// Package pkgSMS sends SMS through a generic HTTP gateway.
+//
+// The message is posted to the gateway URL as JSON {"from", "to", "text"}
+// with the token in the Authorization: Bearer header. The gateway responds
+// with a 2xx status and JSON {"id"} holding the ID of the message
+package pkgSMS
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var ErrEmptyURL = errors.New("sms gateway url must not be empty")
+
+// StatusError is a non-2xx response of the gateway
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sms gateway responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request may succeed if repeated:
+// server errors, 408 Request Timeout and 429 Too Many Requests
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+}
+
+type request struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+type response struct {
+	ID string `json:"id"`
+}
+
+type Client struct {
+	http  *http.Client
+	url   string
+	token string
+	from  string
+}
+
+func New(cfg *Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, ErrEmptyURL
+	}
+	return &Client{
+		http:  &http.Client{Timeout: cfg.Timeout},
+		url:   cfg.URL,
+		token: cfg.Token,
+		from:  cfg.From,
+	}, nil
+}
+
+// Send sends the text to the phone number and returns the gateway message ID
+func (c *Client) Send(ctx context.Context, to, text string) (messageID string, err error) {
+	body, err := json.Marshal(request{From: c.from, To: to, Text: text})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var res response
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("decode response: %w", err)
+	}
+
+	return res.ID, nil
+}
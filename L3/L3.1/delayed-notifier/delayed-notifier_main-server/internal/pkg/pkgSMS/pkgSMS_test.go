@@ -0,0 +1,54 @@
+package pkgSMS
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Send(t *testing.T) {
+	var got request
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"id":"sms-42"}`))
+	}))
+	defer srv.Close()
+
+	client, err := New(&Config{URL: srv.URL, Token: "token", From: "Notifier", Timeout: time.Second})
+	require.NoError(t, err)
+
+	messageID, err := client.Send(context.Background(), "+79990000000", "hello")
+	require.NoError(t, err)
+	require.Equal(t, "sms-42", messageID)
+	require.Equal(t, "Bearer token", auth)
+	require.Equal(t, request{From: "Notifier", To: "+79990000000", Text: "hello"}, got)
+}
+
+func TestClient_Send_Status(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "invalid number", http.StatusUnprocessableEntity)
+	}))
+	defer srv.Close()
+
+	client, err := New(&Config{URL: srv.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	_, err = client.Send(context.Background(), "+1", "hello")
+	var se *StatusError
+	require.True(t, errors.As(err, &se))
+	require.Equal(t, http.StatusUnprocessableEntity, se.StatusCode)
+	require.False(t, se.Temporary())
+}
+
+func TestNew_EmptyURL(t *testing.T) {
+	_, err := New(&Config{})
+	require.ErrorIs(t, err, ErrEmptyURL)
+}
@@ -1,34 +1,91 @@
 package pkgTelegram
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// allowedUpdates are the update types handled by the bot
+var allowedUpdates = []string{"message", "callback_query"}
+
 type Client struct {
-	bot *tgbotapi.BotAPI
+	bot      *tgbotapi.BotAPI
+	endpoint string
+	http     *http.Client
 }
 
 func New(cfg *Config) (*Client, error) {
-	bot, err := tgbotapi.NewBotAPI(cfg.Token)
+	endpoint := cfg.APIEndpoint
+	if endpoint == "" {
+		endpoint = tgbotapi.APIEndpoint
+	}
+
+	httpClient := &http.Client{}
+	bot, err := tgbotapi.NewBotAPIWithClient(cfg.Token, endpoint, httpClient)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{bot: bot}, nil
+	return &Client{bot: bot, endpoint: endpoint, http: httpClient}, nil
 }
 
 func (c *Client) SendTo(chatID int64, message string) error {
+	_, err := c.Send(chatID, message, "")
+	return err
+}
+
+// Send sends the message formatted with parseMode (MarkdownV2, HTML or "" for plain text)
+// and returns its Telegram message ID
+func (c *Client) Send(chatID int64, message string, parseMode string) (messageID int, err error) {
 	msg := tgbotapi.NewMessage(chatID, message)
-	_, err := c.bot.Send(msg)
+	msg.ParseMode = parseMode
+	sent, err := c.bot.Send(msg)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return sent.MessageID, nil
+}
+
+// SendKeyboard sends the plain text message with the inline keyboard
+// and returns its Telegram message ID
+func (c *Client) SendKeyboard(chatID int64, message string, keyboard tgbotapi.InlineKeyboardMarkup) (messageID int, err error) {
+	msg := tgbotapi.NewMessage(chatID, message)
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	sent, err := c.bot.Send(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	return sent.MessageID, nil
+}
+
+// EditKeyboard replaces the text and the inline keyboard of the sent message,
+// the keyboard is removed if it has no buttons
+func (c *Client) EditKeyboard(chatID int64, messageID int, message string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, message)
+	if len(keyboard.InlineKeyboard) > 0 {
+		edit.ReplyMarkup = &keyboard
+	}
+	_, err := c.bot.Request(edit)
+	return err
+}
+
+// AnswerCallback stops the loading indicator of the pressed inline button
+// and shows the text to the user
+func (c *Client) AnswerCallback(callbackID string, text string) error {
+	_, err := c.bot.Request(tgbotapi.NewCallback(callbackID, text))
+	return err
 }
 
 func (c *Client) SendToMany(chatIDs []int64, message string) error {
@@ -75,6 +132,55 @@ func (c *Client) SetWebhook(url string) error {
 	return nil
 }
 
+// DeleteWebhook removes the webhook, getUpdates fails while it is set.
+// The pending updates are kept
+func (c *Client) DeleteWebhook() error {
+	if _, err := c.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	return nil
+}
+
+// GetUpdates waits up to timeout for the updates with IDs starting from offset.
+// Requesting an offset confirms the updates before it, Telegram does not return them again.
+// Unlike the tgbotapi one, the request is cancelled with ctx
+func (c *Client) GetUpdates(ctx context.Context, offset int, timeout time.Duration) ([]tgbotapi.Update, error) {
+	allowed, err := json.Marshal(allowedUpdates)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("timeout", strconv.Itoa(int(timeout/time.Second)))
+	params.Set("allowed_updates", string(allowed))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(c.endpoint, c.bot.Token, "getUpdates"), strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create getUpdates request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get updates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp tgbotapi.APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("decode getUpdates response: %w", err)
+	}
+	if !apiResp.Ok {
+		return nil, fmt.Errorf("get updates: %d %s", apiResp.ErrorCode, apiResp.Description)
+	}
+
+	var updates []tgbotapi.Update
+	if err := json.Unmarshal(apiResp.Result, &updates); err != nil {
+		return nil, fmt.Errorf("decode updates: %w", err)
+	}
+	return updates, nil
+}
+
 func (c *Client) HandleStart(chatID int64, message string) error {
 	if strings.HasPrefix(message, "/start") {
 		if err := c.SendTo(chatID, "Telegram successfully linked!"); err != nil {
@@ -0,0 +1,59 @@
+package pkgWebhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type Config struct {
+	Secret  string
+	Timeout time.Duration
+	// Hosts restricts the endpoints, the hosts lists are comma-separated in the config
+	Hosts HostPolicy
+}
+
+func NewConfig(cfg *config.Config) *Config {
+	timeout := cfg.GetDuration("webhook.timeout")
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Config{
+		Secret:  cfg.GetString("webhook.secret"),
+		Timeout: timeout,
+		Hosts: HostPolicy{
+			AllowedHosts: splitList(cfg.GetString("webhook.allowed_hosts")),
+			DeniedHosts:  splitList(cfg.GetString("webhook.denied_hosts")),
+			AllowPrivate: cfg.GetBool("webhook.allow_private"),
+		},
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (c Config) String() string {
+	var secret string
+	if len(c.Secret) > 0 {
+		secret = "***hidden***"
+	}
+	return fmt.Sprintf(`webhook:
+  %s: %s, %s: %v
+  %s: %v, %s: %v, %s: %v`,
+		"secret", secret,
+		"timeout", c.Timeout,
+		"allowed_hosts", c.Hosts.AllowedHosts,
+		"denied_hosts", c.Hosts.DeniedHosts,
+		"allow_private", c.Hosts.AllowPrivate)
+}
@@ -0,0 +1,128 @@
+// Package pkgWebhook posts signed JSON payloads to HTTP endpoints.
+//
+// Each request carries the headers:
+//
+//	X-Notifier-Delivery:  ID of the delivery, the same in the repeated requests
+//	X-Notifier-Timestamp: unix time of the request
+//	X-Notifier-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" with the secret>
+//
+// Receivers check the signature with Sign, reject stale timestamps and drop
+// the deliveries they have already handled.
+//
+// The endpoints are restricted by HostPolicy: by default the client does not
+// connect to loopback, private and link-local addresses.
+package pkgWebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderDelivery  = "X-Notifier-Delivery"
+	HeaderTimestamp = "X-Notifier-Timestamp"
+	HeaderSignature = "X-Notifier-Signature"
+)
+
+var ErrEmptySecret = errors.New("webhook secret must not be empty")
+
+// StatusError is a non-2xx response of the endpoint
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request may succeed if repeated:
+// server errors, 408 Request Timeout and 429 Too Many Requests
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+}
+
+type Client struct {
+	http   *http.Client
+	secret []byte
+	hosts  HostPolicy
+}
+
+func New(cfg *Config) (*Client, error) {
+	if cfg.Secret == "" {
+		return nil, ErrEmptySecret
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout, ControlContext: cfg.Hosts.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// the policy checks the address of the endpoint, a proxy would hide it
+	transport.Proxy = nil
+
+	return &Client{
+		http: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+			// a redirect could lead to a host the policy denies
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		secret: []byte(cfg.Secret),
+		hosts:  cfg.Hosts,
+	}, nil
+}
+
+// CheckURL checks that the client may post to url
+func (c *Client) CheckURL(url string) error {
+	return c.hosts.CheckURL(url)
+}
+
+// Sign returns the value of the signature header for the body sent at timestamp
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the JSON body to url. The delivery ID must be the same
+// when the delivery is repeated
+func (c *Client) Send(ctx context.Context, url, deliveryID string, body []byte) error {
+	if err := c.hosts.CheckURL(url); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(c.secret, timestamp, body))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package pkgWebhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Send(t *testing.T) {
+	secret := "secret"
+	body := []byte(`{"notice_id":1}`)
+
+	var got *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client, err := New(&Config{Secret: secret, Timeout: time.Second, Hosts: HostPolicy{AllowPrivate: true}})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Send(context.Background(), srv.URL, "delivery-1", body))
+
+	require.Equal(t, http.MethodPost, got.Method)
+	require.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	require.Equal(t, "delivery-1", got.Header.Get(HeaderDelivery))
+	require.Equal(t, body, gotBody)
+	require.Equal(t, Sign([]byte(secret), got.Header.Get(HeaderTimestamp), gotBody), got.Header.Get(HeaderSignature))
+	require.NotEqual(t, Sign([]byte("other"), got.Header.Get(HeaderTimestamp), gotBody), got.Header.Get(HeaderSignature))
+}
+
+func TestClient_Send_Status(t *testing.T) {
+	tests := []struct {
+		status    int
+		temporary bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusGone, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusBadGateway, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "nope", tt.status)
+			}))
+			defer srv.Close()
+
+			client, err := New(&Config{Secret: "secret", Timeout: time.Second, Hosts: HostPolicy{AllowPrivate: true}})
+			require.NoError(t, err)
+
+			err = client.Send(context.Background(), srv.URL, "delivery-1", []byte(`{}`))
+			var se *StatusError
+			require.True(t, errors.As(err, &se))
+			require.Equal(t, tt.status, se.StatusCode)
+			require.Equal(t, tt.temporary, se.Temporary())
+		})
+	}
+}
+
+func TestClient_Send_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	client, err := New(&Config{Secret: "secret", Timeout: 50 * time.Millisecond, Hosts: HostPolicy{AllowPrivate: true}})
+	require.NoError(t, err)
+
+	require.Error(t, client.Send(context.Background(), srv.URL, "delivery-1", []byte(`{}`)))
+}
+
+func TestNew_EmptySecret(t *testing.T) {
+	_, err := New(&Config{Timeout: time.Second})
+	require.ErrorIs(t, err, ErrEmptySecret)
+}
+
+func TestHostPolicy_CheckURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy HostPolicy
+		url    string
+		err    error
+	}{
+		{"public host", HostPolicy{}, "https://example.com/hook", nil},
+		{"public ip", HostPolicy{}, "http://93.184.216.34/hook", nil},
+		{"relative url", HostPolicy{}, "/hook", ErrInvalidURL},
+		{"ftp url", HostPolicy{}, "ftp://example.com", ErrInvalidURL},
+		{"loopback", HostPolicy{}, "http://127.0.0.1:8080/hook", ErrHostNotAllowed},
+		{"loopback ipv6", HostPolicy{}, "http://[::1]/hook", ErrHostNotAllowed},
+		{"mapped loopback", HostPolicy{}, "http://[::ffff:127.0.0.1]/hook", ErrHostNotAllowed},
+		{"localhost", HostPolicy{}, "http://localhost/hook", ErrHostNotAllowed},
+		{"link-local", HostPolicy{}, "http://169.254.169.254/latest/meta-data", ErrHostNotAllowed},
+		{"private", HostPolicy{}, "http://10.0.0.5/hook", ErrHostNotAllowed},
+		{"unspecified", HostPolicy{}, "http://0.0.0.0/hook", ErrHostNotAllowed},
+		{"private allowed", HostPolicy{AllowPrivate: true}, "http://10.0.0.5/hook", nil},
+		{"denied host", HostPolicy{DeniedHosts: []string{"example.com"}}, "https://api.Example.com/hook", ErrHostNotAllowed},
+		{"allowed host", HostPolicy{AllowedHosts: []string{"hooks.example.com"}}, "https://hooks.example.com/a", nil},
+		{"not allowed host", HostPolicy{AllowedHosts: []string{"hooks.example.com"}}, "https://example.org/a", ErrHostNotAllowed},
+		{"allowed private host", HostPolicy{AllowedHosts: []string{"127.0.0.1"}}, "http://127.0.0.1/a", ErrHostNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.CheckURL(tt.url)
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestClient_Send_HostPolicy(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+
+	client, err := New(&Config{Secret: "secret", Timeout: time.Second})
+	require.NoError(t, err)
+
+	err = client.Send(context.Background(), srv.URL, "delivery-1", []byte(`{}`))
+	require.ErrorIs(t, err, ErrHostNotAllowed)
+	require.Zero(t, requests)
+
+	// a public host name resolving to a denied address is rejected on connect
+	require.ErrorIs(t, HostPolicy{}.control(context.Background(), "tcp", "127.0.0.1:80", nil), ErrHostNotAllowed)
+	require.NoError(t, HostPolicy{}.control(context.Background(), "tcp", "93.184.216.34:443", nil))
+}
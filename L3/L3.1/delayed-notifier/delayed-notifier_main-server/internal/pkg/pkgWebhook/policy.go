@@ -0,0 +1,115 @@
+package pkgWebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrInvalidURL     = errors.New("webhook url must be an absolute http or https url")
+	ErrHostNotAllowed = errors.New("webhook host is not allowed")
+)
+
+// nonPublicPrefixes are the non-public ranges not covered by the netip.Addr checks
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// HostPolicy restricts the endpoints the client posts to. The hosts of the
+// lists match themselves and their subdomains
+type HostPolicy struct {
+	// AllowedHosts if not empty, only these hosts are allowed
+	AllowedHosts []string
+	// DeniedHosts are never allowed
+	DeniedHosts []string
+	// AllowPrivate allows loopback, private, link-local and other non-public
+	// addresses, which are denied by default
+	AllowPrivate bool
+}
+
+// CheckURL checks that url is an absolute http or https url with an allowed host.
+// A host name resolving to a non-public address is rejected by the client on connect
+func (p HostPolicy) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	if matchHost(host, p.DeniedHosts) {
+		return fmt.Errorf("%w: %s is denied", ErrHostNotAllowed, host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchHost(host, p.AllowedHosts) {
+		return fmt.Errorf("%w: %s is not in the allowed hosts", ErrHostNotAllowed, host)
+	}
+
+	if p.AllowPrivate {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s is not a public host", ErrHostNotAllowed, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(addr)
+	}
+	return nil
+}
+
+// checkAddr checks the address the client connects to
+func (p HostPolicy) checkAddr(addr netip.Addr) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	addr = addr.Unmap().WithZone("")
+	if !isPublic(addr) {
+		return fmt.Errorf("%w: %s is not a public address", ErrHostNotAllowed, addr)
+	}
+	return nil
+}
+
+// control rejects the connections to the addresses denied by the policy,
+// including the ones a public host name resolves to
+func (p HostPolicy) control(_ context.Context, _, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	return p.checkAddr(addr)
+}
+
+func isPublic(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		h = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(h)), ".")
+		if h != "" && (host == h || strings.HasSuffix(host, "."+h)) {
+			return true
+		}
+	}
+	return false
+}
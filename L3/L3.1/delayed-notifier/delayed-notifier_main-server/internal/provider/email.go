@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgEmail"
+)
+
+// Email sends notices by e-mail, the recipient is the address
+type Email struct {
+	em *pkgEmail.Client
+}
+
+func NewEmail(em *pkgEmail.Client) *Email {
+	return &Email{em: em}
+}
+
+func (p *Email) Send(_ context.Context, env Envelope) (string, error) {
+	msg := env.Message.Email
+	if msg == nil {
+		return "", permanent(fmt.Errorf("no e-mail variant"))
+	}
+
+	return p.em.Send([]string{env.Recipient}, pkgEmail.Message{Subject: msg.Subject, Text: msg.Text, HTML: msg.HTML})
+}
+
+func (p *Email) ValidateRecipient(string) error {
+	return nil
+}
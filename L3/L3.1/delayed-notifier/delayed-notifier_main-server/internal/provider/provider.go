@@ -0,0 +1,90 @@
+// Package provider sends notices through the notification channels.
+//
+// Each channel type is served by a Provider registered in a Registry. Adding
+// a channel means implementing Provider and registering it at startup, the
+// registry is then asked by channel validation which types are valid
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+)
+
+// ErrPermanent marks a sending error that repeating the attempt will not fix
+var ErrPermanent = errors.New("permanent delivery error")
+
+// Envelope is a notice message addressed to one recipient of a channel
+type Envelope struct {
+	NoticeID   int
+	Occurrence int
+	Recipient  string
+	Message    *model.RenderedMessage
+}
+
+type Provider interface {
+	// Send sends the message and returns its ID assigned by the channel.
+	// Errors wrapping ErrPermanent are not retried
+	Send(ctx context.Context, env Envelope) (messageID string, err error)
+	// ValidateRecipient checks the channel value of a notice
+	ValidateRecipient(recipient string) error
+}
+
+// Registry maps channel types to their providers. Providers are registered
+// at startup, the registry is read-only afterwards
+type Registry struct {
+	providers map[model.ChannelType]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[model.ChannelType]Provider)}
+}
+
+// Register sets the provider of the channel type replacing the previous one
+func (r *Registry) Register(t model.ChannelType, p Provider) {
+	r.providers[t] = p
+}
+
+func (r *Registry) Provider(t model.ChannelType) (Provider, bool) {
+	p, ok := r.providers[t]
+	return p, ok
+}
+
+func (r *Registry) Supports(t model.ChannelType) bool {
+	_, ok := r.providers[t]
+	return ok
+}
+
+func (r *Registry) ValidateRecipient(t model.ChannelType, recipient string) error {
+	p, ok := r.providers[t]
+	if !ok {
+		return fmt.Errorf("%w: %s", model.ErrInvalidTypeBase, t)
+	}
+	return p.ValidateRecipient(recipient)
+}
+
+// Types returns the registered channel types sorted by name
+func (r *Registry) Types() []model.ChannelType {
+	types := make([]model.ChannelType, 0, len(r.providers))
+	for t := range r.providers {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+func permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
+// classify marks the errors of the clients reporting them as not temporary as permanent
+func classify(err error) error {
+	var tmp interface{ Temporary() bool }
+	if errors.As(err, &tmp) && !tmp.Temporary() {
+		return permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgSMS"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgWebhook"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/provider"
+)
+
+// newWebhook creates the webhook provider allowed to post to the local test servers
+func newWebhook(t *testing.T, secret string) *provider.Webhook {
+	t.Helper()
+	return newWebhookWithHosts(t, secret, pkgWebhook.HostPolicy{AllowPrivate: true})
+}
+
+func newWebhookWithHosts(t *testing.T, secret string, hosts pkgWebhook.HostPolicy) *provider.Webhook {
+	t.Helper()
+	wh, err := pkgWebhook.New(&pkgWebhook.Config{Secret: secret, Timeout: time.Second, Hosts: hosts})
+	require.NoError(t, err)
+	return provider.NewWebhook(wh)
+}
+
+func TestWebhook_Send(t *testing.T) {
+	secret := "secret"
+	var payload map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if pkgWebhook.Sign([]byte(secret), r.Header.Get(pkgWebhook.HeaderTimestamp), body) != r.Header.Get(pkgWebhook.HeaderSignature) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, json.Unmarshal(body, &payload))
+	}))
+	defer srv.Close()
+
+	env := provider.Envelope{NoticeID: 7, Occurrence: 2, Recipient: srv.URL, Message: model.PlainMessage("hello")}
+
+	deliveryID, err := newWebhook(t, secret).Send(context.Background(), env)
+	require.NoError(t, err)
+	require.NotEmpty(t, deliveryID)
+	require.Equal(t, float64(7), payload["notice_id"])
+	require.Equal(t, float64(2), payload["occurrence"])
+	require.Equal(t, "hello", payload["text"])
+
+	_, err = newWebhook(t, "wrong").Send(context.Background(), env)
+	require.ErrorIs(t, err, provider.ErrPermanent, "4xx is not retried")
+}
+
+func TestWebhook_Send_Temporary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, err := newWebhook(t, "secret").Send(context.Background(),
+		provider.Envelope{Recipient: srv.URL, Message: model.PlainMessage("hello")})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, provider.ErrPermanent, "5xx is retried")
+
+	_, err = newWebhook(t, "secret").Send(context.Background(),
+		provider.Envelope{Recipient: srv.URL, Message: &model.RenderedMessage{}})
+	require.ErrorIs(t, err, provider.ErrPermanent, "no text variant")
+}
+
+func TestWebhook_Send_SameDeliveryOnRetry(t *testing.T) {
+	var deliveries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries = append(deliveries, r.Header.Get(pkgWebhook.HeaderDelivery))
+		if len(deliveries) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	p := newWebhook(t, "secret")
+	env := provider.Envelope{NoticeID: 7, Occurrence: 2, Recipient: srv.URL, Message: model.PlainMessage("hello")}
+
+	_, err := p.Send(context.Background(), env)
+	require.Error(t, err)
+	deliveryID, err := p.Send(context.Background(), env)
+	require.NoError(t, err)
+	require.Equal(t, []string{deliveryID, deliveryID}, deliveries, "the retry repeats the delivery ID")
+
+	env.Occurrence = 3
+	nextID, err := p.Send(context.Background(), env)
+	require.NoError(t, err)
+	require.NotEqual(t, deliveryID, nextID, "the next occurrence is another delivery")
+}
+
+func TestWebhook_HostPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	p := newWebhookWithHosts(t, "secret", pkgWebhook.HostPolicy{DeniedHosts: []string{"internal.example.com"}})
+
+	require.NoError(t, p.ValidateRecipient("https://example.com/hook"))
+	for _, recipient := range []string{srv.URL, "http://169.254.169.254/latest", "https://api.internal.example.com/hook"} {
+		require.ErrorIs(t, p.ValidateRecipient(recipient), pkgWebhook.ErrHostNotAllowed, recipient)
+	}
+
+	_, err := p.Send(context.Background(), provider.Envelope{Recipient: srv.URL, Message: model.PlainMessage("hello")})
+	require.ErrorIs(t, err, provider.ErrPermanent, "a denied host is not retried")
+}
+
+func TestSMS_Send(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got["to"] == "+10000000000" {
+			http.Error(w, "blocked number", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"sms-1"}`))
+	}))
+	defer srv.Close()
+
+	sms, err := pkgSMS.New(&pkgSMS.Config{URL: srv.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	p := provider.NewSMS(sms)
+
+	messageID, err := p.Send(context.Background(), provider.Envelope{Recipient: "+79990000000", Message: model.PlainMessage("hello")})
+	require.NoError(t, err)
+	require.Equal(t, "sms-1", messageID)
+	require.Equal(t, "hello", got["text"])
+
+	_, err = p.Send(context.Background(), provider.Envelope{Recipient: "+10000000000", Message: model.PlainMessage("hello")})
+	require.ErrorIs(t, err, provider.ErrPermanent)
+}
+
+func TestRegistry(t *testing.T) {
+	sms, err := pkgSMS.New(&pkgSMS.Config{URL: "http://localhost", Timeout: time.Second})
+	require.NoError(t, err)
+
+	registry := provider.NewRegistry()
+	registry.Register(model.ChannelWebhook, newWebhookWithHosts(t, "secret", pkgWebhook.HostPolicy{}))
+	registry.Register(model.ChannelSMS, provider.NewSMS(sms))
+	require.Equal(t, []model.ChannelType{model.ChannelSMS, model.ChannelWebhook}, registry.Types())
+
+	model.SetChannelRegistry(registry)
+	t.Cleanup(func() {
+		builtin := provider.NewRegistry()
+		builtin.Register(model.ChannelEmail, provider.NewEmail(nil))
+		builtin.Register(model.ChannelTelegram, provider.NewTelegram(nil, nil))
+		model.SetChannelRegistry(builtin)
+	})
+
+	require.NoError(t, model.Channels{
+		{Type: model.ChannelWebhook, Value: "https://example.com/hook"},
+		{Type: model.ChannelSMS, Value: "+79990000000"},
+	}.Validate())
+
+	for name, ch := range map[string]model.ChannelInfo{
+		"unregistered type": {Type: model.ChannelEmail, Value: "test@example.com"},
+		"relative url":      {Type: model.ChannelWebhook, Value: "/hook"},
+		"ftp url":           {Type: model.ChannelWebhook, Value: "ftp://example.com"},
+		"loopback url":      {Type: model.ChannelWebhook, Value: "http://127.0.0.1:8080/hook"},
+		"phone without +":   {Type: model.ChannelSMS, Value: "89990000000"},
+	} {
+		require.Error(t, model.Channels{ch}.Validate(), name)
+	}
+	require.ErrorIs(t, model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}}.Validate(), model.ErrInvalidTypeBase)
+}
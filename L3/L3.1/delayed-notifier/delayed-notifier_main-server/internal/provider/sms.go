@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgSMS"
+)
+
+var ErrInvalidPhone = errors.New("sms recipient must be a phone number in E.164 format")
+
+var phoneRe = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// SMS sends notices through the SMS gateway, the recipient is the phone number
+type SMS struct {
+	sms *pkgSMS.Client
+}
+
+func NewSMS(sms *pkgSMS.Client) *SMS {
+	return &SMS{sms: sms}
+}
+
+func (p *SMS) Send(ctx context.Context, env Envelope) (string, error) {
+	if env.Message.Text == "" {
+		return "", permanent(fmt.Errorf("no text variant"))
+	}
+
+	messageID, err := p.sms.Send(ctx, env.Recipient, env.Message.Text)
+	if err != nil {
+		return "", classify(err)
+	}
+
+	return messageID, nil
+}
+
+func (p *SMS) ValidateRecipient(recipient string) error {
+	if !phoneRe.MatchString(recipient) {
+		return ErrInvalidPhone
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+)
+
+type iChatIDRepository interface {
+	LoadTelegramChatID(ctx context.Context, username string) (chatID int64, err error)
+}
+
+// Telegram sends notices to Telegram users who started the bot,
+// the recipient is the username
+type Telegram struct {
+	tg *pkgTelegram.Client
+	rp iChatIDRepository
+}
+
+func NewTelegram(tg *pkgTelegram.Client, rp iChatIDRepository) *Telegram {
+	return &Telegram{tg: tg, rp: rp}
+}
+
+func (p *Telegram) Send(ctx context.Context, env Envelope) (string, error) {
+	msg := env.Message.Telegram
+	if msg == nil {
+		return "", permanent(fmt.Errorf("no telegram variant"))
+	}
+
+	chatID, err := p.rp.LoadTelegramChatID(ctx, env.Recipient)
+	if err != nil {
+		return "", permanent(fmt.Errorf("load telegram chat id of %s: %w", env.Recipient, err))
+	}
+
+	messageID, err := p.tg.Send(chatID, msg.Text, string(msg.ParseMode))
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(messageID), nil
+}
+
+func (p *Telegram) ValidateRecipient(string) error {
+	return nil
+}
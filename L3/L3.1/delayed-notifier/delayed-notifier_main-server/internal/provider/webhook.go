@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgWebhook"
+)
+
+// webhookPayload is the JSON body posted to the webhook
+type webhookPayload struct {
+	NoticeID   int                    `json:"notice_id"`
+	Occurrence int                    `json:"occurrence,omitempty"`
+	Text       string                 `json:"text"`
+	Message    *model.RenderedMessage `json:"message"`
+	SentAt     time.Time              `json:"sent_at"`
+}
+
+// Webhook posts notices to HTTP endpoints signed with HMAC-SHA256,
+// the recipient is the endpoint url
+type Webhook struct {
+	wh *pkgWebhook.Client
+}
+
+func NewWebhook(wh *pkgWebhook.Client) *Webhook {
+	return &Webhook{wh: wh}
+}
+
+func (p *Webhook) Send(ctx context.Context, env Envelope) (string, error) {
+	if env.Message.Text == "" {
+		return "", permanent(fmt.Errorf("no text variant"))
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		NoticeID:   env.NoticeID,
+		Occurrence: env.Occurrence,
+		Text:       env.Message.Text,
+		Message:    env.Message,
+		SentAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return "", permanent(fmt.Errorf("marshal webhook payload: %w", err))
+	}
+
+	deliveryID := webhookDeliveryID(env)
+	if err := p.wh.Send(ctx, env.Recipient, deliveryID, body); err != nil {
+		if errors.Is(err, pkgWebhook.ErrHostNotAllowed) || errors.Is(err, pkgWebhook.ErrInvalidURL) {
+			return "", permanent(err)
+		}
+		return "", classify(err)
+	}
+
+	return deliveryID, nil
+}
+
+// ValidateRecipient checks that the recipient is an http or https url
+// with a host allowed by the webhook host policy
+func (p *Webhook) ValidateRecipient(recipient string) error {
+	return p.wh.CheckURL(recipient)
+}
+
+// webhookDeliveryID identifies the delivery of the notice occurrence to the endpoint,
+// the retries of the delivery carry the same ID, so the receiver can drop duplicates
+func webhookDeliveryID(env Envelope) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.Itoa(env.NoticeID)))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(env.Occurrence)))
+	h.Write([]byte{0})
+	h.Write([]byte(model.ChannelWebhook))
+	h.Write([]byte{0})
+	h.Write([]byte(env.Recipient))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
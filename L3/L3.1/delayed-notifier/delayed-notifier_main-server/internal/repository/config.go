@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const (
+	StorageRedis    = "redis"
+	StoragePostgres = "postgres"
+)
+
+type Config struct {
+	// Storage is the storage of the notices: redis or postgres
+	Storage string
+}
+
+func NewConfig(cfg *config.Config) *Config {
+	c := &Config{
+		Storage: cfg.GetString("app.storage"),
+	}
+	if c.Storage == "" {
+		c.Storage = StorageRedis
+	}
+	return c
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf(`repository:
+  %s: %s`,
+		"storage", c.Storage)
+}
@@ -1,24 +1,86 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis"
 	"github.com/wb-go/wbf/zlog"
 )
 
+type iNoticeStore interface {
+	SaveNotice(ctx context.Context, notice model.Notice) (id int, err error)
+	SaveNotices(ctx context.Context, notices []model.Notice) (ids []int, err error)
+	LoadNotice(ctx context.Context, id int) (notice *model.Notice, err error)
+	ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error)
+	UpdateNotice(ctx context.Context, notice *model.Notice) (err error)
+	UpdateNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status) (err error)
+	RetainNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status, retention time.Duration) (err error)
+	DeleteNotice(ctx context.Context, id int) (err error)
+	DeleteNotices(ctx context.Context, ids []int) (err error)
+}
+
+// Repository keeps the schedule, templates, Telegram chat IDs and update offset,
+// preferences, rate limits and dedup reservations in Redis
+// and the notices in Redis or Postgres
 type Repository struct {
 	*rpRedis.RpRedis
+	notices iNoticeStore
 }
 
-func New(rd *pkgRedis.Client) (*Repository, error) {
+// New stores the notices in Postgres if pg is not nil and in Redis otherwise
+func New(rd *pkgRedis.Client, pg *pkgPostgres.Postgres) (*Repository, error) {
 	if rd == nil {
 		return nil, errors.New("Redis client is nil")
 	}
 	lg := zlog.Logger.With().Str("layer", "repository").Logger()
-	return &Repository{
+	rp := &Repository{
 		RpRedis: rpRedis.New(&lg, rd),
-	}, nil
+	}
+	rp.notices = rp.RpRedis
+	if pg != nil {
+		rp.notices = rpPostgres.New(&lg, pg)
+	}
+	return rp, nil
+}
+
+func (rp *Repository) SaveNotice(ctx context.Context, notice model.Notice) (id int, err error) {
+	return rp.notices.SaveNotice(ctx, notice)
+}
+
+func (rp *Repository) SaveNotices(ctx context.Context, notices []model.Notice) (ids []int, err error) {
+	return rp.notices.SaveNotices(ctx, notices)
+}
+
+func (rp *Repository) LoadNotice(ctx context.Context, id int) (notice *model.Notice, err error) {
+	return rp.notices.LoadNotice(ctx, id)
+}
+
+func (rp *Repository) ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error) {
+	return rp.notices.ListNotices(ctx, filter)
+}
+
+func (rp *Repository) UpdateNotice(ctx context.Context, notice *model.Notice) (err error) {
+	return rp.notices.UpdateNotice(ctx, notice)
+}
+
+func (rp *Repository) UpdateNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status) (err error) {
+	return rp.notices.UpdateNoticeIf(ctx, notice, version, status)
+}
+
+func (rp *Repository) RetainNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status, retention time.Duration) (err error) {
+	return rp.notices.RetainNoticeIf(ctx, notice, version, status, retention)
+}
+
+func (rp *Repository) DeleteNotice(ctx context.Context, id int) (err error) {
+	return rp.notices.DeleteNotice(ctx, id)
+}
 
+func (rp *Repository) DeleteNotices(ctx context.Context, ids []int) (err error) {
+	return rp.notices.DeleteNotices(ctx, ids)
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
 )
@@ -13,6 +14,7 @@ func TestNew(t *testing.T) {
 	tests := []struct {
 		name        string
 		redisClient *pkgRedis.Client
+		postgres    *pkgPostgres.Postgres
 		expectErr   bool
 	}{
 		{
@@ -20,6 +22,12 @@ func TestNew(t *testing.T) {
 			redisClient: &pkgRedis.Client{},
 			expectErr:   false,
 		},
+		{
+			name:        "notices in postgres",
+			redisClient: &pkgRedis.Client{},
+			postgres:    &pkgPostgres.Postgres{},
+			expectErr:   false,
+		},
 		{
 			name:        "nil redis client",
 			redisClient: nil,
@@ -29,7 +37,7 @@ func TestNew(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo, err := repository.New(tt.redisClient)
+			repo, err := repository.New(tt.redisClient, tt.postgres)
 
 			if tt.expectErr {
 				require.Error(t, err)
@@ -0,0 +1,28 @@
+package rpPostgres
+
+import (
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres/rpPostgresDeleteNotice"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres/rpPostgresLoadNotice"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres/rpPostgresSaveNotice"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres/rpPostgresUpdateNotice"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// RpPostgres stores the notices in Postgres
+type RpPostgres struct {
+	*rpPostgresSaveNotice.RpPostgresSaveNotice
+	*rpPostgresLoadNotice.RpPostgresLoadNotice
+	*rpPostgresUpdateNotice.RpPostgresUpdateNotice
+	*rpPostgresDeleteNotice.RpPostgresDeleteNotice
+}
+
+func New(parentLg *zlog.Zerolog, pg *pkgPostgres.Postgres) *RpPostgres {
+	lg := parentLg.With().Str("component", "RpPostgres").Logger()
+	return &RpPostgres{
+		RpPostgresSaveNotice:   rpPostgresSaveNotice.New(&lg, pg),
+		RpPostgresLoadNotice:   rpPostgresLoadNotice.New(&lg, pg),
+		RpPostgresUpdateNotice: rpPostgresUpdateNotice.New(&lg, pg),
+		RpPostgresDeleteNotice: rpPostgresDeleteNotice.New(&lg, pg),
+	}
+}
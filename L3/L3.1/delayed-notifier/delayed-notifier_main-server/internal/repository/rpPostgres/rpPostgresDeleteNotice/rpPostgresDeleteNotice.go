@@ -0,0 +1,61 @@
+package rpPostgresDeleteNotice
+
+import (
+	"context"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type RpPostgresDeleteNotice struct {
+	lg *zlog.Zerolog
+	pg *pkgPostgres.Postgres
+}
+
+func New(parentLg *zlog.Zerolog, pg *pkgPostgres.Postgres) *RpPostgresDeleteNotice {
+	lg := parentLg.With().Str("component", "RpPostgresDeleteNotice").Logger()
+	return &RpPostgresDeleteNotice{
+		lg: &lg,
+		pg: pg,
+	}
+}
+
+func (rp *RpPostgresDeleteNotice) DeleteNotice(ctx context.Context, id int) (err error) {
+	lg := rp.lg.With().Str("method", "DeleteNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", id).Msgf("%s deleting notice from Postgres...", pkgConst.OpStart)
+	res, err := rp.pg.DB.ExecContext(ctx, `DELETE FROM notices WHERE id = $1`, id)
+	if err != nil {
+		return pkgErrors.Wrapf(err, "delete notice from Postgres, notice ID: %d", id)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return pkgErrors.Wrapf(err, "get deleted rows, notice ID: %d", id)
+	}
+	if deleted == 0 {
+		return pkgErrors.Wrapf(pkgErrors.ErrNoticeNotFound, "delete notice from Postgres, notice ID: %d", id)
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice deleted from Postgres successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// DeleteNotices deletes the notices in one statement
+func (rp *RpPostgresDeleteNotice) DeleteNotices(ctx context.Context, ids []int) (err error) {
+	lg := rp.lg.With().Str("method", "DeleteNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s deleting notices from Postgres...", pkgConst.OpStart)
+	if _, err := rp.pg.DB.ExecContext(ctx, `DELETE FROM notices WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return pkgErrors.Wrapf(err, "delete notices from Postgres, notice IDs: %v", ids)
+	}
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s notices deleted from Postgres successfully", pkgConst.OpSuccess)
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package rpPostgresLoadNotice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres/rpPostgresRow"
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type RpPostgresLoadNotice struct {
+	lg *zlog.Zerolog
+	pg *pkgPostgres.Postgres
+}
+
+func New(parentLg *zlog.Zerolog, pg *pkgPostgres.Postgres) *RpPostgresLoadNotice {
+	lg := parentLg.With().Str("component", "RpPostgresLoadNotice").Logger()
+	return &RpPostgresLoadNotice{
+		lg: &lg,
+		pg: pg,
+	}
+}
+
+// LoadNotice reads the notice from the master, the notice may be just saved or updated
+func (rp *RpPostgresLoadNotice) LoadNotice(ctx context.Context, id int) (notice *model.Notice, err error) {
+	lg := rp.lg.With().Str("method", "LoadNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	query := `SELECT ` + rpPostgresRow.Columns + ` FROM notices WHERE id = $1 AND ` + rpPostgresRow.NotExpired
+
+	lg.Trace().Int("notice ID", id).Msgf("%s loading notice from Postgres...", pkgConst.OpStart)
+	notice, err = rpPostgresRow.Scan(rp.pg.DB.Master.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkgErrors.Wrapf(pkgErrors.ErrNoticeNotFound, "load notice from Postgres, notice ID: %d", id)
+	}
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "load notice from Postgres, notice ID: %d", id)
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice loaded from Postgres successfully", pkgConst.OpSuccess)
+
+	return notice, nil
+}
+
+// ListNotices reads a page of the notices of a user from a slave
+func (rp *RpPostgresLoadNotice) ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error) {
+	lg := rp.lg.With().Str("method", "ListNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	where, args := whereClause(filter)
+
+	page = &model.NoticePage{Notices: []model.Notice{}, Limit: filter.Limit, Offset: filter.Offset}
+
+	lg.Trace().Int("user ID", filter.UserID).Msgf("%s counting notices in Postgres...", pkgConst.OpStart)
+	if err := rp.pg.DB.QueryRowContext(ctx, `SELECT count(*) FROM notices WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return nil, pkgErrors.Wrapf(err, "count notices in Postgres, user ID: %d", filter.UserID)
+	}
+	lg.Trace().Int("user ID", filter.UserID).Int("total", page.Total).Msgf("%s notices counted in Postgres successfully", pkgConst.OpSuccess)
+
+	if filter.Offset >= page.Total {
+		return page, nil
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM notices WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		rpPostgresRow.Columns, where, orderClause(filter.Order), len(args)+1, len(args)+2)
+
+	lg.Trace().Int("user ID", filter.UserID).Msgf("%s listing notices from Postgres...", pkgConst.OpStart)
+	rows, err := rp.pg.DB.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "list notices from Postgres, user ID: %d", filter.UserID)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		notice, err := rpPostgresRow.Scan(rows)
+		if err != nil {
+			return nil, pkgErrors.Wrap(err, "scan notice")
+		}
+		page.Notices = append(page.Notices, *notice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pkgErrors.Wrap(err, "iterate over notices")
+	}
+	lg.Trace().Int("user ID", filter.UserID).Int("count", len(page.Notices)).Msgf("%s notices listed from Postgres successfully", pkgConst.OpSuccess)
+
+	return page, nil
+}
+
+// whereClause builds the conditions of the filter with their arguments
+func whereClause(filter model.NoticeFilter) (string, []any) {
+	conds := []string{`user_id = $1`, rpPostgresRow.NotExpired}
+	args := []any{filter.UserID}
+
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, s := range filter.Statuses {
+			statuses[i] = string(s)
+		}
+		add(`status = ANY($%d)`, pq.Array(statuses))
+	}
+	if filter.From != nil {
+		add(`sent_at >= $%d`, *filter.From)
+	}
+	if filter.To != nil {
+		add(`sent_at < $%d`, *filter.To)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// orderClause returns the ORDER BY expression of the order, the same as model.NoticeFilter.Page
+func orderClause(order model.NoticeOrder) string {
+	if order == model.OrderDueAtAsc {
+		return `COALESCE(deferred_until, sent_at) ASC NULLS LAST, id ASC`
+	}
+	return `sent_at DESC NULLS LAST, id DESC`
+}
@@ -0,0 +1,33 @@
+package rpPostgresLoadNotice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+)
+
+func TestWhereClause(t *testing.T) {
+	where, args := whereClause(model.NoticeFilter{UserID: 7})
+	require.Equal(t, `user_id = $1 AND (expires_at IS NULL OR expires_at > now())`, where)
+	require.Equal(t, []any{7}, args)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	where, args = whereClause(model.NoticeFilter{
+		UserID:   7,
+		Statuses: []model.Status{model.StatusSent, model.StatusFailed},
+		From:     &from,
+		To:       &to,
+	})
+	require.Equal(t, `user_id = $1 AND (expires_at IS NULL OR expires_at > now()) AND status = ANY($2) AND sent_at >= $3 AND sent_at < $4`, where)
+	require.Equal(t, []any{7, pq.Array([]string{"sent", "failed"}), from, to}, args)
+}
+
+func TestOrderClause(t *testing.T) {
+	require.Equal(t, `sent_at DESC NULLS LAST, id DESC`, orderClause(model.OrderSentAtDesc))
+	require.Equal(t, `COALESCE(deferred_until, sent_at) ASC NULLS LAST, id ASC`, orderClause(model.OrderDueAtAsc))
+}
@@ -0,0 +1,106 @@
+// Package rpPostgresRow maps notices to rows of the notices table
+package rpPostgresRow
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+)
+
+// Columns are the columns of a notice in the order of Args and Scan
+const Columns = `id, user_id, message, template, channels, created_at, sent_at, status, deliveries, recurrence, occurrence, previous, deferred_until, version`
+
+// NotExpired selects the notices whose retention period is not over
+const NotExpired = `(expires_at IS NULL OR expires_at > now())`
+
+// Args returns the values of the columns after id
+func Args(notice *model.Notice) ([]any, error) {
+	template, err := jsonb(notice.Template, notice.Template == nil)
+	if err != nil {
+		return nil, fmt.Errorf("marshal template: %w", err)
+	}
+	channels, err := jsonb(notice.Channels, false)
+	if err != nil {
+		return nil, fmt.Errorf("marshal channels: %w", err)
+	}
+	deliveries, err := jsonb(notice.Deliveries, len(notice.Deliveries) == 0)
+	if err != nil {
+		return nil, fmt.Errorf("marshal deliveries: %w", err)
+	}
+	recurrence, err := jsonb(notice.Recurrence, notice.Recurrence == nil)
+	if err != nil {
+		return nil, fmt.Errorf("marshal recurrence: %w", err)
+	}
+	previous, err := jsonb(notice.Previous, notice.Previous == nil)
+	if err != nil {
+		return nil, fmt.Errorf("marshal previous: %w", err)
+	}
+
+	return []any{
+		notice.UserID, notice.Message, template, channels, notice.CreatedAt,
+		notice.SentAt, notice.Status, deliveries, recurrence, notice.Occurrence, previous, notice.DeferredUntil, notice.Version,
+	}, nil
+}
+
+func jsonb(v any, null bool) (any, error) {
+	if null {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// Scan reads a notice selected with Columns followed by extra destinations
+func Scan(row scanner, extra ...any) (*model.Notice, error) {
+	var (
+		notice                                               model.Notice
+		sentAt, deferredUntil                                sql.NullTime
+		template, channels, deliveries, recurrence, previous []byte
+	)
+
+	dest := []any{
+		&notice.ID, &notice.UserID, &notice.Message, &template, &channels, &notice.CreatedAt,
+		&sentAt, &notice.Status, &deliveries, &recurrence, &notice.Occurrence, &previous, &deferredUntil, &notice.Version,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if sentAt.Valid {
+		notice.SentAt = &sentAt.Time
+	}
+	if deferredUntil.Valid {
+		notice.DeferredUntil = &deferredUntil.Time
+	}
+	// channels are not validated again: the registered channel types may change
+	if err := json.Unmarshal(channels, (*[]model.ChannelInfo)(&notice.Channels)); err != nil {
+		return nil, fmt.Errorf("unmarshal channels: %w", err)
+	}
+	for _, col := range []struct {
+		data []byte
+		dest any
+	}{
+		{template, &notice.Template},
+		{deliveries, &notice.Deliveries},
+		{recurrence, &notice.Recurrence},
+		{previous, &notice.Previous},
+	} {
+		if col.data == nil {
+			continue
+		}
+		if err := json.Unmarshal(col.data, col.dest); err != nil {
+			return nil, fmt.Errorf("unmarshal notice %d: %w", notice.ID, err)
+		}
+	}
+
+	return &notice, nil
+}
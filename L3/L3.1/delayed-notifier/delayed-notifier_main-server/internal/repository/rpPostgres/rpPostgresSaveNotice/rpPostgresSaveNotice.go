@@ -0,0 +1,85 @@
+package rpPostgresSaveNotice
+
+import (
+	"context"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres/rpPostgresRow"
+	"github.com/wb-go/wbf/zlog"
+)
+
+const insertQuery = `
+	INSERT INTO notices (user_id, message, template, channels, created_at, sent_at, status, deliveries, recurrence, occurrence, previous, deferred_until, version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	RETURNING id
+`
+
+type RpPostgresSaveNotice struct {
+	lg *zlog.Zerolog
+	pg *pkgPostgres.Postgres
+}
+
+func New(parentLg *zlog.Zerolog, pg *pkgPostgres.Postgres) *RpPostgresSaveNotice {
+	lg := parentLg.With().Str("component", "RpPostgresSaveNotice").Logger()
+	return &RpPostgresSaveNotice{
+		lg: &lg,
+		pg: pg,
+	}
+}
+
+func (rp *RpPostgresSaveNotice) SaveNotice(ctx context.Context, notice model.Notice) (id int, err error) {
+	lg := rp.lg.With().Str("method", "SaveNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	args, err := rpPostgresRow.Args(&notice)
+	if err != nil {
+		return 0, pkgErrors.Wrap(err, "convert notice to row")
+	}
+
+	lg.Trace().Msgf("%s saving notice to Postgres...", pkgConst.OpStart)
+	if err := rp.pg.DB.Master.QueryRowContext(ctx, insertQuery, args...).Scan(&id); err != nil {
+		return 0, pkgErrors.Wrap(err, "save notice to Postgres")
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice saved to Postgres successfully", pkgConst.OpSuccess)
+
+	return id, nil
+}
+
+// SaveNotices saves the notices in one transaction, either all of them or none,
+// and returns their IDs in the same order
+func (rp *RpPostgresSaveNotice) SaveNotices(ctx context.Context, notices []model.Notice) (ids []int, err error) {
+	lg := rp.lg.With().Str("method", "SaveNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Msgf("%s beginning transaction...", pkgConst.OpStart)
+	tx, err := rp.pg.DB.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+	lg.Trace().Msgf("%s transaction begun successfully", pkgConst.OpSuccess)
+
+	ids = make([]int, len(notices))
+	for i := range notices {
+		args, err := rpPostgresRow.Args(&notices[i])
+		if err != nil {
+			return nil, pkgErrors.Wrap(err, "convert notice to row")
+		}
+		if err := tx.QueryRowContext(ctx, insertQuery, args...).Scan(&ids[i]); err != nil {
+			return nil, pkgErrors.Wrapf(err, "save notice %d to Postgres", i)
+		}
+	}
+
+	lg.Trace().Int("count", len(notices)).Msgf("%s committing transaction...", pkgConst.OpStart)
+	if err := tx.Commit(); err != nil {
+		return nil, pkgErrors.Wrap(err, "commit transaction")
+	}
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s notices saved to Postgres successfully", pkgConst.OpSuccess)
+
+	return ids, nil
+}
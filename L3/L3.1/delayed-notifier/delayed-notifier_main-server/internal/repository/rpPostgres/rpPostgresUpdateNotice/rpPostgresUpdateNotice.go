@@ -0,0 +1,143 @@
+package rpPostgresUpdateNotice
+
+import (
+	"context"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgPostgres"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpPostgres/rpPostgresRow"
+	"github.com/wb-go/wbf/zlog"
+)
+
+const updateQuery = `
+	UPDATE notices
+	SET user_id = $2, message = $3, template = $4, channels = $5, created_at = $6, sent_at = $7,
+		status = $8, deliveries = $9, recurrence = $10, occurrence = $11, previous = $12,
+		deferred_until = $13, version = $14, expires_at = $15
+	WHERE id = $1
+`
+
+// updateIfQuery updates the notice only if it is not changed since it was loaded,
+// the status is not checked if $17 is empty
+const updateIfQuery = updateQuery + `	AND version = $16 AND ($17 = '' OR status = $17)
+`
+
+type RpPostgresUpdateNotice struct {
+	lg *zlog.Zerolog
+	pg *pkgPostgres.Postgres
+}
+
+func New(parentLg *zlog.Zerolog, pg *pkgPostgres.Postgres) *RpPostgresUpdateNotice {
+	lg := parentLg.With().Str("component", "RpPostgresUpdateNotice").Logger()
+	return &RpPostgresUpdateNotice{
+		lg: &lg,
+		pg: pg,
+	}
+}
+
+func (rp *RpPostgresUpdateNotice) UpdateNotice(ctx context.Context, notice *model.Notice) (err error) {
+	lg := rp.lg.With().Str("method", "UpdateNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s updating notice in Postgres...", pkgConst.OpStart)
+	if err := rp.update(ctx, notice, nil); err != nil {
+		return err
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice updated in Postgres successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// UpdateNoticeIf updates the notice only if the stored one has the version and the status,
+// the status is not checked if empty. It returns ErrNoticeConflict otherwise
+func (rp *RpPostgresUpdateNotice) UpdateNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status) (err error) {
+	lg := rp.lg.With().Str("method", "UpdateNoticeIf").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", notice.ID).Int("version", version).Str("status", string(status)).Msgf("%s updating notice in Postgres if not changed...", pkgConst.OpStart)
+	if err := rp.updateIf(ctx, notice, nil, version, status); err != nil {
+		return err
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice updated in Postgres successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// RetainNoticeIf updates the notice and keeps it for the retention period only if the stored
+// one has the version and the status. It returns ErrNoticeConflict otherwise.
+// Notices with the retention period over are deleted on the way
+func (rp *RpPostgresUpdateNotice) RetainNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status, retention time.Duration) (err error) {
+	lg := rp.lg.With().Str("method", "RetainNoticeIf").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	var expiresAt *time.Time
+	if retention > 0 {
+		t := time.Now().Add(retention)
+		expiresAt = &t
+	}
+
+	lg.Trace().Int("notice ID", notice.ID).Dur("retention", retention).Msgf("%s updating notice in Postgres with retention...", pkgConst.OpStart)
+	if err := rp.updateIf(ctx, notice, expiresAt, version, status); err != nil {
+		return err
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice updated in Postgres with retention successfully", pkgConst.OpSuccess)
+
+	lg.Trace().Msgf("%s deleting expired notices from Postgres...", pkgConst.OpStart)
+	res, err := rp.pg.DB.ExecContext(ctx, `DELETE FROM notices WHERE expires_at <= now()`)
+	if err != nil {
+		lg.Warn().Err(err).Msgf("%s failed to delete expired notices from Postgres", pkgConst.Warn)
+		return nil
+	}
+	deleted, _ := res.RowsAffected()
+	lg.Trace().Int64("count", deleted).Msgf("%s expired notices deleted from Postgres successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+func (rp *RpPostgresUpdateNotice) update(ctx context.Context, notice *model.Notice, expiresAt *time.Time) error {
+	args, err := rpPostgresRow.Args(notice)
+	if err != nil {
+		return pkgErrors.Wrap(err, "convert notice to row")
+	}
+	args = append([]any{notice.ID}, append(args, expiresAt)...)
+
+	res, err := rp.pg.DB.ExecContext(ctx, updateQuery, args...)
+	if err != nil {
+		return pkgErrors.Wrapf(err, "update notice in Postgres, notice ID: %d", notice.ID)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return pkgErrors.Wrapf(err, "get updated rows, notice ID: %d", notice.ID)
+	}
+	if updated == 0 {
+		return pkgErrors.Wrapf(pkgErrors.ErrNoticeNotFound, "update notice in Postgres, notice ID: %d", notice.ID)
+	}
+	return nil
+}
+
+func (rp *RpPostgresUpdateNotice) updateIf(ctx context.Context, notice *model.Notice, expiresAt *time.Time, version int, status model.Status) error {
+	args, err := rpPostgresRow.Args(notice)
+	if err != nil {
+		return pkgErrors.Wrap(err, "convert notice to row")
+	}
+	args = append([]any{notice.ID}, append(args, expiresAt, version, string(status))...)
+
+	res, err := rp.pg.DB.ExecContext(ctx, updateIfQuery, args...)
+	if err != nil {
+		return pkgErrors.Wrapf(err, "update notice in Postgres, notice ID: %d", notice.ID)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return pkgErrors.Wrapf(err, "get updated rows, notice ID: %d", notice.ID)
+	}
+	if updated == 0 {
+		return pkgErrors.Wrapf(pkgErrors.ErrNoticeConflict, "update notice in Postgres, notice ID: %d, version %d, status %s", notice.ID, version, status)
+	}
+	return nil
+}
@@ -2,11 +2,22 @@ package rpRedis
 
 import (
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisDedup"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisDeleteNotice"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisDeleteTemplate"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisListNotices"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisLoadNotice"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisLoadPreferences"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisLoadTelChatID"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisLoadTemplate"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisRateLimit"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisSaveNotice"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisSavePreferences"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisSaveTelChatID"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisSaveTemplate"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisScheduleNotice"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisTelegramOffset"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisTelegramOwner"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisUpdateNotice"
 	"github.com/wb-go/wbf/zlog"
 )
@@ -14,20 +25,42 @@ import (
 type RpRedis struct {
 	*rpRedisSaveNotice.RpRedisSaveNotice
 	*rpRedisLoadNotice.RpRedisLoadNotice
+	*rpRedisListNotices.RpRedisListNotices
 	*rpRedisDeleteNotice.RpRedisDeleteNotice
 	*rpRedisUpdateNotice.RpRedisUpdateNotice
+	*rpRedisScheduleNotice.RpRedisScheduleNotice
 	*rpRedisSaveTelChatID.RpRedisSaveChatID
 	*rpRedisLoadTelChatID.RpRedisLoadTelChatID
+	*rpRedisSaveTemplate.RpRedisSaveTemplate
+	*rpRedisLoadTemplate.RpRedisLoadTemplate
+	*rpRedisDeleteTemplate.RpRedisDeleteTemplate
+	*rpRedisSavePreferences.RpRedisSavePreferences
+	*rpRedisLoadPreferences.RpRedisLoadPreferences
+	*rpRedisRateLimit.RpRedisRateLimit
+	*rpRedisDedup.RpRedisDedup
+	*rpRedisTelegramOffset.RpRedisTelegramOffset
+	*rpRedisTelegramOwner.RpRedisTelegramOwner
 }
 
 func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedis {
 	lg := parentLg.With().Str("component", "RpRedis").Logger()
 	return &RpRedis{
-		RpRedisSaveNotice:    rpRedisSaveNotice.New(&lg, rd),
-		RpRedisLoadNotice:    rpRedisLoadNotice.New(&lg, rd),
-		RpRedisDeleteNotice:  rpRedisDeleteNotice.New(&lg, rd),
-		RpRedisUpdateNotice:  rpRedisUpdateNotice.New(&lg, rd),
-		RpRedisSaveChatID:    rpRedisSaveTelChatID.New(&lg, rd),
-		RpRedisLoadTelChatID: rpRedisLoadTelChatID.New(&lg, rd),
+		RpRedisSaveNotice:      rpRedisSaveNotice.New(&lg, rd),
+		RpRedisLoadNotice:      rpRedisLoadNotice.New(&lg, rd),
+		RpRedisListNotices:     rpRedisListNotices.New(&lg, rd),
+		RpRedisDeleteNotice:    rpRedisDeleteNotice.New(&lg, rd),
+		RpRedisUpdateNotice:    rpRedisUpdateNotice.New(&lg, rd),
+		RpRedisScheduleNotice:  rpRedisScheduleNotice.New(&lg, rd),
+		RpRedisSaveChatID:      rpRedisSaveTelChatID.New(&lg, rd),
+		RpRedisLoadTelChatID:   rpRedisLoadTelChatID.New(&lg, rd),
+		RpRedisSaveTemplate:    rpRedisSaveTemplate.New(&lg, rd),
+		RpRedisLoadTemplate:    rpRedisLoadTemplate.New(&lg, rd),
+		RpRedisDeleteTemplate:  rpRedisDeleteTemplate.New(&lg, rd),
+		RpRedisSavePreferences: rpRedisSavePreferences.New(&lg, rd),
+		RpRedisLoadPreferences: rpRedisLoadPreferences.New(&lg, rd),
+		RpRedisRateLimit:       rpRedisRateLimit.New(&lg, rd),
+		RpRedisDedup:           rpRedisDedup.New(&lg, rd),
+		RpRedisTelegramOffset:  rpRedisTelegramOffset.New(&lg, rd),
+		RpRedisTelegramOwner:   rpRedisTelegramOwner.New(&lg, rd),
 	}
 }
@@ -0,0 +1,129 @@
+// Package rpRedisDedup keeps the reservations of notice contents
+// and idempotency keys that expire after the dedup window
+package rpRedisDedup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type RpRedisDedup struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisDedup {
+	lg := parentLg.With().Str("component", "RpRedisDedup").Logger()
+	return &RpRedisDedup{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+// ReserveContent reserves the content to the recipient in the channel for the owner
+// for the window. It returns false if another owner reserved the content
+func (rp *RpRedisDedup) ReserveContent(ctx context.Context, channel model.ChannelType, recipient, hash, owner string, window time.Duration) (reserved bool, err error) {
+	lg := rp.lg.With().Str("method", "ReserveContent").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := contentKey(channel, recipient, hash)
+
+	lg.Trace().Str("key", key).Msgf("%s reserving content in Redis...", pkgConst.OpStart)
+	current, _, err := rp.rd.SetNXGet(ctx, key, owner, window)
+	if err != nil {
+		return false, pkgErrors.Wrapf(err, "reserve content, key: %s", key)
+	}
+	lg.Trace().Str("key", key).Str("owner", current).Msgf("%s content reserved in Redis successfully", pkgConst.OpSuccess)
+
+	return current == owner, nil
+}
+
+// ReleaseContent releases the content reserved by the owner
+func (rp *RpRedisDedup) ReleaseContent(ctx context.Context, channel model.ChannelType, recipient, hash, owner string) (err error) {
+	lg := rp.lg.With().Str("method", "ReleaseContent").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := contentKey(channel, recipient, hash)
+
+	lg.Trace().Str("key", key).Msgf("%s releasing content in Redis...", pkgConst.OpStart)
+	if err := rp.rd.DelIfValue(ctx, key, owner); err != nil {
+		return pkgErrors.Wrapf(err, "release content, key: %s", key)
+	}
+	lg.Trace().Str("key", key).Msgf("%s content released in Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// ReserveIdempotencyKey sets the idempotency key of the user to value for ttl
+// if it is not set yet. It returns false and the current value otherwise
+func (rp *RpRedisDedup) ReserveIdempotencyKey(ctx context.Context, userID int, idempotencyKey, value string, ttl time.Duration) (current string, reserved bool, err error) {
+	lg := rp.lg.With().Str("method", "ReserveIdempotencyKey").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := idemKey(userID, idempotencyKey)
+
+	lg.Trace().Str("key", key).Msgf("%s reserving idempotency key in Redis...", pkgConst.OpStart)
+	current, reserved, err = rp.rd.SetNXGet(ctx, key, value, ttl)
+	if err != nil {
+		return "", false, pkgErrors.Wrapf(err, "reserve idempotency key, key: %s", key)
+	}
+	lg.Trace().Str("key", key).Bool("reserved", reserved).Msgf("%s idempotency key reserved in Redis successfully", pkgConst.OpSuccess)
+
+	return current, reserved, nil
+}
+
+// SetIdempotencyKey replaces the value of the idempotency key of the user
+func (rp *RpRedisDedup) SetIdempotencyKey(ctx context.Context, userID int, idempotencyKey, value string, ttl time.Duration) (err error) {
+	lg := rp.lg.With().Str("method", "SetIdempotencyKey").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := idemKey(userID, idempotencyKey)
+
+	lg.Trace().Str("key", key).Msgf("%s setting idempotency key in Redis...", pkgConst.OpStart)
+	if err := rp.rd.Set(ctx, key, value, ttl); err != nil {
+		return pkgErrors.Wrapf(err, "set idempotency key, key: %s", key)
+	}
+	lg.Trace().Str("key", key).Msgf("%s idempotency key set in Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the idempotency key of the user if it still has the value
+func (rp *RpRedisDedup) ReleaseIdempotencyKey(ctx context.Context, userID int, idempotencyKey, value string) (err error) {
+	lg := rp.lg.With().Str("method", "ReleaseIdempotencyKey").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := idemKey(userID, idempotencyKey)
+
+	lg.Trace().Str("key", key).Msgf("%s releasing idempotency key in Redis...", pkgConst.OpStart)
+	if err := rp.rd.DelIfValue(ctx, key, value); err != nil {
+		return pkgErrors.Wrapf(err, "release idempotency key, key: %s", key)
+	}
+	lg.Trace().Str("key", key).Msgf("%s idempotency key released in Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// contentKey hashes the channel, recipient and content hash into a key
+func contentKey(channel model.ChannelType, recipient, hash string) string {
+	sum := sha256.Sum256([]byte(string(channel) + "\x00" + recipient + "\x00" + hash))
+	return "dedup:" + hex.EncodeToString(sum[:])
+}
+
+func idemKey(userID int, key string) string {
+	return fmt.Sprintf("idempotency:%d:%s", userID, key)
+}
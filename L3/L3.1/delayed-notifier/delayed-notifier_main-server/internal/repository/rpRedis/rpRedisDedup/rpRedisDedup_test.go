@@ -0,0 +1,67 @@
+package rpRedisDedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+)
+
+func TestReserveContent(t *testing.T) {
+	rp, mr := rpTest.NewRpRedis(t)
+	ctx := context.Background()
+
+	reserve := func(recipient, owner string) bool {
+		reserved, err := rp.ReserveContent(ctx, model.ChannelEmail, recipient, "hash", owner, time.Minute)
+		require.NoError(t, err)
+		return reserved
+	}
+
+	require.True(t, reserve("user@example.com", "1"))
+	require.True(t, reserve("user@example.com", "1"), "the owner keeps its reservation")
+	require.False(t, reserve("user@example.com", "2"), "duplicate of another notice")
+	require.True(t, reserve("other@example.com", "2"))
+
+	require.NoError(t, rp.ReleaseContent(ctx, model.ChannelEmail, "user@example.com", "hash", "2"), "not the owner")
+	require.False(t, reserve("user@example.com", "2"))
+	require.NoError(t, rp.ReleaseContent(ctx, model.ChannelEmail, "user@example.com", "hash", "1"))
+	require.True(t, reserve("user@example.com", "2"))
+
+	mr.FastForward(time.Minute)
+	require.True(t, reserve("user@example.com", "3"), "the window is over")
+}
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	rp, mr := rpTest.NewRpRedis(t)
+	ctx := context.Background()
+
+	current, reserved, err := rp.ReserveIdempotencyKey(ctx, 1, "key", "hash", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Equal(t, "hash", current)
+
+	require.NoError(t, rp.SetIdempotencyKey(ctx, 1, "key", "hash:5", time.Hour))
+
+	current, reserved, err = rp.ReserveIdempotencyKey(ctx, 1, "key", "other", time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.Equal(t, "hash:5", current)
+
+	_, reserved, err = rp.ReserveIdempotencyKey(ctx, 2, "key", "other", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved, "keys are per user")
+
+	require.NoError(t, rp.ReleaseIdempotencyKey(ctx, 1, "key", "hash"), "the value changed")
+	_, reserved, err = rp.ReserveIdempotencyKey(ctx, 1, "key", "other", time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+
+	mr.FastForward(time.Hour)
+	_, reserved, err = rp.ReserveIdempotencyKey(ctx, 1, "key", "other", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved, "the key expired")
+}
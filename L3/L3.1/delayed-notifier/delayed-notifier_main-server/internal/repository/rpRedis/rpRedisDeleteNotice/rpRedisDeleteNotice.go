@@ -2,8 +2,11 @@ package rpRedisDeleteNotice
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
@@ -30,11 +33,56 @@ func (rp *RpRedisDeleteNotice) DeleteNotice(ctx context.Context, id int) (err er
 
 	key := fmt.Sprintf("notices:%d", id)
 	lg.Trace().Str("key", key).Msgf("%s deleting notice from Redis...", pkgConst.OpStart)
-	err = rp.rd.Del(ctx, key)
-	if err != nil {
+	if err := rp.del(ctx, []string{key}); err != nil {
 		return pkgErrors.Wrapf(err, "delete notice from Redis, key: %s", key)
 	}
 	lg.Trace().Str("key", key).Msgf("%s notice deleted from Redis successfully", pkgConst.OpSuccess)
 
 	return nil
 }
+
+// DeleteNotices deletes the notices in one transaction
+func (rp *RpRedisDeleteNotice) DeleteNotices(ctx context.Context, ids []int) (err error) {
+	lg := rp.lg.With().Str("method", "DeleteNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("notices:%d", id)
+	}
+
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s deleting notices from Redis...", pkgConst.OpStart)
+	if err := rp.del(ctx, keys); err != nil {
+		return pkgErrors.Wrapf(err, "delete notices from Redis, notice IDs: %v", ids)
+	}
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s notices deleted from Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// del deletes the notice keys together with their IDs in the index of their users.
+// The users are read from the notices, a notice that is already gone is left for
+// ListNotices to drop from the index
+func (rp *RpRedisDeleteNotice) del(ctx context.Context, keys []string) error {
+	values, err := rp.rd.MGet(ctx, keys...)
+	if err != nil {
+		return pkgErrors.Wrap(err, "get notices from Redis")
+	}
+
+	index := make(map[string][]string)
+	for _, key := range keys {
+		data, ok := values[key]
+		if !ok {
+			continue
+		}
+		var notice model.Notice
+		if err := json.Unmarshal([]byte(data), &notice); err != nil {
+			return pkgErrors.Wrapf(err, "unmarshal notice, key: %s", key)
+		}
+		userKey := fmt.Sprintf("notices:user:%d", notice.UserID)
+		index[userKey] = append(index[userKey], strconv.Itoa(notice.ID))
+	}
+
+	return rp.rd.DelIndexedTx(ctx, keys, index)
+}
@@ -0,0 +1,49 @@
+package rpRedisDeleteTemplate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+const namesKey = "templates"
+
+type RpRedisDeleteTemplate struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisDeleteTemplate {
+	lg := parentLg.With().Str("component", "RpRedisDeleteTemplate").Logger()
+	return &RpRedisDeleteTemplate{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+func (rp *RpRedisDeleteTemplate) DeleteTemplate(ctx context.Context, name string) (err error) {
+	lg := rp.lg.With().Str("method", "DeleteTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := namesKey + ":" + name
+
+	lg.Trace().Str("key", key).Msgf("%s deleting template from Redis...", pkgConst.OpStart)
+	err = rp.rd.Del(ctx, key)
+	if errors.Is(err, pkgErrors.ErrNoticeNotFound) {
+		return pkgErrors.Wrapf(pkgErrors.ErrTemplateNotFound, "delete template from Redis, name: %s", name)
+	}
+	if err != nil {
+		return pkgErrors.Wrapf(err, "delete template from Redis, name: %s", name)
+	}
+	if err := rp.rd.SRem(ctx, namesKey, name); err != nil {
+		return pkgErrors.Wrapf(err, "remove template name from Redis, name: %s", name)
+	}
+	lg.Trace().Str("key", key).Msgf("%s template deleted from Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package rpRedisListNotices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// indexChunk is the number of notice IDs read from the index of the user at a time
+const indexChunk = 100
+
+type RpRedisListNotices struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisListNotices {
+	lg := parentLg.With().Str("component", "RpRedisListNotices").Logger()
+	return &RpRedisListNotices{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+// ListNotices reads the notices of the user from the sorted set of their IDs
+// chunk by chunk and filters them in memory, so it costs as many reads as the user has notices.
+// IDs of the notices expired after retention are removed from the set on the way
+func (rp *RpRedisListNotices) ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error) {
+	lg := rp.lg.With().Str("method", "ListNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	indexKey := fmt.Sprintf("notices:user:%d", filter.UserID)
+	var notices []model.Notice
+	var expired []string
+	for after := 0; ; {
+		lg.Trace().Str("key", indexKey).Int("after", after).Msgf("%s reading notice IDs from Redis...", pkgConst.OpStart)
+		ids, err := rp.rd.ZRangeAfter(ctx, indexKey, float64(after), indexChunk)
+		if err != nil {
+			return nil, pkgErrors.Wrapf(err, "read notice IDs from Redis, key: %s", indexKey)
+		}
+		lg.Trace().Int("count", len(ids)).Msgf("%s notice IDs read from Redis successfully", pkgConst.OpSuccess)
+		if len(ids) == 0 {
+			break
+		}
+		// the score of an ID is the ID itself
+		if after, err = strconv.Atoi(ids[len(ids)-1]); err != nil {
+			return nil, pkgErrors.Wrapf(err, "convert notice ID to int, key: %s", indexKey)
+		}
+
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = "notices:" + id
+		}
+		values, err := rp.rd.MGet(ctx, keys...)
+		if err != nil {
+			return nil, pkgErrors.Wrapf(err, "get notices from Redis, key: %s", indexKey)
+		}
+
+		for i, key := range keys {
+			data, ok := values[key]
+			if !ok {
+				expired = append(expired, ids[i])
+				continue
+			}
+			var notice model.Notice
+			if err := json.Unmarshal([]byte(data), &notice); err != nil {
+				return nil, pkgErrors.Wrapf(err, "unmarshal notice, key: %s", key)
+			}
+			notices = append(notices, notice)
+		}
+
+		if len(ids) < indexChunk {
+			break
+		}
+	}
+
+	if len(expired) > 0 {
+		lg.Trace().Strs("notice IDs", expired).Msgf("%s removing expired notice IDs from Redis...", pkgConst.OpStart)
+		if err := rp.rd.ZRem(ctx, indexKey, expired...); err != nil {
+			lg.Warn().Err(err).Str("key", indexKey).Msgf("%s failed to remove expired notice IDs from Redis", pkgConst.Warn)
+		} else {
+			lg.Trace().Strs("notice IDs", expired).Msgf("%s expired notice IDs removed from Redis successfully", pkgConst.OpSuccess)
+		}
+	}
+
+	result := filter.Page(notices)
+	return &result, nil
+}
@@ -0,0 +1,105 @@
+package rpRedisListNotices_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+)
+
+func TestListNotices(t *testing.T) {
+	rp, _ := rpTest.NewRpRedis(t)
+	ctx := context.Background()
+
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(h int) *time.Time {
+		t := base.Add(time.Duration(h) * time.Hour)
+		return &t
+	}
+	channels := model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}}
+	for _, n := range []model.Notice{
+		{UserID: 1, Message: "first", Channels: channels, SentAt: at(1), Status: model.StatusSent},
+		{UserID: 1, Message: "second", Channels: channels, SentAt: at(2), Status: model.StatusScheduled},
+		{UserID: 1, Message: "third", Channels: channels, SentAt: at(3), Status: model.StatusScheduled},
+		{UserID: 2, Message: "other user", Channels: channels, SentAt: at(2), Status: model.StatusScheduled},
+	} {
+		_, err := rp.SaveNotice(ctx, n)
+		require.NoError(t, err)
+	}
+	require.NoError(t, rp.ScheduleNotice(ctx, 1, base), "schedule keys are not notices")
+
+	page, err := rp.ListNotices(ctx, model.NoticeFilter{UserID: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Total)
+	require.Len(t, page.Notices, 2)
+	require.Equal(t, "third", page.Notices[0].Message, "latest sent_at first")
+	require.Equal(t, "second", page.Notices[1].Message)
+
+	page, err = rp.ListNotices(ctx, model.NoticeFilter{UserID: 1, Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Notices, 1)
+	require.Equal(t, "first", page.Notices[0].Message)
+
+	page, err = rp.ListNotices(ctx, model.NoticeFilter{
+		UserID:   1,
+		Statuses: []model.Status{model.StatusScheduled},
+		From:     at(1),
+		To:       at(3),
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	require.Equal(t, "second", page.Notices[0].Message)
+}
+
+func TestListNotices_UserIndex(t *testing.T) {
+	rp, mr := rpTest.NewRpRedis(t)
+	ctx := context.Background()
+
+	channels := model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}}
+	notices := make([]model.Notice, 0, 250)
+	for i := range 250 {
+		// every fifth notice belongs to user 2, user 1 has more notices than one chunk of the index
+		userID := 1
+		if i%5 == 4 {
+			userID = 2
+		}
+		notices = append(notices, model.Notice{UserID: userID, Message: "test", Channels: channels, Status: model.StatusScheduled})
+	}
+	ids, err := rp.SaveNotices(ctx, notices)
+	require.NoError(t, err)
+	id, err := rp.SaveNotice(ctx, model.Notice{UserID: 1, Message: "test", Channels: channels, Status: model.StatusScheduled})
+	require.NoError(t, err)
+
+	page, err := rp.ListNotices(ctx, model.NoticeFilter{UserID: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 201, page.Total)
+
+	page, err = rp.ListNotices(ctx, model.NoticeFilter{UserID: 2, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 50, page.Total)
+
+	require.NoError(t, rp.DeleteNotice(ctx, id))
+	require.NoError(t, rp.DeleteNotices(ctx, ids[:5]))
+	members, err := mr.ZMembers("notices:user:1")
+	require.NoError(t, err)
+	require.Len(t, members, 196, "deleted notices are removed from the index")
+	members, err = mr.ZMembers("notices:user:2")
+	require.NoError(t, err)
+	require.Len(t, members, 49)
+
+	// the notice expired after retention
+	mr.Del(fmt.Sprintf("notices:%d", ids[5]))
+
+	page, err = rp.ListNotices(ctx, model.NoticeFilter{UserID: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 195, page.Total)
+	members, err = mr.ZMembers("notices:user:1")
+	require.NoError(t, err)
+	require.Len(t, members, 195, "expired notices are removed from the index")
+}
@@ -0,0 +1,52 @@
+package rpRedisLoadPreferences
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type RpRedisLoadPreferences struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisLoadPreferences {
+	lg := parentLg.With().Str("component", "RpRedisLoadPreferences").Logger()
+	return &RpRedisLoadPreferences{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+func (rp *RpRedisLoadPreferences) LoadPreferences(ctx context.Context, userID int) (preferences *model.Preferences, err error) {
+	lg := rp.lg.With().Str("method", "LoadPreferences").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := fmt.Sprintf("preferences:%d", userID)
+
+	lg.Trace().Str("key", key).Msgf("%s getting preferences from Redis...", pkgConst.OpStart)
+	data, err := rp.rd.Get(ctx, key)
+	if errors.Is(err, pkgErrors.ErrNoticeNotFound) {
+		return nil, pkgErrors.Wrapf(pkgErrors.ErrPreferencesNotFound, "get preferences from Redis, user ID: %d", userID)
+	}
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "get preferences from Redis, user ID: %d", userID)
+	}
+	lg.Trace().Str("key", key).Msgf("%s preferences got from Redis successfully", pkgConst.OpSuccess)
+
+	preferences = &model.Preferences{}
+	if err := json.Unmarshal([]byte(data), preferences); err != nil {
+		return nil, pkgErrors.Wrap(err, "unmarshal preferences")
+	}
+
+	return preferences, nil
+}
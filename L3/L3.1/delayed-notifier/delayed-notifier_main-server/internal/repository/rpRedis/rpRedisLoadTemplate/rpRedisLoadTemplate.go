@@ -0,0 +1,85 @@
+package rpRedisLoadTemplate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+const namesKey = "templates"
+
+type RpRedisLoadTemplate struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisLoadTemplate {
+	lg := parentLg.With().Str("component", "RpRedisLoadTemplate").Logger()
+	return &RpRedisLoadTemplate{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+func (rp *RpRedisLoadTemplate) LoadTemplate(ctx context.Context, name string) (template *model.Template, err error) {
+	lg := rp.lg.With().Str("method", "LoadTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := namesKey + ":" + name
+
+	lg.Trace().Str("key", key).Msgf("%s getting template from Redis...", pkgConst.OpStart)
+	data, err := rp.rd.Get(ctx, key)
+	if errors.Is(err, pkgErrors.ErrNoticeNotFound) {
+		return nil, pkgErrors.Wrapf(pkgErrors.ErrTemplateNotFound, "get template from Redis, name: %s", name)
+	}
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "get template from Redis, name: %s", name)
+	}
+	lg.Trace().Str("key", key).Msgf("%s template got from Redis successfully", pkgConst.OpSuccess)
+
+	template = &model.Template{}
+	if err := json.Unmarshal([]byte(data), template); err != nil {
+		return nil, pkgErrors.Wrap(err, "unmarshal template")
+	}
+
+	return template, nil
+}
+
+// ListTemplates returns all templates sorted by name
+func (rp *RpRedisLoadTemplate) ListTemplates(ctx context.Context) (templates []model.Template, err error) {
+	lg := rp.lg.With().Str("method", "ListTemplates").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Msgf("%s getting template names from Redis...", pkgConst.OpStart)
+	names, err := rp.rd.SMembers(ctx, namesKey)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "get template names from Redis")
+	}
+	lg.Trace().Int("count", len(names)).Msgf("%s template names got from Redis successfully", pkgConst.OpSuccess)
+
+	slices.Sort(names)
+
+	templates = make([]model.Template, 0, len(names))
+	for _, name := range names {
+		template, err := rp.LoadTemplate(ctx, name)
+		if errors.Is(err, pkgErrors.ErrTemplateNotFound) {
+			// deleted after the names were read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,46 @@
+package rpRedisRateLimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type RpRedisRateLimit struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisRateLimit {
+	lg := parentLg.With().Str("component", "RpRedisRateLimit").Logger()
+	return &RpRedisRateLimit{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+// TakeToken takes a token from the bucket of the recipient in the channel,
+// limit tokens are refilled over interval. It returns 0 if the token is taken,
+// otherwise the time until a token is available
+func (rp *RpRedisRateLimit) TakeToken(ctx context.Context, channel model.ChannelType, recipient string, limit int, interval time.Duration, now time.Time) (wait time.Duration, err error) {
+	lg := rp.lg.With().Str("method", "TakeToken").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := fmt.Sprintf("ratelimit:%s:%s", channel, recipient)
+
+	lg.Trace().Str("key", key).Msgf("%s taking token from Redis...", pkgConst.OpStart)
+	wait, err = rp.rd.TakeToken(ctx, key, limit, interval, now)
+	if err != nil {
+		return 0, pkgErrors.Wrapf(err, "take token, key: %s", key)
+	}
+	lg.Trace().Str("key", key).Dur("wait", wait).Msgf("%s token taken from Redis successfully", pkgConst.OpSuccess)
+
+	return wait, nil
+}
@@ -0,0 +1,33 @@
+package rpRedisRateLimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+)
+
+func TestTakeToken(t *testing.T) {
+	rp, _ := rpTest.NewRpRedis(t)
+	ctx := context.Background()
+
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	take := func(recipient string, at time.Time) time.Duration {
+		wait, err := rp.TakeToken(ctx, model.ChannelTelegram, recipient, 2, time.Minute, at)
+		require.NoError(t, err)
+		return wait
+	}
+
+	require.Zero(t, take("user", now))
+	require.Zero(t, take("user", now))
+	require.Equal(t, 30*time.Second, take("user", now), "bucket is empty, a token is refilled in 30s")
+	require.Zero(t, take("other", now), "buckets are per recipient")
+
+	require.Equal(t, 10*time.Second, take("user", now.Add(20*time.Second)), "a denied request takes no token")
+	require.Zero(t, take("user", now.Add(30*time.Second)))
+	require.Equal(t, 30*time.Second, take("user", now.Add(30*time.Second)))
+}
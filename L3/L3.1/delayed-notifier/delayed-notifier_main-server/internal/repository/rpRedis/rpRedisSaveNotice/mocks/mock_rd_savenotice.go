@@ -35,6 +35,21 @@ func (m *MockRedisClient) EXPECT() *MockRedisClientMockRecorder {
 	return m.recorder
 }
 
+// ReserveIDs mocks base method.
+func (m *MockRedisClient) ReserveIDs(ctx context.Context, prefix string, n int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIDs", ctx, prefix, n)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveIDs indicates an expected call of ReserveIDs.
+func (mr *MockRedisClientMockRecorder) ReserveIDs(ctx, prefix, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIDs", reflect.TypeOf((*MockRedisClient)(nil).ReserveIDs), ctx, prefix, n)
+}
+
 // Set mocks base method.
 func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}, ttl ...time.Duration) error {
 	m.ctrl.T.Helper()
@@ -54,6 +69,25 @@ func (mr *MockRedisClientMockRecorder) Set(ctx, key, value interface{}, ttl ...i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRedisClient)(nil).Set), varargs...)
 }
 
+// SetIndexedTx mocks base method.
+func (m *MockRedisClient) SetIndexedTx(ctx context.Context, values map[string]interface{}, index map[string]map[string]float64, ttl ...time.Duration) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, values, index}
+	for _, a := range ttl {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetIndexedTx", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetIndexedTx indicates an expected call of SetIndexedTx.
+func (mr *MockRedisClientMockRecorder) SetIndexedTx(ctx, values, index interface{}, ttl ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, values, index}, ttl...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIndexedTx", reflect.TypeOf((*MockRedisClient)(nil).SetIndexedTx), varargs...)
+}
+
 // SetWithID mocks base method.
 func (m *MockRedisClient) SetWithID(ctx context.Context, prefix string, value interface{}, ttl ...time.Duration) (string, error) {
 	m.ctrl.T.Helper()
@@ -3,6 +3,8 @@ package rpRedisSaveNotice
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"maps"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +18,8 @@ import (
 type RedisClient interface {
 	SetWithID(ctx context.Context, prefix string, value interface{}, ttl ...time.Duration) (string, error)
 	Set(ctx context.Context, key string, value interface{}, ttl ...time.Duration) error
+	ReserveIDs(ctx context.Context, prefix string, n int) (int, error)
+	SetIndexedTx(ctx context.Context, values map[string]interface{}, index map[string]map[string]float64, ttl ...time.Duration) error
 }
 
 type RpRedisSaveNotice struct {
@@ -67,7 +71,7 @@ func (rp *RpRedisSaveNotice) SaveNotice(ctx context.Context, notice model.Notice
 	lg.Trace().Msgf("%s notice marshaled successfully", pkgConst.OpSuccess)
 
 	lg.Trace().Msgf("%s saving notice to Redis...", pkgConst.OpStart)
-	err = rp.rd.Set(ctx, key, data)
+	err = rp.rd.SetIndexedTx(ctx, map[string]interface{}{key: data}, userIndex(notice))
 	if err != nil {
 		return 0, pkgErrors.Wrap(err, "save to Redis")
 	}
@@ -75,3 +79,53 @@ func (rp *RpRedisSaveNotice) SaveNotice(ctx context.Context, notice model.Notice
 
 	return id, nil
 }
+
+// SaveNotices saves the notices in one transaction, either all of them or none,
+// and returns their IDs in the same order
+func (rp *RpRedisSaveNotice) SaveNotices(ctx context.Context, notices []model.Notice) (ids []int, err error) {
+	lg := rp.lg.With().Str("method", "SaveNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("count", len(notices)).Msgf("%s reserving notice IDs in Redis...", pkgConst.OpStart)
+	first, err := rp.rd.ReserveIDs(ctx, "notices", len(notices))
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "reserve notice IDs in Redis")
+	}
+	lg.Trace().Int("first ID", first).Msgf("%s notice IDs reserved in Redis successfully", pkgConst.OpSuccess)
+
+	ids = make([]int, len(notices))
+	values := make(map[string]interface{}, len(notices))
+	index := make(map[string]map[string]float64)
+	for i, notice := range notices {
+		notice.ID = first + i
+		data, err := json.Marshal(notice)
+		if err != nil {
+			return nil, pkgErrors.Wrap(err, "marshal notice")
+		}
+		ids[i] = notice.ID
+		values[fmt.Sprintf("notices:%d", notice.ID)] = data
+		for key, members := range userIndex(notice) {
+			if index[key] == nil {
+				index[key] = make(map[string]float64)
+			}
+			maps.Copy(index[key], members)
+		}
+	}
+
+	lg.Trace().Int("count", len(notices)).Msgf("%s saving notices to Redis...", pkgConst.OpStart)
+	if err := rp.rd.SetIndexedTx(ctx, values, index); err != nil {
+		return nil, pkgErrors.Wrap(err, "save to Redis")
+	}
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s notices saved to Redis successfully", pkgConst.OpSuccess)
+
+	return ids, nil
+}
+
+// userIndex adds the notice to the sorted set of the notice IDs of its user,
+// which ListNotices reads instead of scanning all notices
+func userIndex(notice model.Notice) map[string]map[string]float64 {
+	return map[string]map[string]float64{
+		fmt.Sprintf("notices:user:%d", notice.UserID): {strconv.Itoa(notice.ID): float64(notice.ID)},
+	}
+}
@@ -39,8 +39,11 @@ func TestSaveNotice_GoMock(t *testing.T) {
 			SetWithID(gomock.Any(), "notices", gomock.Any()).
 			Return("notices:42", nil)
 		mockRedis.EXPECT().
-			Set(gomock.Any(), "notices:42", gomock.Any()).
-			Return(nil)
+			SetIndexedTx(gomock.Any(), gomock.Any(), map[string]map[string]float64{"notices:user:1": {"42": 42}}).
+			DoAndReturn(func(_ context.Context, values map[string]interface{}, _ map[string]map[string]float64, _ ...time.Duration) error {
+				assert.Contains(t, values, "notices:42")
+				return nil
+			})
 
 		rp := New(&logger, mockRedis)
 
@@ -63,14 +66,14 @@ func TestSaveNotice_GoMock(t *testing.T) {
 		assert.Equal(t, 0, id)
 	})
 
-	t.Run("Set fails", func(t *testing.T) {
+	t.Run("SetIndexedTx fails", func(t *testing.T) {
 		mockRedis := rpRedisSaveNotice.NewMockRedisClient(ctrl)
 
 		mockRedis.EXPECT().
 			SetWithID(gomock.Any(), "notices", gomock.Any()).
 			Return("notices:100", nil)
 		mockRedis.EXPECT().
-			Set(gomock.Any(), "notices:100", gomock.Any()).
+			SetIndexedTx(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(errors.New("redis save error"))
 
 		rp := New(&logger, mockRedis)
@@ -119,3 +122,58 @@ func TestSaveNotice_WithTTL(t *testing.T) {
 	err = rp.rd.Set(ctx, key, notice, ttl)
 	assert.NoError(t, err)
 }
+
+func TestSaveNotices_GoMock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	logger := zlog.Logger
+
+	notices := []model.Notice{
+		{UserID: 1, Message: "first", Status: model.StatusScheduled, CreatedAt: time.Now()},
+		{UserID: 2, Message: "second", Status: model.StatusScheduled, CreatedAt: time.Now()},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockRedis := rpRedisSaveNotice.NewMockRedisClient(ctrl)
+
+		mockRedis.EXPECT().
+			ReserveIDs(gomock.Any(), "notices", 2).
+			Return(7, nil)
+		mockRedis.EXPECT().
+			SetIndexedTx(gomock.Any(), gomock.Any(), map[string]map[string]float64{
+				"notices:user:1": {"7": 7},
+				"notices:user:2": {"8": 8},
+			}).
+			DoAndReturn(func(_ context.Context, values map[string]interface{}, _ map[string]map[string]float64, _ ...time.Duration) error {
+				assert.Len(t, values, 2)
+				assert.Contains(t, values, "notices:7")
+				assert.Contains(t, values, "notices:8")
+				return nil
+			})
+
+		rp := New(&logger, mockRedis)
+
+		ids, err := rp.SaveNotices(ctx, notices)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{7, 8}, ids)
+	})
+
+	t.Run("SetIndexedTx fails", func(t *testing.T) {
+		mockRedis := rpRedisSaveNotice.NewMockRedisClient(ctrl)
+
+		mockRedis.EXPECT().
+			ReserveIDs(gomock.Any(), "notices", 2).
+			Return(9, nil)
+		mockRedis.EXPECT().
+			SetIndexedTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errors.New("redis transaction error"))
+
+		rp := New(&logger, mockRedis)
+
+		ids, err := rp.SaveNotices(ctx, notices)
+		assert.Error(t, err)
+		assert.Nil(t, ids)
+	})
+}
@@ -0,0 +1,50 @@
+package rpRedisSavePreferences
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type RpRedisSavePreferences struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisSavePreferences {
+	lg := parentLg.With().Str("component", "RpRedisSavePreferences").Logger()
+	return &RpRedisSavePreferences{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+// SavePreferences creates or replaces the preferences of the user, preferences do not expire
+func (rp *RpRedisSavePreferences) SavePreferences(ctx context.Context, preferences model.Preferences) (err error) {
+	lg := rp.lg.With().Str("method", "SavePreferences").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Msgf("%s marshaling preferences...", pkgConst.OpStart)
+	data, err := json.Marshal(preferences)
+	if err != nil {
+		return pkgErrors.Wrap(err, "marshal preferences")
+	}
+	lg.Trace().Msgf("%s preferences marshaled successfully", pkgConst.OpSuccess)
+
+	key := fmt.Sprintf("preferences:%d", preferences.UserID)
+
+	lg.Trace().Str("key", key).Msgf("%s saving preferences to Redis...", pkgConst.OpStart)
+	if err := rp.rd.Set(ctx, key, data, 0); err != nil {
+		return pkgErrors.Wrapf(err, "save preferences to Redis, key: %s", key)
+	}
+	lg.Trace().Str("key", key).Msgf("%s preferences saved to Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
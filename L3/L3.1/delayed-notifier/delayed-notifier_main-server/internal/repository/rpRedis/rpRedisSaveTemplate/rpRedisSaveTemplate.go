@@ -0,0 +1,55 @@
+package rpRedisSaveTemplate
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// namesKey is a set of the names of all templates
+const namesKey = "templates"
+
+type RpRedisSaveTemplate struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisSaveTemplate {
+	lg := parentLg.With().Str("component", "RpRedisSaveTemplate").Logger()
+	return &RpRedisSaveTemplate{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+// SaveTemplate creates or replaces the template, templates do not expire
+func (rp *RpRedisSaveTemplate) SaveTemplate(ctx context.Context, template model.Template) (err error) {
+	lg := rp.lg.With().Str("method", "SaveTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Msgf("%s marshaling template...", pkgConst.OpStart)
+	data, err := json.Marshal(template)
+	if err != nil {
+		return pkgErrors.Wrap(err, "marshal template")
+	}
+	lg.Trace().Msgf("%s template marshaled successfully", pkgConst.OpSuccess)
+
+	key := namesKey + ":" + template.Name
+
+	lg.Trace().Str("key", key).Msgf("%s saving template to Redis...", pkgConst.OpStart)
+	if err := rp.rd.Set(ctx, key, data, 0); err != nil {
+		return pkgErrors.Wrapf(err, "save template to Redis, key: %s", key)
+	}
+	if err := rp.rd.SAdd(ctx, namesKey, template.Name); err != nil {
+		return pkgErrors.Wrapf(err, "add template name to Redis, name: %s", template.Name)
+	}
+	lg.Trace().Str("key", key).Msgf("%s template saved to Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package rpRedisScheduleNotice
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// scheduleKey is a sorted set of notice IDs scored by due time in Unix milliseconds
+const scheduleKey = "notices:schedule"
+
+type RpRedisScheduleNotice struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisScheduleNotice {
+	lg := parentLg.With().Str("component", "RpRedisScheduleNotice").Logger()
+	return &RpRedisScheduleNotice{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+func (rp *RpRedisScheduleNotice) ScheduleNotice(ctx context.Context, id int, dueAt time.Time) (err error) {
+	lg := rp.lg.With().Str("method", "ScheduleNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", id).Time("due at", dueAt).Msgf("%s adding notice to schedule...", pkgConst.OpStart)
+	if err := rp.rd.ZAdd(ctx, scheduleKey, float64(dueAt.UnixMilli()), strconv.Itoa(id)); err != nil {
+		return pkgErrors.Wrapf(err, "add notice to schedule, notice ID: %d", id)
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice added to schedule successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// ScheduleNotices adds the notices to the schedule at once, either all of them or none
+func (rp *RpRedisScheduleNotice) ScheduleNotices(ctx context.Context, due map[int]time.Time) (err error) {
+	lg := rp.lg.With().Str("method", "ScheduleNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	members := make(map[string]float64, len(due))
+	for id, dueAt := range due {
+		members[strconv.Itoa(id)] = float64(dueAt.UnixMilli())
+	}
+
+	lg.Trace().Int("count", len(due)).Msgf("%s adding notices to schedule...", pkgConst.OpStart)
+	if err := rp.rd.ZAddMany(ctx, scheduleKey, members); err != nil {
+		return pkgErrors.Wrapf(err, "add %d notices to schedule", len(due))
+	}
+	lg.Trace().Int("count", len(due)).Msgf("%s notices added to schedule successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// ClaimDueNotices returns up to limit IDs of notices due at now and postpones them by lease.
+// A claimed notice must be released after publishing, otherwise it is claimed again after lease
+func (rp *RpRedisScheduleNotice) ClaimDueNotices(ctx context.Context, now time.Time, lease time.Duration, limit int) (ids []int, err error) {
+	lg := rp.lg.With().Str("method", "ClaimDueNotices").Logger()
+
+	members, err := rp.rd.ClaimByScore(ctx, scheduleKey, float64(now.UnixMilli()), float64(now.Add(lease).UnixMilli()), limit)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "claim due notices")
+	}
+
+	ids = make([]int, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.Atoi(member)
+		if err != nil {
+			lg.Warn().Str("member", member).Msgf("%s invalid notice ID in schedule, removing", pkgConst.Warn)
+			if err := rp.rd.ZRem(ctx, scheduleKey, member); err != nil {
+				return nil, pkgErrors.Wrapf(err, "remove invalid schedule member %q", member)
+			}
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ReleaseNotice removes a notice claimed until claimedUntil from the schedule.
+// A notice scheduled again since it was claimed, e.g. the next occurrence
+// of a recurring notice, stays scheduled
+func (rp *RpRedisScheduleNotice) ReleaseNotice(ctx context.Context, id int, claimedUntil time.Time) (err error) {
+	lg := rp.lg.With().Str("method", "ReleaseNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", id).Msgf("%s releasing claimed notice...", pkgConst.OpStart)
+	if err := rp.rd.ZRemIfScore(ctx, scheduleKey, strconv.Itoa(id), float64(claimedUntil.UnixMilli())); err != nil {
+		return pkgErrors.Wrapf(err, "release claimed notice, notice ID: %d", id)
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s claimed notice released successfully", pkgConst.OpSuccess)
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package rpRedisTelegramOffset
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// offsetKey stores the ID of the next Telegram update to receive
+const offsetKey = "telegram:offset"
+
+type RpRedisTelegramOffset struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisTelegramOffset {
+	lg := parentLg.With().Str("component", "RpRedisTelegramOffset").Logger()
+	return &RpRedisTelegramOffset{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+// LoadTelegramOffset returns the saved update offset, 0 if it is not saved
+func (rp *RpRedisTelegramOffset) LoadTelegramOffset(ctx context.Context) (offset int, err error) {
+	lg := rp.lg.With().Str("method", "LoadTelegramOffset").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Msgf("%s getting update offset from Redis...", pkgConst.OpStart)
+	data, err := rp.rd.Get(ctx, offsetKey)
+	if errors.Is(err, pkgErrors.ErrNoticeNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, pkgErrors.Wrap(err, "get update offset from Redis")
+	}
+
+	offset, err = strconv.Atoi(data)
+	if err != nil {
+		return 0, pkgErrors.Wrapf(err, "convert update offset: %s", data)
+	}
+	lg.Trace().Int("offset", offset).Msgf("%s update offset got from Redis successfully", pkgConst.OpSuccess)
+
+	return offset, nil
+}
+
+func (rp *RpRedisTelegramOffset) SaveTelegramOffset(ctx context.Context, offset int) (err error) {
+	lg := rp.lg.With().Str("method", "SaveTelegramOffset").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("offset", offset).Msgf("%s saving update offset to Redis...", pkgConst.OpStart)
+	if err := rp.rd.Set(ctx, offsetKey, offset, 0); err != nil {
+		return pkgErrors.Wrapf(err, "save update offset to Redis, offset: %d", offset)
+	}
+	lg.Trace().Int("offset", offset).Msgf("%s update offset saved to Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package rpRedisTelegramOwner
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+const (
+	// ownersKey maps the Telegram user IDs to the numbers of their owner IDs
+	ownersKey = "telegram:owners"
+	// ownersCounterKey stores the number of the last allocated owner ID
+	ownersCounterKey = "telegram:owners:next_id"
+)
+
+type RpRedisTelegramOwner struct {
+	lg *zlog.Zerolog
+	rd *pkgRedis.Client
+}
+
+func New(parentLg *zlog.Zerolog, rd *pkgRedis.Client) *RpRedisTelegramOwner {
+	lg := parentLg.With().Str("component", "RpRedisTelegramOwner").Logger()
+	return &RpRedisTelegramOwner{
+		lg: &lg,
+		rd: rd,
+	}
+}
+
+// TelegramOwner returns the user ID owning the notices of the Telegram user,
+// allocating it on the first call. The owner IDs are negative: -1, -2 and so on,
+// so they never match the positive user IDs of the HTTP API
+func (rp *RpRedisTelegramOwner) TelegramOwner(ctx context.Context, telegramUserID int64) (userID int, err error) {
+	lg := rp.lg.With().Str("method", "TelegramOwner").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int64("telegram user ID", telegramUserID).Msgf("%s getting owner ID from Redis...", pkgConst.OpStart)
+	n, err := rp.rd.HGetOrIncr(ctx, ownersKey, strconv.FormatInt(telegramUserID, 10), ownersCounterKey)
+	if err != nil {
+		return 0, pkgErrors.Wrapf(err, "get owner ID from Redis, telegram user ID: %d", telegramUserID)
+	}
+	userID = -int(n)
+	lg.Trace().Int64("telegram user ID", telegramUserID).Int("user ID", userID).Msgf("%s owner ID got from Redis successfully", pkgConst.OpSuccess)
+
+	return userID, nil
+}
@@ -0,0 +1,31 @@
+package rpRedisTelegramOwner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis/rpRedisTelegramOwner"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+)
+
+func TestTelegramOwner(t *testing.T) {
+	rd, _ := rpTest.NewRedis(t)
+	lg := zlog.Logger
+	rp := rpRedisTelegramOwner.New(&lg, rd)
+	ctx := context.Background()
+
+	alice, err := rp.TelegramOwner(ctx, 7_000_000_001)
+	require.NoError(t, err)
+	require.Equal(t, -1, alice)
+
+	bob, err := rp.TelegramOwner(ctx, 42)
+	require.NoError(t, err)
+	require.Equal(t, -2, bob)
+
+	again, err := rp.TelegramOwner(ctx, 7_000_000_001)
+	require.NoError(t, err)
+	require.Equal(t, alice, again, "the owner ID is kept")
+}
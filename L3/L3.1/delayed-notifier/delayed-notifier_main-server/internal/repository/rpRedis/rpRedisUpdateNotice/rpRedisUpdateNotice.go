@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
@@ -48,3 +50,60 @@ func (rp *RpRedisUpdateNotice) UpdateNotice(ctx context.Context, notice *model.N
 
 	return nil
 }
+
+// UpdateNoticeIf updates the notice only if the stored one has the version and the status,
+// the status is not checked if empty. It returns ErrNoticeConflict otherwise
+func (rp *RpRedisUpdateNotice) UpdateNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status) (err error) {
+	lg := rp.lg.With().Str("method", "UpdateNoticeIf").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := fmt.Sprintf("notices:%d", notice.ID)
+
+	lg.Trace().Str("key", key).Int("version", version).Str("status", string(status)).Msgf("%s updating notice to Redis if not changed...", pkgConst.OpStart)
+	if err := rp.setIf(ctx, key, notice, version, status); err != nil {
+		return err
+	}
+	lg.Trace().Str("key", key).Msgf("%s notice updated to Redis successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// RetainNoticeIf updates the notice and keeps it in Redis for the retention period
+// only if the stored one has the version and the status. It returns ErrNoticeConflict otherwise
+func (rp *RpRedisUpdateNotice) RetainNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status, retention time.Duration) (err error) {
+	lg := rp.lg.With().Str("method", "RetainNoticeIf").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	key := fmt.Sprintf("notices:%d", notice.ID)
+
+	lg.Trace().Str("key", key).Dur("retention", retention).Msgf("%s updating notice to Redis with retention...", pkgConst.OpStart)
+	if err := rp.setIf(ctx, key, notice, version, status, retention); err != nil {
+		return err
+	}
+	lg.Trace().Str("key", key).Msgf("%s notice updated to Redis with retention successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+func (rp *RpRedisUpdateNotice) setIf(ctx context.Context, key string, notice *model.Notice, version int, status model.Status, ttl ...time.Duration) error {
+	data, err := json.Marshal(notice)
+	if err != nil {
+		return pkgErrors.Wrap(err, "marshal notice")
+	}
+
+	fields := map[string]string{"version": strconv.Itoa(version)}
+	if status != "" {
+		fields["status"] = string(status)
+	}
+
+	updated, err := rp.rd.SetIfFields(ctx, key, data, fields, ttl...)
+	if err != nil {
+		return pkgErrors.Wrapf(err, "update to Redis, key %s", key)
+	}
+	if !updated {
+		return pkgErrors.Wrapf(pkgErrors.ErrNoticeConflict, "update to Redis, key %s, version %d, status %s", key, version, status)
+	}
+	return nil
+}
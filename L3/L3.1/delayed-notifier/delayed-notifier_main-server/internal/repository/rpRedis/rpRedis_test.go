@@ -20,8 +20,19 @@ func TestNewRpRedis(t *testing.T) {
 	require.NotNil(t, r)
 	require.NotNil(t, r.RpRedisSaveNotice)
 	require.NotNil(t, r.RpRedisLoadNotice)
+	require.NotNil(t, r.RpRedisListNotices)
 	require.NotNil(t, r.RpRedisDeleteNotice)
 	require.NotNil(t, r.RpRedisUpdateNotice)
+	require.NotNil(t, r.RpRedisScheduleNotice)
 	require.NotNil(t, r.RpRedisSaveChatID)
 	require.NotNil(t, r.RpRedisLoadTelChatID)
+	require.NotNil(t, r.RpRedisSaveTemplate)
+	require.NotNil(t, r.RpRedisLoadTemplate)
+	require.NotNil(t, r.RpRedisDeleteTemplate)
+	require.NotNil(t, r.RpRedisSavePreferences)
+	require.NotNil(t, r.RpRedisLoadPreferences)
+	require.NotNil(t, r.RpRedisRateLimit)
+	require.NotNil(t, r.RpRedisDedup)
+	require.NotNil(t, r.RpRedisTelegramOffset)
+	require.NotNil(t, r.RpRedisTelegramOwner)
 }
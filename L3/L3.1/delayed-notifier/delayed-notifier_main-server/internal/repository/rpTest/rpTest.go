@@ -0,0 +1,47 @@
+// Package rpTest provides the repositories of the tests backed by miniredis
+package rpTest
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRedis"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpRedis"
+)
+
+// NewRedis starts miniredis and returns the client connected to it.
+// Both are closed when the test ends
+func NewRedis(t testing.TB) (*pkgRedis.Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rd, err := pkgRedis.New(&pkgRedis.Config{Host: mr.Host(), Port: mr.Server().Addr().Port})
+	require.NoError(t, err)
+	t.Cleanup(func() { rd.Close() })
+
+	return rd, mr
+}
+
+// NewRpRedis returns the Redis repository backed by a new miniredis
+func NewRpRedis(t testing.TB) (*rpRedis.RpRedis, *miniredis.Miniredis) {
+	t.Helper()
+
+	rd, mr := NewRedis(t)
+	lg := zlog.Logger
+	return rpRedis.New(&lg, rd), mr
+}
+
+// New returns the repository storing everything in a new miniredis
+func New(t testing.TB) *repository.Repository {
+	t.Helper()
+
+	rd, _ := NewRedis(t)
+	rp, err := repository.New(rd, nil)
+	require.NoError(t, err)
+
+	return rp
+}
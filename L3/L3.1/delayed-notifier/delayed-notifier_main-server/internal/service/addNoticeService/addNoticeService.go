@@ -2,71 +2,263 @@ package addNoticeService
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
-	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRabbitmq"
 	"github.com/wb-go/wbf/zlog"
 )
 
 type ISaveNoticeRepository interface {
 	SaveNotice(ctx context.Context, notice model.Notice) (id int, err error)
+	SaveNotices(ctx context.Context, notices []model.Notice) (ids []int, err error)
+	DeleteNotices(ctx context.Context, ids []int) (err error)
+}
+
+type IScheduleNoticeRepository interface {
+	ScheduleNotice(ctx context.Context, id int, dueAt time.Time) (err error)
+	ScheduleNotices(ctx context.Context, due map[int]time.Time) (err error)
 }
 
 type IDeleteNoticeService interface {
 	DeleteNotice(ctx context.Context, id int) (err error)
 }
 
+type ITemplateService interface {
+	ValidateTemplateRef(ctx context.Context, ref model.TemplateRef, channels model.Channels) (err error)
+}
+
+type IGuardNoticeService interface {
+	ReserveRequest(ctx context.Context, reqNotice model.ReqNotice) (id int, err error)
+	ConfirmRequest(ctx context.Context, reqNotice model.ReqNotice, id int) (err error)
+	ReleaseRequest(ctx context.Context, reqNotice model.ReqNotice) (err error)
+}
+
 type AddNoticeService struct {
 	lg       *zlog.Zerolog
-	rb       *pkgRabbitmq.Client
+	cfg      *Config
 	delNotSv IDeleteNoticeService
 	rp       ISaveNoticeRepository
+	rpSch    IScheduleNoticeRepository
+	tplSv    ITemplateService
+	grdSv    IGuardNoticeService
 }
 
 func New(
 	parentLg *zlog.Zerolog,
-	rb *pkgRabbitmq.Client,
+	cfg *Config,
 	delNotSv IDeleteNoticeService,
 	rp ISaveNoticeRepository,
+	rpSch IScheduleNoticeRepository,
+	tplSv ITemplateService,
+	grdSv IGuardNoticeService,
 ) *AddNoticeService {
 	lg := parentLg.With().Str("component", "AddNoticeService").Logger()
 	return &AddNoticeService{
 		lg:       &lg,
-		rb:       rb,
+		cfg:      cfg,
 		delNotSv: delNotSv,
 		rp:       rp,
+		rpSch:    rpSch,
+		tplSv:    tplSv,
+		grdSv:    grdSv,
 	}
 }
 
+// AddNotice creates and schedules the notice. A request with an idempotency key
+// used before returns the notice created by the first request with the key
 func (sv *AddNoticeService) AddNotice(ctx context.Context, reqNotice model.ReqNotice) (id int, err error) {
 	lg := sv.lg.With().Str("method", "AddNotice").Logger()
 	lg.Trace().Msgf("%s method starting", pkgConst.Start)
 	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
 
+	notice, err := sv.prepare(ctx, reqNotice)
+	if err != nil {
+		return 0, err
+	}
+
+	if reqNotice.IdempotencyKey != "" {
+		lg.Trace().Str("idempotency key", reqNotice.IdempotencyKey).Msgf("%s reserving idempotency key...", pkgConst.OpStart)
+		createdID, err := sv.grdSv.ReserveRequest(ctx, reqNotice)
+		if err != nil {
+			lg.Debug().Err(err).Msgf("%s failed to reserve idempotency key", pkgConst.Error)
+			return 0, pkgErrors.Wrap(err, "reserve idempotency key")
+		}
+		if createdID != 0 {
+			lg.Debug().Int("notice ID", createdID).Msgf("%s notice already created with idempotency key", pkgConst.Info)
+			return createdID, nil
+		}
+		lg.Trace().Str("idempotency key", reqNotice.IdempotencyKey).Msgf("%s idempotency key reserved successfully", pkgConst.OpSuccess)
+
+		defer func() {
+			if err != nil {
+				if err := sv.grdSv.ReleaseRequest(ctx, reqNotice); err != nil {
+					lg.Warn().Err(err).Msgf("%s failed to release idempotency key", pkgConst.Warn)
+				}
+				return
+			}
+			if err := sv.grdSv.ConfirmRequest(ctx, reqNotice, id); err != nil {
+				lg.Warn().Err(err).Int("notice ID", id).Msgf("%s failed to confirm idempotency key", pkgConst.Warn)
+			}
+		}()
+	}
+
+	return sv.create(ctx, notice)
+}
+
+// AddNotices creates the notices of the batch. The notices are saved in one transaction
+// and scheduled only after all of them are saved. Nothing is created if some notice
+// is invalid, the results tell which one, or if the batch fails to be saved or scheduled
+func (sv *AddNoticeService) AddNotices(ctx context.Context, batch model.ReqBatch) (results []model.BatchResult, err error) {
+	lg := sv.lg.With().Str("method", "AddNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	if err := batch.Validate(sv.cfg.BatchMaxSize); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidBatch, err)
+	}
+
+	results = make([]model.BatchResult, len(batch.Notices))
+	notices := make([]*model.Notice, len(batch.Notices))
+	invalid := 0
+
+	lg.Trace().Int("count", len(batch.Notices)).Msgf("%s validating batch...", pkgConst.OpStart)
+	for i, reqNotice := range batch.Notices {
+		results[i].Index = i
+		notice, err := sv.prepare(ctx, reqNotice)
+		if err != nil {
+			results[i].Error = err.Error()
+			invalid++
+			continue
+		}
+		notices[i] = notice
+	}
+	if invalid > 0 {
+		lg.Debug().Int("invalid", invalid).Msgf("%s batch validation failed", pkgConst.Error)
+		return results, pkgErrors.Wrapf(pkgErrors.ErrInvalidBatch, "%d of %d notices are invalid", invalid, len(batch.Notices))
+	}
+	lg.Trace().Int("count", len(batch.Notices)).Msgf("%s batch validated successfully", pkgConst.OpSuccess)
+
+	saved := make([]model.Notice, len(notices))
+	for i, notice := range notices {
+		saved[i] = *notice
+	}
+
+	lg.Trace().Int("count", len(saved)).Msgf("%s saving batch to repository...", pkgConst.OpStart)
+	ids, err := sv.rp.SaveNotices(ctx, saved)
+	if err != nil {
+		return failBatch(results, err), pkgErrors.Wrap(err, "save batch to repository")
+	}
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s batch saved to repository successfully", pkgConst.OpSuccess)
+
+	// the scheduler publishes the notices to the message broker when they are due
+	due := make(map[int]time.Time, len(ids))
+	for i, id := range ids {
+		due[id] = *saved[i].SentAt
+	}
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s scheduling batch...", pkgConst.OpStart)
+	if err := sv.rpSch.ScheduleNotices(ctx, due); err != nil {
+		lg.Debug().Err(err).Msgf("%s failed to schedule batch", pkgConst.Error)
+		if delErr := sv.rp.DeleteNotices(ctx, ids); delErr != nil {
+			lg.Error().Err(delErr).Ints("notice IDs", ids).Msgf("%s failed to delete unscheduled batch from repository", pkgConst.Error)
+			return failBatch(results, err), pkgErrors.Wrapf(err, "schedule batch, notices %v are saved but not scheduled", ids)
+		}
+		return failBatch(results, err), pkgErrors.Wrap(err, "schedule batch")
+	}
+	lg.Trace().Ints("notice IDs", ids).Msgf("%s batch scheduled successfully", pkgConst.OpSuccess)
+
+	for i, id := range ids {
+		results[i].ID = id
+	}
+	lg.Debug().Int("count", len(ids)).Msgf("%s batch created successfully", pkgConst.OpSuccess)
+
+	return results, nil
+}
+
+// failBatch sets the error of the batch to the results of all its notices
+func failBatch(results []model.BatchResult, err error) []model.BatchResult {
+	for i := range results {
+		results[i].ID = 0
+		results[i].Error = err.Error()
+	}
+	return results
+}
+
+// prepare validates the request and makes the notice to create
+func (sv *AddNoticeService) prepare(ctx context.Context, reqNotice model.ReqNotice) (*model.Notice, error) {
+	lg := sv.lg.With().Str("method", "prepare").Logger()
+
+	lg.Trace().Msgf("%s validating request...", pkgConst.OpStart)
+	if err := reqNotice.Validate(); err != nil {
+		lg.Debug().Err(err).Msgf("%s request validation failed", pkgConst.Error)
+		return nil, pkgErrors.Wrap(err, "request validation failed")
+	}
+	lg.Trace().Msgf("%s request validated successfully", pkgConst.OpSuccess)
+
+	if reqNotice.Template != nil {
+		lg.Trace().Str("template", reqNotice.Template.Name).Msgf("%s validating template reference...", pkgConst.OpStart)
+		if err := sv.tplSv.ValidateTemplateRef(ctx, *reqNotice.Template, reqNotice.Channels); err != nil {
+			lg.Debug().Err(err).Msgf("%s template reference validation failed", pkgConst.Error)
+			return nil, pkgErrors.Wrap(err, "template reference validation failed")
+		}
+		lg.Trace().Str("template", reqNotice.Template.Name).Msgf("%s template reference validated successfully", pkgConst.OpSuccess)
+	}
+
 	createdAt := time.Now()
 	sentAt := reqNotice.SentAt
-	ttl := sentAt.Sub(createdAt)
 	notice := model.Notice{
 		UserID:    reqNotice.UserID,
 		Message:   reqNotice.Message,
+		Template:  reqNotice.Template,
 		Channels:  reqNotice.Channels,
 		CreatedAt: createdAt,
 		SentAt:    sentAt,
 		Status:    model.StatusScheduled,
 	}
 
+	if reqNotice.Recurrence != nil {
+		lg.Trace().Msgf("%s calculating first occurrence...", pkgConst.OpStart)
+		recurrence := *reqNotice.Recurrence
+		if sentAt != nil {
+			recurrence.Start = *sentAt
+		} else {
+			// occurrences are whole seconds, the first one must be after createdAt
+			recurrence.Start = createdAt.Truncate(time.Second).Add(time.Second)
+		}
+		if err := recurrence.Validate(); err != nil {
+			lg.Debug().Err(err).Msgf("%s recurrence validation failed", pkgConst.Error)
+			return nil, pkgErrors.Wrap(err, "recurrence validation failed")
+		}
+		first, err := recurrence.First()
+		if err != nil {
+			return nil, pkgErrors.Wrap(err, "calculate first occurrence")
+		}
+		lg.Trace().Time("first occurrence", first).Msgf("%s first occurrence calculated successfully", pkgConst.OpSuccess)
+
+		sentAt = &first
+		notice.SentAt = sentAt
+		notice.Recurrence = &recurrence
+		notice.Occurrence = 1
+	}
+
 	lg.Trace().Msgf("%s validating notice...", pkgConst.OpStart)
 	if err := notice.Validate(); err != nil {
 		lg.Debug().Err(err).Msgf("%s notice validation failed", pkgConst.Error)
-		return 0, pkgErrors.Wrap(err, "notice validation failed")
+		return nil, pkgErrors.Wrap(err, "notice validation failed")
 	}
 	lg.Trace().Msgf("%s notice validated successfully", pkgConst.OpSuccess)
 
+	return &notice, nil
+}
+
+// create saves and schedules the notice
+func (sv *AddNoticeService) create(ctx context.Context, notice *model.Notice) (id int, err error) {
+	lg := sv.lg.With().Str("method", "create").Logger()
+
 	lg.Trace().Msgf("%s saving notice to repository...", pkgConst.OpStart)
-	id, err = sv.rp.SaveNotice(ctx, notice)
+	id, err = sv.rp.SaveNotice(ctx, *notice)
 	if err != nil {
 		return 0, pkgErrors.Wrap(err, "save notice to repository")
 	}
@@ -74,15 +266,16 @@ func (sv *AddNoticeService) AddNotice(ctx context.Context, reqNotice model.ReqNo
 
 	notice.ID = id
 
-	lg.Trace().Int("notice ID", notice.ID).Msgf("%s publishing notice with TTL to message broker...", pkgConst.OpStart)
-	if err = sv.rb.PublishStructWithTTL(notice, ttl); err != nil {
-		lg.Debug().Err(err).Msgf("%s failed to publish struct with TTL to RabbitMQ", pkgConst.Error)
+	// the scheduler publishes the notice to the message broker when it is due
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s scheduling notice...", pkgConst.OpStart)
+	if err = sv.rpSch.ScheduleNotice(ctx, notice.ID, *notice.SentAt); err != nil {
+		lg.Debug().Err(err).Msgf("%s failed to schedule notice", pkgConst.Error)
 		if err := sv.delNotSv.DeleteNotice(ctx, notice.ID); err != nil {
 			lg.Debug().Err(err).Int("notice ID", notice.ID).Msgf("%s failed deleted notice from Redis", pkgConst.Error)
 		}
-		return 0, pkgErrors.Wrap(err, "error publish struct with TTL to RabbitMQ")
+		return 0, pkgErrors.Wrap(err, "schedule notice")
 	}
-	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice with TTL published to message broker successfully", pkgConst.OpSuccess)
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice scheduled successfully", pkgConst.OpSuccess)
 
 	return id, nil
 }
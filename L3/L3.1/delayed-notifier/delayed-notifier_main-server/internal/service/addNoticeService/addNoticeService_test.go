@@ -0,0 +1,145 @@
+package addNoticeService_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/addNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/deleteNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/getNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/guardNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/scheduleNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/templateService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/updateNoticeService"
+)
+
+// failingScheduleRepository fails to schedule batches
+type failingScheduleRepository struct {
+	*repository.Repository
+}
+
+func (rp failingScheduleRepository) ScheduleNotices(ctx context.Context, due map[int]time.Time) error {
+	return errors.New("schedule is unavailable")
+}
+
+func newService(t *testing.T) (*addNoticeService.AddNoticeService, *repository.Repository) {
+	t.Helper()
+
+	rp := rpTest.New(t)
+	return newServiceWith(t, rp, rp), rp
+}
+
+func newServiceWith(t *testing.T, rp *repository.Repository, rpSch addNoticeService.IScheduleNoticeRepository) *addNoticeService.AddNoticeService {
+	t.Helper()
+
+	lg := zlog.Logger
+	getNotSv := getNoticeService.New(&lg, rp)
+	tplSv := templateService.New(&lg, rp)
+	updNotSv := updateNoticeService.New(&lg, rp, tplSv)
+	schNotSv := scheduleNoticeService.New(&lg, &scheduleNoticeService.Config{}, rp, getNotSv, nil)
+	delNotSv := deleteNoticeService.New(&lg, rp, getNotSv, updNotSv, schNotSv)
+	grdSv := guardNoticeService.New(&lg, &guardNoticeService.Config{IdempotencyTTL: time.Hour}, rp)
+
+	return addNoticeService.New(&lg, &addNoticeService.Config{BatchMaxSize: 3}, delNotSv, rp, rpSch, tplSv, grdSv)
+}
+
+func reqNotice(message string) model.ReqNotice {
+	sentAt := time.Now().Add(time.Hour)
+	return model.ReqNotice{
+		UserID:   1,
+		Message:  message,
+		Channels: model.Channels{{Type: model.ChannelEmail, Value: "user@example.com"}},
+		SentAt:   &sentAt,
+	}
+}
+
+func TestAddNotices(t *testing.T) {
+	sv, rp := newService(t)
+	ctx := context.Background()
+
+	results, err := sv.AddNotices(ctx, model.ReqBatch{Notices: []model.ReqNotice{reqNotice("first"), reqNotice("second")}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	var ids []int
+	for i, res := range results {
+		require.Equal(t, i, res.Index)
+		require.Empty(t, res.Error)
+		notice, err := rp.LoadNotice(ctx, res.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.StatusScheduled, notice.Status)
+		require.Equal(t, []string{"first", "second"}[i], notice.Message)
+		ids = append(ids, res.ID)
+	}
+
+	due, err := rp.ClaimDueNotices(ctx, time.Now().Add(2*time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ids, due)
+}
+
+func TestAddNotices_ScheduleFailureCreatesNothing(t *testing.T) {
+	rp := rpTest.New(t)
+	sv := newServiceWith(t, rp, failingScheduleRepository{rp})
+	ctx := context.Background()
+
+	results, err := sv.AddNotices(ctx, model.ReqBatch{Notices: []model.ReqNotice{reqNotice("first"), reqNotice("second")}})
+	require.Error(t, err)
+	require.Len(t, results, 2)
+	for _, res := range results {
+		require.Zero(t, res.ID)
+		require.Contains(t, res.Error, "schedule is unavailable")
+	}
+
+	page, err := rp.ListNotices(ctx, model.NoticeFilter{UserID: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Zero(t, page.Total, "saved notices are deleted")
+}
+
+func TestAddNotices_InvalidCreatesNothing(t *testing.T) {
+	sv, rp := newService(t)
+	ctx := context.Background()
+
+	invalid := reqNotice("invalid")
+	invalid.Template = &model.TemplateRef{Name: "missing"}
+
+	results, err := sv.AddNotices(ctx, model.ReqBatch{Notices: []model.ReqNotice{reqNotice("valid"), invalid}})
+	require.ErrorIs(t, err, pkgErrors.ErrInvalidBatch)
+	require.Empty(t, results[0].Error)
+	require.Zero(t, results[0].ID)
+	require.Contains(t, results[1].Error, "template")
+
+	page, err := rp.ListNotices(ctx, model.NoticeFilter{UserID: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+
+	_, err = sv.AddNotices(ctx, model.ReqBatch{})
+	require.ErrorIs(t, err, pkgErrors.ErrInvalidBatch)
+	_, err = sv.AddNotices(ctx, model.ReqBatch{Notices: make([]model.ReqNotice, 4)})
+	require.ErrorIs(t, err, model.ErrTooLargeBatch)
+}
+
+func TestAddNotice_IdempotencyKey(t *testing.T) {
+	sv, _ := newService(t)
+	ctx := context.Background()
+
+	req := reqNotice("hello")
+	req.IdempotencyKey = "key"
+
+	id, err := sv.AddNotice(ctx, req)
+	require.NoError(t, err)
+	again, err := sv.AddNotice(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, id, again)
+
+	req.Message = "bye"
+	_, err = sv.AddNotice(ctx, req)
+	require.ErrorIs(t, err, pkgErrors.ErrIdempotencyConflict)
+}
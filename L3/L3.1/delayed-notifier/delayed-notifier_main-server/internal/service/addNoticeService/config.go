@@ -0,0 +1,30 @@
+package addNoticeService
+
+import (
+	"fmt"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const defaultBatchMaxSize = 100
+
+type Config struct {
+	// BatchMaxSize is the maximum number of notices created by one batch request
+	BatchMaxSize int
+}
+
+func NewConfig(cfg *config.Config) *Config {
+	c := &Config{
+		BatchMaxSize: cfg.GetInt("app.batch.max_size"),
+	}
+	if c.BatchMaxSize <= 0 {
+		c.BatchMaxSize = defaultBatchMaxSize
+	}
+	return c
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf(`batch:
+  %s: %v`,
+		"max_size", c.BatchMaxSize)
+}
@@ -0,0 +1,31 @@
+package consumeNoticeService
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const defaultRetention = 7 * 24 * time.Hour
+
+type Config struct {
+	// Retention is how long a notice is kept after it was sent, failed or deleted
+	Retention time.Duration
+}
+
+func NewConfig(cfg *config.Config) *Config {
+	c := &Config{
+		Retention: cfg.GetDuration("app.consumer.retention"),
+	}
+	if c.Retention <= 0 {
+		c.Retention = defaultRetention
+	}
+	return c
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf(`consumer:
+  %s: %v`,
+		"retention", c.Retention)
+}
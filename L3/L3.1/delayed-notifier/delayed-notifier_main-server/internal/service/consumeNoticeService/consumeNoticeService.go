@@ -3,6 +3,8 @@ package consumeNoticeService
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
@@ -12,12 +14,8 @@ import (
 	"github.com/wb-go/wbf/zlog"
 )
 
-type iDeleteNoticeService interface {
-	DeleteNotice(ctx context.Context, id int) (err error)
-}
-
 type iSendNoticeService interface {
-	SendNotice(ctx context.Context, notice model.Notice)
+	SendNotice(ctx context.Context, notice *model.Notice)
 }
 
 type iGetNoticeService interface {
@@ -25,34 +23,52 @@ type iGetNoticeService interface {
 }
 
 type iUpdateNoticeService interface {
-	UpdateStatus(ctx context.Context, notice *model.Notice, newStatus model.Status) (err error)
+	ClaimNotice(ctx context.Context, notice *model.Notice) (err error)
+	RetainNotice(ctx context.Context, notice *model.Notice, status model.Status, retention time.Duration) (err error)
+}
+
+type iScheduleNoticeService interface {
+	ScheduleNextOccurrence(ctx context.Context, notice *model.Notice, status model.Status) (scheduled bool, err error)
+	DeferNotice(ctx context.Context, notice *model.Notice, status model.Status, until time.Time) (err error)
+}
+
+type iGuardNoticeService interface {
+	QuietUntil(ctx context.Context, notice *model.Notice, now time.Time) (until time.Time, quiet bool, err error)
+	Admit(ctx context.Context, notice *model.Notice, now time.Time) (retryAt time.Time, deferred bool)
+	Release(ctx context.Context, notice *model.Notice)
 }
 
 type ConsumeNoticeService struct {
 	lg        *zlog.Zerolog
+	cfg       *Config
 	rb        *pkgRabbitmq.Client
-	delNotSv  iDeleteNoticeService
 	sendNotSv iSendNoticeService
 	getNotSv  iGetNoticeService
 	updNotSv  iUpdateNoticeService
+	schNotSv  iScheduleNoticeService
+	grdSv     iGuardNoticeService
 }
 
 func New(
 	parentLg *zlog.Zerolog,
+	cfg *Config,
 	rb *pkgRabbitmq.Client,
-	delNotSv iDeleteNoticeService,
 	sendNotSv iSendNoticeService,
 	getNotSv iGetNoticeService,
 	updNotSv iUpdateNoticeService,
+	schNotSv iScheduleNoticeService,
+	grdSv iGuardNoticeService,
 ) *ConsumeNoticeService {
 	lg := parentLg.With().Str("component", "ConsumeNoticeService").Logger()
 	return &ConsumeNoticeService{
 		lg:        &lg,
+		cfg:       cfg,
 		rb:        rb,
-		delNotSv:  delNotSv,
 		sendNotSv: sendNotSv,
 		getNotSv:  getNotSv,
 		updNotSv:  updNotSv,
+		schNotSv:  schNotSv,
+		grdSv:     grdSv,
 	}
 }
 
@@ -98,27 +114,124 @@ func (sv *ConsumeNoticeService) handleMessage(ctx context.Context, message amqp.
 	lg.Trace().Int("notice ID", notice.ID).Msgf("%s message acknowledged successfully", pkgConst.OpSuccess)
 
 	// cheking status, setting new status and sending notice
-	updateNotice, err := sv.getNotSv.GetNotice(ctx, notice.ID)
+	storedNotice, err := sv.getNotSv.GetNotice(ctx, notice.ID)
 	if err != nil {
-		lg.Error().Err(err).Int("notice ID", notice.ID).Msg("failed to update notice status")
+		lg.Error().Err(err).Int("notice ID", notice.ID).Msg("failed to get notice")
+		return
+	}
+
+	if storedNotice.Occurrence != notice.Occurrence {
+		// the occurrence was skipped after the scheduler published it
+		lg.Debug().Int("notice ID", notice.ID).Int("occurrence", notice.Occurrence).Msgf("%s occurrence is not scheduled anymore, skipping", pkgConst.Info)
+		return
+	}
+
+	if storedNotice.Version != notice.Version {
+		// the notice was changed after the scheduler published it, its new version is scheduled
+		lg.Debug().Int("notice ID", notice.ID).Int("version", notice.Version).Int("stored version", storedNotice.Version).Msgf("%s outdated copy of notice, skipping", pkgConst.Info)
 		return
 	}
-	if updateNotice.Status != model.StatusDeleted {
-		if err := sv.updNotSv.UpdateStatus(ctx, &notice, model.StatusPending); err != nil {
-			lg.Error().Err(err).Int("notice ID", notice.ID).Msg("failed to update notice status")
+
+	// every write below succeeds only if the stored notice still has this status and the version
+	stored := storedNotice.Status
+
+	switch {
+	case storedNotice.Status.IsFinal():
+		// the scheduler published the notice again after its lease expired
+		lg.Debug().Int("notice ID", notice.ID).Str("status", string(storedNotice.Status)).Msgf("%s notice already processed, skipping", pkgConst.Info)
+		return
+	case storedNotice.Status == model.StatusDeleted:
+	case storedNotice.Missed(time.Now()):
+		lg.Debug().Int("notice ID", notice.ID).Int("occurrence", storedNotice.Occurrence).Msgf("%s occurrence missed, skipping", pkgConst.Info)
+		storedNotice.Status = model.StatusSkipped
+	default:
+		now := time.Now()
+		until, quiet, err := sv.grdSv.QuietUntil(ctx, storedNotice, now)
+		if err != nil {
+			lg.Warn().Err(err).Int("notice ID", notice.ID).Msgf("%s failed to check quiet hours", pkgConst.Warn)
+		}
+		if quiet {
+			lg.Debug().Int("notice ID", notice.ID).Time("until", until).Msgf("%s quiet hours, deferring notice", pkgConst.Info)
+			err := sv.schNotSv.DeferNotice(ctx, storedNotice, stored, until)
+			if errors.Is(err, pkgErrors.ErrNoticeConflict) {
+				sv.handleChanged(ctx, notice.ID)
+				return
+			}
+			if err != nil {
+				lg.Error().Err(err).Int("notice ID", notice.ID).Msgf("%s failed to defer notice", pkgConst.Error)
+			}
+			return
+		}
+
+		// a notice published again after its lease expired is pending, only one consumer claims it
+		err = sv.updNotSv.ClaimNotice(ctx, storedNotice)
+		if errors.Is(err, pkgErrors.ErrNoticeConflict) {
+			sv.handleChanged(ctx, notice.ID)
+			return
+		}
+		if err != nil {
+			lg.Error().Err(err).Int("notice ID", notice.ID).Msg("failed to claim notice")
 			return
 		}
+		stored = model.StatusPending
+		retryAt, deferred := sv.grdSv.Admit(ctx, storedNotice, now)
 		lg.Trace().Int("notice ID", notice.ID).Msgf("%s sending message...", pkgConst.OpStart)
-		sv.sendNotSv.SendNotice(ctx, notice)
-		lg.Trace().Int("notice ID", notice.ID).Msgf("%s message sending completed", pkgConst.OpSuccess)
-		notice.Status = model.StatusSent
+		sv.sendNotSv.SendNotice(ctx, storedNotice)
+		sv.grdSv.Release(ctx, storedNotice)
+
+		if deferred {
+			// the deliveries over the rate limit are sent with the notice deferred,
+			// the sent ones are kept and not sent again
+			lg.Debug().Int("notice ID", notice.ID).Time("until", retryAt).Msgf("%s rate limit exceeded, deferring notice", pkgConst.Info)
+			if err := sv.schNotSv.DeferNotice(ctx, storedNotice, stored, retryAt); err != nil {
+				lg.Error().Err(err).Int("notice ID", notice.ID).Msgf("%s failed to defer notice", pkgConst.Error)
+			}
+			return
+		}
+		storedNotice.Status = storedNotice.Deliveries.Status()
+		lg.Debug().Int("notice ID", notice.ID).Str("status", string(storedNotice.Status)).Msgf("%s message sending completed", pkgConst.OpSuccess)
+	}
+
+	// scheduling next occurrence of recurring notice
+	if storedNotice.Status != model.StatusDeleted && storedNotice.Recurrence != nil {
+		scheduled, err := sv.schNotSv.ScheduleNextOccurrence(ctx, storedNotice, stored)
+		if err != nil {
+			sv.lg.Error().Err(err).Int("notice ID", notice.ID).Msgf("%s failed to schedule next occurrence", pkgConst.Error)
+			return
+		}
+		if scheduled {
+			return
+		}
+	}
+
+	// keeping notice in repository for the retention period
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s retaining notice in repository...", pkgConst.OpStart)
+	if err := sv.updNotSv.RetainNotice(ctx, storedNotice, stored, sv.cfg.Retention); err != nil {
+		sv.lg.Error().Err(err).Int("notice ID", notice.ID).Msgf("%s failed to retain notice in repository", pkgConst.Error)
+		return
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice retained in repository", pkgConst.OpSuccess)
+}
+
+// handleChanged handles the notice changed after the consumer loaded it. A patched notice
+// is skipped, its new version is scheduled. A deleted one is retained as it is when due
+func (sv *ConsumeNoticeService) handleChanged(ctx context.Context, id int) {
+	lg := sv.lg.With().Str("method", "handleChanged").Logger()
+
+	notice, err := sv.getNotSv.GetNotice(ctx, id)
+	if err != nil {
+		lg.Error().Err(err).Int("notice ID", id).Msg("failed to get notice")
+		return
+	}
+	if notice.Status != model.StatusDeleted {
+		lg.Debug().Int("notice ID", id).Int("version", notice.Version).Msgf("%s notice changed, skipping", pkgConst.Info)
+		return
 	}
 
-	// deleting notice from repository
-	lg.Trace().Int("notice ID", notice.ID).Msgf("%s deleting message from repository...", pkgConst.OpStart)
-	if err := sv.delNotSv.DeleteNotice(ctx, notice.ID); err != nil {
-		sv.lg.Error().Err(err).Int("notice ID", notice.ID).Msgf("%s failed to delete notice from repository", pkgConst.Error)
+	lg.Trace().Int("notice ID", id).Msgf("%s retaining deleted notice in repository...", pkgConst.OpStart)
+	if err := sv.updNotSv.RetainNotice(ctx, notice, model.StatusDeleted, sv.cfg.Retention); err != nil {
+		lg.Error().Err(err).Int("notice ID", id).Msgf("%s failed to retain notice in repository", pkgConst.Error)
 		return
 	}
-	lg.Trace().Int("notice ID", notice.ID).Msgf("%s message deleted from repository", pkgConst.OpSuccess)
+	lg.Trace().Int("notice ID", id).Msgf("%s deleted notice retained in repository", pkgConst.OpSuccess)
 }
@@ -0,0 +1,155 @@
+package consumeNoticeService
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/getNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/guardNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/scheduleNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/templateService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/updateNoticeService"
+)
+
+// fakeSender records the sent occurrences and delivers every channel
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []int
+}
+
+func (s *fakeSender) SendNotice(ctx context.Context, notice *model.Notice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notice.PrepareDeliveries()
+	for i := range notice.Deliveries {
+		if notice.Deliveries[i].Status.Sendable() {
+			notice.Deliveries[i].Status = model.DeliverySent
+		}
+	}
+	s.sent = append(s.sent, notice.Occurrence)
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) PublishReady(data interface{}) error { return nil }
+
+func newService(t *testing.T) (*ConsumeNoticeService, *repository.Repository, *fakeSender) {
+	t.Helper()
+
+	rp := rpTest.New(t)
+	lg := zlog.Logger
+	getNotSv := getNoticeService.New(&lg, rp)
+	updNotSv := updateNoticeService.New(&lg, rp, templateService.New(&lg, rp))
+	schNotSv := scheduleNoticeService.New(&lg, &scheduleNoticeService.Config{}, rp, getNotSv, nopPublisher{})
+	grdSv := guardNoticeService.New(&lg, &guardNoticeService.Config{}, rp)
+	sender := &fakeSender{}
+
+	return New(&lg, &Config{Retention: time.Hour}, nil, sender, getNotSv, updNotSv, schNotSv, grdSv), rp, sender
+}
+
+// consume passes the stored notice to the consumer as the scheduler publishes it
+func consume(t *testing.T, sv *ConsumeNoticeService, rp *repository.Repository, id int) {
+	t.Helper()
+
+	notice, err := rp.LoadNotice(context.Background(), id)
+	require.NoError(t, err)
+	body, err := json.Marshal(notice)
+	require.NoError(t, err)
+
+	sv.handleMessage(context.Background(), amqp.Delivery{Body: body})
+}
+
+func TestHandleMessage_MissedOccurrences(t *testing.T) {
+	// the first four daily occurrences were due during a downtime
+	start := time.Now().UTC().Truncate(24 * time.Hour).Add(-3 * 24 * time.Hour)
+
+	addRecurring := func(t *testing.T, rp *repository.Repository, skipMissed bool) int {
+		t.Helper()
+
+		sentAt := start
+		id, err := rp.SaveNotice(context.Background(), model.Notice{
+			UserID:     1,
+			Message:    "standup",
+			Channels:   model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}},
+			CreatedAt:  start,
+			SentAt:     &sentAt,
+			Status:     model.StatusScheduled,
+			Recurrence: &model.Recurrence{Cron: "@daily", SkipMissed: skipMissed, Start: start},
+			Occurrence: 1,
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	t.Run("sent late one by one", func(t *testing.T) {
+		sv, rp, sender := newService(t)
+		id := addRecurring(t, rp, false)
+
+		consume(t, sv, rp, id)
+		consume(t, sv, rp, id)
+
+		require.Equal(t, []int{1, 2}, sender.sent)
+		notice, err := rp.LoadNotice(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, 3, notice.Occurrence)
+		require.Equal(t, model.StatusScheduled, notice.Status)
+		require.Equal(t, model.StatusSent, notice.Previous.Status)
+	})
+
+	t.Run("skip missed", func(t *testing.T) {
+		sv, rp, sender := newService(t)
+		id := addRecurring(t, rp, true)
+
+		consume(t, sv, rp, id)
+
+		require.Empty(t, sender.sent)
+		notice, err := rp.LoadNotice(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, 4, notice.Occurrence, "the next occurrence is the latest due one")
+		require.Equal(t, model.StatusSkipped, notice.Previous.Status)
+
+		consume(t, sv, rp, id)
+		require.Equal(t, []int{4}, sender.sent)
+	})
+}
+
+func TestHandleMessage_PendingNotSentAgain(t *testing.T) {
+	sv, rp, sender := newService(t)
+	ctx := context.Background()
+
+	sentAt := time.Now()
+	id, err := rp.SaveNotice(ctx, model.Notice{
+		UserID:    1,
+		Message:   "test",
+		Channels:  model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}},
+		CreatedAt: sentAt,
+		SentAt:    &sentAt,
+		Status:    model.StatusScheduled,
+	})
+	require.NoError(t, err)
+
+	// the scheduler published the notice again after its lease expired,
+	// another consumer claimed it and is still sending it
+	notice, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	notice.Status = model.StatusPending
+	require.NoError(t, rp.UpdateNotice(ctx, notice))
+
+	consume(t, sv, rp, id)
+
+	require.Empty(t, sender.sent)
+	stored, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusPending, stored.Status)
+}
@@ -16,26 +16,33 @@ type IDelRepository interface {
 
 type IGetService interface {
 	GetNotice(ctx context.Context, id int) (notice *model.Notice, err error)
+	ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error)
 }
 
 type IUpdService interface {
 	UpdateStatus(ctx context.Context, notice *model.Notice, newStatus model.Status) (err error)
 }
 
+type ISchService interface {
+	ScheduleNextOccurrence(ctx context.Context, notice *model.Notice, status model.Status) (scheduled bool, err error)
+}
+
 type DeleteNoticeService struct {
 	lg    *zlog.Zerolog
 	rpDel IDelRepository
 	svGet IGetService
 	svUpd IUpdService
+	svSch ISchService
 }
 
-func New(parentLg *zlog.Zerolog, rpDel IDelRepository, svGet IGetService, svUpd IUpdService) *DeleteNoticeService {
+func New(parentLg *zlog.Zerolog, rpDel IDelRepository, svGet IGetService, svUpd IUpdService, svSch ISchService) *DeleteNoticeService {
 	lg := zlog.Logger.With().Str("component", "deleteNoticeService").Logger()
 	return &DeleteNoticeService{
 		lg:    &lg,
 		rpDel: rpDel,
 		svGet: svGet,
 		svUpd: svUpd,
+		svSch: svSch,
 	}
 }
 
@@ -67,7 +74,7 @@ func (sv *DeleteNoticeService) PreDeleteNotice(ctx context.Context, id int) (err
 	lg.Trace().Int("notice ID", id).Msgf("%s notice got from repository successfully", pkgConst.OpSuccess)
 
 	if notice.Status != model.StatusScheduled {
-		return errors.New("failed to delete notice, notice status: " + string(notice.Status))
+		return pkgErrors.Wrapf(pkgErrors.ErrNoticeNotScheduled, "failed to delete notice, notice status: %s", notice.Status)
 	}
 
 	lg.Trace().Msgf("%s updating notice status to repository...", pkgConst.OpStart)
@@ -78,3 +85,95 @@ func (sv *DeleteNoticeService) PreDeleteNotice(ctx context.Context, id int) (err
 
 	return nil
 }
+
+// SkipOccurrence cancels the scheduled occurrence of a recurring notice and schedules
+// the next one. The notice is deleted if the cancelled occurrence was the last one
+func (sv *DeleteNoticeService) SkipOccurrence(ctx context.Context, id int) (status model.Status, err error) {
+	lg := sv.lg.With().Str("method", "SkipOccurrence").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", id).Msgf("%s getting notice from repository...", pkgConst.OpStart)
+	notice, err := sv.svGet.GetNotice(ctx, id)
+	if err != nil {
+		return "", pkgErrors.Wrap(err, "skip occurrence")
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice got from repository successfully", pkgConst.OpSuccess)
+
+	if notice.Recurrence == nil {
+		return "", pkgErrors.ErrNotRecurring
+	}
+	if notice.Status != model.StatusScheduled {
+		return "", errors.New("failed to skip occurrence, notice status: " + string(notice.Status))
+	}
+
+	// the stored notice keeps its status until it is updated
+	skipped := *notice
+	skipped.Status = model.StatusSkipped
+
+	lg.Trace().Int("notice ID", id).Int("occurrence", notice.Occurrence).Msgf("%s scheduling next occurrence...", pkgConst.OpStart)
+	scheduled, err := sv.svSch.ScheduleNextOccurrence(ctx, &skipped, model.StatusScheduled)
+	if err != nil {
+		return "", pkgErrors.Wrap(err, "skip occurrence")
+	}
+	if scheduled {
+		lg.Trace().Int("notice ID", id).Int("occurrence", skipped.Occurrence).Msgf("%s next occurrence scheduled successfully", pkgConst.OpSuccess)
+		return model.StatusSkipped, nil
+	}
+
+	// the skipped occurrence was the last one, the consumer retains the deleted notice when it is due
+	lg.Trace().Int("notice ID", id).Msgf("%s no next occurrence, updating notice status to repository...", pkgConst.OpStart)
+	if err := sv.svUpd.UpdateStatus(ctx, notice, model.StatusDeleted); err != nil {
+		return "", pkgErrors.Wrap(err, "skip occurrence")
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice status updated to repository successfully", pkgConst.OpSuccess)
+
+	return model.StatusDeleted, nil
+}
+
+// CancelUserNotices deletes all scheduled notices of the user.
+// Notices being sent are not cancelled
+func (sv *DeleteNoticeService) CancelUserNotices(ctx context.Context, userID int) (cancelled int, err error) {
+	lg := sv.lg.With().Str("method", "CancelUserNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	// the IDs are collected first: cancelled notices leave the filter and shift the pages
+	var ids []int
+	filter := model.NoticeFilter{
+		UserID:   userID,
+		Statuses: []model.Status{model.StatusScheduled},
+		Limit:    model.MaxPageLimit,
+	}
+	lg.Trace().Int("user ID", userID).Msgf("%s listing scheduled notices...", pkgConst.OpStart)
+	for {
+		page, err := sv.svGet.ListNotices(ctx, filter)
+		if err != nil {
+			return 0, pkgErrors.Wrap(err, "list scheduled notices")
+		}
+		for _, notice := range page.Notices {
+			ids = append(ids, notice.ID)
+		}
+		filter.Offset += len(page.Notices)
+		if len(page.Notices) == 0 || filter.Offset >= page.Total {
+			break
+		}
+	}
+	lg.Trace().Int("user ID", userID).Int("count", len(ids)).Msgf("%s scheduled notices listed successfully", pkgConst.OpSuccess)
+
+	for _, id := range ids {
+		err := sv.PreDeleteNotice(ctx, id)
+		if errors.Is(err, pkgErrors.ErrNoticeNotFound) || errors.Is(err, pkgErrors.ErrNoticeNotScheduled) ||
+			errors.Is(err, pkgErrors.ErrNoticeConflict) {
+			// sent, changed or deleted after it was listed
+			continue
+		}
+		if err != nil {
+			return cancelled, pkgErrors.Wrapf(err, "cancel notice, notice ID: %d", id)
+		}
+		cancelled++
+	}
+	lg.Debug().Int("user ID", userID).Int("cancelled", cancelled).Msgf("%s user notices cancelled successfully", pkgConst.OpSuccess)
+
+	return cancelled, nil
+}
@@ -2,6 +2,7 @@ package getNoticeService
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
@@ -11,6 +12,7 @@ import (
 
 type IRepository interface {
 	LoadNotice(ctx context.Context, id int) (notice *model.Notice, err error)
+	ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error)
 }
 
 type GetNoticeService struct {
@@ -40,3 +42,23 @@ func (sv *GetNoticeService) GetNotice(ctx context.Context, id int) (notice *mode
 
 	return notice, nil
 }
+
+// ListNotices returns a page of the notices of a user selected by the filter
+func (sv *GetNoticeService) ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error) {
+	lg := sv.lg.With().Str("method", "ListNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidFilter, err)
+	}
+
+	lg.Trace().Int("user ID", filter.UserID).Msgf("%s listing notices from repository...", pkgConst.OpStart)
+	page, err = sv.rp.ListNotices(ctx, filter)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "list notices from repository, user ID: %d", filter.UserID)
+	}
+	lg.Trace().Int("user ID", filter.UserID).Int("total", page.Total).Msgf("%s notices listed from repository successfully", pkgConst.OpSuccess)
+
+	return page, nil
+}
@@ -0,0 +1,78 @@
+package guardNoticeService
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/wb-go/wbf/config"
+)
+
+const (
+	defaultDedupWindow    = 10 * time.Minute
+	defaultIdempotencyTTL = 24 * time.Hour
+)
+
+// RateLimit is a token bucket of Limit deliveries refilled evenly over Interval
+type RateLimit struct {
+	Limit    int           `mapstructure:"limit"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+type Config struct {
+	// RateLimits are the limits per recipient of each channel type, channels without one are not limited
+	RateLimits map[model.ChannelType]RateLimit
+	// DedupWindow is how long the same content to the same recipient from another notice is skipped
+	DedupWindow time.Duration
+	// IdempotencyTTL is how long an idempotency key of a created notice is kept
+	IdempotencyTTL time.Duration
+}
+
+func NewConfig(cfg *config.Config) *Config {
+	c := &Config{
+		RateLimits:     make(map[model.ChannelType]RateLimit),
+		DedupWindow:    cfg.GetDuration("app.guard.dedup_window"),
+		IdempotencyTTL: cfg.GetDuration("app.guard.idempotency_ttl"),
+	}
+	if c.DedupWindow <= 0 {
+		c.DedupWindow = defaultDedupWindow
+	}
+	if c.IdempotencyTTL <= 0 {
+		c.IdempotencyTTL = defaultIdempotencyTTL
+	}
+
+	var limits map[string]RateLimit
+	if err := cfg.UnmarshalKey("app.guard.rate_limits", &limits); err == nil {
+		for channel, limit := range limits {
+			if limit.Limit > 0 && limit.Interval > 0 {
+				c.RateLimits[model.ChannelType(channel)] = limit
+			}
+		}
+	}
+
+	return c
+}
+
+func (c Config) String() string {
+	channels := make([]string, 0, len(c.RateLimits))
+	for channel := range c.RateLimits {
+		channels = append(channels, string(channel))
+	}
+	slices.Sort(channels)
+
+	limits := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		limit := c.RateLimits[model.ChannelType(channel)]
+		limits = append(limits, fmt.Sprintf("%s: %d/%v", channel, limit.Limit, limit.Interval))
+	}
+
+	return fmt.Sprintf(`guard:
+  %s: %v
+  %s: %v
+  %s: [%s]`,
+		"dedup_window", c.DedupWindow,
+		"idempotency_ttl", c.IdempotencyTTL,
+		"rate_limits", strings.Join(limits, ", "))
+}
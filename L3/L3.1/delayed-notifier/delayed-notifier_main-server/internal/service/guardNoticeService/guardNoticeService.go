@@ -0,0 +1,193 @@
+// Package guardNoticeService decides whether a notice goes out now:
+// quiet hours of the user, rate limits and duplicates per recipient
+// and idempotency keys of created notices
+package guardNoticeService
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type IGuardRepository interface {
+	LoadPreferences(ctx context.Context, userID int) (preferences *model.Preferences, err error)
+	TakeToken(ctx context.Context, channel model.ChannelType, recipient string, limit int, interval time.Duration, now time.Time) (wait time.Duration, err error)
+	ReserveContent(ctx context.Context, channel model.ChannelType, recipient, hash, owner string, window time.Duration) (reserved bool, err error)
+	ReleaseContent(ctx context.Context, channel model.ChannelType, recipient, hash, owner string) (err error)
+	ReserveIdempotencyKey(ctx context.Context, userID int, idempotencyKey, value string, ttl time.Duration) (current string, reserved bool, err error)
+	SetIdempotencyKey(ctx context.Context, userID int, idempotencyKey, value string, ttl time.Duration) (err error)
+	ReleaseIdempotencyKey(ctx context.Context, userID int, idempotencyKey, value string) (err error)
+}
+
+type GuardNoticeService struct {
+	lg  *zlog.Zerolog
+	cfg *Config
+	rp  IGuardRepository
+}
+
+func New(parentLg *zlog.Zerolog, cfg *Config, rp IGuardRepository) *GuardNoticeService {
+	lg := parentLg.With().Str("component", "GuardNoticeService").Logger()
+	return &GuardNoticeService{
+		lg:  &lg,
+		cfg: cfg,
+		rp:  rp,
+	}
+}
+
+// QuietUntil returns the end of the quiet hours of the user of the notice if now is in them
+func (sv *GuardNoticeService) QuietUntil(ctx context.Context, notice *model.Notice, now time.Time) (until time.Time, quiet bool, err error) {
+	lg := sv.lg.With().Str("method", "QuietUntil").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("user ID", notice.UserID).Msgf("%s loading preferences...", pkgConst.OpStart)
+	preferences, err := sv.rp.LoadPreferences(ctx, notice.UserID)
+	if errors.Is(err, pkgErrors.ErrPreferencesNotFound) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, pkgErrors.Wrapf(err, "load preferences, user ID: %d", notice.UserID)
+	}
+	lg.Trace().Int("user ID", notice.UserID).Msgf("%s preferences loaded successfully", pkgConst.OpSuccess)
+
+	if preferences.QuietHours == nil {
+		return time.Time{}, false, nil
+	}
+	until, quiet = preferences.QuietHours.Until(now)
+	return until, quiet, nil
+}
+
+// Admit prepares the deliveries of the notice, marks the duplicates skipped
+// and the deliveries over the rate limit of the recipient deferred.
+// It returns the time to retry the deferred deliveries at.
+// A failing check lets the delivery go
+func (sv *GuardNoticeService) Admit(ctx context.Context, notice *model.Notice, now time.Time) (retryAt time.Time, deferred bool) {
+	lg := sv.lg.With().Str("method", "Admit").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	notice.PrepareDeliveries()
+	hash := notice.ContentHash()
+	owner := strconv.Itoa(notice.ID)
+
+	for i := range notice.Deliveries {
+		dl := &notice.Deliveries[i]
+		if dl.Status == model.DeliveryDeferred {
+			dl.Status = model.DeliveryPending
+		}
+		if !dl.Status.Sendable() {
+			continue
+		}
+
+		reserved, err := sv.rp.ReserveContent(ctx, dl.Channel, dl.Recipient, hash, owner, sv.cfg.DedupWindow)
+		if err != nil {
+			lg.Warn().Err(err).Int("notice ID", notice.ID).Str("channel", string(dl.Channel)).Msgf("%s failed to check duplicate", pkgConst.Warn)
+		} else if !reserved {
+			lg.Debug().Int("notice ID", notice.ID).Str("channel", string(dl.Channel)).Msgf("%s duplicate notice, skipping", pkgConst.Info)
+			dl.Status = model.DeliverySkipped
+			dl.LastError = "duplicate of a notice sent within " + sv.cfg.DedupWindow.String()
+			continue
+		}
+
+		limit, ok := sv.cfg.RateLimits[dl.Channel]
+		if !ok {
+			continue
+		}
+		wait, err := sv.rp.TakeToken(ctx, dl.Channel, dl.Recipient, limit.Limit, limit.Interval, now)
+		if err != nil {
+			lg.Warn().Err(err).Int("notice ID", notice.ID).Str("channel", string(dl.Channel)).Msgf("%s failed to check rate limit", pkgConst.Warn)
+			continue
+		}
+		if wait > 0 {
+			lg.Debug().Int("notice ID", notice.ID).Str("channel", string(dl.Channel)).Dur("wait", wait).Msgf("%s rate limit exceeded, deferring", pkgConst.Info)
+			dl.Status = model.DeliveryDeferred
+			if at := now.Add(wait); !deferred || at.Before(retryAt) {
+				retryAt = at
+			}
+			deferred = true
+		}
+	}
+
+	return retryAt, deferred
+}
+
+// Release releases the content of the failed deliveries of the notice,
+// so that a later notice with the same content is not a duplicate
+func (sv *GuardNoticeService) Release(ctx context.Context, notice *model.Notice) {
+	lg := sv.lg.With().Str("method", "Release").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	hash := notice.ContentHash()
+	owner := strconv.Itoa(notice.ID)
+
+	for _, dl := range notice.Deliveries {
+		if dl.Status != model.DeliveryFailed {
+			continue
+		}
+		if err := sv.rp.ReleaseContent(ctx, dl.Channel, dl.Recipient, hash, owner); err != nil {
+			lg.Warn().Err(err).Int("notice ID", notice.ID).Str("channel", string(dl.Channel)).Msgf("%s failed to release content", pkgConst.Warn)
+		}
+	}
+}
+
+// ReserveRequest reserves the idempotency key of the request.
+// It returns the ID of the notice already created with the key
+// or ErrIdempotencyConflict if the key was used with another request
+func (sv *GuardNoticeService) ReserveRequest(ctx context.Context, reqNotice model.ReqNotice) (id int, err error) {
+	lg := sv.lg.With().Str("method", "ReserveRequest").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	hash := reqNotice.ContentHash()
+
+	lg.Trace().Int("user ID", reqNotice.UserID).Msgf("%s reserving idempotency key...", pkgConst.OpStart)
+	current, reserved, err := sv.rp.ReserveIdempotencyKey(ctx, reqNotice.UserID, reqNotice.IdempotencyKey, hash, sv.cfg.IdempotencyTTL)
+	if err != nil {
+		return 0, pkgErrors.Wrap(err, "reserve idempotency key")
+	}
+	if reserved {
+		lg.Trace().Int("user ID", reqNotice.UserID).Msgf("%s idempotency key reserved successfully", pkgConst.OpSuccess)
+		return 0, nil
+	}
+
+	// the value is the hash of the request and the notice ID once it is created
+	currentHash, currentID, created := strings.Cut(current, ":")
+	if currentHash != hash {
+		return 0, pkgErrors.Wrapf(pkgErrors.ErrIdempotencyConflict, "idempotency key: %s", reqNotice.IdempotencyKey)
+	}
+	if !created {
+		return 0, pkgErrors.Wrapf(pkgErrors.ErrIdempotencyPending, "idempotency key: %s", reqNotice.IdempotencyKey)
+	}
+	id, err = strconv.Atoi(currentID)
+	if err != nil {
+		return 0, pkgErrors.Wrapf(err, "parse notice ID of idempotency key: %s", reqNotice.IdempotencyKey)
+	}
+	lg.Debug().Int("notice ID", id).Msgf("%s notice already created with idempotency key", pkgConst.Info)
+
+	return id, nil
+}
+
+// ConfirmRequest keeps the ID of the notice created by the request with the idempotency key
+func (sv *GuardNoticeService) ConfirmRequest(ctx context.Context, reqNotice model.ReqNotice, id int) (err error) {
+	value := reqNotice.ContentHash() + ":" + strconv.Itoa(id)
+	if err := sv.rp.SetIdempotencyKey(ctx, reqNotice.UserID, reqNotice.IdempotencyKey, value, sv.cfg.IdempotencyTTL); err != nil {
+		return pkgErrors.Wrap(err, "confirm idempotency key")
+	}
+	return nil
+}
+
+// ReleaseRequest releases the idempotency key of a failed request, so that it can be retried
+func (sv *GuardNoticeService) ReleaseRequest(ctx context.Context, reqNotice model.ReqNotice) (err error) {
+	if err := sv.rp.ReleaseIdempotencyKey(ctx, reqNotice.UserID, reqNotice.IdempotencyKey, reqNotice.ContentHash()); err != nil {
+		return pkgErrors.Wrap(err, "release idempotency key")
+	}
+	return nil
+}
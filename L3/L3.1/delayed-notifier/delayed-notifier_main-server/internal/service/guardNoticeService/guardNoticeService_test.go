@@ -0,0 +1,136 @@
+package guardNoticeService_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/guardNoticeService"
+)
+
+func newService(t *testing.T, cfg *guardNoticeService.Config) (*guardNoticeService.GuardNoticeService, *repository.Repository) {
+	t.Helper()
+
+	rp := rpTest.New(t)
+
+	lg := zlog.Logger
+	return guardNoticeService.New(&lg, cfg, rp), rp
+}
+
+func TestQuietUntil(t *testing.T) {
+	sv, rp := newService(t, &guardNoticeService.Config{})
+	ctx := context.Background()
+	notice := &model.Notice{ID: 1, UserID: 1}
+	night := time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
+
+	_, quiet, err := sv.QuietUntil(ctx, notice, night)
+	require.NoError(t, err)
+	require.False(t, quiet, "no preferences")
+
+	require.NoError(t, rp.SavePreferences(ctx, model.Preferences{
+		UserID:     1,
+		QuietHours: &model.QuietHours{Start: "22:00", End: "08:00"},
+	}))
+	until, quiet, err := sv.QuietUntil(ctx, notice, night)
+	require.NoError(t, err)
+	require.True(t, quiet)
+	require.Equal(t, time.Date(2026, 1, 11, 8, 0, 0, 0, time.UTC), until.UTC())
+}
+
+func TestAdmit(t *testing.T) {
+	sv, _ := newService(t, &guardNoticeService.Config{
+		DedupWindow: time.Minute,
+		RateLimits: map[model.ChannelType]guardNoticeService.RateLimit{
+			model.ChannelTelegram: {Limit: 1, Interval: time.Minute},
+		},
+	})
+	ctx := context.Background()
+	now := time.Now()
+
+	newNotice := func(id int, message string) *model.Notice {
+		return &model.Notice{
+			ID:      id,
+			Message: message,
+			Channels: model.Channels{
+				{Type: model.ChannelTelegram, Value: "user"},
+				{Type: model.ChannelEmail, Value: "user@example.com"},
+			},
+		}
+	}
+	statuses := func(n *model.Notice) []model.DeliveryStatus {
+		var ss []model.DeliveryStatus
+		for _, dl := range n.Deliveries {
+			ss = append(ss, dl.Status)
+		}
+		return ss
+	}
+
+	first := newNotice(1, "hello")
+	_, deferred := sv.Admit(ctx, first, now)
+	require.False(t, deferred)
+	require.Equal(t, []model.DeliveryStatus{model.DeliveryPending, model.DeliveryPending}, statuses(first))
+
+	duplicate := newNotice(2, "hello")
+	_, deferred = sv.Admit(ctx, duplicate, now)
+	require.False(t, deferred, "duplicates take no tokens")
+	require.Equal(t, []model.DeliveryStatus{model.DeliverySkipped, model.DeliverySkipped}, statuses(duplicate))
+	require.Equal(t, model.StatusSkipped, duplicate.Deliveries.Status())
+
+	other := newNotice(3, "bye")
+	retryAt, deferred := sv.Admit(ctx, other, now)
+	require.True(t, deferred)
+	require.Equal(t, now.Add(time.Minute).UnixMilli(), retryAt.UnixMilli())
+	require.Equal(t, []model.DeliveryStatus{model.DeliveryDeferred, model.DeliveryPending}, statuses(other), "e-mail is not limited")
+
+	// the e-mail is sent, the deferred telegram delivery goes out once a token is refilled
+	other.Deliveries[1].Status = model.DeliverySent
+	_, deferred = sv.Admit(ctx, other, now.Add(time.Minute))
+	require.False(t, deferred)
+	require.Equal(t, []model.DeliveryStatus{model.DeliveryPending, model.DeliverySent}, statuses(other), "the same notice is not a duplicate")
+
+	// a failed delivery releases the content
+	first.Deliveries[1].Status = model.DeliveryFailed
+	sv.Release(ctx, first)
+	again := newNotice(4, "hello")
+	sv.Admit(ctx, again, now.Add(2*time.Minute))
+	require.Equal(t, []model.DeliveryStatus{model.DeliverySkipped, model.DeliveryPending}, statuses(again))
+}
+
+func TestReserveRequest(t *testing.T) {
+	sv, _ := newService(t, &guardNoticeService.Config{IdempotencyTTL: time.Hour})
+	ctx := context.Background()
+
+	req := model.ReqNotice{UserID: 1, Message: "hello", IdempotencyKey: "key"}
+
+	id, err := sv.ReserveRequest(ctx, req)
+	require.NoError(t, err)
+	require.Zero(t, id)
+
+	_, err = sv.ReserveRequest(ctx, req)
+	require.ErrorIs(t, err, pkgErrors.ErrIdempotencyPending)
+
+	require.NoError(t, sv.ConfirmRequest(ctx, req, 7))
+	id, err = sv.ReserveRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 7, id, "the notice created with the key")
+
+	changed := req
+	changed.Message = "bye"
+	_, err = sv.ReserveRequest(ctx, changed)
+	require.ErrorIs(t, err, pkgErrors.ErrIdempotencyConflict)
+
+	failed := model.ReqNotice{UserID: 1, Message: "hello", IdempotencyKey: "failed"}
+	_, err = sv.ReserveRequest(ctx, failed)
+	require.NoError(t, err)
+	require.NoError(t, sv.ReleaseRequest(ctx, failed))
+	id, err = sv.ReserveRequest(ctx, failed)
+	require.NoError(t, err)
+	require.Zero(t, id, "a released key can be used again")
+}
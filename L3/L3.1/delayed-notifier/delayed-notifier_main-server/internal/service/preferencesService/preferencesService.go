@@ -0,0 +1,71 @@
+package preferencesService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type IPreferencesRepository interface {
+	SavePreferences(ctx context.Context, preferences model.Preferences) (err error)
+	LoadPreferences(ctx context.Context, userID int) (preferences *model.Preferences, err error)
+}
+
+type PreferencesService struct {
+	lg *zlog.Zerolog
+	rp IPreferencesRepository
+}
+
+func New(parentLg *zlog.Zerolog, rp IPreferencesRepository) *PreferencesService {
+	lg := parentLg.With().Str("component", "PreferencesService").Logger()
+	return &PreferencesService{
+		lg: &lg,
+		rp: rp,
+	}
+}
+
+// SavePreferences creates or replaces the preferences of the user
+func (sv *PreferencesService) SavePreferences(ctx context.Context, preferences model.Preferences) (saved *model.Preferences, err error) {
+	lg := sv.lg.With().Str("method", "SavePreferences").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("user ID", preferences.UserID).Msgf("%s validating preferences...", pkgConst.OpStart)
+	if err := preferences.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidPreferences, err)
+	}
+	lg.Trace().Int("user ID", preferences.UserID).Msgf("%s preferences validated successfully", pkgConst.OpSuccess)
+
+	preferences.UpdatedAt = time.Now()
+
+	lg.Trace().Int("user ID", preferences.UserID).Msgf("%s saving preferences to repository...", pkgConst.OpStart)
+	if err := sv.rp.SavePreferences(ctx, preferences); err != nil {
+		return nil, pkgErrors.Wrap(err, "save preferences to repository")
+	}
+	lg.Debug().Int("user ID", preferences.UserID).Msgf("%s preferences saved to repository successfully", pkgConst.OpSuccess)
+
+	return &preferences, nil
+}
+
+// GetPreferences returns the preferences of the user, empty ones if the user has not set them
+func (sv *PreferencesService) GetPreferences(ctx context.Context, userID int) (preferences *model.Preferences, err error) {
+	lg := sv.lg.With().Str("method", "GetPreferences").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	preferences, err = sv.rp.LoadPreferences(ctx, userID)
+	if errors.Is(err, pkgErrors.ErrPreferencesNotFound) {
+		return &model.Preferences{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "get preferences from repository")
+	}
+
+	return preferences, nil
+}
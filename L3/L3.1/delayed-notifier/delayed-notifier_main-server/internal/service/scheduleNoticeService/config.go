@@ -0,0 +1,49 @@
+package scheduleNoticeService
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const (
+	defaultPollInterval = 100 * time.Millisecond
+	defaultLease        = 30 * time.Second
+	defaultBatchSize    = 100
+)
+
+type Config struct {
+	// PollInterval bounds how late a due notice is published
+	PollInterval time.Duration
+	// Lease is how long a claimed notice waits before it is claimed again
+	// if the instance that claimed it failed to publish it
+	Lease     time.Duration
+	BatchSize int
+}
+
+func NewConfig(cfg *config.Config) *Config {
+	c := &Config{
+		PollInterval: cfg.GetDuration("app.scheduler.poll_interval"),
+		Lease:        cfg.GetDuration("app.scheduler.lease"),
+		BatchSize:    cfg.GetInt("app.scheduler.batch_size"),
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	if c.Lease <= 0 {
+		c.Lease = defaultLease
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	return c
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf(`scheduler:
+  %s: %v, %s: %v, %s: %d`,
+		"poll_interval", c.PollInterval,
+		"lease", c.Lease,
+		"batch_size", c.BatchSize)
+}
@@ -0,0 +1,191 @@
+// Package scheduleNoticeService publishes notices to the message broker when they are due.
+//
+// Due times are kept in a Redis sorted set, so a notice due in a minute is published
+// on time regardless of notices due later that were added before it.
+// Several instances may run the scheduler: a due notice is claimed by one of them
+// and claimed again only if it is not published within the lease.
+package scheduleNoticeService
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type IScheduleRepository interface {
+	ClaimDueNotices(ctx context.Context, now time.Time, lease time.Duration, limit int) (ids []int, err error)
+	ReleaseNotice(ctx context.Context, id int, claimedUntil time.Time) (err error)
+	ScheduleNotice(ctx context.Context, id int, dueAt time.Time) (err error)
+	UpdateNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status) (err error)
+}
+
+type iGetNoticeService interface {
+	GetNotice(ctx context.Context, id int) (notice *model.Notice, err error)
+}
+
+type iPublisher interface {
+	PublishReady(data interface{}) error
+}
+
+type ScheduleNoticeService struct {
+	lg       *zlog.Zerolog
+	cfg      *Config
+	rp       IScheduleRepository
+	getNotSv iGetNoticeService
+	rb       iPublisher
+}
+
+func New(
+	parentLg *zlog.Zerolog,
+	cfg *Config,
+	rp IScheduleRepository,
+	getNotSv iGetNoticeService,
+	rb iPublisher,
+) *ScheduleNoticeService {
+	lg := parentLg.With().Str("component", "ScheduleNoticeService").Logger()
+	return &ScheduleNoticeService{
+		lg:       &lg,
+		cfg:      cfg,
+		rp:       rp,
+		getNotSv: getNotSv,
+		rb:       rb,
+	}
+}
+
+// RunScheduler publishes due notices every PollInterval until ctx is cancelled
+func (sv *ScheduleNoticeService) RunScheduler(ctx context.Context) {
+	lg := sv.lg.With().Str("method", "RunScheduler").Logger()
+	lg.Info().Msgf("%s scheduler started", pkgConst.Finished)
+	defer lg.Info().Msgf("%s scheduler stopped", pkgConst.Stop)
+
+	ticker := time.NewTicker(sv.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// a full batch means more notices may be due already
+		if sv.publishDue(ctx) == sv.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDue publishes one batch of due notices and returns the number of claimed notices
+func (sv *ScheduleNoticeService) publishDue(ctx context.Context) int {
+	lg := sv.lg.With().Str("method", "publishDue").Logger()
+
+	now := time.Now()
+	claimedUntil := now.Add(sv.cfg.Lease)
+
+	ids, err := sv.rp.ClaimDueNotices(ctx, now, sv.cfg.Lease, sv.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			lg.Error().Err(err).Msgf("%s failed to claim due notices", pkgConst.Error)
+		}
+		return 0
+	}
+
+	for _, id := range ids {
+		if err := sv.publishNotice(ctx, id, claimedUntil); err != nil {
+			// the notice is claimed again after the lease
+			lg.Error().Err(err).Int("notice ID", id).Msgf("%s failed to publish due notice", pkgConst.Error)
+		}
+	}
+
+	return len(ids)
+}
+
+func (sv *ScheduleNoticeService) publishNotice(ctx context.Context, id int, claimedUntil time.Time) error {
+	lg := sv.lg.With().Str("method", "publishNotice").Logger()
+
+	notice, err := sv.getNotSv.GetNotice(ctx, id)
+	if errors.Is(err, pkgErrors.ErrNoticeNotFound) {
+		lg.Debug().Int("notice ID", id).Msgf("%s scheduled notice not found, unscheduling", pkgConst.Warn)
+		return sv.rp.ReleaseNotice(ctx, id, claimedUntil)
+	}
+	if err != nil {
+		return pkgErrors.Wrap(err, "get due notice")
+	}
+	if notice.DueAt().After(time.Now()) {
+		// the notice was changed after it was claimed and is scheduled at its new time
+		lg.Debug().Int("notice ID", id).Time("due at", notice.DueAt()).Msgf("%s notice rescheduled, skipping", pkgConst.Info)
+		return nil
+	}
+
+	lg.Trace().Int("notice ID", id).Msgf("%s publishing due notice to message broker...", pkgConst.OpStart)
+	if err := sv.rb.PublishReady(notice); err != nil {
+		return pkgErrors.Wrap(err, "publish due notice to message broker")
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s due notice published to message broker successfully", pkgConst.OpSuccess)
+
+	if err := sv.rp.ReleaseNotice(ctx, id, claimedUntil); err != nil {
+		// published, but may be published again after the lease
+		return pkgErrors.Wrap(err, "release published notice")
+	}
+
+	return nil
+}
+
+// ScheduleNextOccurrence moves a recurring notice to its next occurrence and schedules it
+// if the stored notice has the version of the copy and the status. It returns false if the notice
+// is not recurring or its series is over and ErrNoticeConflict if the notice was changed
+func (sv *ScheduleNoticeService) ScheduleNextOccurrence(ctx context.Context, notice *model.Notice, status model.Status) (scheduled bool, err error) {
+	lg := sv.lg.With().Str("method", "ScheduleNextOccurrence").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	if !notice.Advance(time.Now()) {
+		lg.Debug().Int("notice ID", notice.ID).Msgf("%s no next occurrence", pkgConst.Info)
+		return false, nil
+	}
+
+	lg.Trace().Int("notice ID", notice.ID).Int("occurrence", notice.Occurrence).Msgf("%s updating notice to repository...", pkgConst.OpStart)
+	if err := sv.rp.UpdateNoticeIf(ctx, notice, notice.Version, status); err != nil {
+		return false, pkgErrors.Wrap(err, "update notice to repository")
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice updated to repository successfully", pkgConst.OpSuccess)
+
+	lg.Trace().Int("notice ID", notice.ID).Time("due at", *notice.SentAt).Msgf("%s scheduling next occurrence...", pkgConst.OpStart)
+	if err := sv.rp.ScheduleNotice(ctx, notice.ID, *notice.SentAt); err != nil {
+		return false, pkgErrors.Wrap(err, "schedule next occurrence")
+	}
+	lg.Debug().Int("notice ID", notice.ID).Int("occurrence", notice.Occurrence).Time("due at", *notice.SentAt).Msgf("%s next occurrence scheduled successfully", pkgConst.OpSuccess)
+
+	return true, nil
+}
+
+// DeferNotice schedules the current occurrence of the notice again at until,
+// the notice keeps its sent_at and occurrence. It returns ErrNoticeConflict
+// if the stored notice does not have the version of the copy and the status
+func (sv *ScheduleNoticeService) DeferNotice(ctx context.Context, notice *model.Notice, status model.Status, until time.Time) (err error) {
+	lg := sv.lg.With().Str("method", "DeferNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	notice.DeferredUntil = &until
+	notice.Status = model.StatusScheduled
+
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s updating notice to repository...", pkgConst.OpStart)
+	if err := sv.rp.UpdateNoticeIf(ctx, notice, notice.Version, status); err != nil {
+		return pkgErrors.Wrap(err, "update notice to repository")
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice updated to repository successfully", pkgConst.OpSuccess)
+
+	lg.Trace().Int("notice ID", notice.ID).Time("due at", until).Msgf("%s scheduling deferred notice...", pkgConst.OpStart)
+	if err := sv.rp.ScheduleNotice(ctx, notice.ID, until); err != nil {
+		return pkgErrors.Wrap(err, "schedule deferred notice")
+	}
+	lg.Debug().Int("notice ID", notice.ID).Time("due at", until).Msgf("%s deferred notice scheduled successfully", pkgConst.OpSuccess)
+
+	return nil
+}
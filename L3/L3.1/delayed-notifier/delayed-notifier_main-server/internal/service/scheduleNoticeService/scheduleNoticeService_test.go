@@ -0,0 +1,225 @@
+package scheduleNoticeService_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/getNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/scheduleNoticeService"
+)
+
+type published struct {
+	id int
+	at time.Time
+}
+
+// fakePublisher records published notices and fails the first failures publishes
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	published []published
+}
+
+func (p *fakePublisher) PublishReady(data interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, published{id: data.(*model.Notice).ID, at: time.Now()})
+	return nil
+}
+
+func (p *fakePublisher) list() []published {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]published(nil), p.published...)
+}
+
+func addNotice(t *testing.T, rp *repository.Repository, dueAt time.Time) int {
+	t.Helper()
+
+	ctx := context.Background()
+	id, err := rp.SaveNotice(ctx, model.Notice{
+		UserID:    1,
+		Message:   "test",
+		Channels:  model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}},
+		CreatedAt: time.Now(),
+		SentAt:    &dueAt,
+		Status:    model.StatusScheduled,
+	})
+	require.NoError(t, err)
+	require.NoError(t, rp.ScheduleNotice(ctx, id, dueAt))
+	return id
+}
+
+func runScheduler(t *testing.T, cfg *scheduleNoticeService.Config, rp *repository.Repository, pub *fakePublisher) {
+	t.Helper()
+
+	lg := zlog.Logger
+	sv := scheduleNoticeService.New(&lg, cfg, rp, getNoticeService.New(&lg, rp), pub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sv.RunScheduler(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestRunScheduler_OutOfOrderDueTimes(t *testing.T) {
+	rp := rpTest.New(t)
+	pub := &fakePublisher{}
+	const pollInterval = 20 * time.Millisecond
+
+	start := time.Now()
+	// notices added first are due later, with a per-message TTL queue
+	// the first one would hold back all the others
+	inThreeDays := addNotice(t, rp, start.Add(72*time.Hour))
+	inSecond := addNotice(t, rp, start.Add(time.Second))
+	inHalfSecond := addNotice(t, rp, start.Add(500*time.Millisecond))
+	now := addNotice(t, rp, start)
+
+	runScheduler(t, &scheduleNoticeService.Config{PollInterval: pollInterval, Lease: time.Minute, BatchSize: 2}, rp, pub)
+
+	require.Eventually(t, func() bool { return len(pub.list()) == 3 }, 3*time.Second, pollInterval)
+
+	due := map[int]time.Time{
+		now:          start,
+		inHalfSecond: start.Add(500 * time.Millisecond),
+		inSecond:     start.Add(time.Second),
+	}
+	got := pub.list()
+	require.Equal(t, []int{now, inHalfSecond, inSecond}, []int{got[0].id, got[1].id, got[2].id})
+	for _, p := range got {
+		require.False(t, p.at.Before(due[p.id]), "notice %d published %v before due time", p.id, due[p.id].Sub(p.at))
+		require.Less(t, p.at.Sub(due[p.id]), 10*pollInterval, "notice %d published late", p.id)
+	}
+
+	ids, err := rp.ClaimDueNotices(context.Background(), start.Add(73*time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{inThreeDays}, ids, "only the notice due in three days stays scheduled")
+}
+
+func TestRunScheduler_RetriesAfterLease(t *testing.T) {
+	rp := rpTest.New(t)
+	pub := &fakePublisher{failures: 1}
+	const lease = 200 * time.Millisecond
+
+	id := addNotice(t, rp, time.Now())
+	failedAt := time.Now()
+
+	runScheduler(t, &scheduleNoticeService.Config{PollInterval: 10 * time.Millisecond, Lease: lease, BatchSize: 10}, rp, pub)
+
+	require.Eventually(t, func() bool { return len(pub.list()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	got := pub.list()[0]
+	require.Equal(t, id, got.id)
+	require.GreaterOrEqual(t, got.at.Sub(failedAt), lease, "notice claimed again before the lease expired")
+
+	// deleted notices are unscheduled without publishing
+	require.NoError(t, rp.ScheduleNotice(context.Background(), id+100, time.Now()))
+	require.Never(t, func() bool { return len(pub.list()) > 1 }, 100*time.Millisecond, 10*time.Millisecond)
+	ids, err := rp.ClaimDueNotices(context.Background(), time.Now().Add(time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+func TestScheduleNextOccurrence_KeptAfterRelease(t *testing.T) {
+	rp := rpTest.New(t)
+	ctx := context.Background()
+	lg := zlog.Logger
+	sv := scheduleNoticeService.New(&lg, &scheduleNoticeService.Config{}, rp, getNoticeService.New(&lg, rp), &fakePublisher{})
+
+	sentAt := time.Now().Truncate(time.Hour)
+	id, err := rp.SaveNotice(ctx, model.Notice{
+		UserID:     1,
+		Message:    "test",
+		Channels:   model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}},
+		CreatedAt:  sentAt.Add(-time.Minute),
+		SentAt:     &sentAt,
+		Status:     model.StatusScheduled,
+		Recurrence: &model.Recurrence{Cron: "0 * * * *", Count: 2, Start: sentAt},
+		Occurrence: 1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, rp.ScheduleNotice(ctx, id, sentAt))
+
+	now := time.Now()
+	ids, err := rp.ClaimDueNotices(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{id}, ids)
+
+	// the occurrence is delivered and the next one is scheduled before the scheduler releases the claim
+	notice, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	notice.Status = model.StatusSent
+	scheduled, err := sv.ScheduleNextOccurrence(ctx, notice, model.StatusScheduled)
+	require.NoError(t, err)
+	require.True(t, scheduled)
+	require.NoError(t, rp.ReleaseNotice(ctx, id, now.Add(time.Minute)))
+
+	nextHour := sentAt.Add(time.Hour)
+	ids, err = rp.ClaimDueNotices(ctx, nextHour, time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{id}, ids, "next occurrence stays scheduled")
+
+	notice, err = rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, 2, notice.Occurrence)
+	require.True(t, notice.SentAt.Equal(nextHour))
+	require.Equal(t, model.StatusScheduled, notice.Status)
+	require.Equal(t, model.StatusSent, notice.Previous.Status)
+
+	scheduled, err = sv.ScheduleNextOccurrence(ctx, notice, model.StatusScheduled)
+	require.NoError(t, err)
+	require.False(t, scheduled, "series is over after count occurrences")
+}
+
+func TestDeferNotice_KeptAfterRelease(t *testing.T) {
+	rp := rpTest.New(t)
+	ctx := context.Background()
+	lg := zlog.Logger
+	sv := scheduleNoticeService.New(&lg, &scheduleNoticeService.Config{}, rp, getNoticeService.New(&lg, rp), &fakePublisher{})
+
+	now := time.Now()
+	id := addNotice(t, rp, now)
+	ids, err := rp.ClaimDueNotices(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{id}, ids)
+
+	notice, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	sentAt := *notice.SentAt
+	notice.Status = model.StatusPending
+	until := now.Add(time.Hour)
+	require.NoError(t, sv.DeferNotice(ctx, notice, model.StatusScheduled, until))
+	require.NoError(t, rp.ReleaseNotice(ctx, id, now.Add(time.Minute)))
+
+	ids, err = rp.ClaimDueNotices(ctx, now.Add(30*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, ids, "deferred notice is not due before until")
+	ids, err = rp.ClaimDueNotices(ctx, until, time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{id}, ids)
+
+	notice, err = rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusScheduled, notice.Status)
+	require.True(t, notice.SentAt.Equal(sentAt), "sent_at is kept")
+	require.True(t, notice.DeferredUntil.Equal(until))
+}
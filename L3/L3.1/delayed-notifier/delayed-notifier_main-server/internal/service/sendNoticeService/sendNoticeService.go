@@ -2,128 +2,171 @@ package sendNoticeService
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
-	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgEmail"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRetry"
-	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/provider"
 	"github.com/wb-go/wbf/retry"
 	"github.com/wb-go/wbf/zlog"
 )
 
-type IRepository interface {
-	LoadTelegramChatID(ctx context.Context, username string) (chatID int64, err error)
+type iProviderRegistry interface {
+	Provider(t model.ChannelType) (provider.Provider, bool)
+}
+
+type iTemplateService interface {
+	RenderTemplate(ctx context.Context, ref model.TemplateRef) (msg *model.RenderedMessage, err error)
 }
 
 type SendNoticeService struct {
-	lg *zlog.Zerolog
-	rs *pkgRetry.Retry
-	tg *pkgTelegram.Client
-	em *pkgEmail.Client
-	rp IRepository
+	lg        *zlog.Zerolog
+	rs        *pkgRetry.Retry
+	providers iProviderRegistry
+	tplSv     iTemplateService
 }
 
 func New(
 	parentLg *zlog.Zerolog,
 	rs *pkgRetry.Retry,
-	tg *pkgTelegram.Client,
-	em *pkgEmail.Client,
-	rp IRepository,
+	providers iProviderRegistry,
+	tplSv iTemplateService,
 ) *SendNoticeService {
 	lg := parentLg.With().Str("component", "SendNoticeService").Logger()
 	return &SendNoticeService{
-		lg: &lg,
-		rs: rs,
-		tg: tg,
-		em: em,
-		rp: rp,
+		lg:        &lg,
+		rs:        rs,
+		providers: providers,
+		tplSv:     tplSv,
 	}
 }
 
-func (sv *SendNoticeService) SendNotice(ctx context.Context, notice model.Notice) {
+// SendNotice sends the notice to all its channels and records a delivery
+// per channel in notice.Deliveries. Channels that already got the notice
+// on a previous attempt, skipped and deferred ones are not sent
+func (sv *SendNoticeService) SendNotice(ctx context.Context, notice *model.Notice) {
 	lg := sv.lg.With().Str("method", "SendNotice").Logger()
 	lg.Trace().Msgf("%s method starting", pkgConst.Start)
 	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
 
+	notice.PrepareDeliveries()
+	deliveries := notice.Deliveries
+
+	msg, err := sv.message(ctx, notice)
+	if err != nil {
+		lg.Error().Err(err).Int("notice ID", notice.ID).Msgf("%s failed to render notice message", pkgConst.Error)
+		for i := range deliveries {
+			if deliveries[i].Status.Sendable() {
+				finish(&deliveries[i], err)
+			}
+		}
+		return
+	}
+
 	wg := sync.WaitGroup{}
 
-	for _, ch := range notice.Channels {
+	for i := range deliveries {
+		dl := &deliveries[i]
+		if !dl.Status.Sendable() {
+			continue
+		}
 		wg.Go(func() {
-			switch ch.Type {
-			case model.ChannelTelegram:
-				if ch.Value != "" {
-					sv.SendNoticeToTelegram(ctx, ch.Value, notice)
-				}
-			case model.ChannelEmail:
-				if ch.Value != "" {
-					sv.SendNoticeToEmail(ctx, ch.Value, notice)
-				}
+			if err := sv.deliver(ctx, dl, notice, msg); err != nil {
+				lg.Error().Err(err).Int("notice ID", notice.ID).Str("channel", string(dl.Channel)).Msgf("%s failed to deliver notice", pkgConst.Error)
 			}
 		})
 	}
 	wg.Wait()
 }
 
-func (sv *SendNoticeService) SendNoticeToTelegram(ctx context.Context, username string, notice model.Notice) error {
-	lg := sv.lg.With().Str("method", "SendNoticeToTelegram").Logger()
-	lg.Trace().Msgf("%s method starting", pkgConst.Start)
-	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
-
-	lg.Trace().Str("username", username).Int("notice ID", notice.ID).Msgf("%s loading chat ID from repository...", pkgConst.OpStart)
-	chatID, err := sv.rp.LoadTelegramChatID(ctx, username)
+// message renders the template of the notice or returns its plain message
+func (sv *SendNoticeService) message(ctx context.Context, notice *model.Notice) (*model.RenderedMessage, error) {
+	if notice.Template == nil {
+		return model.PlainMessage(notice.Message), nil
+	}
+	msg, err := sv.tplSv.RenderTemplate(ctx, *notice.Template)
 	if err != nil {
-		return pkgErrors.Wrapf(err, "load telegram chat id from repository;  username: %s, notice ID: %d", username, notice.ID)
+		return nil, pkgErrors.Wrapf(err, "render template %s", notice.Template.Name)
 	}
-	lg.Trace().Str("username", username).Int("notice ID", notice.ID).Int64("chat ID", chatID).Msgf("%s chat ID loaded from repository successfully", pkgConst.OpSuccess)
+	return msg, nil
+}
 
-	fn := func() error {
-		lg.Trace().Str("username", username).Int("notice ID", notice.ID).Msgf("%s sending message to Telegram...", pkgConst.OpStart)
-		err := sv.tg.SendTo(chatID, notice.Message)
-		if err != nil {
-			sv.lg.Warn().Err(err).Int64("chat ID", chatID).Int("notice ID", notice.ID).Msgf("%s failed to send notice to telegram", pkgConst.Warn)
-			return err
-		}
-		lg.Trace().Str("username", username).Int("notice ID", notice.ID).Int64("chat ID", chatID).Msgf("%s message sended to Telegram successfully", pkgConst.OpSuccess)
-		return nil
+// attempt records the result of one sending attempt in the delivery
+func attempt(dl *model.Delivery, providerMessageID string, err error) {
+	now := time.Now()
+	dl.Attempts++
+	dl.LastAttemptAt = &now
+	if err != nil {
+		dl.LastError = err.Error()
+		return
 	}
+	dl.LastError = ""
+	dl.ProviderMessageID = providerMessageID
+}
 
-	lg.Trace().Str("username", username).Int("notice ID", notice.ID).Msgf("%s sending message to Telegram with retry starting...", pkgConst.OpStart)
-	if err := retry.Do(fn, retry.Strategy(*sv.rs)); err != nil {
-		return pkgErrors.Wrapf(err,
-			"send notice to telegram after all ettempts; chat ID: %d, notice ID: %d, attempts: %d",
-			chatID, notice.ID, sv.rs.Attempts)
+// finish sets the final status of the delivery after all attempts
+func finish(dl *model.Delivery, err error) {
+	if err != nil {
+		dl.Status = model.DeliveryFailed
+		dl.LastError = err.Error()
+		return
 	}
-	lg.Debug().Str("username", username).Int("notice ID", notice.ID).Int64("chat ID", chatID).Msgf("%s sending message to Telegram with retry completed", pkgConst.OpSuccess)
-
-	return nil
+	dl.Status = model.DeliverySent
+	dl.DeliveredAt = dl.LastAttemptAt
 }
 
-func (sv *SendNoticeService) SendNoticeToEmail(ctx context.Context, email string, notice model.Notice) error {
-	lg := sv.lg.With().Str("method", "SendNoticeToEmail").Logger()
+// deliver sends the message to the recipient of the delivery with retry.
+// Permanent errors of the provider stop the retry
+func (sv *SendNoticeService) deliver(ctx context.Context, dl *model.Delivery, notice *model.Notice, msg *model.RenderedMessage) (err error) {
+	lg := sv.lg.With().Str("method", "deliver").Str("channel", string(dl.Channel)).Logger()
 	lg.Trace().Msgf("%s method starting", pkgConst.Start)
 	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
 
+	defer func() { finish(dl, err) }()
+
+	p, ok := sv.providers.Provider(dl.Channel)
+	if !ok {
+		return pkgErrors.Wrapf(model.ErrInvalidTypeBase, "no provider for channel %s; notice ID: %d", dl.Channel, notice.ID)
+	}
+
+	env := provider.Envelope{
+		NoticeID:   notice.ID,
+		Occurrence: notice.Occurrence,
+		Recipient:  dl.Recipient,
+		Message:    msg,
+	}
+
+	var permanentErr error
 	fn := func() error {
-		lg.Trace().Str("e-mail", email).Int("notice ID", notice.ID).Msgf("%s sending message to e-mail...", pkgConst.OpStart)
-		err := sv.em.SendEmail([]string{email}, "delayed-notifier", notice.Message, false)
-		if err != nil {
-			sv.lg.Warn().Err(err).Str("e-mail", email).Int("notice ID", notice.ID).Msgf("%s failed to send notice to e-mail", pkgConst.Warn)
-		} else {
-			lg.Trace().Str("e-mail", email).Int("notice ID", notice.ID).Msgf("%s message sended to e-mail successfully", pkgConst.OpSuccess)
+		lg.Trace().Str("recipient", dl.Recipient).Int("notice ID", notice.ID).Msgf("%s sending message...", pkgConst.OpStart)
+		messageID, err := p.Send(ctx, env)
+		attempt(dl, messageID, err)
+		switch {
+		case errors.Is(err, provider.ErrPermanent):
+			permanentErr = err
+			return nil
+		case err != nil:
+			lg.Warn().Err(err).Str("recipient", dl.Recipient).Int("notice ID", notice.ID).Msgf("%s failed to send notice", pkgConst.Warn)
+			return err
 		}
-		return err
+		lg.Trace().Str("recipient", dl.Recipient).Int("notice ID", notice.ID).Msgf("%s message sended successfully", pkgConst.OpSuccess)
+		return nil
 	}
 
-	lg.Trace().Str("e-mail", email).Int("notice ID", notice.ID).Msgf("%s sending message to e-mail with retry starting...", pkgConst.OpStart)
+	lg.Trace().Str("recipient", dl.Recipient).Int("notice ID", notice.ID).Msgf("%s sending message with retry starting...", pkgConst.OpStart)
 	if err := retry.Do(fn, retry.Strategy(*sv.rs)); err != nil {
 		return pkgErrors.Wrapf(err,
-			"send notice to e-mail after all ettempts; e-mail: %s, notice ID: %d, attempts: %d",
-			email, notice.ID, sv.rs.Attempts)
+			"send notice to %s after all attempts; recipient: %s, notice ID: %d, attempts: %d",
+			dl.Channel, dl.Recipient, notice.ID, sv.rs.Attempts)
+	}
+	if permanentErr != nil {
+		return pkgErrors.Wrapf(permanentErr, "send notice to %s; recipient: %s, notice ID: %d", dl.Channel, dl.Recipient, notice.ID)
 	}
-	lg.Debug().Str("e-mail", email).Int("notice ID", notice.ID).Msgf("%s sending message to e-mail with retry completed", pkgConst.OpSuccess)
+	lg.Debug().Str("recipient", dl.Recipient).Int("notice ID", notice.ID).Msgf("%s sending message with retry completed", pkgConst.OpSuccess)
 
 	return nil
 }
@@ -1,27 +1,39 @@
 package service
 
 import (
-	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgEmail"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRabbitmq"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgRetry"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/provider"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/addNoticeService"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/consumeNoticeService"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/deleteNoticeService"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/getNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/guardNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/preferencesService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/scheduleNoticeService"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/sendNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/telegramBotService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/telegramPollService"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/telegramStartService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/templateService"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/updateNoticeService"
 	"github.com/wb-go/wbf/zlog"
 )
 
 type iRepository interface {
 	addNoticeService.ISaveNoticeRepository
+	addNoticeService.IScheduleNoticeRepository
+	scheduleNoticeService.IScheduleRepository
 	deleteNoticeService.IDelRepository
 	getNoticeService.IRepository
-	sendNoticeService.IRepository
 	updateNoticeService.IUpdateNoticeRepository
 	telegramStartService.IRepository
+	telegramBotService.IRepository
+	templateService.ITemplateRepository
+	guardNoticeService.IGuardRepository
+	preferencesService.IPreferencesRepository
+	telegramPollService.IOffsetRepository
 }
 
 type Service struct {
@@ -29,24 +41,54 @@ type Service struct {
 	*deleteNoticeService.DeleteNoticeService
 	*getNoticeService.GetNoticeService
 	*telegramStartService.TelegramStartService
+	*telegramBotService.TelegramBotService
+	*telegramPollService.TelegramPollService
 	*consumeNoticeService.ConsumeNoticeService
+	*scheduleNoticeService.ScheduleNoticeService
 	*sendNoticeService.SendNoticeService
 	*updateNoticeService.UpdateNoticeService
+	*templateService.TemplateService
+	*guardNoticeService.GuardNoticeService
+	*preferencesService.PreferencesService
 }
 
-func New(rs *pkgRetry.Retry, rp iRepository, rb *pkgRabbitmq.Client, tg *pkgTelegram.Client, em *pkgEmail.Client) *Service {
+func New(
+	rs *pkgRetry.Retry,
+	addCfg *addNoticeService.Config,
+	schCfg *scheduleNoticeService.Config,
+	conCfg *consumeNoticeService.Config,
+	grdCfg *guardNoticeService.Config,
+	tgCfg *pkgTelegram.Config,
+	rp iRepository,
+	rb *pkgRabbitmq.Client,
+	tg *pkgTelegram.Client,
+	providers *provider.Registry,
+) *Service {
 	lg := zlog.Logger.With().Str("layer", "service").Logger()
 	getNotSv := getNoticeService.New(&lg, rp)
-	updNotSv := updateNoticeService.New(&lg, rp)
-	delNotSv := deleteNoticeService.New(&lg, rp, getNotSv, updNotSv)
-	sendNotSv := sendNoticeService.New(&lg, rs, tg, em, rp)
+	tplSv := templateService.New(&lg, rp)
+	updNotSv := updateNoticeService.New(&lg, rp, tplSv)
+	schNotSv := scheduleNoticeService.New(&lg, schCfg, rp, getNotSv, rb)
+	delNotSv := deleteNoticeService.New(&lg, rp, getNotSv, updNotSv, schNotSv)
+	sendNotSv := sendNoticeService.New(&lg, rs, providers, tplSv)
+	grdSv := guardNoticeService.New(&lg, grdCfg, rp)
+	addNotSv := addNoticeService.New(&lg, addCfg, delNotSv, rp, rp, tplSv, grdSv)
+	tgStartSv := telegramStartService.New(&lg, tg, rp)
+	prefSv := preferencesService.New(&lg, rp)
+	tgBotSv := telegramBotService.New(&lg, tg, rp, tgStartSv, addNotSv, getNotSv, delNotSv, prefSv)
 	return &Service{
-		AddNoticeService:     addNoticeService.New(&lg, rb, delNotSv, rp),
-		DeleteNoticeService:  delNotSv,
-		GetNoticeService:     getNotSv,
-		TelegramStartService: telegramStartService.New(&lg, tg, rp),
-		ConsumeNoticeService: consumeNoticeService.New(&lg, rb, delNotSv, sendNotSv, getNotSv, updNotSv),
-		SendNoticeService:    sendNotSv,
-		UpdateNoticeService:  updNotSv,
+		AddNoticeService:      addNotSv,
+		DeleteNoticeService:   delNotSv,
+		GetNoticeService:      getNotSv,
+		TelegramStartService:  tgStartSv,
+		TelegramBotService:    tgBotSv,
+		TelegramPollService:   telegramPollService.New(&lg, tgCfg, tg, rp, tgBotSv),
+		ConsumeNoticeService:  consumeNoticeService.New(&lg, conCfg, rb, sendNotSv, getNotSv, updNotSv, schNotSv, grdSv),
+		ScheduleNoticeService: schNotSv,
+		SendNoticeService:     sendNotSv,
+		UpdateNoticeService:   updNotSv,
+		TemplateService:       tplSv,
+		GuardNoticeService:    grdSv,
+		PreferencesService:    prefSv,
 	}
 }
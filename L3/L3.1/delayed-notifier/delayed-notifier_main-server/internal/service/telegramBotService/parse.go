@@ -0,0 +1,212 @@
+package telegramBotService
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoReminderTime    = errors.New("reminder time is not recognized")
+	ErrEmptyReminderText = errors.New("reminder text cannot be empty")
+	ErrReminderInPast    = errors.New("reminder time has already passed")
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	clockLayout    = "15:04"
+	dateTimeLayout = "2006-01-02T15:04"
+)
+
+// durationUnits are the accepted spellings of the duration units
+var durationUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// maxReminderIn is the longest relative reminder time, longer amounts are not recognized,
+// so that they do not overflow time.Duration
+const maxReminderIn = 10 * 365 * 24 * time.Hour
+
+// compactDuration matches the durations written without spaces: 2h, 1h30m, 10min, 3days
+var compactDuration = regexp.MustCompile(`^(?:\d+[a-z]+)+$`)
+var compactPart = regexp.MustCompile(`(\d+)([a-z]+)`)
+
+// ParseReminder splits the /remind arguments into the reminder time and text.
+// The time is either relative to now:
+//
+//	2h call mom, 1h30m ..., in 2 hours ..., 10 min ..., an hour ..., 1 day 2 hours ...
+//
+// or local time in loc:
+//
+//	2026-11-01 09:00 ..., 2026-11-01T09:00 ..., tomorrow 09:00 ..., today 18:30 ..., 09:00 ...
+//
+// A bare clock time that has passed today means tomorrow
+func ParseReminder(args string, now time.Time, loc *time.Location) (at time.Time, text string, err error) {
+	fields := strings.Fields(args)
+
+	at, rest, ok := parseLocalTime(fields, now.In(loc))
+	if !ok {
+		var d time.Duration
+		d, rest, ok = parseDuration(fields)
+		if !ok {
+			return time.Time{}, "", ErrNoReminderTime
+		}
+		at = now.Add(d)
+	}
+
+	if !at.After(now) {
+		return time.Time{}, "", ErrReminderInPast
+	}
+
+	text = strings.Join(rest, " ")
+	if text == "" {
+		return time.Time{}, "", ErrEmptyReminderText
+	}
+
+	return at, text, nil
+}
+
+// parseLocalTime parses the leading date and/or clock time in the location of now
+func parseLocalTime(fields []string, now time.Time) (time.Time, []string, bool) {
+	if len(fields) == 0 {
+		return time.Time{}, nil, false
+	}
+	loc := now.Location()
+
+	if t, err := time.ParseInLocation(dateTimeLayout, fields[0], loc); err == nil {
+		return t, fields[1:], true
+	}
+
+	var day time.Time
+	switch strings.ToLower(fields[0]) {
+	case "today":
+		day = now
+	case "tomorrow":
+		day = now.AddDate(0, 0, 1)
+	default:
+		if d, err := time.ParseInLocation(dateLayout, fields[0], loc); err == nil {
+			day = d
+		}
+	}
+
+	if day.IsZero() {
+		// a bare clock time is the nearest one
+		clock, err := time.Parse(clockLayout, fields[0])
+		if err != nil {
+			return time.Time{}, nil, false
+		}
+		t := atClock(now, clock)
+		if !t.After(now) {
+			t = atClock(now.AddDate(0, 0, 1), clock)
+		}
+		return t, fields[1:], true
+	}
+
+	if len(fields) < 2 {
+		return time.Time{}, nil, false
+	}
+	clock, err := time.Parse(clockLayout, fields[1])
+	if err != nil {
+		return time.Time{}, nil, false
+	}
+	return atClock(day, clock), fields[2:], true
+}
+
+func atClock(day time.Time, clock time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, day.Location())
+}
+
+// parseDuration parses the leading duration, optionally prefixed with "in".
+// The amounts are either compact (2h, 1h30m) or separate words (2 hours, an hour),
+// several amounts may be joined with "and"
+func parseDuration(fields []string) (time.Duration, []string, bool) {
+	if len(fields) > 0 && strings.EqualFold(fields[0], "in") {
+		fields = fields[1:]
+	}
+
+	var total time.Duration
+	parsed := false
+	for len(fields) > 0 {
+		if parsed && strings.EqualFold(fields[0], "and") && len(fields) > 1 {
+			if d, n, ok := parseAmount(fields[1:]); ok {
+				if total > maxReminderIn-d {
+					return 0, nil, false
+				}
+				total += d
+				fields = fields[1+n:]
+				continue
+			}
+			break
+		}
+		d, n, ok := parseAmount(fields)
+		if !ok {
+			break
+		}
+		if total > maxReminderIn-d {
+			return 0, nil, false
+		}
+		total += d
+		fields = fields[n:]
+		parsed = true
+	}
+
+	if !parsed || total <= 0 {
+		return 0, nil, false
+	}
+	return total, fields, true
+}
+
+// parseAmount parses one compact amount or a number with a unit word
+// and returns the number of fields used
+func parseAmount(fields []string) (time.Duration, int, bool) {
+	word := strings.ToLower(fields[0])
+
+	if compactDuration.MatchString(word) {
+		var total time.Duration
+		for _, part := range compactPart.FindAllStringSubmatch(word, -1) {
+			unit, ok := durationUnits[part[2]]
+			if !ok {
+				return 0, 0, false
+			}
+			d, ok := amount(part[1], unit)
+			if !ok || total > maxReminderIn-d {
+				return 0, 0, false
+			}
+			total += d
+		}
+		return total, 1, true
+	}
+
+	if len(fields) < 2 {
+		return 0, 0, false
+	}
+	unit, ok := durationUnits[strings.ToLower(fields[1])]
+	if !ok {
+		return 0, 0, false
+	}
+	if word == "a" || word == "an" {
+		word = "1"
+	}
+	d, ok := amount(word, unit)
+	if !ok {
+		return 0, 0, false
+	}
+	return d, 2, true
+}
+
+// amount returns the number of the units, the number is bounded before multiplying
+// so that the duration does not exceed maxReminderIn
+func amount(number string, unit time.Duration) (time.Duration, bool) {
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > int64(maxReminderIn/unit) {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
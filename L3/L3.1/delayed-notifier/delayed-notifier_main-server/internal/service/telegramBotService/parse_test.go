@@ -0,0 +1,80 @@
+package telegramBotService_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/telegramBotService"
+)
+
+func TestParseReminder(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	// 12:00 in Moscow
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, moscow)
+	}
+
+	tests := []struct {
+		name     string
+		args     string
+		wantAt   time.Time
+		wantText string
+	}{
+		{"compact duration", "2h call mom", now.Add(2 * time.Hour), "call mom"},
+		{"compact combined duration", "1h30m stretch", now.Add(90 * time.Minute), "stretch"},
+		{"compact word unit", "10min tea", now.Add(10 * time.Minute), "tea"},
+		{"days", "3d pay rent", now.Add(72 * time.Hour), "pay rent"},
+		{"in words", "in 2 hours call mom", now.Add(2 * time.Hour), "call mom"},
+		{"article", "in an hour check oven", now.Add(time.Hour), "check oven"},
+		{"several amounts", "1 day and 2 hours renew", now.Add(26 * time.Hour), "renew"},
+		{"weeks", "2 weeks dentist", now.Add(14 * 24 * time.Hour), "dentist"},
+		{"number in text", "2h buy 3 apples", now.Add(2 * time.Hour), "buy 3 apples"},
+		{"date and time", "2026-11-01 09:00 pay rent", local(11, 1, 9, 0), "pay rent"},
+		{"date time with T", "2026-11-01T09:00 pay rent", local(11, 1, 9, 0), "pay rent"},
+		{"tomorrow", "tomorrow 09:00 standup", local(10, 20, 9, 0), "standup"},
+		{"today", "today 18:30 gym", local(10, 19, 18, 30), "gym"},
+		{"clock later today", "18:00 dinner", local(10, 19, 18, 0), "dinner"},
+		{"clock passed today", "09:00 standup", local(10, 20, 9, 0), "standup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, text, err := telegramBotService.ParseReminder(tt.args, now, moscow)
+			require.NoError(t, err)
+			require.True(t, tt.wantAt.Equal(at), "want %v, got %v", tt.wantAt, at)
+			require.Equal(t, tt.wantText, text)
+		})
+	}
+}
+
+func TestParseReminder_Errors(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		args string
+		want error
+	}{
+		{"empty", "", telegramBotService.ErrNoReminderTime},
+		{"no time", "call mom", telegramBotService.ErrNoReminderTime},
+		{"unknown unit", "2 parrots call mom", telegramBotService.ErrNoReminderTime},
+		{"date without time", "2026-11-01 pay rent", telegramBotService.ErrNoReminderTime},
+		{"no text", "2h", telegramBotService.ErrEmptyReminderText},
+		{"past date", "2026-01-01 09:00 pay rent", telegramBotService.ErrReminderInPast},
+		{"zero duration", "0m now", telegramBotService.ErrNoReminderTime},
+		{"overflowing amount", "9223372036854775807h call mom", telegramBotService.ErrNoReminderTime},
+		{"overflowing words", "100000000000 days call mom", telegramBotService.ErrNoReminderTime},
+		{"overflowing sum", "3000 days and 3000 days call mom", telegramBotService.ErrNoReminderTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := telegramBotService.ParseReminder(tt.args, now, time.UTC)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package telegramBotService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/wb-go/wbf/zlog"
+)
+
+const (
+	// listSize is the number of the nearest notices shown by /list
+	listSize = 10
+	// cancelPrefix prefixes the notice ID in the data of the cancel buttons
+	cancelPrefix = "cancel:"
+	timeLayout   = "2006-01-02 15:04"
+)
+
+const helpText = `Commands:
+/remind <when> <text> - remind, e.g. /remind 2h call mom, /remind in 30 minutes stretch, /remind tomorrow 09:00 standup, /remind 2026-11-01 09:00 pay rent
+/list - scheduled reminders
+/tz <timezone> - set your timezone, e.g. /tz Europe/Moscow`
+
+type IRepository interface {
+	SaveTelegramChatID(ctx context.Context, username string, chatID int64) (err error)
+	TelegramOwner(ctx context.Context, telegramUserID int64) (userID int, err error)
+}
+
+type IStartService interface {
+	Start(ctx context.Context, username string, chatID int64, message string) (err error)
+}
+
+type IAddNoticeService interface {
+	AddNotice(ctx context.Context, reqNotice model.ReqNotice) (id int, err error)
+}
+
+type IGetNoticeService interface {
+	GetNotice(ctx context.Context, id int) (notice *model.Notice, err error)
+	ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error)
+}
+
+type IDeleteNoticeService interface {
+	PreDeleteNotice(ctx context.Context, id int) (err error)
+}
+
+type IPreferencesService interface {
+	GetPreferences(ctx context.Context, userID int) (preferences *model.Preferences, err error)
+	SavePreferences(ctx context.Context, preferences model.Preferences) (saved *model.Preferences, err error)
+}
+
+// TelegramBotService handles the bot commands. The notices and preferences of a Telegram
+// user belong to its own owner ID, which never matches a user ID of the HTTP API,
+// the notices are delivered to the username
+type TelegramBotService struct {
+	lg      *zlog.Zerolog
+	tg      *pkgTelegram.Client
+	rp      IRepository
+	startSv IStartService
+	addSv   IAddNoticeService
+	getSv   IGetNoticeService
+	delSv   IDeleteNoticeService
+	prefSv  IPreferencesService
+}
+
+func New(
+	parentLg *zlog.Zerolog,
+	tg *pkgTelegram.Client,
+	rp IRepository,
+	startSv IStartService,
+	addSv IAddNoticeService,
+	getSv IGetNoticeService,
+	delSv IDeleteNoticeService,
+	prefSv IPreferencesService,
+) *TelegramBotService {
+	lg := parentLg.With().Str("component", "TelegramBotService").Logger()
+	return &TelegramBotService{
+		lg:      &lg,
+		tg:      tg,
+		rp:      rp,
+		startSv: startSv,
+		addSv:   addSv,
+		getSv:   getSv,
+		delSv:   delSv,
+		prefSv:  prefSv,
+	}
+}
+
+// HandleUpdate handles a message with a command or a pressed inline button
+func (sv *TelegramBotService) HandleUpdate(ctx context.Context, update tgbotapi.Update) (err error) {
+	lg := sv.lg.With().Str("method", "HandleUpdate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	switch {
+	case update.CallbackQuery != nil:
+		return sv.handleCallback(ctx, update.CallbackQuery)
+	case update.Message != nil && update.Message.From != nil:
+		return sv.handleMessage(ctx, update.Message)
+	}
+
+	lg.Trace().Int("update ID", update.UpdateID).Msgf("%s update has nothing to handle", pkgConst.Info)
+	return nil
+}
+
+func (sv *TelegramBotService) handleMessage(ctx context.Context, msg *tgbotapi.Message) error {
+	lg := sv.lg.With().Str("method", "handleMessage").Logger()
+
+	command, args := msg.Command(), strings.TrimSpace(msg.CommandArguments())
+	lg.Trace().Int64("chat ID", msg.Chat.ID).Str("command", command).Msgf("%s handling command...", pkgConst.OpStart)
+
+	var err error
+	switch command {
+	case "start":
+		if !msg.Chat.IsPrivate() {
+			// the notices of the user are delivered to the chat saved on /start
+			err = sv.reply(msg.Chat.ID, "Send /start in a private chat with the bot.")
+			break
+		}
+		return sv.startSv.Start(ctx, msg.From.UserName, msg.Chat.ID, msg.Text)
+	case "remind":
+		err = sv.remind(ctx, msg, args)
+	case "list":
+		err = sv.list(ctx, msg.Chat.ID, msg.From.ID)
+	case "tz":
+		err = sv.setTimezone(ctx, msg, args)
+	case "help":
+		err = sv.reply(msg.Chat.ID, helpText)
+	default:
+		err = sv.reply(msg.Chat.ID, "Unknown command.\n\n"+helpText)
+	}
+	if err != nil {
+		return pkgErrors.Wrapf(err, "handle command /%s", command)
+	}
+	lg.Trace().Int64("chat ID", msg.Chat.ID).Str("command", command).Msgf("%s command handled successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// remind creates a notice delivered to the Telegram user at the time from the arguments
+func (sv *TelegramBotService) remind(ctx context.Context, msg *tgbotapi.Message, args string) error {
+	lg := sv.lg.With().Str("method", "remind").Logger()
+
+	username := msg.From.UserName
+	if username == "" {
+		return sv.reply(msg.Chat.ID, "Set a Telegram username to receive reminders.")
+	}
+
+	userID, err := sv.rp.TelegramOwner(ctx, msg.From.ID)
+	if err != nil {
+		return pkgErrors.Wrap(err, "get owner ID")
+	}
+	preferences, err := sv.prefSv.GetPreferences(ctx, userID)
+	if err != nil {
+		return pkgErrors.Wrap(err, "get preferences")
+	}
+	loc := preferences.Location()
+
+	at, text, err := ParseReminder(args, time.Now(), loc)
+	if err != nil {
+		lg.Debug().Err(err).Str("args", args).Msgf("%s failed to parse reminder", pkgConst.Warn)
+		return sv.reply(msg.Chat.ID, fmt.Sprintf("Failed to set reminder: %s.\n\n%s", err, helpText))
+	}
+
+	// the reminder is delivered to the private chat even if the user has not sent /start,
+	// a reminder set in a group goes to the private chat saved before, not to the group
+	if msg.Chat.IsPrivate() {
+		lg.Trace().Str("username", username).Int64("chat ID", msg.Chat.ID).Msgf("%s saving name, chat ID to repository...", pkgConst.OpStart)
+		if err := sv.rp.SaveTelegramChatID(ctx, username, msg.Chat.ID); err != nil {
+			return pkgErrors.Wrap(err, "save chat ID")
+		}
+		lg.Trace().Str("username", username).Int64("chat ID", msg.Chat.ID).Msgf("%s name, chat ID saved to repository successfully", pkgConst.OpSuccess)
+	}
+
+	id, err := sv.addSv.AddNotice(ctx, model.ReqNotice{
+		UserID:   userID,
+		Message:  text,
+		Channels: model.Channels{{Type: model.ChannelTelegram, Value: username}},
+		SentAt:   &at,
+	})
+	if err != nil {
+		lg.Warn().Err(err).Int("user ID", userID).Msgf("%s failed to add notice", pkgConst.Warn)
+		return sv.reply(msg.Chat.ID, fmt.Sprintf("Failed to set reminder: %s.", err))
+	}
+	lg.Debug().Int("user ID", userID).Int("notice ID", id).Time("sent at", at).Msgf("%s reminder set successfully", pkgConst.OpSuccess)
+
+	answer := fmt.Sprintf("Reminder #%d is set for %s (%s).", id, at.In(loc).Format(timeLayout), loc)
+	if !msg.Chat.IsPrivate() {
+		answer += " It is sent to your private chat with the bot, send /start there if you have not yet."
+	}
+	return sv.reply(msg.Chat.ID, answer)
+}
+
+// list sends the nearest scheduled notices of the user with the cancel buttons
+func (sv *TelegramBotService) list(ctx context.Context, chatID int64, telegramUserID int64) error {
+	userID, err := sv.rp.TelegramOwner(ctx, telegramUserID)
+	if err != nil {
+		return pkgErrors.Wrap(err, "get owner ID")
+	}
+	text, keyboard, err := sv.renderList(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := sv.tg.SendKeyboard(chatID, text, keyboard); err != nil {
+		return pkgErrors.Wrap(err, "send list")
+	}
+	return nil
+}
+
+func (sv *TelegramBotService) renderList(ctx context.Context, userID int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	preferences, err := sv.prefSv.GetPreferences(ctx, userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, pkgErrors.Wrap(err, "get preferences")
+	}
+	loc := preferences.Location()
+
+	page, err := sv.getSv.ListNotices(ctx, model.NoticeFilter{
+		UserID:   userID,
+		Statuses: []model.Status{model.StatusScheduled},
+		Limit:    listSize,
+		Order:    model.OrderDueAtAsc,
+	})
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, pkgErrors.Wrap(err, "list scheduled notices")
+	}
+	if len(page.Notices) == 0 {
+		return "You have no scheduled reminders.", tgbotapi.InlineKeyboardMarkup{}, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Scheduled reminders:\n")
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, notice := range page.Notices {
+		fmt.Fprintf(&b, "\n#%d %s - %s", notice.ID, notice.DueAt().In(loc).Format(timeLayout), noticeTitle(notice))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Cancel #%d", notice.ID), cancelPrefix+strconv.Itoa(notice.ID)),
+		))
+	}
+	if more := page.Total - len(page.Notices); more > 0 {
+		fmt.Fprintf(&b, "\n\nand %d more", more)
+	}
+
+	return b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// noticeTitle returns the beginning of the notice message
+func noticeTitle(notice model.Notice) string {
+	title := notice.Message
+	if notice.Template != nil {
+		title = "template " + notice.Template.Name
+	}
+	if runes := []rune(title); len(runes) > 40 {
+		title = string(runes[:40]) + "..."
+	}
+	return title
+}
+
+// setTimezone shows or sets the timezone of the reminder times
+func (sv *TelegramBotService) setTimezone(ctx context.Context, msg *tgbotapi.Message, args string) error {
+	lg := sv.lg.With().Str("method", "setTimezone").Logger()
+
+	userID, err := sv.rp.TelegramOwner(ctx, msg.From.ID)
+	if err != nil {
+		return pkgErrors.Wrap(err, "get owner ID")
+	}
+	preferences, err := sv.prefSv.GetPreferences(ctx, userID)
+	if err != nil {
+		return pkgErrors.Wrap(err, "get preferences")
+	}
+
+	if args == "" {
+		return sv.reply(msg.Chat.ID, fmt.Sprintf("Your timezone is %s. Set another one with /tz <timezone>, e.g. /tz Europe/Moscow", preferences.Location()))
+	}
+
+	preferences.Timezone = args
+	if _, err := sv.prefSv.SavePreferences(ctx, *preferences); err != nil {
+		if errors.Is(err, pkgErrors.ErrInvalidPreferences) {
+			return sv.reply(msg.Chat.ID, fmt.Sprintf("Unknown timezone %q, use an IANA name, e.g. Europe/Moscow.", args))
+		}
+		return pkgErrors.Wrap(err, "save preferences")
+	}
+	lg.Debug().Int("user ID", userID).Str("timezone", args).Msgf("%s timezone set successfully", pkgConst.OpSuccess)
+
+	return sv.reply(msg.Chat.ID, fmt.Sprintf("Your timezone is set to %s.", args))
+}
+
+// handleCallback cancels the notice of the pressed cancel button and refreshes the list
+func (sv *TelegramBotService) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	lg := sv.lg.With().Str("method", "handleCallback").Logger()
+
+	idText, ok := strings.CutPrefix(query.Data, cancelPrefix)
+	id, err := strconv.Atoi(idText)
+	if !ok || err != nil {
+		lg.Debug().Str("data", query.Data).Msgf("%s unknown callback data", pkgConst.Warn)
+		return sv.answer(query.ID, "Unknown button.")
+	}
+
+	userID, err := sv.rp.TelegramOwner(ctx, query.From.ID)
+	if err != nil {
+		return pkgErrors.Wrap(err, "get owner ID")
+	}
+	lg.Trace().Int("user ID", userID).Int("notice ID", id).Msgf("%s cancelling notice...", pkgConst.OpStart)
+	answer, err := sv.cancel(ctx, userID, id)
+	if err != nil {
+		if err := sv.answer(query.ID, "Failed to cancel the reminder, try again later."); err != nil {
+			lg.Warn().Err(err).Msgf("%s failed to answer callback", pkgConst.Warn)
+		}
+		return pkgErrors.Wrapf(err, "cancel notice, notice ID: %d", id)
+	}
+	lg.Debug().Int("user ID", userID).Int("notice ID", id).Str("answer", answer).Msgf("%s cancel button handled", pkgConst.OpSuccess)
+
+	if err := sv.answer(query.ID, answer); err != nil {
+		return err
+	}
+
+	if query.Message == nil {
+		return nil
+	}
+	text, keyboard, err := sv.renderList(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := sv.tg.EditKeyboard(query.Message.Chat.ID, query.Message.MessageID, text, keyboard); err != nil {
+		return pkgErrors.Wrap(err, "refresh list")
+	}
+	return nil
+}
+
+// cancel deletes the scheduled notice of the user and returns the answer to show
+func (sv *TelegramBotService) cancel(ctx context.Context, userID int, id int) (answer string, err error) {
+	notice, err := sv.getSv.GetNotice(ctx, id)
+	if errors.Is(err, pkgErrors.ErrNoticeNotFound) || err == nil && notice.UserID != userID {
+		return fmt.Sprintf("Reminder #%d is not found.", id), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = sv.delSv.PreDeleteNotice(ctx, id)
+	if errors.Is(err, pkgErrors.ErrNoticeConflict) {
+		return fmt.Sprintf("Reminder #%d was just changed, try again.", id), nil
+	}
+	if errors.Is(err, pkgErrors.ErrNoticeNotScheduled) {
+		return fmt.Sprintf("Reminder #%d is already sent or cancelled.", id), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Reminder #%d is cancelled.", id), nil
+}
+
+func (sv *TelegramBotService) reply(chatID int64, text string) error {
+	if err := sv.tg.SendTo(chatID, text); err != nil {
+		return pkgErrors.Wrap(err, "send reply")
+	}
+	return nil
+}
+
+func (sv *TelegramBotService) answer(callbackID string, text string) error {
+	if err := sv.tg.AnswerCallback(callbackID, text); err != nil {
+		return pkgErrors.Wrap(err, "answer callback")
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package telegramBotService_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/addNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/deleteNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/getNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/guardNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/preferencesService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/scheduleNoticeService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/telegramBotService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/telegramStartService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/templateService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/updateNoticeService"
+)
+
+type call struct {
+	method string
+	params url.Values
+}
+
+// fakeTelegram is a Bot API server recording the called methods
+type fakeTelegram struct {
+	mu    sync.Mutex
+	calls []call
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := path.Base(r.URL.Path)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, call{method: method, params: r.PostForm})
+	messageID := len(f.calls)
+	f.mu.Unlock()
+
+	var result any
+	switch method {
+	case "getMe":
+		result = map[string]any{"id": 1, "is_bot": true, "username": "notifier_bot"}
+	case "answerCallbackQuery":
+		result = true
+	default:
+		chatID, _ := strconv.ParseInt(r.PostForm.Get("chat_id"), 10, 64)
+		result = map[string]any{"message_id": messageID, "date": 0, "chat": map[string]any{"id": chatID}}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// take returns the calls of the method made since the previous take
+func (f *fakeTelegram) take(method string) []call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var taken, rest []call
+	for _, c := range f.calls {
+		if c.method == method {
+			taken = append(taken, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	f.calls = rest
+	return taken
+}
+
+func newService(t *testing.T) (*telegramBotService.TelegramBotService, *repository.Repository, *fakeTelegram) {
+	t.Helper()
+
+	rp := rpTest.New(t)
+
+	fake := &fakeTelegram{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	tg, err := pkgTelegram.New(&pkgTelegram.Config{Token: "test", APIEndpoint: srv.URL + "/bot%s/%s"})
+	require.NoError(t, err)
+	fake.take("getMe")
+
+	lg := zlog.Logger
+	getNotSv := getNoticeService.New(&lg, rp)
+	tplSv := templateService.New(&lg, rp)
+	updNotSv := updateNoticeService.New(&lg, rp, tplSv)
+	schNotSv := scheduleNoticeService.New(&lg, &scheduleNoticeService.Config{}, rp, getNotSv, nil)
+	delNotSv := deleteNoticeService.New(&lg, rp, getNotSv, updNotSv, schNotSv)
+	grdSv := guardNoticeService.New(&lg, &guardNoticeService.Config{}, rp)
+	addNotSv := addNoticeService.New(&lg, &addNoticeService.Config{}, delNotSv, rp, rp, tplSv, grdSv)
+
+	sv := telegramBotService.New(&lg, tg, rp, telegramStartService.New(&lg, tg, rp),
+		addNotSv, getNotSv, delNotSv, preferencesService.New(&lg, rp))
+	return sv, rp, fake
+}
+
+const (
+	chatID      = 100
+	groupChatID = -200
+)
+
+var alice = &tgbotapi.User{ID: 42, UserName: "alice"}
+
+func command(from *tgbotapi.User, text string) tgbotapi.Update {
+	name, _, _ := strings.Cut(text, " ")
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From:     from,
+		Chat:     &tgbotapi.Chat{ID: chatID, Type: "private"},
+		Text:     text,
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(name)}},
+	}}
+}
+
+// inGroup moves the command to a group chat
+func inGroup(update tgbotapi.Update) tgbotapi.Update {
+	update.Message.Chat = &tgbotapi.Chat{ID: groupChatID, Type: "group"}
+	return update
+}
+
+func pressed(from *tgbotapi.User, data string, messageID int) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "callback",
+		From:    from,
+		Data:    data,
+		Message: &tgbotapi.Message{MessageID: messageID, Chat: &tgbotapi.Chat{ID: chatID, Type: "private"}},
+	}}
+}
+
+// buttons returns the callback data of the inline keyboard
+func buttons(t *testing.T, c call) []string {
+	t.Helper()
+
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	if c.params.Get("reply_markup") == "" {
+		return nil
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.params.Get("reply_markup")), &keyboard))
+	var data []string
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			data = append(data, *button.CallbackData)
+		}
+	}
+	return data
+}
+
+func TestHandleUpdate_Remind(t *testing.T) {
+	sv, rp, fake := newService(t)
+	ctx := context.Background()
+
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/tz Europe/Moscow")))
+	sent := fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Contains(t, sent[0].params.Get("text"), "set to Europe/Moscow")
+
+	before := time.Now()
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/remind 2h call mom")))
+	sent = fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Equal(t, strconv.Itoa(chatID), sent[0].params.Get("chat_id"))
+	require.Contains(t, sent[0].params.Get("text"), "Europe/Moscow")
+
+	owner, err := rp.TelegramOwner(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Negative(t, owner)
+	page, err := rp.ListNotices(ctx, model.NoticeFilter{UserID: int(alice.ID), Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, page.Notices, "the notices do not belong to the API user with the Telegram user ID")
+	page, err = rp.ListNotices(ctx, model.NoticeFilter{UserID: owner, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Notices, 1)
+	notice := page.Notices[0]
+	require.Equal(t, "call mom", notice.Message)
+	require.Equal(t, model.Channels{{Type: model.ChannelTelegram, Value: "alice"}}, notice.Channels)
+	require.WithinDuration(t, before.Add(2*time.Hour), *notice.SentAt, time.Minute)
+
+	savedChatID, err := rp.LoadTelegramChatID(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(chatID), savedChatID)
+
+	// a reminder set in a group does not redirect the deliveries to the group
+	require.NoError(t, sv.HandleUpdate(ctx, inGroup(command(alice, "/remind 3h call dad"))))
+	sent = fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Equal(t, strconv.Itoa(groupChatID), sent[0].params.Get("chat_id"))
+	require.Contains(t, sent[0].params.Get("text"), "private chat")
+	savedChatID, err = rp.LoadTelegramChatID(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(chatID), savedChatID)
+
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/remind someday call mom")))
+	sent = fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Contains(t, sent[0].params.Get("text"), telegramBotService.ErrNoReminderTime.Error())
+
+	require.NoError(t, sv.HandleUpdate(ctx, command(&tgbotapi.User{ID: 43}, "/remind 2h call mom")))
+	sent = fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Contains(t, sent[0].params.Get("text"), "username")
+}
+
+func TestHandleUpdate_ListAndCancel(t *testing.T) {
+	sv, rp, fake := newService(t)
+	ctx := context.Background()
+
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/remind 3h later")))
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/remind 1h sooner")))
+	fake.take("sendMessage")
+
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/list")))
+	sent := fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	text := sent[0].params.Get("text")
+	require.Less(t, strings.Index(text, "sooner"), strings.Index(text, "later"), "nearest reminder goes first")
+	data := buttons(t, sent[0])
+	require.Len(t, data, 2)
+
+	soonerID, err := strconv.Atoi(strings.TrimPrefix(data[0], "cancel:"))
+	require.NoError(t, err)
+	laterID, err := strconv.Atoi(strings.TrimPrefix(data[1], "cancel:"))
+	require.NoError(t, err)
+
+	// another user cannot cancel the reminder
+	require.NoError(t, sv.HandleUpdate(ctx, pressed(&tgbotapi.User{ID: 43}, data[0], 7)))
+	answers := fake.take("answerCallbackQuery")
+	require.Len(t, answers, 1)
+	require.Contains(t, answers[0].params.Get("text"), "not found")
+	notice, err := rp.LoadNotice(ctx, soonerID)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusScheduled, notice.Status)
+	fake.take("editMessageText")
+
+	require.NoError(t, sv.HandleUpdate(ctx, pressed(alice, data[0], 7)))
+	answers = fake.take("answerCallbackQuery")
+	require.Len(t, answers, 1)
+	require.Contains(t, answers[0].params.Get("text"), "cancelled")
+	notice, err = rp.LoadNotice(ctx, soonerID)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusDeleted, notice.Status)
+
+	edits := fake.take("editMessageText")
+	require.Len(t, edits, 1)
+	require.Equal(t, "7", edits[0].params.Get("message_id"))
+	require.Equal(t, []string{"cancel:" + strconv.Itoa(laterID)}, buttons(t, edits[0]))
+
+	require.NoError(t, sv.HandleUpdate(ctx, pressed(alice, data[0], 7)))
+	answers = fake.take("answerCallbackQuery")
+	require.Len(t, answers, 1)
+	require.Contains(t, answers[0].params.Get("text"), "already sent or cancelled")
+}
+
+func TestHandleUpdate_Start(t *testing.T) {
+	sv, rp, fake := newService(t)
+	ctx := context.Background()
+
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/start")))
+	sent := fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Contains(t, sent[0].params.Get("text"), "linked")
+
+	savedChatID, err := rp.LoadTelegramChatID(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(chatID), savedChatID)
+
+	require.NoError(t, sv.HandleUpdate(ctx, inGroup(command(alice, "/start"))))
+	sent = fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Contains(t, sent[0].params.Get("text"), "private chat")
+	savedChatID, err = rp.LoadTelegramChatID(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(chatID), savedChatID)
+
+	require.NoError(t, sv.HandleUpdate(ctx, command(alice, "/unknown")))
+	sent = fake.take("sendMessage")
+	require.Len(t, sent, 1)
+	require.Contains(t, sent[0].params.Get("text"), "/remind")
+}
@@ -0,0 +1,113 @@
+package telegramPollService
+
+import (
+	"context"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// retryDelay is the pause after a failed getUpdates request
+const retryDelay = time.Second
+
+type IOffsetRepository interface {
+	LoadTelegramOffset(ctx context.Context) (offset int, err error)
+	SaveTelegramOffset(ctx context.Context, offset int) (err error)
+}
+
+// IUpdateService handles the updates, the same service handles the webhook updates
+type IUpdateService interface {
+	HandleUpdate(ctx context.Context, update tgbotapi.Update) (err error)
+}
+
+// TelegramPollService receives the Telegram updates with getUpdates long polling
+// instead of the webhook
+type TelegramPollService struct {
+	lg    *zlog.Zerolog
+	cfg   *pkgTelegram.Config
+	tg    *pkgTelegram.Client
+	rp    IOffsetRepository
+	updSv IUpdateService
+}
+
+func New(parentLg *zlog.Zerolog, cfg *pkgTelegram.Config, tg *pkgTelegram.Client, rp IOffsetRepository, updSv IUpdateService) *TelegramPollService {
+	lg := parentLg.With().Str("component", "TelegramPollService").Logger()
+	return &TelegramPollService{
+		lg:    &lg,
+		cfg:   cfg,
+		tg:    tg,
+		rp:    rp,
+		updSv: updSv,
+	}
+}
+
+// RunPolling receives and handles the updates until ctx is done. The offset of the next
+// update is saved after each handled one, so a restarted service does not handle it again.
+// The update being handled when ctx is done is handled to the end
+func (sv *TelegramPollService) RunPolling(ctx context.Context) (err error) {
+	lg := sv.lg.With().Str("method", "RunPolling").Logger()
+
+	// getUpdates fails while the webhook is set
+	lg.Trace().Msgf("%s deleting webhook...", pkgConst.OpStart)
+	if err := sv.tg.DeleteWebhook(); err != nil {
+		return pkgErrors.Wrap(err, "delete webhook")
+	}
+	lg.Trace().Msgf("%s webhook deleted successfully", pkgConst.OpSuccess)
+
+	offset, err := sv.rp.LoadTelegramOffset(ctx)
+	if err != nil {
+		return pkgErrors.Wrap(err, "load update offset")
+	}
+
+	lg.Info().Int("offset", offset).Msgf("%s telegram polling started", pkgConst.Finished)
+	defer lg.Info().Msgf("%s telegram polling stopped", pkgConst.Stop)
+
+	for ctx.Err() == nil {
+		updates, err := sv.tg.GetUpdates(ctx, offset, sv.cfg.PollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			lg.Error().Err(err).Int("offset", offset).Msgf("%s failed to get updates", pkgConst.Error)
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			if ctx.Err() != nil {
+				// the rest is received again after the restart
+				break
+			}
+			offset = sv.handle(context.WithoutCancel(ctx), update)
+		}
+	}
+
+	return nil
+}
+
+// handle handles the update, saves the offset of the next one and returns it.
+// Failed updates are not received again, as the webhook ones
+func (sv *TelegramPollService) handle(ctx context.Context, update tgbotapi.Update) (offset int) {
+	lg := sv.lg.With().Str("method", "handle").Logger()
+
+	lg.Trace().Int("update ID", update.UpdateID).Msgf("%s handling update...", pkgConst.OpStart)
+	if err := sv.updSv.HandleUpdate(ctx, update); err != nil {
+		lg.Warn().Int("update ID", update.UpdateID).Err(err).Msgf("%s failed to handle update", pkgConst.Warn)
+	} else {
+		lg.Debug().Int("update ID", update.UpdateID).Msgf("%s Telegram update processed", pkgConst.OpSuccess)
+	}
+
+	offset = update.UpdateID + 1
+	if err := sv.rp.SaveTelegramOffset(ctx, offset); err != nil {
+		lg.Error().Err(err).Int("offset", offset).Msgf("%s failed to save update offset", pkgConst.Error)
+	}
+
+	return offset
+}
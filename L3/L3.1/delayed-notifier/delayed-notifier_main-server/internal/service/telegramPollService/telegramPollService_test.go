@@ -0,0 +1,251 @@
+package telegramPollService_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgTelegram"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/telegramPollService"
+)
+
+// fakeBotAPI serves getUpdates with long polling and records the requested offsets
+type fakeBotAPI struct {
+	mu             sync.Mutex
+	updates        []tgbotapi.Update
+	added          chan struct{}
+	offsets        []int
+	webhookDeleted bool
+}
+
+func newFakeBotAPI() *fakeBotAPI {
+	return &fakeBotAPI{added: make(chan struct{})}
+}
+
+func (f *fakeBotAPI) push(ids ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range ids {
+		f.updates = append(f.updates, tgbotapi.Update{UpdateID: id})
+	}
+	close(f.added)
+	f.added = make(chan struct{})
+}
+
+// pending returns the updates starting from offset and the channel closed on the next push
+func (f *fakeBotAPI) pending(offset int) ([]tgbotapi.Update, chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var pending []tgbotapi.Update
+	for _, u := range f.updates {
+		if u.UpdateID >= offset {
+			pending = append(pending, u)
+		}
+	}
+	return pending, f.added
+}
+
+func (f *fakeBotAPI) requestedOffsets() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.offsets...)
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result any = true
+	switch path.Base(r.URL.Path) {
+	case "getMe":
+		result = map[string]any{"id": 1, "is_bot": true, "username": "notifier_bot"}
+	case "deleteWebhook":
+		f.mu.Lock()
+		f.webhookDeleted = true
+		f.mu.Unlock()
+	case "getUpdates":
+		offset, _ := strconv.Atoi(r.PostForm.Get("offset"))
+		timeout, _ := strconv.Atoi(r.PostForm.Get("timeout"))
+		f.mu.Lock()
+		f.offsets = append(f.offsets, offset)
+		f.mu.Unlock()
+
+		updates, added := f.pending(offset)
+		if len(updates) == 0 {
+			select {
+			case <-added:
+				updates, _ = f.pending(offset)
+			case <-time.After(time.Duration(timeout) * time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		result = append([]tgbotapi.Update{}, updates...)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// fakeUpdateService records the updates handled with a live context.
+// If release is set, an update is handled only after release is closed
+type fakeUpdateService struct {
+	mu      sync.Mutex
+	handled []int
+	started chan int
+	release chan struct{}
+}
+
+func (s *fakeUpdateService) HandleUpdate(ctx context.Context, update tgbotapi.Update) error {
+	if s.release != nil {
+		s.started <- update.UpdateID
+		<-s.release
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handled = append(s.handled, update.UpdateID)
+	return nil
+}
+
+func (s *fakeUpdateService) list() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.handled...)
+}
+
+func newService(t *testing.T, api *fakeBotAPI, updSv telegramPollService.IUpdateService) (*telegramPollService.TelegramPollService, *repository.Repository) {
+	t.Helper()
+
+	rp := rpTest.New(t)
+
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	cfg := &pkgTelegram.Config{Token: "test", APIEndpoint: srv.URL + "/bot%s/%s", PollTimeout: 5 * time.Second}
+	tg, err := pkgTelegram.New(cfg)
+	require.NoError(t, err)
+
+	lg := zlog.Logger
+	return telegramPollService.New(&lg, cfg, tg, rp, updSv), rp
+}
+
+// runPolling runs the polling and returns the function stopping it and waiting for it to return
+func runPolling(t *testing.T, sv *telegramPollService.TelegramPollService) (stop func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sv.RunPolling(ctx) }()
+
+	stopped := false
+	stop = func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		cancel()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("polling did not stop")
+		}
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestRunPolling(t *testing.T) {
+	api := newFakeBotAPI()
+	updSv := &fakeUpdateService{}
+	sv, rp := newService(t, api, updSv)
+	ctx := context.Background()
+
+	api.push(10, 11)
+	stop := runPolling(t, sv)
+
+	require.Eventually(t, func() bool { return len(updSv.list()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int{10, 11}, updSv.list())
+
+	// the next request confirms the handled updates
+	require.Eventually(t, func() bool {
+		offsets := api.requestedOffsets()
+		return offsets[len(offsets)-1] == 12
+	}, 2*time.Second, 10*time.Millisecond)
+	offset, err := rp.LoadTelegramOffset(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 12, offset)
+
+	api.push(12)
+	require.Eventually(t, func() bool { return len(updSv.list()) == 3 }, 2*time.Second, 10*time.Millisecond)
+
+	// the long poll request waiting for updates is cancelled on shutdown
+	started := time.Now()
+	stop()
+	require.Less(t, time.Since(started), time.Second)
+	require.True(t, api.webhookDeleted)
+}
+
+func TestRunPolling_ResumesFromSavedOffset(t *testing.T) {
+	api := newFakeBotAPI()
+	updSv := &fakeUpdateService{}
+	sv, rp := newService(t, api, updSv)
+
+	// update 20 was handled before the restart, but not confirmed
+	require.NoError(t, rp.SaveTelegramOffset(context.Background(), 21))
+	api.push(20, 21)
+	runPolling(t, sv)
+
+	require.Eventually(t, func() bool { return len(updSv.list()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return len(updSv.list()) > 1 }, 100*time.Millisecond, 10*time.Millisecond)
+	require.Equal(t, []int{21}, updSv.list())
+	require.Equal(t, 21, api.requestedOffsets()[0])
+}
+
+func TestRunPolling_FinishesUpdateOnShutdown(t *testing.T) {
+	api := newFakeBotAPI()
+	updSv := &fakeUpdateService{started: make(chan int), release: make(chan struct{})}
+	sv, rp := newService(t, api, updSv)
+
+	api.push(30, 31)
+	stop := runPolling(t, sv)
+	require.Equal(t, 30, <-updSv.started)
+
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	require.Never(t, func() bool {
+		select {
+		case <-stopped:
+			return true
+		default:
+			return false
+		}
+	}, 100*time.Millisecond, 10*time.Millisecond, "polling waits for the update being handled")
+
+	close(updSv.release)
+	<-stopped
+
+	require.Equal(t, []int{30}, updSv.list(), "the update is handled with a live context, the rest are left")
+	offset, err := rp.LoadTelegramOffset(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 31, offset)
+}
@@ -0,0 +1,164 @@
+package templateService
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+)
+
+// markdownV2Escaper escapes the characters reserved in Telegram MarkdownV2
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, `_`, `\_`, `*`, `\*`, `[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`,
+	`~`, `\~`, "`", "\\`", `>`, `\>`, `#`, `\#`, `+`, `\+`, `-`, `\-`, `=`, `\=`,
+	`|`, `\|`, `{`, `\{`, `}`, `\}`, `.`, `\.`, `!`, `\!`,
+)
+
+// executor is a parsed text or html template
+type executor interface {
+	Execute(wr io.Writer, data any) error
+}
+
+// parse parses a variant of the template, referencing a missing variable is an error
+func parse(name, text string, html bool) (executor, error) {
+	if html {
+		return htmltemplate.New(name).Option("missingkey=error").Parse(text)
+	}
+	return texttemplate.New(name).Option("missingkey=error").Parse(text)
+}
+
+func execute(name, text string, html bool, vars map[string]any) (string, error) {
+	t, err := parse(name, text, html)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// variant is a part of the template rendered separately
+type variant struct {
+	name string
+	text string
+	html bool
+	// escape prepares variables for the variant
+	escape func(any) any
+	set    func(msg *model.RenderedMessage, rendered string)
+}
+
+func variants(t *model.Template) []variant {
+	var vs []variant
+
+	if tg := t.Telegram; tg != nil {
+		msg := &model.TelegramMessage{ParseMode: tg.ParseMode}
+		v := variant{
+			name: "telegram",
+			text: tg.Text,
+			html: tg.ParseMode == model.ParseModeHTML,
+			set: func(m *model.RenderedMessage, s string) {
+				msg.Text = s
+				m.Telegram = msg
+			},
+		}
+		if tg.ParseMode == model.ParseModeMarkdownV2 {
+			v.escape = escapeMarkdownV2
+		}
+		vs = append(vs, v)
+	}
+
+	if em := t.Email; em != nil {
+		msg := &model.EmailMessage{}
+		subject := em.Subject
+		if subject == "" {
+			subject = model.DefaultEmailSubject
+		}
+		vs = append(vs, variant{
+			name: "email subject",
+			text: subject,
+			set: func(m *model.RenderedMessage, s string) {
+				msg.Subject = s
+				m.Email = msg
+			},
+		})
+		if em.HTML != "" {
+			vs = append(vs, variant{
+				name: "email html",
+				text: em.HTML,
+				html: true,
+				set:  func(_ *model.RenderedMessage, s string) { msg.HTML = s },
+			})
+		}
+		if em.Text != "" {
+			vs = append(vs, variant{
+				name: "email text",
+				text: em.Text,
+				set:  func(_ *model.RenderedMessage, s string) { msg.Text = s },
+			})
+		}
+	}
+
+	if t.Text != "" {
+		vs = append(vs, variant{
+			name: "text",
+			text: t.Text,
+			set:  func(m *model.RenderedMessage, s string) { m.Text = s },
+		})
+	}
+
+	return vs
+}
+
+// check parses all variants of the template
+func check(t *model.Template) error {
+	for _, v := range variants(t) {
+		if _, err := parse(v.name, v.text, v.html); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render renders all variants of the template with vars. Variables are escaped
+// by html/template in HTML variants and for MarkdownV2 in Telegram MarkdownV2 ones
+func render(t *model.Template, vars map[string]any) (*model.RenderedMessage, error) {
+	msg := &model.RenderedMessage{}
+	for _, v := range variants(t) {
+		data := vars
+		if v.escape != nil {
+			data, _ = v.escape(vars).(map[string]any)
+		}
+		rendered, err := execute(v.name, v.text, v.html, data)
+		if err != nil {
+			return nil, err
+		}
+		v.set(msg, rendered)
+	}
+	return msg, nil
+}
+
+// escapeMarkdownV2 escapes the strings in the variables. Other values are
+// printed as is, numbers with '.' or '-' have to be passed as strings
+func escapeMarkdownV2(v any) any {
+	switch v := v.(type) {
+	case string:
+		return markdownV2Escaper.Replace(v)
+	case map[string]any:
+		escaped := make(map[string]any, len(v))
+		for k, val := range v {
+			escaped[k] = escapeMarkdownV2(val)
+		}
+		return escaped
+	case []any:
+		escaped := make([]any, len(v))
+		for i, val := range v {
+			escaped[i] = escapeMarkdownV2(val)
+		}
+		return escaped
+	}
+	return v
+}
@@ -0,0 +1,158 @@
+// Package templateService stores named message templates and renders them per channel.
+//
+// Telegram, e-mail and plain text variants are rendered with text/template, Telegram HTML
+// and e-mail HTML variants with html/template, which escapes the variables.
+package templateService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type ITemplateRepository interface {
+	SaveTemplate(ctx context.Context, template model.Template) (err error)
+	LoadTemplate(ctx context.Context, name string) (template *model.Template, err error)
+	ListTemplates(ctx context.Context) (templates []model.Template, err error)
+	DeleteTemplate(ctx context.Context, name string) (err error)
+}
+
+type TemplateService struct {
+	lg *zlog.Zerolog
+	rp ITemplateRepository
+}
+
+func New(parentLg *zlog.Zerolog, rp ITemplateRepository) *TemplateService {
+	lg := parentLg.With().Str("component", "TemplateService").Logger()
+	return &TemplateService{
+		lg: &lg,
+		rp: rp,
+	}
+}
+
+// SaveTemplate creates the template or replaces the template with the same name
+func (sv *TemplateService) SaveTemplate(ctx context.Context, template model.Template) (saved *model.Template, err error) {
+	lg := sv.lg.With().Str("method", "SaveTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Str("name", template.Name).Msgf("%s validating template...", pkgConst.OpStart)
+	if err := template.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidTemplate, err)
+	}
+	if err := check(&template); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidTemplate, err)
+	}
+	lg.Trace().Str("name", template.Name).Msgf("%s template validated successfully", pkgConst.OpSuccess)
+
+	now := time.Now()
+	template.CreatedAt, template.UpdatedAt = now, now
+
+	existing, err := sv.rp.LoadTemplate(ctx, template.Name)
+	switch {
+	case err == nil:
+		template.CreatedAt = existing.CreatedAt
+	case !errors.Is(err, pkgErrors.ErrTemplateNotFound):
+		return nil, pkgErrors.Wrap(err, "load existing template from repository")
+	}
+
+	lg.Trace().Str("name", template.Name).Msgf("%s saving template to repository...", pkgConst.OpStart)
+	if err := sv.rp.SaveTemplate(ctx, template); err != nil {
+		return nil, pkgErrors.Wrap(err, "save template to repository")
+	}
+	lg.Debug().Str("name", template.Name).Msgf("%s template saved to repository successfully", pkgConst.OpSuccess)
+
+	return &template, nil
+}
+
+func (sv *TemplateService) GetTemplate(ctx context.Context, name string) (template *model.Template, err error) {
+	lg := sv.lg.With().Str("method", "GetTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	template, err = sv.rp.LoadTemplate(ctx, name)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "get template from repository")
+	}
+
+	return template, nil
+}
+
+func (sv *TemplateService) ListTemplates(ctx context.Context) (templates []model.Template, err error) {
+	lg := sv.lg.With().Str("method", "ListTemplates").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	templates, err = sv.rp.ListTemplates(ctx)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "list templates from repository")
+	}
+
+	return templates, nil
+}
+
+// DeleteTemplate deletes the template. Notices referencing it fail to be delivered
+func (sv *TemplateService) DeleteTemplate(ctx context.Context, name string) (err error) {
+	lg := sv.lg.With().Str("method", "DeleteTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	if err := sv.rp.DeleteTemplate(ctx, name); err != nil {
+		return pkgErrors.Wrap(err, "delete template from repository")
+	}
+	lg.Debug().Str("name", name).Msgf("%s template deleted successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// RenderTemplate renders the referenced template with its variables for all channels
+func (sv *TemplateService) RenderTemplate(ctx context.Context, ref model.TemplateRef) (msg *model.RenderedMessage, err error) {
+	lg := sv.lg.With().Str("method", "RenderTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	template, err := sv.rp.LoadTemplate(ctx, ref.Name)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "load template from repository")
+	}
+
+	lg.Trace().Str("name", ref.Name).Msgf("%s rendering template...", pkgConst.OpStart)
+	msg, err = render(template, ref.Vars)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrTemplateVars, err)
+	}
+	lg.Trace().Str("name", ref.Name).Msgf("%s template rendered successfully", pkgConst.OpSuccess)
+
+	return msg, nil
+}
+
+// ValidateTemplateRef checks that the referenced template has variants
+// for all the channels and renders with the variables
+func (sv *TemplateService) ValidateTemplateRef(ctx context.Context, ref model.TemplateRef, channels model.Channels) (err error) {
+	lg := sv.lg.With().Str("method", "ValidateTemplateRef").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	template, err := sv.rp.LoadTemplate(ctx, ref.Name)
+	if err != nil {
+		return pkgErrors.Wrap(err, "load template from repository")
+	}
+
+	for _, ch := range channels {
+		if !template.Supports(ch.Type) {
+			return fmt.Errorf("%w: template %s has no %s variant", pkgErrors.ErrInvalidTemplate, ref.Name, ch.Type)
+		}
+	}
+
+	if _, err := render(template, ref.Vars); err != nil {
+		return fmt.Errorf("%w: %w", pkgErrors.ErrTemplateVars, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package templateService_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/templateService"
+)
+
+func newService(t *testing.T) *templateService.TemplateService {
+	t.Helper()
+
+	rp := rpTest.New(t)
+
+	lg := zlog.Logger
+	return templateService.New(&lg, rp)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	sv := newService(t)
+	ctx := context.Background()
+
+	_, err := sv.SaveTemplate(ctx, model.Template{
+		Name: "reminder",
+		Telegram: &model.TelegramTemplate{
+			Text:      "*{{.title}}* at {{.time}}",
+			ParseMode: model.ParseModeMarkdownV2,
+		},
+		Email: &model.EmailTemplate{
+			Subject: "Reminder: {{.title}}",
+			HTML:    "<b>{{.title}}</b> at {{.time}}",
+			Text:    "{{.title}} at {{.time}}",
+		},
+	})
+	require.NoError(t, err)
+
+	msg, err := sv.RenderTemplate(ctx, model.TemplateRef{
+		Name: "reminder",
+		Vars: map[string]any{"title": "Stand-up <team>", "time": "09:00"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, &model.TelegramMessage{Text: `*Stand\-up <team\>* at 09:00`, ParseMode: model.ParseModeMarkdownV2}, msg.Telegram)
+	require.Equal(t, &model.EmailMessage{
+		Subject: "Reminder: Stand-up <team>",
+		HTML:    "<b>Stand-up &lt;team&gt;</b> at 09:00",
+		Text:    "Stand-up <team> at 09:00",
+	}, msg.Email)
+
+	_, err = sv.RenderTemplate(ctx, model.TemplateRef{Name: "reminder", Vars: map[string]any{"title": "Stand-up"}})
+	require.ErrorIs(t, err, pkgErrors.ErrTemplateVars, "missing variable")
+
+	_, err = sv.RenderTemplate(ctx, model.TemplateRef{Name: "unknown"})
+	require.ErrorIs(t, err, pkgErrors.ErrTemplateNotFound)
+}
+
+func TestSaveTemplate(t *testing.T) {
+	sv := newService(t)
+	ctx := context.Background()
+
+	first, err := sv.SaveTemplate(ctx, model.Template{Name: "plain", Email: &model.EmailTemplate{Text: "{{.text}}"}})
+	require.NoError(t, err)
+	require.Equal(t, first.CreatedAt, first.UpdatedAt)
+
+	second, err := sv.SaveTemplate(ctx, model.Template{Name: "plain", Email: &model.EmailTemplate{Text: "{{.text}}!"}})
+	require.NoError(t, err)
+	require.True(t, second.CreatedAt.Equal(first.CreatedAt), "replacing keeps created_at")
+
+	msg, err := sv.RenderTemplate(ctx, model.TemplateRef{Name: "plain", Vars: map[string]any{"text": "hi"}})
+	require.NoError(t, err)
+	require.Equal(t, &model.EmailMessage{Subject: model.DefaultEmailSubject, Text: "hi!"}, msg.Email)
+	require.Nil(t, msg.Telegram)
+
+	for _, invalid := range []model.Template{
+		{Name: "no variants"},
+		{Name: "bad", Email: &model.EmailTemplate{Text: "{{.text"}},
+		{Name: "bad", Telegram: &model.TelegramTemplate{Text: "hi", ParseMode: "Markdown"}},
+	} {
+		_, err := sv.SaveTemplate(ctx, invalid)
+		require.ErrorIs(t, err, pkgErrors.ErrInvalidTemplate, invalid.Name)
+	}
+
+	templates, err := sv.ListTemplates(ctx)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+
+	require.NoError(t, sv.DeleteTemplate(ctx, "plain"))
+	require.ErrorIs(t, sv.DeleteTemplate(ctx, "plain"), pkgErrors.ErrTemplateNotFound)
+}
+
+func TestValidateTemplateRef(t *testing.T) {
+	sv := newService(t)
+	ctx := context.Background()
+
+	_, err := sv.SaveTemplate(ctx, model.Template{Name: "email-only", Email: &model.EmailTemplate{Text: "{{.text}}"}})
+	require.NoError(t, err)
+
+	email := model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}}
+	telegram := model.Channels{{Type: model.ChannelTelegram, Value: "user"}}
+	ref := model.TemplateRef{Name: "email-only", Vars: map[string]any{"text": "hi"}}
+
+	require.NoError(t, sv.ValidateTemplateRef(ctx, ref, email))
+	require.ErrorIs(t, sv.ValidateTemplateRef(ctx, ref, telegram), pkgErrors.ErrInvalidTemplate)
+	require.ErrorIs(t, sv.ValidateTemplateRef(ctx, model.TemplateRef{Name: "email-only"}, email), pkgErrors.ErrTemplateVars)
+
+	sms := model.Channels{{Type: model.ChannelSMS, Value: "+79990000000"}}
+	require.ErrorIs(t, sv.ValidateTemplateRef(ctx, ref, sms), pkgErrors.ErrInvalidTemplate, "sms needs the text variant")
+
+	_, err = sv.SaveTemplate(ctx, model.Template{Name: "text-only", Text: "{{.text}}!"})
+	require.NoError(t, err)
+	textRef := model.TemplateRef{Name: "text-only", Vars: map[string]any{"text": "hi"}}
+	require.NoError(t, sv.ValidateTemplateRef(ctx, textRef, sms))
+
+	msg, err := sv.RenderTemplate(ctx, textRef)
+	require.NoError(t, err)
+	require.Equal(t, &model.RenderedMessage{Text: "hi!"}, msg)
+}
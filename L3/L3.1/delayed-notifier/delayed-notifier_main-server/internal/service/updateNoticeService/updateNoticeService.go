@@ -2,6 +2,8 @@ package updateNoticeService
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
@@ -11,39 +13,150 @@ import (
 )
 
 type IUpdateNoticeRepository interface {
-	UpdateNotice(ctx context.Context, notice *model.Notice) (err error)
+	LoadNotice(ctx context.Context, id int) (notice *model.Notice, err error)
+	UpdateNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status) (err error)
+	RetainNoticeIf(ctx context.Context, notice *model.Notice, version int, status model.Status, retention time.Duration) (err error)
+	ScheduleNotice(ctx context.Context, id int, dueAt time.Time) (err error)
+}
+
+type ITemplateService interface {
+	ValidateTemplateRef(ctx context.Context, ref model.TemplateRef, channels model.Channels) (err error)
 }
 
 type UpdateNoticeService struct {
-	lg *zlog.Zerolog
-	rb *pkgRabbitmq.Client
-	rp IUpdateNoticeRepository
+	lg    *zlog.Zerolog
+	rb    *pkgRabbitmq.Client
+	rp    IUpdateNoticeRepository
+	tplSv ITemplateService
 }
 
 func New(
 	parentLg *zlog.Zerolog,
 	rp IUpdateNoticeRepository,
+	tplSv ITemplateService,
 ) *UpdateNoticeService {
-	lg := parentLg.With().Str("component", "AddNoticeService").Logger()
+	lg := parentLg.With().Str("component", "UpdateNoticeService").Logger()
 	return &UpdateNoticeService{
-		lg: &lg,
-		rp: rp,
+		lg:    &lg,
+		rp:    rp,
+		tplSv: tplSv,
 	}
 }
 
+// UpdateStatus changes the status of the notice if the stored notice has the version
+// and the status of the copy, so a notice changed after the copy was loaded is not
+// overwritten. It returns ErrNoticeConflict otherwise
 func (sv *UpdateNoticeService) UpdateStatus(ctx context.Context, notice *model.Notice, newStatus model.Status) (err error) {
-	lg := sv.lg.With().Str("method", "UpdateNotice").Logger()
+	lg := sv.lg.With().Str("method", "UpdateStatus").Logger()
 	lg.Trace().Msgf("%s method starting", pkgConst.Start)
 	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
 
+	status := notice.Status
 	notice.Status = newStatus
 
 	lg.Trace().Msgf("%s updating notice status to repository...", pkgConst.OpStart)
-	err = sv.rp.UpdateNotice(ctx, notice)
+	err = sv.rp.UpdateNoticeIf(ctx, notice, notice.Version, status)
 	if err != nil {
+		notice.Status = status
 		return pkgErrors.Wrap(err, "update notice status to repository")
 	}
 	lg.Trace().Msgf("%s notice status updated to repository successfully", pkgConst.OpSuccess)
 
 	return nil
 }
+
+// ClaimNotice sets the pending status of the notice being sent if the stored notice
+// has the version of the copy and is scheduled, so a notice is claimed by one consumer only.
+// It returns ErrNoticeConflict otherwise
+func (sv *UpdateNoticeService) ClaimNotice(ctx context.Context, notice *model.Notice) (err error) {
+	lg := sv.lg.With().Str("method", "ClaimNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	status := notice.Status
+	notice.Status = model.StatusPending
+
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s claiming notice in repository...", pkgConst.OpStart)
+	err = sv.rp.UpdateNoticeIf(ctx, notice, notice.Version, model.StatusScheduled)
+	if err != nil {
+		notice.Status = status
+		return pkgErrors.Wrap(err, "claim notice in repository")
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice claimed in repository successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// RetainNotice saves the notice in its final status if the stored notice has the version
+// of the copy and the status, the repository removes it after the retention period.
+// It returns ErrNoticeConflict otherwise
+func (sv *UpdateNoticeService) RetainNotice(ctx context.Context, notice *model.Notice, status model.Status, retention time.Duration) (err error) {
+	lg := sv.lg.With().Str("method", "RetainNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", notice.ID).Str("status", string(notice.Status)).Msgf("%s retaining notice in repository...", pkgConst.OpStart)
+	err = sv.rp.RetainNoticeIf(ctx, notice, notice.Version, status, retention)
+	if err != nil {
+		return pkgErrors.Wrap(err, "retain notice in repository")
+	}
+	lg.Trace().Int("notice ID", notice.ID).Msgf("%s notice retained in repository successfully", pkgConst.OpSuccess)
+
+	return nil
+}
+
+// PatchNotice changes the scheduled notice and schedules it again.
+// A copy of the notice published before the change is not sent: its version is outdated.
+// It returns ErrNoticeConflict if the notice was changed or taken by the consumer meanwhile
+func (sv *UpdateNoticeService) PatchNotice(ctx context.Context, id int, patch model.PatchNotice) (notice *model.Notice, err error) {
+	lg := sv.lg.With().Str("method", "PatchNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Int("notice ID", id).Msgf("%s validating patch...", pkgConst.OpStart)
+	if err := patch.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidPatch, err)
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s patch validated successfully", pkgConst.OpSuccess)
+
+	lg.Trace().Int("notice ID", id).Msgf("%s getting notice from repository...", pkgConst.OpStart)
+	notice, err = sv.rp.LoadNotice(ctx, id)
+	if err != nil {
+		return nil, pkgErrors.Wrap(err, "get notice from repository")
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice got from repository successfully", pkgConst.OpSuccess)
+
+	if notice.Status != model.StatusScheduled {
+		return nil, pkgErrors.Wrapf(pkgErrors.ErrNoticeNotScheduled, "patch notice, notice status: %s", notice.Status)
+	}
+
+	version := notice.Version
+	if err := patch.Apply(notice, time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidPatch, err)
+	}
+	if notice.Template != nil && patch.Channels != nil {
+		lg.Trace().Str("template", notice.Template.Name).Msgf("%s validating template reference...", pkgConst.OpStart)
+		if err := sv.tplSv.ValidateTemplateRef(ctx, *notice.Template, notice.Channels); err != nil {
+			return nil, pkgErrors.Wrap(err, "template reference validation failed")
+		}
+		lg.Trace().Str("template", notice.Template.Name).Msgf("%s template reference validated successfully", pkgConst.OpSuccess)
+	}
+	if err := notice.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgErrors.ErrInvalidPatch, err)
+	}
+
+	lg.Trace().Int("notice ID", id).Int("version", notice.Version).Msgf("%s updating notice to repository...", pkgConst.OpStart)
+	if err := sv.rp.UpdateNoticeIf(ctx, notice, version, model.StatusScheduled); err != nil {
+		return nil, pkgErrors.Wrap(err, "update notice to repository")
+	}
+	lg.Trace().Int("notice ID", id).Msgf("%s notice updated to repository successfully", pkgConst.OpSuccess)
+
+	// the scheduler publishes the new version when it is due
+	lg.Trace().Int("notice ID", id).Time("due at", notice.DueAt()).Msgf("%s scheduling notice...", pkgConst.OpStart)
+	if err := sv.rp.ScheduleNotice(ctx, notice.ID, notice.DueAt()); err != nil {
+		return nil, pkgErrors.Wrap(err, "schedule notice")
+	}
+	lg.Debug().Int("notice ID", id).Int("version", notice.Version).Time("due at", notice.DueAt()).Msgf("%s notice patched successfully", pkgConst.OpSuccess)
+
+	return notice, nil
+}
@@ -0,0 +1,215 @@
+package updateNoticeService_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/repository/rpTest"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/templateService"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/service/updateNoticeService"
+)
+
+func newService(t *testing.T) (*updateNoticeService.UpdateNoticeService, *repository.Repository) {
+	t.Helper()
+
+	rp := rpTest.New(t)
+
+	lg := zlog.Logger
+	return updateNoticeService.New(&lg, rp, templateService.New(&lg, rp)), rp
+}
+
+func addNotice(t *testing.T, rp *repository.Repository, dueAt time.Time, status model.Status) int {
+	t.Helper()
+
+	ctx := context.Background()
+	id, err := rp.SaveNotice(ctx, model.Notice{
+		UserID:    1,
+		Message:   "test",
+		Channels:  model.Channels{{Type: model.ChannelEmail, Value: "test@example.com"}},
+		CreatedAt: time.Now(),
+		SentAt:    &dueAt,
+		Status:    status,
+	})
+	require.NoError(t, err)
+	require.NoError(t, rp.ScheduleNotice(ctx, id, dueAt))
+	return id
+}
+
+func TestPatchNotice(t *testing.T) {
+	sv, rp := newService(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	id := addNotice(t, rp, now.Add(time.Hour), model.StatusScheduled)
+
+	sentAt := now.Add(2 * time.Hour)
+	message := "changed"
+	notice, err := sv.PatchNotice(ctx, id, model.PatchNotice{SentAt: &sentAt, Message: &message})
+	require.NoError(t, err)
+	require.Equal(t, 1, notice.Version)
+
+	stored, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "changed", stored.Message)
+	require.Equal(t, 1, stored.Version)
+	require.True(t, stored.SentAt.Equal(sentAt))
+
+	ids, err := rp.ClaimDueNotices(ctx, now.Add(90*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, ids, "notice is not due at the previous time")
+	ids, err = rp.ClaimDueNotices(ctx, sentAt, time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{id}, ids)
+}
+
+func TestPatchNotice_Errors(t *testing.T) {
+	sv, rp := newService(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	scheduled := addNotice(t, rp, now.Add(time.Hour), model.StatusScheduled)
+	sent := addNotice(t, rp, now.Add(time.Hour), model.StatusSent)
+
+	message := "changed"
+	_, err := sv.PatchNotice(ctx, sent, model.PatchNotice{Message: &message})
+	require.ErrorIs(t, err, pkgErrors.ErrNoticeNotScheduled)
+
+	_, err = sv.PatchNotice(ctx, scheduled+100, model.PatchNotice{Message: &message})
+	require.ErrorIs(t, err, pkgErrors.ErrNoticeNotFound)
+
+	_, err = sv.PatchNotice(ctx, scheduled, model.PatchNotice{})
+	require.ErrorIs(t, err, pkgErrors.ErrInvalidPatch)
+
+	past := now.Add(-time.Minute)
+	_, err = sv.PatchNotice(ctx, scheduled, model.PatchNotice{SentAt: &past})
+	require.ErrorIs(t, err, pkgErrors.ErrInvalidPatch)
+
+	stored, err := rp.LoadNotice(ctx, scheduled)
+	require.NoError(t, err)
+	require.Zero(t, stored.Version, "rejected patch does not change the notice")
+}
+
+func TestPatchNotice_RacesConsumer(t *testing.T) {
+	sv, rp := newService(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	message := "changed"
+
+	// the consumer loaded the notice before the patch
+	id := addNotice(t, rp, now.Add(time.Hour), model.StatusScheduled)
+	consumed, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	_, err = sv.PatchNotice(ctx, id, model.PatchNotice{Message: &message})
+	require.NoError(t, err)
+	err = sv.ClaimNotice(ctx, consumed)
+	require.ErrorIs(t, err, pkgErrors.ErrNoticeConflict)
+	stored, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusScheduled, stored.Status)
+	require.Equal(t, "changed", stored.Message, "patch is not overwritten by the stale copy")
+
+	// the consumer took the notice before the patch
+	id = addNotice(t, rp, now.Add(time.Hour), model.StatusScheduled)
+	consumed, err = rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.NoError(t, sv.ClaimNotice(ctx, consumed))
+	_, err = sv.PatchNotice(ctx, id, model.PatchNotice{Message: &message})
+	require.ErrorIs(t, err, pkgErrors.ErrNoticeNotScheduled)
+
+	// both at once: exactly one of them wins and the other one changes nothing
+	for range 20 {
+		id := addNotice(t, rp, now.Add(time.Hour), model.StatusScheduled)
+		consumed, err := rp.LoadNotice(ctx, id)
+		require.NoError(t, err)
+
+		var patchErr, consumeErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, patchErr = sv.PatchNotice(ctx, id, model.PatchNotice{Message: &message})
+		}()
+		go func() {
+			defer wg.Done()
+			consumeErr = sv.ClaimNotice(ctx, consumed)
+		}()
+		wg.Wait()
+
+		stored, err := rp.LoadNotice(ctx, id)
+		require.NoError(t, err)
+		if consumeErr == nil {
+			require.Error(t, patchErr)
+			require.True(t, errors.Is(patchErr, pkgErrors.ErrNoticeConflict) || errors.Is(patchErr, pkgErrors.ErrNoticeNotScheduled))
+			require.Equal(t, model.StatusPending, stored.Status)
+			require.Equal(t, "test", stored.Message)
+			require.Zero(t, stored.Version)
+		} else {
+			require.ErrorIs(t, consumeErr, pkgErrors.ErrNoticeConflict)
+			require.NoError(t, patchErr)
+			require.Equal(t, model.StatusScheduled, stored.Status)
+			require.Equal(t, "changed", stored.Message)
+			require.Equal(t, 1, stored.Version)
+		}
+	}
+}
+
+func TestClaimNotice_Once(t *testing.T) {
+	sv, rp := newService(t)
+	ctx := context.Background()
+
+	// both consumers got the notice, the second one after the lease expired
+	id := addNotice(t, rp, time.Now(), model.StatusScheduled)
+	first, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.NoError(t, sv.ClaimNotice(ctx, first))
+	second, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusPending, second.Status)
+	require.ErrorIs(t, sv.ClaimNotice(ctx, second), pkgErrors.ErrNoticeConflict, "pending notice is being sent")
+
+	// both at once: exactly one of them claims the notice
+	for range 20 {
+		id := addNotice(t, rp, time.Now(), model.StatusScheduled)
+		var claimed sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			notice, err := rp.LoadNotice(ctx, id)
+			require.NoError(t, err)
+			claimed.Go(func() { errs[i] = sv.ClaimNotice(ctx, notice) })
+		}
+		claimed.Wait()
+		require.True(t, (errs[0] == nil) != (errs[1] == nil), "errors: %v", errs)
+	}
+}
+
+func TestRetainNotice_Conflict(t *testing.T) {
+	sv, rp := newService(t)
+	ctx := context.Background()
+
+	// the consumer skips the loaded occurrence while the notice is deleted
+	id := addNotice(t, rp, time.Now(), model.StatusScheduled)
+	consumed, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	deleted, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.NoError(t, sv.UpdateStatus(ctx, deleted, model.StatusDeleted))
+
+	consumed.Status = model.StatusSkipped
+	err = sv.RetainNotice(ctx, consumed, model.StatusScheduled, time.Hour)
+	require.ErrorIs(t, err, pkgErrors.ErrNoticeConflict)
+	stored, err := rp.LoadNotice(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusDeleted, stored.Status, "deletion is not overwritten")
+
+	require.NoError(t, sv.RetainNotice(ctx, stored, model.StatusDeleted, time.Hour))
+}
@@ -2,6 +2,8 @@ package addNoticeHandler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -14,6 +16,7 @@ import (
 
 type IService interface {
 	AddNotice(ctx context.Context, reqNotice model.ReqNotice) (id int, err error)
+	AddNotices(ctx context.Context, batch model.ReqBatch) (results []model.BatchResult, err error)
 }
 
 type Handler struct {
@@ -33,6 +36,7 @@ func New(parentLg *zlog.Zerolog, rt *ginext.Engine, sv IService) *Handler {
 
 func (hd *Handler) RegisterRoutes() {
 	hd.rt.POST("/notify", hd.CreateNotice)
+	hd.rt.POST("/notify/batch", hd.CreateNotices)
 }
 
 func (hd *Handler) CreateNotice(c *ginext.Context) {
@@ -55,10 +59,27 @@ func (hd *Handler) CreateNotice(c *ginext.Context) {
 		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to bind json: " + err.Error()})
 		return
 	}
+	if err := model.CheckAPIUserID(req.UserID); err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s invalid user_id", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
 	lg.Trace().Msgf("%s json data unmarshaled to notice successfully", pkgConst.OpSuccess)
 
+	req.IdempotencyKey = c.GetHeader("Idempotency-Key")
+
 	lg.Trace().Msgf("%s adding notice...", pkgConst.OpStart)
 	id, err := hd.sv.AddNotice(c.Request.Context(), req)
+	if errors.Is(err, pkgErrors.ErrTemplateNotFound) || errors.Is(err, pkgErrors.ErrInvalidTemplate) || errors.Is(err, pkgErrors.ErrTemplateVars) {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s invalid notice template", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to add notice: " + err.Error()})
+		return
+	}
+	if errors.Is(err, pkgErrors.ErrIdempotencyConflict) || errors.Is(err, pkgErrors.ErrIdempotencyPending) {
+		lg.Warn().Err(err).Int("status", http.StatusConflict).Msgf("%s idempotency key is in use", pkgConst.Warn)
+		c.JSON(http.StatusConflict, ginext.H{"error": "failed to add notice: " + err.Error()})
+		return
+	}
 	if err != nil {
 		lg.Error().Err(err).Int("status", http.StatusInternalServerError).Msgf("%s failed to add notice", pkgConst.Error)
 		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to add notice: " + err.Error()})
@@ -68,3 +89,48 @@ func (hd *Handler) CreateNotice(c *ginext.Context) {
 
 	c.JSON(http.StatusOK, ginext.H{"id": id})
 }
+
+// CreateNotices creates the notices of the batch, all or none of them
+func (hd *Handler) CreateNotices(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "CreateNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	lg.Trace().Msgf("%s checking content type...", pkgConst.OpStart)
+	if !strings.Contains(c.ContentType(), "application/json") {
+		lg.Warn().Str("content-type", c.ContentType()).Int("status", http.StatusBadRequest).Msgf("%s invalid content-type", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": pkgErrors.ErrContentTypeAJ.Error()})
+		return
+	}
+	lg.Trace().Msgf("%s content type is valid", pkgConst.OpSuccess)
+
+	var batch model.ReqBatch
+	if err := c.ShouldBindJSON(&batch); err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s failed to bind json", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to bind json: " + err.Error()})
+		return
+	}
+	for i, req := range batch.Notices {
+		if err := model.CheckAPIUserID(req.UserID); err != nil {
+			lg.Warn().Err(err).Int("index", i).Int("status", http.StatusBadRequest).Msgf("%s invalid user_id", pkgConst.Warn)
+			c.JSON(http.StatusBadRequest, ginext.H{"error": fmt.Sprintf("notice %d: %s", i, err)})
+			return
+		}
+	}
+
+	lg.Trace().Int("count", len(batch.Notices)).Msgf("%s adding notices...", pkgConst.OpStart)
+	results, err := hd.sv.AddNotices(c.Request.Context(), batch)
+	if errors.Is(err, pkgErrors.ErrInvalidBatch) {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s invalid batch", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to add notices: " + err.Error(), "results": results})
+		return
+	}
+	if err != nil {
+		lg.Error().Err(err).Int("status", http.StatusInternalServerError).Msgf("%s failed to add notices", pkgConst.Error)
+		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to add notices: " + err.Error(), "results": results})
+		return
+	}
+	lg.Debug().Int("count", len(results)).Msgf("%s notices added successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, ginext.H{"results": results})
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
 	"github.com/wb-go/wbf/ginext"
@@ -14,6 +15,8 @@ import (
 
 type IService interface {
 	PreDeleteNotice(ctx context.Context, id int) (err error)
+	SkipOccurrence(ctx context.Context, id int) (status model.Status, err error)
+	CancelUserNotices(ctx context.Context, userID int) (cancelled int, err error)
 }
 
 type Handler struct {
@@ -34,6 +37,7 @@ func New(parentLg *zlog.Zerolog, rt *ginext.Engine, sv IService) *Handler {
 
 func (hd *Handler) RegisterRoutes() {
 	hd.rt.DELETE("/notify/:id", hd.DeleteNotice)
+	hd.rt.DELETE("/notify", hd.CancelUserNotices)
 }
 
 func (hd *Handler) DeleteNotice(c *ginext.Context) {
@@ -55,6 +59,18 @@ func (hd *Handler) DeleteNotice(c *ginext.Context) {
 		return
 	}
 
+	// scope=occurrence cancels only the scheduled occurrence of a recurring notice
+	switch scope := c.Query("scope"); scope {
+	case "", "series":
+	case "occurrence":
+		hd.skipOccurrence(c, id)
+		return
+	default:
+		lg.Warn().Str("scope", scope).Msgf("%s invalid scope param", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "scope must be series or occurrence"})
+		return
+	}
+
 	lg.Trace().Msgf("%s deleting notice...", pkgConst.OpStart)
 	err = hd.sv.PreDeleteNotice(c.Request.Context(), id)
 	if errors.Is(err, pkgErrors.ErrNoticeNotFound) {
@@ -62,6 +78,11 @@ func (hd *Handler) DeleteNotice(c *ginext.Context) {
 		c.JSON(http.StatusNotFound, ginext.H{"error": "notice with ID=" + idStr + " not found: " + err.Error()})
 		return
 	}
+	if errors.Is(err, pkgErrors.ErrNoticeNotScheduled) || errors.Is(err, pkgErrors.ErrNoticeConflict) {
+		lg.Warn().Err(err).Int("notice ID", id).Msgf("%s notice is not scheduled", pkgConst.Warn)
+		c.JSON(http.StatusConflict, ginext.H{"error": "failed to delete notice: " + err.Error()})
+		return
+	}
 	if err != nil {
 		lg.Warn().Err(err).Int("notice ID", id).Msgf("%s failed to delete notice", pkgConst.Warn)
 		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to delete notice: " + err.Error()})
@@ -71,3 +92,56 @@ func (hd *Handler) DeleteNotice(c *ginext.Context) {
 
 	c.JSON(http.StatusOK, ginext.H{"status": "deleted"})
 }
+
+func (hd *Handler) skipOccurrence(c *ginext.Context, id int) {
+	lg := hd.lg.With().Str("method", "skipOccurrence").Logger()
+
+	lg.Trace().Msgf("%s skipping occurrence...", pkgConst.OpStart)
+	status, err := hd.sv.SkipOccurrence(c.Request.Context(), id)
+	if errors.Is(err, pkgErrors.ErrNoticeNotFound) {
+		lg.Warn().Err(err).Int("notice ID", id).Msgf("%s no exists notice ID", pkgConst.Warn)
+		c.JSON(http.StatusNotFound, ginext.H{"error": "notice with ID=" + strconv.Itoa(id) + " not found: " + err.Error()})
+		return
+	}
+	if errors.Is(err, pkgErrors.ErrNotRecurring) {
+		lg.Warn().Err(err).Int("notice ID", id).Msgf("%s notice is not recurring", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		lg.Warn().Err(err).Int("notice ID", id).Msgf("%s failed to skip occurrence", pkgConst.Warn)
+		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to skip occurrence: " + err.Error()})
+		return
+	}
+	lg.Debug().Int("notice ID", id).Str("status", string(status)).Msgf("%s occurrence skipped successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, ginext.H{"status": status})
+}
+
+// CancelUserNotices deletes all scheduled notices of the user_id query param
+func (hd *Handler) CancelUserNotices(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "CancelUserNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	userID, err := strconv.Atoi(c.Query("user_id"))
+	if err == nil {
+		err = model.CheckAPIUserID(userID)
+	}
+	if err != nil {
+		lg.Warn().Str("user ID", c.Query("user_id")).Msgf("%s user_id param is not a valid integer", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "user_id is required and must be a positive integer"})
+		return
+	}
+
+	lg.Trace().Int("user ID", userID).Msgf("%s cancelling user notices...", pkgConst.OpStart)
+	cancelled, err := hd.sv.CancelUserNotices(c.Request.Context(), userID)
+	if err != nil {
+		lg.Error().Err(err).Int("user ID", userID).Msgf("%s failed to cancel user notices", pkgConst.Error)
+		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to cancel user notices: " + err.Error(), "cancelled": cancelled})
+		return
+	}
+	lg.Debug().Int("user ID", userID).Int("cancelled", cancelled).Msgf("%s user notices cancelled successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, ginext.H{"cancelled": cancelled})
+}
@@ -3,8 +3,11 @@ package getStatusHandler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
@@ -15,6 +18,7 @@ import (
 
 type IService interface {
 	GetNotice(ctx context.Context, id int) (notice *model.Notice, err error)
+	ListNotices(ctx context.Context, filter model.NoticeFilter) (page *model.NoticePage, err error)
 }
 
 type Handler struct {
@@ -34,6 +38,7 @@ func New(parentLg *zlog.Zerolog, rt *ginext.Engine, sv IService) *Handler {
 
 func (hd *Handler) RegisterRoutes() {
 	hd.rt.GET("/notify/:id", hd.GetNotice)
+	hd.rt.GET("/users/:user_id/notices", hd.ListNotices)
 }
 
 func (hd *Handler) GetNotice(c *ginext.Context) {
@@ -69,5 +74,82 @@ func (hd *Handler) GetNotice(c *ginext.Context) {
 	}
 	lg.Debug().Int("notice ID", id).Msgf("%s notice got successfully", pkgConst.OpSuccess)
 
-	c.JSON(http.StatusOK, ginext.H{"status": notice.Status})
+	c.JSON(http.StatusOK, ginext.H{
+		"status":     notice.Status,
+		"deliveries": notice.Deliveries,
+		"occurrence": notice.Occurrence,
+		"sent_at":    notice.SentAt,
+		"previous":   notice.Previous,
+	})
+}
+
+// ListNotices returns a page of the notices of the user filtered by the query
+// parameters status (comma separated), from and to (RFC 3339, sent_at range),
+// limit and offset
+func (hd *Handler) ListNotices(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "ListNotices").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		lg.Warn().Err(err).Msgf("%s invalid query parameters", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+
+	lg.Trace().Int("user ID", filter.UserID).Msgf("%s listing notices...", pkgConst.OpStart)
+	page, err := hd.sv.ListNotices(c.Request.Context(), filter)
+	if errors.Is(err, pkgErrors.ErrInvalidFilter) {
+		lg.Warn().Err(err).Int("user ID", filter.UserID).Msgf("%s invalid notice filter", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		lg.Warn().Err(err).Int("user ID", filter.UserID).Msgf("%s failed to list notices", pkgConst.Warn)
+		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to list notices: " + err.Error()})
+		return
+	}
+	lg.Debug().Int("user ID", filter.UserID).Int("total", page.Total).Msgf("%s notices listed successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, page)
+}
+
+func parseFilter(c *ginext.Context) (filter model.NoticeFilter, err error) {
+	if filter.UserID, err = strconv.Atoi(c.Param("user_id")); err != nil {
+		return filter, fmt.Errorf("user_id must be an integer: %w", err)
+	}
+	if err = model.CheckAPIUserID(filter.UserID); err != nil {
+		return filter, err
+	}
+
+	if statuses := c.Query("status"); statuses != "" {
+		for _, s := range strings.Split(statuses, ",") {
+			filter.Statuses = append(filter.Statuses, model.Status(strings.TrimSpace(s)))
+		}
+	}
+
+	for name, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be an RFC 3339 time: %w", name, err)
+		}
+		*dest = &t
+	}
+
+	for name, dest := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		if *dest, err = strconv.Atoi(value); err != nil {
+			return filter, fmt.Errorf("%s must be an integer: %w", name, err)
+		}
+	}
+
+	return filter, nil
 }
@@ -9,7 +9,10 @@ import (
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport/trhttp/handler/deleteNoticeHandler"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport/trhttp/handler/getStatusHandler"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport/trhttp/handler/healthHandler"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport/trhttp/handler/preferencesHandler"
 	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport/trhttp/handler/telegramHandler"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport/trhttp/handler/templateHandler"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/transport/trhttp/handler/updateNoticeHandler"
 	"github.com/wb-go/wbf/ginext"
 	"github.com/wb-go/wbf/zlog"
 )
@@ -19,6 +22,9 @@ type IService interface {
 	deleteNoticeHandler.IService
 	getStatusHandler.IService
 	telegramHandler.IService
+	templateHandler.IService
+	preferencesHandler.IService
+	updateNoticeHandler.IService
 }
 
 type Handler struct {
@@ -35,8 +41,8 @@ func New(cfg *Config, parentLg *zlog.Zerolog, sv IService, publicHost string, we
 			publicHost,
 			webPublicHost,
 		},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -55,12 +61,21 @@ func New(cfg *Config, parentLg *zlog.Zerolog, sv IService, publicHost string, we
 	deleteNoticeHandler := deleteNoticeHandler.New(&lg, rt, sv)
 	deleteNoticeHandler.RegisterRoutes()
 
+	updateNoticeHandler := updateNoticeHandler.New(&lg, rt, sv)
+	updateNoticeHandler.RegisterRoutes()
+
 	getStatusHandler := getStatusHandler.New(&lg, rt, sv)
 	getStatusHandler.RegisterRoutes()
 
 	telegramHandler := telegramHandler.New(&lg, rt, sv)
 	telegramHandler.RegisterRoutes()
 
+	templateHandler := templateHandler.New(&lg, rt, sv)
+	templateHandler.RegisterRoutes()
+
+	preferencesHandler := preferencesHandler.New(&lg, rt, sv)
+	preferencesHandler.RegisterRoutes()
+
 	healthHandler := healthHandler.New(&lg, rt)
 	healthHandler.RegisterRoutes()
 
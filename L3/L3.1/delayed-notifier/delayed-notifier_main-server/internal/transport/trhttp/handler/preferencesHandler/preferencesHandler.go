@@ -0,0 +1,103 @@
+package preferencesHandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type IService interface {
+	SavePreferences(ctx context.Context, preferences model.Preferences) (saved *model.Preferences, err error)
+	GetPreferences(ctx context.Context, userID int) (preferences *model.Preferences, err error)
+}
+
+type Handler struct {
+	lg *zlog.Zerolog
+	rt *ginext.Engine
+	sv IService
+}
+
+func New(parentLg *zlog.Zerolog, rt *ginext.Engine, sv IService) *Handler {
+	lg := parentLg.With().Str("component", "preferencesHandler").Logger()
+	return &Handler{
+		lg: &lg,
+		rt: rt,
+		sv: sv,
+	}
+}
+
+func (hd *Handler) RegisterRoutes() {
+	hd.rt.GET("/users/:user_id/preferences", hd.GetPreferences)
+	hd.rt.PUT("/users/:user_id/preferences", hd.SavePreferences)
+}
+
+func (hd *Handler) GetPreferences(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "GetPreferences").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err == nil {
+		err = model.CheckAPIUserID(userID)
+	}
+	if err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s invalid user_id", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": model.ErrInvalidUserID.Error()})
+		return
+	}
+
+	preferences, err := hd.sv.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		lg.Error().Err(err).Int("user ID", userID).Msgf("%s failed to get preferences", pkgConst.Error)
+		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to get preferences: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (hd *Handler) SavePreferences(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "SavePreferences").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err == nil {
+		err = model.CheckAPIUserID(userID)
+	}
+	if err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s invalid user_id", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": model.ErrInvalidUserID.Error()})
+		return
+	}
+
+	var preferences model.Preferences
+	if err := c.ShouldBindJSON(&preferences); err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s failed to bind json", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to bind json: " + err.Error()})
+		return
+	}
+	preferences.UserID = userID
+
+	saved, err := hd.sv.SavePreferences(c.Request.Context(), preferences)
+	if errors.Is(err, pkgErrors.ErrInvalidPreferences) {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s invalid preferences", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to save preferences: " + err.Error()})
+		return
+	}
+	if err != nil {
+		lg.Error().Err(err).Int("user ID", userID).Msgf("%s failed to save preferences", pkgConst.Error)
+		c.JSON(http.StatusInternalServerError, ginext.H{"error": "failed to save preferences: " + err.Error()})
+		return
+	}
+	lg.Debug().Int("user ID", userID).Msgf("%s preferences saved successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, saved)
+}
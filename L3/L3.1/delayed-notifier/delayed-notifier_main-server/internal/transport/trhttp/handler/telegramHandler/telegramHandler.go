@@ -13,7 +13,7 @@ import (
 )
 
 type IService interface {
-	Start(ctx context.Context, username string, chatID int64, message string) error
+	HandleUpdate(ctx context.Context, update tgbotapi.Update) error
 }
 
 type Handler struct {
@@ -55,21 +55,13 @@ func (hd *Handler) WebHookHandler(c *ginext.Context) {
 		return
 	}
 
-	if update.Message == nil {
+	// Telegram resends the update until it gets 200, failures are only logged
+	if err := hd.sv.HandleUpdate(c.Request.Context(), update); err != nil {
+		lg.Warn().Int("update ID", update.UpdateID).Err(err).Msgf("%s failed to handle update", pkgConst.Warn)
 		c.Status(http.StatusOK)
 		return
 	}
 
-	username := update.Message.From.UserName
-	chatID := update.Message.Chat.ID
-	message := update.Message.Text
-
-	if err := hd.sv.Start(c.Request.Context(), username, chatID, message); err != nil {
-		lg.Warn().Int64("chatID", chatID).Str("message_body", message).Err(err).Msgf("%s failed to handle message", pkgConst.Warn)
-		c.Status(http.StatusOK)
-		return
-	}
-
-	lg.Debug().Int64("chatID", chatID).Str("message_body", message).Msgf("%s Telegram message processed", pkgConst.OpSuccess)
+	lg.Debug().Int("update ID", update.UpdateID).Msgf("%s Telegram update processed", pkgConst.OpSuccess)
 	c.Status(http.StatusOK)
 }
@@ -0,0 +1,152 @@
+package templateHandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type IService interface {
+	SaveTemplate(ctx context.Context, template model.Template) (saved *model.Template, err error)
+	GetTemplate(ctx context.Context, name string) (template *model.Template, err error)
+	ListTemplates(ctx context.Context) (templates []model.Template, err error)
+	DeleteTemplate(ctx context.Context, name string) (err error)
+	RenderTemplate(ctx context.Context, ref model.TemplateRef) (msg *model.RenderedMessage, err error)
+}
+
+type Handler struct {
+	lg *zlog.Zerolog
+	rt *ginext.Engine
+	sv IService
+}
+
+func New(parentLg *zlog.Zerolog, rt *ginext.Engine, sv IService) *Handler {
+	lg := parentLg.With().Str("component", "templateHandler").Logger()
+	return &Handler{
+		lg: &lg,
+		rt: rt,
+		sv: sv,
+	}
+}
+
+func (hd *Handler) RegisterRoutes() {
+	hd.rt.GET("/templates", hd.ListTemplates)
+	hd.rt.GET("/templates/:name", hd.GetTemplate)
+	hd.rt.PUT("/templates/:name", hd.SaveTemplate)
+	hd.rt.DELETE("/templates/:name", hd.DeleteTemplate)
+	hd.rt.POST("/templates/:name/preview", hd.PreviewTemplate)
+}
+
+// status returns the HTTP status of a template service error
+func status(err error) int {
+	switch {
+	case errors.Is(err, pkgErrors.ErrTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, pkgErrors.ErrInvalidTemplate), errors.Is(err, pkgErrors.ErrTemplateVars):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (hd *Handler) ListTemplates(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "ListTemplates").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	templates, err := hd.sv.ListTemplates(c.Request.Context())
+	if err != nil {
+		lg.Warn().Err(err).Msgf("%s failed to list templates", pkgConst.Warn)
+		c.JSON(status(err), ginext.H{"error": "failed to list templates: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ginext.H{"templates": templates})
+}
+
+func (hd *Handler) GetTemplate(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "GetTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	name := c.Param("name")
+	template, err := hd.sv.GetTemplate(c.Request.Context(), name)
+	if err != nil {
+		lg.Warn().Err(err).Str("name", name).Msgf("%s failed to get template", pkgConst.Warn)
+		c.JSON(status(err), ginext.H{"error": "failed to get template: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (hd *Handler) SaveTemplate(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "SaveTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	var template model.Template
+	if err := c.ShouldBindJSON(&template); err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s failed to bind json", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to bind json: " + err.Error()})
+		return
+	}
+	template.Name = c.Param("name")
+
+	saved, err := hd.sv.SaveTemplate(c.Request.Context(), template)
+	if err != nil {
+		lg.Warn().Err(err).Str("name", template.Name).Msgf("%s failed to save template", pkgConst.Warn)
+		c.JSON(status(err), ginext.H{"error": "failed to save template: " + err.Error()})
+		return
+	}
+	lg.Debug().Str("name", template.Name).Msgf("%s template saved successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, saved)
+}
+
+func (hd *Handler) DeleteTemplate(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "DeleteTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	name := c.Param("name")
+	if err := hd.sv.DeleteTemplate(c.Request.Context(), name); err != nil {
+		lg.Warn().Err(err).Str("name", name).Msgf("%s failed to delete template", pkgConst.Warn)
+		c.JSON(status(err), ginext.H{"error": "failed to delete template: " + err.Error()})
+		return
+	}
+	lg.Debug().Str("name", name).Msgf("%s template deleted successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, ginext.H{"status": "deleted"})
+}
+
+// PreviewTemplate renders the template with the variables from the request body
+func (hd *Handler) PreviewTemplate(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "PreviewTemplate").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	var req struct {
+		Vars map[string]any `json:"vars"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s failed to bind json", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to bind json: " + err.Error()})
+		return
+	}
+
+	name := c.Param("name")
+	msg, err := hd.sv.RenderTemplate(c.Request.Context(), model.TemplateRef{Name: name, Vars: req.Vars})
+	if err != nil {
+		lg.Warn().Err(err).Str("name", name).Msgf("%s failed to render template", pkgConst.Warn)
+		c.JSON(status(err), ginext.H{"error": "failed to render template: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, msg)
+}
@@ -0,0 +1,87 @@
+package updateNoticeHandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/L3/L3.1/delayed-notifier/delayed-notifier_main-server/internal/pkg/pkgErrors"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type IService interface {
+	PatchNotice(ctx context.Context, id int, patch model.PatchNotice) (notice *model.Notice, err error)
+}
+
+type Handler struct {
+	lg *zlog.Zerolog
+	rt *ginext.Engine
+	sv IService
+}
+
+func New(parentLg *zlog.Zerolog, rt *ginext.Engine, sv IService) *Handler {
+	lg := parentLg.With().Str("component", "updateNoticeHandler").Logger()
+	return &Handler{
+		lg: &lg,
+		rt: rt,
+		sv: sv,
+	}
+}
+
+func (hd *Handler) RegisterRoutes() {
+	hd.rt.PATCH("/notify/:id", hd.PatchNotice)
+}
+
+// status returns the HTTP status of a patch error
+func status(err error) int {
+	switch {
+	case errors.Is(err, pkgErrors.ErrNoticeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, pkgErrors.ErrNoticeNotScheduled),
+		errors.Is(err, pkgErrors.ErrNoticeConflict):
+		return http.StatusConflict
+	case errors.Is(err, pkgErrors.ErrInvalidPatch),
+		errors.Is(err, pkgErrors.ErrTemplateNotFound),
+		errors.Is(err, pkgErrors.ErrInvalidTemplate),
+		errors.Is(err, pkgErrors.ErrTemplateVars):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// PatchNotice changes sent_at, the message or channels of a scheduled notice
+func (hd *Handler) PatchNotice(c *ginext.Context) {
+	lg := hd.lg.With().Str("method", "PatchNotice").Logger()
+	lg.Trace().Msgf("%s method starting", pkgConst.Start)
+	defer lg.Trace().Msgf("%s method stopped", pkgConst.Stop)
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		lg.Warn().Str("notice ID", idStr).Msgf("%s id param is not a valid integer", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "id must be an integer: " + err.Error()})
+		return
+	}
+
+	var patch model.PatchNotice
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		lg.Warn().Err(err).Int("status", http.StatusBadRequest).Msgf("%s failed to bind json", pkgConst.Warn)
+		c.JSON(http.StatusBadRequest, ginext.H{"error": "failed to bind json: " + err.Error()})
+		return
+	}
+
+	lg.Trace().Int("notice ID", id).Msgf("%s patching notice...", pkgConst.OpStart)
+	notice, err := hd.sv.PatchNotice(c.Request.Context(), id, patch)
+	if err != nil {
+		lg.Warn().Err(err).Int("notice ID", id).Msgf("%s failed to patch notice", pkgConst.Warn)
+		c.JSON(status(err), ginext.H{"error": "failed to patch notice: " + err.Error()})
+		return
+	}
+	lg.Debug().Int("notice ID", id).Int("version", notice.Version).Msgf("%s notice patched successfully", pkgConst.OpSuccess)
+
+	c.JSON(http.StatusOK, notice)
+}
@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS idx_notices_expires_at;
+DROP INDEX IF EXISTS idx_notices_sent_at;
+DROP INDEX IF EXISTS idx_notices_status;
+DROP INDEX IF EXISTS idx_notices_user_id;
+
+ALTER TABLE notices
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS previous,
+    DROP COLUMN IF EXISTS occurrence,
+    DROP COLUMN IF EXISTS recurrence,
+    DROP COLUMN IF EXISTS deliveries,
+    DROP COLUMN IF EXISTS template,
+    DROP COLUMN IF EXISTS message,
+    ALTER COLUMN status DROP NOT NULL,
+    ALTER COLUMN sent_at TYPE TIMESTAMP,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at TYPE TIMESTAMP,
+    ALTER COLUMN channels DROP NOT NULL,
+    ALTER COLUMN channels TYPE TEXT USING channels::TEXT,
+    ALTER COLUMN user_id DROP NOT NULL;
@@ -0,0 +1,20 @@
+ALTER TABLE notices
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN channels TYPE JSONB USING channels::JSONB,
+    ALTER COLUMN channels SET NOT NULL,
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN sent_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN status SET NOT NULL,
+    ADD COLUMN IF NOT EXISTS message TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS template JSONB,
+    ADD COLUMN IF NOT EXISTS deliveries JSONB,
+    ADD COLUMN IF NOT EXISTS recurrence JSONB,
+    ADD COLUMN IF NOT EXISTS occurrence INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS previous JSONB,
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_notices_user_id ON notices(user_id);
+CREATE INDEX IF NOT EXISTS idx_notices_status ON notices(status);
+CREATE INDEX IF NOT EXISTS idx_notices_sent_at ON notices(sent_at);
+CREATE INDEX IF NOT EXISTS idx_notices_expires_at ON notices(expires_at) WHERE expires_at IS NOT NULL;
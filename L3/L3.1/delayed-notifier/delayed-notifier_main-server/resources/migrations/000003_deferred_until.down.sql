@@ -0,0 +1,2 @@
+ALTER TABLE notices
+    DROP COLUMN IF EXISTS deferred_until;
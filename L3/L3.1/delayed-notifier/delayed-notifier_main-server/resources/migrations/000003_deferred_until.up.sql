@@ -0,0 +1,2 @@
+ALTER TABLE notices
+    ADD COLUMN IF NOT EXISTS deferred_until TIMESTAMPTZ;
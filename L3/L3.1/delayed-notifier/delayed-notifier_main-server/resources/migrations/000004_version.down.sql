@@ -0,0 +1,2 @@
+ALTER TABLE notices
+    DROP COLUMN IF EXISTS version;
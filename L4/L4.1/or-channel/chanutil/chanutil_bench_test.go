@@ -0,0 +1,35 @@
+package chanutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func benchmarkOr(b *testing.B, or func(context.Context, ...<-chan struct{}) <-chan struct{}) {
+	for _, n := range []int{4, 16, 64, 256, 1024} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				channels := make([]<-chan struct{}, n)
+				never := make(chan struct{})
+				for i := range channels {
+					channels[i] = never
+				}
+				last := make(chan struct{})
+				channels[n-1] = last
+
+				done := or(context.Background(), channels...)
+				close(last)
+				<-done
+			}
+		})
+	}
+}
+
+func BenchmarkOrRecursive(b *testing.B) {
+	benchmarkOr(b, orRecursive[struct{}])
+}
+
+func BenchmarkOrReflect(b *testing.B) {
+	benchmarkOr(b, orReflect[struct{}])
+}
@@ -0,0 +1,219 @@
+package chanutil
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L4/L4.1/or-channel/leakcheck"
+)
+
+func generate[T any](values ...T) <-chan T {
+	ch := make(chan T, len(values))
+	for _, v := range values {
+		ch <- v
+	}
+	close(ch)
+	return ch
+}
+
+func collect[T any](ch <-chan T) []T {
+	var res []T
+	for v := range ch {
+		res = append(res, v)
+	}
+	return res
+}
+
+func closeAfter(d time.Duration) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		time.Sleep(d)
+		close(ch)
+	}()
+	return ch
+}
+
+func waitClosed(t *testing.T, ch <-chan struct{}, timeout time.Duration) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(timeout):
+		t.Fatalf("channel is not closed after %v", timeout)
+	}
+}
+
+func TestOr(t *testing.T) {
+	leakcheck.Check(t)
+
+	for _, n := range []int{1, 2, 3, 10, reflectThreshold + 5} {
+		channels := make([]<-chan struct{}, n)
+		never := make(chan struct{})
+		for i := range channels {
+			channels[i] = never
+		}
+		channels[n-1] = closeAfter(20 * time.Millisecond)
+
+		start := time.Now()
+		waitClosed(t, Or(context.Background(), channels...), time.Second)
+		if d := time.Since(start); d < 20*time.Millisecond {
+			t.Errorf("n=%d: closed too early: %v", n, d)
+		}
+	}
+
+	t.Run("no channels", func(t *testing.T) {
+		waitClosed(t, Or[struct{}](context.Background()), 5*time.Millisecond)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		never := make(chan int)
+		done := Or(ctx, never, never, never, never, never)
+		cancel()
+		waitClosed(t, done, time.Second)
+	})
+}
+
+func TestAnd(t *testing.T) {
+	leakcheck.Check(t)
+
+	ch1 := closeAfter(10 * time.Millisecond)
+	ch2 := closeAfter(40 * time.Millisecond)
+
+	start := time.Now()
+	waitClosed(t, And(context.Background(), ch1, ch2), time.Second)
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Errorf("closed before all channels: %v", d)
+	}
+}
+
+func TestFirstOf(t *testing.T) {
+	leakcheck.Check(t)
+
+	never := make(chan int)
+	fired, err := FirstOf(context.Background(), never, generate(7), never)
+	if err != nil || fired != (Fired[int]{Index: 1, Value: 7, Ok: true}) {
+		t.Errorf("got %+v, %v", fired, err)
+	}
+
+	closed := make(chan int)
+	close(closed)
+	fired, err = FirstOf(context.Background(), never, closed)
+	if err != nil || fired != (Fired[int]{Index: 1}) {
+		t.Errorf("got %+v, %v", fired, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	fired, err = FirstOf(ctx, never)
+	if err != context.DeadlineExceeded || fired.Index != -1 {
+		t.Errorf("got %+v, %v", fired, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	leakcheck.Check(t)
+
+	res := collect(Merge(context.Background(), generate(1, 2), generate(3), generate(4, 5, 6)))
+	slices.Sort(res)
+	if !slices.Equal(res, []int{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("got %v", res)
+	}
+}
+
+func TestTee(t *testing.T) {
+	leakcheck.Check(t)
+
+	out1, out2 := Tee(context.Background(), generate(1, 2, 3))
+
+	var res1, res2 []int
+	for range 3 {
+		// read in alternating order to check that neither output blocks the other
+		res1 = append(res1, <-out1)
+		res2 = append(res2, <-out2)
+	}
+	if !slices.Equal(res1, []int{1, 2, 3}) || !slices.Equal(res2, []int{1, 2, 3}) {
+		t.Errorf("got %v and %v", res1, res2)
+	}
+}
+
+func TestBridge(t *testing.T) {
+	leakcheck.Check(t)
+
+	stream := make(chan (<-chan int), 2)
+	stream <- generate(1, 2)
+	stream <- generate(3)
+	close(stream)
+
+	if res := collect(Bridge(context.Background(), stream)); !slices.Equal(res, []int{1, 2, 3}) {
+		t.Errorf("got %v", res)
+	}
+}
+
+func TestTake(t *testing.T) {
+	leakcheck.Check(t)
+
+	if res := collect(Take(context.Background(), generate(1, 2, 3, 4), 2)); !slices.Equal(res, []int{1, 2}) {
+		t.Errorf("got %v", res)
+	}
+	if res := collect(Take(context.Background(), generate(1), 5)); !slices.Equal(res, []int{1}) {
+		t.Errorf("got %v", res)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	leakcheck.Check(t)
+
+	t.Run("by size", func(t *testing.T) {
+		res := collect(Batch(context.Background(), generate(1, 2, 3, 4, 5), 2, time.Hour))
+		if len(res) != 3 || !slices.Equal(res[2], []int{5}) {
+			t.Errorf("got %v", res)
+		}
+	})
+
+	t.Run("by timeout", func(t *testing.T) {
+		in := make(chan int)
+		out := Batch(context.Background(), in, 10, 20*time.Millisecond)
+		in <- 1
+		in <- 2
+
+		select {
+		case b := <-out:
+			if !slices.Equal(b, []int{1, 2}) {
+				t.Errorf("got %v", b)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("batch is not flushed by timeout")
+		}
+		close(in)
+	})
+}
+
+func TestCancel(t *testing.T) {
+	leakcheck.Check(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	never := make(chan int)
+
+	outs := []<-chan int{
+		OrDone(ctx, never),
+		Merge(ctx, never, never),
+		Take(ctx, never, 1),
+		Bridge(ctx, make(chan (<-chan int))),
+	}
+	t1, t2 := Tee(ctx, never)
+	outs = append(outs, t1, t2)
+	batch := Batch(ctx, never, 1, time.Millisecond)
+
+	cancel()
+
+	for _, out := range outs {
+		if _, ok := <-out; ok {
+			t.Error("expected closed channel")
+		}
+	}
+	if _, ok := <-batch; ok {
+		t.Error("expected closed channel")
+	}
+}
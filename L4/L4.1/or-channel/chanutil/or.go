@@ -0,0 +1,149 @@
+// Package chanutil provides generic context-aware channel combinators.
+//
+// Every combinator stops its goroutines when ctx is done, so callers
+// that lose interest only need to cancel the context.
+package chanutil
+
+import (
+	"context"
+	"reflect"
+)
+
+// reflectThreshold is the number of channels starting from which Or
+// switches from the recursive implementation to reflect.Select.
+const reflectThreshold = 32
+
+// Or returns a channel that is closed as soon as any of channels
+// receives a value or is closed, or ctx is done.
+func Or[T any](ctx context.Context, channels ...<-chan T) <-chan struct{} {
+	if len(channels) >= reflectThreshold {
+		return orReflect(ctx, channels...)
+	}
+	return orRecursive(ctx, channels...)
+}
+
+// orRecursive is the generic version of or.Or: every goroutine waits
+// for up to three channels and delegates the rest to a nested call.
+func orRecursive[T any](ctx context.Context, channels ...<-chan T) <-chan struct{} {
+	orDone := make(chan struct{})
+
+	if len(channels) == 0 {
+		close(orDone)
+		return orDone
+	}
+
+	go func() {
+		defer close(orDone)
+
+		switch len(channels) {
+		case 1:
+			select {
+			case <-channels[0]:
+			case <-ctx.Done():
+			}
+		case 2:
+			select {
+			case <-channels[0]:
+			case <-channels[1]:
+			case <-ctx.Done():
+			}
+		case 3:
+			select {
+			case <-channels[0]:
+			case <-channels[1]:
+			case <-channels[2]:
+			case <-ctx.Done():
+			}
+		default:
+			// the nested call gets its own context that is canceled
+			// when this level is done, so it never outlives orDone
+			nestedCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			select {
+			case <-channels[0]:
+			case <-channels[1]:
+			case <-channels[2]:
+			case <-orRecursive(nestedCtx, channels[3:]...):
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return orDone
+}
+
+// orReflect waits for all channels in a single goroutine using reflect.Select.
+func orReflect[T any](ctx context.Context, channels ...<-chan T) <-chan struct{} {
+	orDone := make(chan struct{})
+
+	cases := make([]reflect.SelectCase, 0, len(channels)+1)
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	for _, ch := range channels {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+	}
+
+	go func() {
+		defer close(orDone)
+		reflect.Select(cases)
+	}()
+
+	return orDone
+}
+
+// And returns a channel that is closed when all of channels are closed
+// or ctx is done. Values received from the channels are discarded.
+func And[T any](ctx context.Context, channels ...<-chan T) <-chan struct{} {
+	andDone := make(chan struct{})
+
+	go func() {
+		defer close(andDone)
+
+		for _, ch := range channels {
+			for closed := false; !closed; {
+				select {
+				case _, ok := <-ch:
+					closed = !ok
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return andDone
+}
+
+// Fired describes the channel that fired first in FirstOf.
+type Fired[T any] struct {
+	// Index is the position of the channel in the FirstOf arguments.
+	Index int
+	// Value is the received value, the zero value if the channel was closed.
+	Value T
+	// Ok is false if the channel was closed.
+	Ok bool
+}
+
+// FirstOf blocks until one of channels receives a value or is closed
+// and reports which one it was. It returns ctx.Err() if ctx is done first.
+// Nil channels are never selected.
+func FirstOf[T any](ctx context.Context, channels ...<-chan T) (Fired[T], error) {
+	cases := make([]reflect.SelectCase, 0, len(channels)+1)
+	for _, ch := range channels {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+	}
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+
+	chosen, value, ok := reflect.Select(cases)
+	if chosen == len(channels) {
+		return Fired[T]{Index: -1}, ctx.Err()
+	}
+
+	fired := Fired[T]{Index: chosen, Ok: ok}
+	if ok {
+		// comma-ok keeps a nil value of an interface type from panicking
+		fired.Value, _ = value.Interface().(T)
+	}
+
+	return fired, nil
+}
@@ -0,0 +1,192 @@
+package chanutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OrDone forwards values from in until in is closed or ctx is done.
+// It lets a consumer range over a channel without checking ctx on every receive.
+func OrDone[T any](ctx context.Context, in <-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				if !send(ctx, out, v) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Merge fans in values from all channels into one.
+// The result is closed when all channels are closed or ctx is done.
+func Merge[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		wg.Go(func() {
+			for v := range OrDone(ctx, ch) {
+				if !send(ctx, out, v) {
+					return
+				}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// Tee duplicates every value from in into two channels.
+// A value is passed on only after both outputs have received it.
+func Tee[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
+	out1 := make(chan T)
+	out2 := make(chan T)
+
+	go func() {
+		defer close(out1)
+		defer close(out2)
+
+		for v := range OrDone(ctx, in) {
+			// a nil channel blocks forever, so each output is sent to exactly once
+			o1, o2 := out1, out2
+			for range 2 {
+				select {
+				case o1 <- v:
+					o1 = nil
+				case o2 <- v:
+					o2 = nil
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out1, out2
+}
+
+// Bridge flattens a channel of channels into one channel,
+// reading each inner channel to the end before taking the next one.
+func Bridge[T any](ctx context.Context, chanStream <-chan <-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for ch := range OrDone(ctx, chanStream) {
+			for v := range OrDone(ctx, ch) {
+				if !send(ctx, out, v) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// Take forwards at most n values from in.
+func Take[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for range n {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				if !send(ctx, out, v) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Batch groups values from in into slices of up to size elements.
+// A non-empty batch is also sent when timeout passes since its first value.
+// The last incomplete batch is sent when in is closed.
+func Batch[T any](ctx context.Context, in <-chan T, size int, timeout time.Duration) <-chan []T {
+	out := make(chan []T)
+	size = max(size, 1)
+
+	go func() {
+		defer close(out)
+
+		batch := make([]T, 0, size)
+		timer := time.NewTimer(timeout)
+		timer.Stop()
+
+		flush := func() bool {
+			timer.Stop()
+			if len(batch) == 0 {
+				return true
+			}
+			ok := send(ctx, out, batch)
+			batch = make([]T, 0, size)
+			return ok
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				if len(batch) == 0 {
+					timer.Reset(timeout)
+				}
+				batch = append(batch, v)
+				if len(batch) == size && !flush() {
+					return
+				}
+			case <-timer.C:
+				if !flush() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// send delivers v to out unless ctx is done first.
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
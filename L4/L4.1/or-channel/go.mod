@@ -1,3 +1,3 @@
 module github.com/golovanevvs/wbtech-school-go/L4/L4.1/or-channel
 
-go 1.25.4
+go 1.25.1
@@ -0,0 +1,139 @@
+// Package leakcheck finds goroutines that a test left running.
+//
+// Call Check at the start of a test. It takes a snapshot of all goroutines
+// and, when the test finishes, fails it with the stacks of every goroutine
+// that was started during the test and is still alive:
+//
+//	func TestSomething(t *testing.T) {
+//		leakcheck.Check(t)
+//		...
+//	}
+//
+// Goroutines of tests running in parallel are indistinguishable from leaks,
+// so Check is meant for tests that do not call t.Parallel.
+package leakcheck
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// defaultIgnore lists functions of goroutines that belong to the runtime
+// or the testing framework and are not leaks of the code under test.
+var defaultIgnore = []string{
+	"testing.tRunner",
+	"testing.(*T).Run",
+	"testing.runTests",
+	"testing.(*M).",
+	"os/signal.signal_recv",
+	"runtime.ensureSigM",
+}
+
+type config struct {
+	ignore  []string
+	timeout time.Duration
+}
+
+// Option configures Check and Find.
+type Option func(*config)
+
+// IgnoreFunc skips goroutines with a stack frame of a function whose
+// fully qualified name contains name, e.g. "net/http.(*persistConn)".
+func IgnoreFunc(name string) Option {
+	return func(c *config) {
+		c.ignore = append(c.ignore, name)
+	}
+}
+
+// Timeout sets how long Check waits for goroutines to exit
+// before reporting them. The default is one second.
+func Timeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		ignore:  append([]string(nil), defaultIgnore...),
+		timeout: time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Check snapshots the running goroutines and registers a cleanup that fails t
+// if goroutines started after the snapshot are still running when t finishes.
+func Check(t testing.TB, opts ...Option) {
+	t.Helper()
+
+	before := Take()
+	c := newConfig(opts)
+
+	t.Cleanup(func() {
+		leaked := find(before, c)
+		if len(leaked) == 0 {
+			return
+		}
+		t.Errorf("found %d leaked goroutine(s) after %v:\n\n%s", len(leaked), c.timeout, Report(leaked))
+	})
+}
+
+// Find returns goroutines that are not in before and are not ignored.
+// It retries until they exit or the timeout passes.
+func Find(before Snapshot, opts ...Option) []Goroutine {
+	return find(before, newConfig(opts))
+}
+
+func find(before Snapshot, c *config) []Goroutine {
+	deadline := time.Now().Add(c.timeout)
+	delay := time.Millisecond
+
+	for {
+		leaked := diff(before, Take(), c)
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+
+		time.Sleep(delay)
+		delay = min(2*delay, 100*time.Millisecond)
+	}
+}
+
+func diff(before, after Snapshot, c *config) []Goroutine {
+	self := currentID()
+
+	var leaked []Goroutine
+	for _, g := range after {
+		if _, ok := before.byID(g.ID); ok || g.ID == self || g.matches(c.ignore) {
+			continue
+		}
+		leaked = append(leaked, g)
+	}
+
+	return leaked
+}
+
+// Report formats goroutines for a test failure message.
+func Report(goroutines []Goroutine) string {
+	var sb strings.Builder
+	for i, g := range goroutines {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "goroutine %d [%s] in %s:\n%s", g.ID, g.State, g.TopFunc, g.Stack)
+	}
+	return sb.String()
+}
+
+func currentID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	g, _ := parseGoroutine(string(buf))
+	return g.ID
+}
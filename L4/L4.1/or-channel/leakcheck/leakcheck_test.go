@@ -0,0 +1,98 @@
+package leakcheck
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder is a testing.TB that collects errors instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func blockForever(ch chan struct{}) {
+	<-ch
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("leak", func(t *testing.T) {
+		stop := make(chan struct{})
+		defer close(stop)
+
+		r := &recorder{TB: t}
+		Check(r, Timeout(50*time.Millisecond))
+		go blockForever(stop)
+		r.finish()
+
+		if len(r.errors) != 1 {
+			t.Fatalf("expected one error, got %v", r.errors)
+		}
+		if !strings.Contains(r.errors[0], "leakcheck.blockForever") || !strings.Contains(r.errors[0], "chan receive") {
+			t.Errorf("report does not contain the leaked stack:\n%s", r.errors[0])
+		}
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		stop := make(chan struct{})
+		defer close(stop)
+
+		r := &recorder{TB: t}
+		Check(r, Timeout(50*time.Millisecond), IgnoreFunc("leakcheck.blockForever"))
+		go blockForever(stop)
+		r.finish()
+
+		if len(r.errors) != 0 {
+			t.Errorf("expected no errors, got %v", r.errors)
+		}
+	})
+
+	t.Run("exits in time", func(t *testing.T) {
+		r := &recorder{TB: t}
+		Check(r)
+		go time.Sleep(20 * time.Millisecond)
+		r.finish()
+
+		if len(r.errors) != 0 {
+			t.Errorf("expected no errors, got %v", r.errors)
+		}
+	})
+}
+
+func TestParseGoroutine(t *testing.T) {
+	block := "goroutine 7 [chan receive, 2 minutes]:\n" +
+		"main.worker(0xc000010000)\n" +
+		"\t/src/main.go:12 +0x25\n" +
+		"created by main.main in goroutine 1\n" +
+		"\t/src/main.go:8 +0x3c"
+
+	g, ok := parseGoroutine(block)
+	if !ok {
+		t.Fatal("block is not parsed")
+	}
+	if g.ID != 7 || g.State != "chan receive, 2 minutes" || g.TopFunc != "main.worker" {
+		t.Errorf("unexpected goroutine: %+v", g)
+	}
+	if len(g.Funcs) != 2 || g.Funcs[1] != "main.main" {
+		t.Errorf("unexpected funcs: %v", g.Funcs)
+	}
+}
@@ -3,6 +3,8 @@ package or
 import (
 	"testing"
 	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/L4/L4.1/or-channel/leakcheck"
 )
 
 func testFunc(ch chan any, sleepTime time.Duration) {
@@ -12,6 +14,7 @@ func testFunc(ch chan any, sleepTime time.Duration) {
 
 func TestOr(t *testing.T) {
 	t.Run("single channel", func(t *testing.T) {
+		leakcheck.Check(t)
 		ch := make(chan interface{})
 
 		go testFunc(ch, 50*time.Millisecond)
@@ -26,6 +29,7 @@ func TestOr(t *testing.T) {
 	})
 
 	t.Run("multiple channels", func(t *testing.T) {
+		leakcheck.Check(t)
 		ch1 := make(chan interface{})
 		ch2 := make(chan interface{})
 		ch3 := make(chan interface{})
@@ -44,6 +48,7 @@ func TestOr(t *testing.T) {
 	})
 
 	t.Run("no channels", func(t *testing.T) {
+		leakcheck.Check(t)
 		start := time.Now()
 		<-Or()
 		duration := time.Since(start)
@@ -52,4 +57,19 @@ func TestOr(t *testing.T) {
 			t.Errorf("expected immediate return, got %v", duration)
 		}
 	})
+
+	t.Run("no leaks with never closed channels", func(t *testing.T) {
+		leakcheck.Check(t, leakcheck.Timeout(100*time.Millisecond))
+
+		never := make(chan any)
+		channels := make([]<-chan any, 20)
+		for i := range channels {
+			channels[i] = never
+		}
+		fired := make(chan any)
+		channels[0] = fired
+		close(fired)
+
+		<-Or(channels...)
+	})
 }
@@ -1,3 +1,5 @@
 module github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep
 
 go 1.25.4
+
+require github.com/klauspost/compress v1.18.0
@@ -1,22 +1,30 @@
 package app
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/cluster"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/input"
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/service"
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/transport"
 )
 
+// Version is the mygrep version reported to cluster members
+var Version = "dev"
+
 // App represents the main application
 type App struct {
 	config      *model.Config
 	grepService *service.GrepService
 	server      *transport.Server
 	client      *transport.Client
+	membership  *cluster.Membership
 }
 
 // New creates a new application instance
@@ -26,6 +34,10 @@ func New() *App {
 
 // Run starts the application
 func (a *App) Run() error {
+	if len(os.Args) > 1 && os.Args[1] == "cluster" {
+		return a.runClusterCommand(os.Args[2:])
+	}
+
 	var err error
 	a.config, err = a.parseFlags()
 	if err != nil {
@@ -52,9 +64,10 @@ func (a *App) Run() error {
 
 // parseFlags parses command line arguments
 func (a *App) parseFlags() (*model.Config, error) {
-	var pattern string
-	flag.StringVar(&pattern, "pattern", "", "Pattern to search for")
-	flag.StringVar(&pattern, "e", "", "Pattern to search for (alias for --pattern)")
+	var patterns, patternFiles stringList
+	flag.Var(&patterns, "pattern", "Pattern to search for, may be repeated")
+	flag.Var(&patterns, "e", "Pattern to search for (alias for --pattern)")
+	flag.Var(&patternFiles, "f", "Read patterns from file, one per line")
 
 	// Standard grep flags
 	color := flag.Bool("color", false, "Highlight matches with color")
@@ -64,47 +77,72 @@ func (a *App) parseFlags() (*model.Config, error) {
 	lineNumber := flag.Bool("n", false, "Show line numbers")
 	count := flag.Bool("c", false, "Show only match count")
 	onlyMatching := flag.Bool("o", false, "Show only matching parts")
+	fixed := flag.Bool("F", false, "Interpret patterns as fixed strings")
+	wordRegexp := flag.Bool("w", false, "Match whole words only")
+	after := flag.Int("A", 0, "Print NUM lines of context after matching lines")
+	before := flag.Int("B", 0, "Print NUM lines of context before matching lines")
+	contextLines := flag.Int("C", 0, "Print NUM lines of context around matching lines")
+
+	// Input flags
+	var include, exclude, excludeDir stringList
+	recursive := flag.Bool("r", false, "Search files in directories recursively")
+	flag.Var(&include, "include", "Search only files whose base name matches GLOB, may be repeated")
+	flag.Var(&exclude, "exclude", "Skip files whose base name matches GLOB, may be repeated")
+	flag.Var(&excludeDir, "exclude-dir", "Skip directories whose base name matches GLOB, may be repeated")
 
 	// Distributed flags
 	port := flag.String("port", "", "Port for distributed mode")
 	peers := flag.String("peers", "", "Comma-separated list of peers")
 	serverID := flag.String("server-id", "", "Server identifier (default: hostname:port)")
+	timeout := flag.Duration("timeout", 30*time.Second, "Timeout for a job sent to a peer")
+	quorumTimeout := flag.Duration("quorum-timeout", 60*time.Second, "Timeout for reaching quorum on a chunk")
+	replicas := flag.Int("replicas", 1, "Number of servers each chunk is sent to (R)")
+	writeQuorum := flag.Int("quorum", 0, "Number of replicas that must return identical results (W, default: R/2+1)")
+	chunkSize := flag.Int64("chunk-size", 0, "Chunk size in bytes (default: file size divided by number of servers)")
+	sharedFS := flag.Bool("shared-fs", false, "Send file references instead of chunk data (peers share the filesystem)")
+	advertise := flag.String("advertise", "", "Address other members use to reach this server (default: hostname:port)")
+	heartbeat := flag.Duration("heartbeat", cluster.DefaultHeartbeat, "Interval between heartbeats to cluster members")
 
 	flag.Parse()
 
-	if pattern == "" {
-		args := flag.Args()
-		if len(args) == 0 {
-			return nil, fmt.Errorf("no pattern specified for search")
+	for _, name := range patternFiles {
+		filePatterns, err := readPatterns(name)
+		if err != nil {
+			return nil, err
 		}
-		pattern = args[0]
-		args = args[1:]
-
-		config := &model.Config{
-			Pattern: pattern,
-			Files:   args,
-			Flags: model.GrepFlags{
-				Color:        *color,
-				InvertMatch:  *invertMatch,
-				IgnoreCase:   *ignoreCase,
-				WholeLine:    *wholeLine,
-				LineNumber:   *lineNumber,
-				Count:        *count,
-				OnlyMatching: *onlyMatching,
-			},
-			Input:         os.Stdin,
-			Output:        os.Stdout,
-			IsDistributed: *port != "" || *peers != "",
-			Port:          *port,
-			Peers:         parsePeers(*peers),
-			ServerID:      *serverID,
+		patterns = append(patterns, filePatterns...)
+	}
+
+	args := flag.Args()
+	if len(patterns) == 0 && len(patternFiles) == 0 {
+		switch {
+		case len(args) > 0:
+			patterns = append(patterns, args[0])
+			args = args[1:]
+		case *port == "":
+			// a server started only with -port just waits for jobs from peers
+			return nil, fmt.Errorf("no pattern specified for search")
 		}
-		return config, nil
+	}
+
+	// -A and -B take precedence over -C
+	if *after == 0 {
+		*after = *contextLines
+	}
+	if *before == 0 {
+		*before = *contextLines
+	}
+	if *after < 0 || *before < 0 {
+		return nil, fmt.Errorf("context length must not be negative")
 	}
 
 	config := &model.Config{
-		Pattern: pattern,
-		Files:   flag.Args(),
+		Patterns:   patterns,
+		Files:      args,
+		Recursive:  *recursive,
+		Include:    include,
+		Exclude:    exclude,
+		ExcludeDir: excludeDir,
 		Flags: model.GrepFlags{
 			Color:        *color,
 			InvertMatch:  *invertMatch,
@@ -113,6 +151,10 @@ func (a *App) parseFlags() (*model.Config, error) {
 			LineNumber:   *lineNumber,
 			Count:        *count,
 			OnlyMatching: *onlyMatching,
+			Fixed:        *fixed,
+			WordRegexp:   *wordRegexp,
+			After:        *after,
+			Before:       *before,
 		},
 		Input:         os.Stdin,
 		Output:        os.Stdout,
@@ -120,10 +162,59 @@ func (a *App) parseFlags() (*model.Config, error) {
 		Port:          *port,
 		Peers:         parsePeers(*peers),
 		ServerID:      *serverID,
+		Timeout:       *timeout,
+		QuorumTimeout: *quorumTimeout,
+		Replicas:      *replicas,
+		WriteQuorum:   *writeQuorum,
+		ChunkSize:     *chunkSize,
+		SharedFS:      *sharedFS,
+		Advertise:     *advertise,
+		Heartbeat:     *heartbeat,
+		Version:       Version,
 	}
+	filter := input.Filter{Include: include, Exclude: exclude, ExcludeDir: excludeDir}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Replicas < 1 {
+		return nil, fmt.Errorf("replicas must be positive")
+	}
+	if config.Heartbeat <= 0 {
+		return nil, fmt.Errorf("heartbeat interval must be positive")
+	}
+	if config.WriteQuorum > config.Replicas {
+		return nil, fmt.Errorf("quorum %d is greater than replicas %d", config.WriteQuorum, config.Replicas)
+	}
+
 	return config, nil
 }
 
+// stringList is a flag that may be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// readPatterns reads patterns from a file, one per line
+func readPatterns(name string) ([]string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read patterns: %v", err)
+	}
+
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
 // parsePeers parses comma-separated peer list
 func parsePeers(peersStr string) []string {
 	if peersStr == "" {
@@ -134,29 +225,45 @@ func parsePeers(peersStr string) []string {
 
 // startDistributedMode starts distributed mode operation
 func (a *App) startDistributedMode() error {
+	hostname, _ := os.Hostname()
+
 	// Generate ServerID if not specified
 	if a.config.ServerID == "" {
-		hostname, _ := os.Hostname()
 		a.config.ServerID = fmt.Sprintf("%s:%s", hostname, a.config.Port)
 	}
+	if a.config.Advertise == "" && a.config.Port != "" {
+		a.config.Advertise = fmt.Sprintf("%s:%s", hostname, a.config.Port)
+	}
 
 	fmt.Printf("Starting distributed mode. ServerID: %s\n", a.config.ServerID)
 
-	// Start TCP server with job handler adapter
 	jobHandler := transport.NewJobHandlerAdapter(a.grepService)
+	a.client = transport.NewClient(a.config, jobHandler)
+	defer a.client.Close()
+
+	// Cluster members are discovered from the configured peers (seeds)
+	a.membership = cluster.New(a.config, a.client, a.grepService.RunningJobs)
+
+	// Start TCP server with job handler adapter
 	a.server = transport.NewServer(a.config, jobHandler)
+	a.server.SetHeartbeatHandler(a.membership)
 	if err := a.server.Start(); err != nil {
 		return fmt.Errorf("TCP server startup error: %v", err)
 	}
 	defer a.server.Stop()
 
-	// Connect to peers
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	if len(a.config.Peers) > 0 {
-		a.client = transport.NewClient(a.config)
-		if err := a.client.ConnectToPeers(); err != nil {
-			fmt.Printf("Warning: failed to connect to peers: %v\n", err)
-		}
+		a.membership.Join(ctx)
+		fmt.Printf("Alive peers: %s\n", strings.Join(a.membership.Alive(), ", "))
 	}
+	go a.membership.Run(ctx)
+
+	// Jobs are sent only to members the failure detector considers alive
+	a.client.SetPeerSource(a.membership.Alive)
+	a.grepService.SetDispatcher(a.client)
 
 	// In distributed mode, process files or wait for commands
 	if len(a.config.Files) > 0 {
@@ -176,24 +283,7 @@ func (a *App) startDistributedMode() error {
 func (a *App) runDistributedGrep() error {
 	fmt.Println("Running distributed grep...")
 
-	// If we have peers, use distributed processing
-	if len(a.config.Peers) > 0 && a.client != nil {
-		// Use client's distributed processing
-		return a.runDistributedProcessingWithClient()
-	}
-
-	// Fallback to local processing
-	return a.grepService.ExecuteDistributedGrep()
-}
-
-// runDistributedProcessingWithClient runs distributed processing using client
-func (a *App) runDistributedProcessingWithClient() error {
-	// Get jobs from grep service
-	// For now, this is a simplified version
-	// In full implementation, this would coordinate with the transport layer
-
-	fmt.Println("Distributed processing with client not fully implemented yet")
-	fmt.Println("Falling back to local processing...")
-
+	// Jobs are sent to peers through the client set as the service dispatcher;
+	// without peers the service processes files locally
 	return a.grepService.ExecuteDistributedGrep()
 }
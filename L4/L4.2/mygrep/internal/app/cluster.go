@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/transport"
+)
+
+const clusterUsage = "usage: mygrep cluster status [-timeout 5s] [address]"
+
+// runClusterCommand runs `mygrep cluster <command>`
+func (a *App) runClusterCommand(args []string) error {
+	if len(args) == 0 || args[0] != "status" {
+		fmt.Fprintln(os.Stderr, clusterUsage)
+		return fmt.Errorf("unknown cluster command")
+	}
+
+	flags := flag.NewFlagSet("cluster status", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 5*time.Second, "Timeout for the status request")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	addr := "localhost:8080"
+	if flags.NArg() > 0 {
+		addr = flags.Arg(0)
+	}
+
+	return a.printClusterStatus(addr, *timeout)
+}
+
+// printClusterStatus asks a cluster member for its view of the cluster
+// and prints health, load and version of every member
+func (a *App) printClusterStatus(addr string, timeout time.Duration) error {
+	client := transport.NewClient(&model.Config{ServerID: "cluster-status", Version: Version}, nil)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// a heartbeat without our address makes the member answer
+	// without adding us to the cluster
+	hb, err := client.SendHeartbeat(ctx, addr, model.Heartbeat{SentAt: time.Now()})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot get cluster status from %s: %v\n", addr, err)
+		return err
+	}
+
+	self := hb.From
+	if self.Address == "" {
+		self.Address = addr
+	}
+	members := append([]model.ServerInfo{self}, hb.Members...)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tADDRESS\tSTATUS\tLOAD\tVERSION\tPROTOCOL\tPHI\tLAST SEEN")
+	for i, m := range members {
+		id := m.ID
+		if id == "" {
+			id = "-"
+		}
+		if i == 0 {
+			id += " (*)"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			id, m.Address, m.Status, m.JobsCount, orDash(m.Version), protocolVersion(m.Protocol), phi(m.Phi), lastSeen(m.UpdatedAt, hb.SentAt))
+	}
+	w.Flush()
+
+	fmt.Printf("\n(*) %s answered the request\n", self.Address)
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func protocolVersion(v int) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
+
+func phi(v float64) string {
+	if math.IsInf(v, 1) {
+		return "inf"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// lastSeen returns how long ago the member was seen by the answering server
+func lastSeen(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return now.Sub(t).Round(time.Millisecond).String() + " ago"
+}
@@ -0,0 +1,311 @@
+// Package cluster maintains the list of mygrep cluster members.
+//
+// Every member sends a heartbeat to all members it knows about each
+// heartbeat interval. A heartbeat carries the member list of the sender,
+// so a new node needs only one seed to learn the whole cluster.
+// Member health is judged by a phi accrual failure detector.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/protocol"
+)
+
+const (
+	// DefaultHeartbeat is used when the configuration has no heartbeat interval
+	DefaultHeartbeat = time.Second
+
+	// phi levels after which a member is considered suspect or dead
+	suspectPhi = 8
+	deadPhi    = 16
+
+	// a dead member that isn't a seed is forgotten after removeAfter intervals
+	removeAfter = 60
+)
+
+// Sender sends a heartbeat to a member and returns its answer
+type Sender interface {
+	SendHeartbeat(ctx context.Context, addr string, hb model.Heartbeat) (*model.Heartbeat, error)
+}
+
+// member is a known cluster member
+type member struct {
+	info     model.ServerInfo
+	detector *phiDetector
+	seed     bool
+	added    time.Time
+}
+
+// Membership tracks cluster members and their health
+type Membership struct {
+	self     model.ServerInfo
+	interval time.Duration
+	sender   Sender
+	load     func() int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	members map[string]*member // address -> member
+}
+
+// New creates a membership of the current server.
+// Configured peers are used as seeds; load returns the number of running jobs
+func New(config *model.Config, sender Sender, load func() int) *Membership {
+	interval := config.Heartbeat
+	if interval <= 0 {
+		interval = DefaultHeartbeat
+	}
+
+	m := &Membership{
+		self: model.ServerInfo{
+			ID:       config.ServerID,
+			Address:  config.Advertise,
+			Status:   model.StatusAlive,
+			Version:  config.Version,
+			Protocol: int(protocol.Version),
+		},
+		interval: interval,
+		sender:   sender,
+		load:     load,
+		now:      time.Now,
+		members:  make(map[string]*member),
+	}
+
+	for _, peer := range config.Peers {
+		if peer != m.self.Address {
+			m.members[peer] = &member{
+				info:     model.ServerInfo{Address: peer},
+				detector: newPhiDetector(interval),
+				seed:     true,
+				added:    m.now(),
+			}
+		}
+	}
+
+	return m
+}
+
+// Join contacts the seeds and then the members learned from them
+func (m *Membership) Join(ctx context.Context) {
+	m.probe(ctx)
+	m.probe(ctx)
+}
+
+// Run sends heartbeats every interval until ctx is canceled
+func (m *Membership) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.probe(ctx)
+		}
+	}
+}
+
+// probe sends a heartbeat to every known member and records the answers
+func (m *Membership) probe(ctx context.Context) {
+	m.mu.Lock()
+	m.forgetDead()
+	addrs := make([]string, 0, len(m.members))
+	for addr := range m.members {
+		addrs = append(addrs, addr)
+	}
+	m.mu.Unlock()
+
+	hb := m.heartbeat()
+
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, m.interval)
+			defer cancel()
+
+			reply, err := m.sender.SendHeartbeat(ctx, addr, hb)
+			if err != nil {
+				return
+			}
+
+			if reply.From.Address == "" {
+				reply.From.Address = addr
+			} else if reply.From.Address != addr {
+				// the member advertises another address than the one we know it by
+				m.rename(addr, reply.From.Address)
+			}
+			m.record(*reply)
+		})
+	}
+	wg.Wait()
+}
+
+// HandleHeartbeat records a heartbeat received from another member
+// and returns the heartbeat of the current server.
+// A heartbeat without the sender address comes from an observer
+// (e.g. `mygrep cluster status`) and doesn't add a member
+func (m *Membership) HandleHeartbeat(hb model.Heartbeat) model.Heartbeat {
+	if hb.From.Address != "" {
+		m.record(hb)
+	}
+	return m.heartbeat()
+}
+
+// record marks the sender alive and adds members it knows about
+func (m *Membership) record(hb model.Heartbeat) {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	from := hb.From
+	if from.Address != m.self.Address {
+		mb := m.member(from.Address)
+		if mb.info.UpdatedAt.IsZero() {
+			fmt.Printf("Member %s (%s) joined\n", from.ID, from.Address)
+		}
+		mb.detector.heartbeat(now)
+		mb.info.ID = from.ID
+		mb.info.JobsCount = from.JobsCount
+		mb.info.Version = from.Version
+		mb.info.Protocol = from.Protocol
+		mb.info.UpdatedAt = now
+	}
+
+	for _, info := range hb.Members {
+		if info.Address == "" || info.Address == m.self.Address || info.Status == model.StatusDead {
+			continue
+		}
+		m.member(info.Address)
+	}
+}
+
+// member returns the member with the address, adding it if it's unknown
+func (m *Membership) member(addr string) *member {
+	mb, ok := m.members[addr]
+	if !ok {
+		mb = &member{
+			info:     model.ServerInfo{Address: addr},
+			detector: newPhiDetector(m.interval),
+			added:    m.now(),
+		}
+		m.members[addr] = mb
+	}
+	return mb
+}
+
+// rename moves a member known by alias to its advertised address,
+// so the same server isn't listed twice
+func (m *Membership) rename(alias, addr string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mb, ok := m.members[alias]
+	if !ok {
+		return
+	}
+	delete(m.members, alias)
+
+	if addr == m.self.Address {
+		return
+	}
+	if current, ok := m.members[addr]; ok {
+		current.seed = current.seed || mb.seed
+		return
+	}
+	mb.info.Address = addr
+	m.members[addr] = mb
+}
+
+// forgetDead removes members that have been dead for a long time
+func (m *Membership) forgetDead() {
+	now := m.now()
+	for addr, mb := range m.members {
+		if mb.seed {
+			continue
+		}
+		lastSeen := mb.info.UpdatedAt
+		if lastSeen.IsZero() {
+			lastSeen = mb.added
+		}
+		if now.Sub(lastSeen) > removeAfter*m.interval {
+			fmt.Printf("Member %s (%s) removed\n", mb.info.ID, addr)
+			delete(m.members, addr)
+		}
+	}
+}
+
+// heartbeat returns the heartbeat of the current server
+func (m *Membership) heartbeat() model.Heartbeat {
+	self := m.self
+	self.JobsCount = m.load()
+	self.UpdatedAt = m.now()
+
+	// dead members are sent too, so that observers see them,
+	// but other members don't add them
+	var members []model.ServerInfo
+	for _, info := range m.Members() {
+		if info.Address != self.Address {
+			members = append(members, info)
+		}
+	}
+
+	return model.Heartbeat{From: self, Members: members, SentAt: self.UpdatedAt}
+}
+
+// Members returns the current server and all known members sorted by address
+func (m *Membership) Members() []model.ServerInfo {
+	now := m.now()
+
+	m.mu.Lock()
+	members := make([]model.ServerInfo, 0, len(m.members)+1)
+	for _, mb := range m.members {
+		info := mb.info
+		info.Phi = mb.detector.phi(now)
+		info.Status = status(info.Phi)
+		members = append(members, info)
+	}
+	m.mu.Unlock()
+
+	self := m.self
+	self.JobsCount = m.load()
+	self.UpdatedAt = now
+	members = append(members, self)
+
+	slices.SortFunc(members, func(a, b model.ServerInfo) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return members
+}
+
+// Alive returns addresses of alive members, the current server excluded
+func (m *Membership) Alive() []string {
+	var addrs []string
+	for _, info := range m.Members() {
+		if info.Address != m.self.Address && info.Status == model.StatusAlive {
+			addrs = append(addrs, info.Address)
+		}
+	}
+	return addrs
+}
+
+// status converts phi into a member status
+func status(phi float64) string {
+	switch {
+	case phi >= deadPhi || math.IsInf(phi, 1):
+		return model.StatusDead
+	case phi >= suspectPhi:
+		return model.StatusSuspect
+	default:
+		return model.StatusAlive
+	}
+}
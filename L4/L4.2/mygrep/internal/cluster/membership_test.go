@@ -0,0 +1,156 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// network delivers heartbeats between memberships in memory
+type network struct {
+	mu    sync.Mutex
+	nodes map[string]*Membership
+	down  map[string]bool
+}
+
+func (n *network) SendHeartbeat(ctx context.Context, addr string, hb model.Heartbeat) (*model.Heartbeat, error) {
+	n.mu.Lock()
+	node, ok := n.nodes[addr]
+	down := n.down[addr]
+	n.mu.Unlock()
+
+	if !ok || down {
+		return nil, errors.New("connection refused")
+	}
+	reply := node.HandleHeartbeat(hb)
+	return &reply, nil
+}
+
+// clock is a manual time source shared by all nodes
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestMembershipGossipAndFailure(t *testing.T) {
+	net := &network{nodes: make(map[string]*Membership), down: make(map[string]bool)}
+	clk := &clock{now: time.Unix(1700000000, 0)}
+
+	newNode := func(addr string, seeds ...string) *Membership {
+		m := New(&model.Config{ServerID: addr, Advertise: addr, Peers: seeds, Heartbeat: time.Second}, net, func() int { return 0 })
+		m.now = clk.Now
+		net.mu.Lock()
+		net.nodes[addr] = m
+		net.mu.Unlock()
+		return m
+	}
+
+	a := newNode("a")
+	b := newNode("b", "a")
+	c := newNode("c", "b")
+
+	// every node joins through a single seed
+	b.Join(context.Background())
+	c.Join(context.Background())
+	clk.Advance(time.Second)
+	a.probe(context.Background())
+
+	for _, m := range []*Membership{a, b, c} {
+		alive := m.Alive()
+		want := slices.DeleteFunc([]string{"a", "b", "c"}, func(s string) bool { return s == m.self.Address })
+		if !slices.Equal(alive, want) {
+			t.Errorf("%s: alive %v, want %v", m.self.Address, alive, want)
+		}
+	}
+
+	// c stops answering: it becomes suspect and then dead for a
+	net.mu.Lock()
+	net.down["c"] = true
+	net.mu.Unlock()
+
+	statusOf := func(m *Membership, addr string) string {
+		for _, info := range m.Members() {
+			if info.Address == addr {
+				return info.Status
+			}
+		}
+		return ""
+	}
+
+	var seen []string
+	for range 15 {
+		clk.Advance(time.Second)
+		a.probe(context.Background())
+		b.probe(context.Background())
+		if s := statusOf(a, "c"); len(seen) == 0 || seen[len(seen)-1] != s {
+			seen = append(seen, s)
+		}
+	}
+
+	if !slices.Equal(seen, []string{model.StatusAlive, model.StatusSuspect, model.StatusDead}) {
+		t.Errorf("status of c changed as %v", seen)
+	}
+	if alive := a.Alive(); !slices.Equal(alive, []string{"b"}) {
+		t.Errorf("alive after failure %v, want [b]", alive)
+	}
+}
+
+func TestHandleHeartbeatObserver(t *testing.T) {
+	m := New(&model.Config{ServerID: "a", Advertise: "a"}, nil, func() int { return 3 })
+
+	reply := m.HandleHeartbeat(model.Heartbeat{})
+
+	if reply.From.ID != "a" || reply.From.JobsCount != 3 {
+		t.Errorf("unexpected reply %+v", reply.From)
+	}
+	if len(m.Members()) != 1 {
+		t.Errorf("observer was added as a member")
+	}
+}
+
+func TestPhiDetector(t *testing.T) {
+	start := time.Unix(0, 0)
+	d := newPhiDetector(time.Second)
+
+	if phi := d.phi(start); phi < deadPhi {
+		t.Errorf("phi before first heartbeat = %v, want dead", phi)
+	}
+
+	for i := range 10 {
+		d.heartbeat(start.Add(time.Duration(i) * time.Second))
+	}
+	last := start.Add(9 * time.Second)
+
+	tests := []struct {
+		after  time.Duration
+		status string
+	}{
+		{500 * time.Millisecond, model.StatusAlive},
+		{time.Second, model.StatusAlive},
+		{4 * time.Second, model.StatusSuspect},
+		{10 * time.Second, model.StatusDead},
+	}
+
+	for _, tt := range tests {
+		if s := status(d.phi(last.Add(tt.after))); s != tt.status {
+			t.Errorf("%v after last heartbeat: %s (phi %.2f), want %s", tt.after, s, d.phi(last.Add(tt.after)), tt.status)
+		}
+	}
+}
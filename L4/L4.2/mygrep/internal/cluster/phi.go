@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"math"
+	"time"
+)
+
+// phiWindow is the number of heartbeat intervals the detector remembers
+const phiWindow = 100
+
+// phiDetector is a phi accrual failure detector (Hayashibara et al.).
+// Instead of a yes/no answer it returns phi - the suspicion level that
+// grows the longer a heartbeat is overdue compared to the intervals
+// seen so far. phi = 1 means ~10% chance of a mistake, phi = 2 ~1%, etc.
+type phiDetector struct {
+	intervals []float64 // milliseconds, ring buffer
+	next      int
+	sum       float64
+	sumSq     float64
+	last      time.Time
+	// minStdDev keeps phi from jumping on perfectly regular heartbeats
+	minStdDev float64
+}
+
+// newPhiDetector creates a detector that expects heartbeats every interval
+func newPhiDetector(interval time.Duration) *phiDetector {
+	ms := float64(interval.Milliseconds())
+	d := &phiDetector{
+		intervals: make([]float64, 0, phiWindow),
+		minStdDev: ms / 2,
+	}
+
+	// bootstrap with the expected interval, so the first heartbeats
+	// don't make the member look suspicious
+	d.add(ms - ms/4)
+	d.add(ms + ms/4)
+
+	return d
+}
+
+// heartbeat records a heartbeat received at now
+func (d *phiDetector) heartbeat(now time.Time) {
+	if !d.last.IsZero() {
+		d.add(float64(now.Sub(d.last).Milliseconds()))
+	}
+	d.last = now
+}
+
+// add puts an interval into the window, evicting the oldest one
+func (d *phiDetector) add(ms float64) {
+	if len(d.intervals) < phiWindow {
+		d.intervals = append(d.intervals, ms)
+	} else {
+		old := d.intervals[d.next]
+		d.sum -= old
+		d.sumSq -= old * old
+		d.intervals[d.next] = ms
+		d.next = (d.next + 1) % phiWindow
+	}
+	d.sum += ms
+	d.sumSq += ms * ms
+}
+
+// phi returns the suspicion level at now. Before the first heartbeat
+// it is +Inf: the member was never seen alive
+func (d *phiDetector) phi(now time.Time) float64 {
+	if d.last.IsZero() {
+		return math.Inf(1)
+	}
+
+	n := float64(len(d.intervals))
+	mean := d.sum / n
+	stdDev := math.Sqrt(math.Max(d.sumSq/n-mean*mean, 0))
+	stdDev = math.Max(stdDev, d.minStdDev)
+
+	elapsed := float64(now.Sub(d.last).Milliseconds())
+
+	// logistic approximation of the normal CDF, as in Akka and Cassandra
+	y := (elapsed - mean) / stdDev
+	e := math.Exp(-y * (1.5976 + 0.070566*y*y))
+	if elapsed > mean {
+		return -math.Log10(e / (1 + e))
+	}
+	return -math.Log10(1 - 1/(1+e))
+}
@@ -0,0 +1,123 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compression is a compression format of an input
+type compression int
+
+const (
+	none compression = iota
+	gzipFormat
+	bzip2Format
+	zstdFormat
+)
+
+// magicSize is the number of leading bytes needed to detect a format
+const magicSize = 4
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+func (c compression) String() string {
+	switch c {
+	case gzipFormat:
+		return "gzip"
+	case bzip2Format:
+		return "bzip2"
+	case zstdFormat:
+		return "zstd"
+	default:
+		return "none"
+	}
+}
+
+// detectByName detects the format by the file extension.
+// Query strings of URLs are ignored
+func detectByName(name string) compression {
+	name, _, _ = strings.Cut(name, "?")
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".gz":
+		return gzipFormat
+	case ".bz2":
+		return bzip2Format
+	case ".zst", ".zstd":
+		return zstdFormat
+	default:
+		return none
+	}
+}
+
+// detect detects the format by the extension and, for names without
+// a known extension, by the leading bytes. The bzip2 magic "BZh" may
+// start a text line, so bzip2 is recognized by the extension only
+func detect(name string, header []byte) compression {
+	if c := detectByName(name); c != none {
+		return c
+	}
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return gzipFormat
+	case bytes.HasPrefix(header, zstdMagic):
+		return zstdFormat
+	default:
+		return none
+	}
+}
+
+// Decompress wraps rc with a decompressor of its format.
+// Closing the result closes rc
+func Decompress(rc io.ReadCloser, name string) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	header, err := br.Peek(magicSize)
+	if err != nil && err != io.EOF {
+		rc.Close()
+		return nil, err
+	}
+
+	c := detect(name, header)
+	var r io.Reader
+	switch c {
+	case none:
+		return readCloser{br, rc}, nil
+	case gzipFormat:
+		r, err = gzip.NewReader(br)
+	case bzip2Format:
+		r = bzip2.NewReader(br)
+	case zstdFormat:
+		var d *zstd.Decoder
+		d, err = zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err == nil {
+			return readCloser{d.IOReadCloser(), closerFunc(func() error {
+				d.Close()
+				return rc.Close()
+			})}, nil
+		}
+	}
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("cannot read %s data: %v", c, err)
+	}
+
+	return readCloser{r, rc}, nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,128 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// httpClient fetches URL inputs; requests are limited by their contexts
+var httpClient = &http.Client{}
+
+// openURL requests length bytes of the URL content starting at offset,
+// the whole content if length is negative
+func openURL(ctx context.Context, url string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ranged := offset > 0 || length > 0
+	if ranged {
+		end := ""
+		if length > 0 {
+			end = fmt.Sprint(offset + length - 1)
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%s", offset, end))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// the range starts at the end of the content
+		resp.Body.Close()
+		return io.NopCloser(strings.NewReader("")), nil
+	case resp.StatusCode == http.StatusOK && ranged:
+		// the server ignored the range, skip to it
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil && err != io.EOF {
+			resp.Body.Close()
+			return nil, err
+		}
+	case resp.StatusCode == http.StatusOK:
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	if length < 0 {
+		return resp.Body, nil
+	}
+	return readCloser{io.LimitReader(resp.Body, length), resp.Body}, nil
+}
+
+// httpFile reads a URL at arbitrary offsets with range requests
+type httpFile struct {
+	// ctx limits all requests, io.ReaderAt has no context argument
+	ctx  context.Context
+	url  string
+	size int64
+}
+
+// openHTTPFile checks with a HEAD request that the server
+// supports range requests and knows the content length
+func openHTTPFile(ctx context.Context, url string) (*httpFile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HEAD %s: %s", url, resp.Status)
+	}
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.ContentLength < 0 {
+		return nil, ErrNotSeekable
+	}
+	// the server compresses the content on the fly, offsets would not match
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		return nil, ErrNotSeekable
+	}
+
+	return &httpFile{ctx: ctx, url: url, size: resp.ContentLength}, nil
+}
+
+func (f *httpFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+
+	length := min(int64(len(p)), f.size-off)
+	body, err := openURL(f.ctx, f.url, off, length)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p[:length])
+	if err == nil && int64(len(p)) > length {
+		err = io.EOF
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = fmt.Errorf("GET %s: content is shorter than %d bytes", f.url, f.size)
+	}
+	return n, err
+}
+
+func (f *httpFile) Size() int64 {
+	return f.size
+}
+
+func (f *httpFile) Close() error {
+	return nil
+}
@@ -0,0 +1,201 @@
+// Package input opens mygrep inputs: local files, directories and
+// HTTP(S) URLs, compressed with gzip, bzip2 or zstd or not compressed.
+//
+// Compressed inputs can only be read from the beginning, so they are
+// decompressed by the server that owns them. Plain files and URLs of
+// servers supporting range requests can be read at any offset, which
+// lets peers read their chunks themselves.
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotSeekable is returned by OpenAt for inputs that can only be read sequentially
+var ErrNotSeekable = errors.New("input can only be read sequentially")
+
+// File is an input opened for reading at arbitrary offsets
+type File interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+}
+
+// Filter selects files found in directories by their base names
+type Filter struct {
+	// Include globs, a file must match one of them if any are given
+	Include []string
+	// Exclude globs, a file must not match any of them
+	Exclude []string
+	// ExcludeDir globs of directories that are not descended into
+	ExcludeDir []string
+}
+
+// Validate checks that all globs are well-formed
+func (f Filter) Validate() error {
+	for _, globs := range [][]string{f.Include, f.Exclude, f.ExcludeDir} {
+		for _, glob := range globs {
+			if _, err := filepath.Match(glob, ""); err != nil {
+				return fmt.Errorf("invalid glob %q: %v", glob, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f Filter) file(name string) bool {
+	if len(f.Include) > 0 && !matchAny(f.Include, name) {
+		return false
+	}
+	return !matchAny(f.Exclude, name)
+}
+
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsURL reports whether the input name is an HTTP(S) URL
+func IsURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// Expand replaces directories with the files inside them in lexical order.
+// Directories are allowed only with recursive set. The filter applies to
+// files found in directories; files and URLs given explicitly are kept
+func Expand(names []string, recursive bool, filter Filter) ([]string, error) {
+	var files []string
+
+	for _, name := range names {
+		if IsURL(name) {
+			files = append(files, name)
+			continue
+		}
+
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, name)
+			continue
+		}
+		if !recursive {
+			return nil, fmt.Errorf("%s is a directory (use -r to search in it)", name)
+		}
+
+		err = filepath.WalkDir(name, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != name && matchAny(filter.ExcludeDir, d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// symlinks, devices and sockets are skipped like grep -r does
+			if d.Type().IsRegular() && filter.file(d.Name()) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// Open opens the input for sequential reading of its decompressed content
+func Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	var err error
+	if IsURL(name) {
+		rc, err = openURL(ctx, name, 0, -1)
+	} else {
+		rc, err = os.Open(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return Decompress(rc, name)
+}
+
+// OpenAt opens a plain input for reading at arbitrary offsets.
+// It returns ErrNotSeekable for compressed inputs and for URLs
+// whose servers do not support range requests
+func OpenAt(ctx context.Context, name string) (File, error) {
+	if IsURL(name) {
+		if detectByName(name) != none {
+			return nil, ErrNotSeekable
+		}
+		return openHTTPFile(ctx, name)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, magicSize)
+	n, err := file.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		file.Close()
+		return nil, err
+	}
+	if detect(name, header[:n]) != none {
+		file.Close()
+		return nil, ErrNotSeekable
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &localFile{File: file, size: info.Size()}, nil
+}
+
+// OpenRange opens length bytes of a plain input starting at offset.
+// Peers use it to read chunks of shared files and URLs themselves
+func OpenRange(ctx context.Context, name string, offset, length int64) (io.ReadCloser, error) {
+	if IsURL(name) {
+		return openURL(ctx, name, offset, length)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{io.NewSectionReader(file, offset, length), file}, nil
+}
+
+// localFile is a local file with its size taken once at opening
+type localFile struct {
+	*os.File
+	size int64
+}
+
+func (f *localFile) Size() int64 {
+	return f.size
+}
+
+// readCloser reads from one reader and closes another closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
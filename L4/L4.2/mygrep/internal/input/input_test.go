@@ -0,0 +1,222 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const content = "alpha\nbeta\ngamma\n"
+
+// bzip2Content is content compressed with bzip2 -9, the standard
+// library has no bzip2 writer
+var bzip2Content = []byte("\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\x45\xdd\xc7\x7a\x00\x00\x03\x41\x80\x00\x10\x32\xc6\x44\x00\x20\x00\x22\x1a\x0c\x9a\x10\x03\x01\x28\xbc\x40\x86\x90\x6f\xc5\xdc\x91\x4e\x14\x24\x11\x77\x71\xde\x80")
+
+func gzipped(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstdCompressed(t *testing.T, data string) []byte {
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	return w.EncodeAll([]byte(data), nil)
+}
+
+func readAll(t *testing.T, rc io.ReadCloser, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestOpenCompressed(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"plain.txt":  []byte(content),
+		"data.gz":    gzipped(t, content),
+		"data.bz2":   bzip2Content,
+		"data.zst":   zstdCompressed(t, content),
+		"gzip.log":   gzipped(t, content),
+		"zstd.log":   zstdCompressed(t, content),
+		"text.bz2":   []byte("BZh is not bzip2 here\n"),
+		"bzh.txt":    []byte("BZh91AY&SY looks like bzip2\n"),
+		"empty.txt":  nil,
+		"one-byte.x": []byte("a"),
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		rc, err := Open(context.Background(), path)
+		switch name {
+		case "text.bz2":
+			if err == nil {
+				_, err = io.ReadAll(rc)
+				rc.Close()
+			}
+			if err == nil {
+				t.Errorf("%s: invalid bzip2 data was read without errors", name)
+			}
+		case "plain.txt", "data.gz", "data.bz2", "data.zst", "gzip.log", "zstd.log":
+			if got := readAll(t, rc, err); got != content {
+				t.Errorf("%s: got %q, want %q", name, got, content)
+			}
+		default:
+			if got := readAll(t, rc, err); got != string(data) {
+				t.Errorf("%s: got %q, want %q", name, got, data)
+			}
+		}
+
+		_, err = OpenAt(context.Background(), path)
+		compressed := strings.HasPrefix(name, "data.") || strings.HasSuffix(name, ".log") || name == "text.bz2"
+		if compressed != errors.Is(err, ErrNotSeekable) {
+			t.Errorf("%s: OpenAt error %v", name, err)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.txt", "sub/c.go", "sub/d_test.go", "vendor/e.go", "sub/deep/f.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rel := func(files []string) []string {
+		for i, f := range files {
+			files[i], _ = filepath.Rel(dir, f)
+			files[i] = filepath.ToSlash(files[i])
+		}
+		return files
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"a.go", "b.txt", "sub/c.go", "sub/d_test.go", "sub/deep/f.go", "vendor/e.go"}},
+		{"include", Filter{Include: []string{"*.go"}}, []string{"a.go", "sub/c.go", "sub/d_test.go", "sub/deep/f.go", "vendor/e.go"}},
+		{"exclude", Filter{Include: []string{"*.go"}, Exclude: []string{"*_test.go"}}, []string{"a.go", "sub/c.go", "sub/deep/f.go", "vendor/e.go"}},
+		{"exclude dir", Filter{ExcludeDir: []string{"vendor", "deep"}}, []string{"a.go", "b.txt", "sub/c.go", "sub/d_test.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Expand([]string{dir}, true, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rel(files); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Expand([]string{dir}, false, Filter{}); err == nil {
+		t.Errorf("directory was expanded without recursive")
+	}
+
+	// explicit files and URLs are not filtered
+	explicit := []string{filepath.Join(dir, "b.txt"), "https://example.com/log.txt"}
+	files, err := Expand(explicit, true, Filter{Include: []string{"*.go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(files, explicit) {
+		t.Errorf("got %v, want %v", files, explicit)
+	}
+}
+
+func TestURL(t *testing.T) {
+	text := strings.Repeat("0123456789\n", 100)
+	modTime := time.Unix(1700000000, 0)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ranges/log.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "log.txt", modTime, strings.NewReader(text))
+	})
+	mux.HandleFunc("/plain/log.txt", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, text)
+	})
+	mux.HandleFunc("/ranges/log.gz", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "log.gz", modTime, bytes.NewReader(gzipped(t, text)))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	for _, path := range []string{"/ranges/log.txt", "/plain/log.txt", "/ranges/log.gz"} {
+		rc, err := Open(ctx, server.URL+path)
+		if got := readAll(t, rc, err); got != text {
+			t.Errorf("%s: content differs", path)
+		}
+
+		// servers ignoring ranges still give the right bytes
+		if !strings.HasSuffix(path, ".gz") {
+			rc, err = OpenRange(ctx, server.URL+path, 15, 20)
+			if got := readAll(t, rc, err); got != text[15:35] {
+				t.Errorf("%s: range got %q, want %q", path, got, text[15:35])
+			}
+		}
+	}
+
+	file, err := OpenAt(ctx, server.URL+"/ranges/log.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Size() != int64(len(text)) {
+		t.Errorf("size %d, want %d", file.Size(), len(text))
+	}
+
+	buf := make([]byte, 30)
+	n, err := file.ReadAt(buf, int64(len(text))-10)
+	if n != 10 || err != io.EOF || string(buf[:n]) != text[len(text)-10:] {
+		t.Errorf("ReadAt at the end: %d %v %q", n, err, buf[:n])
+	}
+
+	for _, path := range []string{"/plain/log.txt", "/ranges/log.gz"} {
+		if _, err := OpenAt(ctx, server.URL+path); !errors.Is(err, ErrNotSeekable) {
+			t.Errorf("%s: OpenAt error %v, want ErrNotSeekable", path, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package matcher
+
+// ahoCorasick searches for many fixed strings in a single pass over the line.
+// The automaton is built as a DFA over byte classes: bytes that don't occur
+// in patterns share class 0, so the transition table stays small.
+// With foldCase ASCII letters are compared case-insensitively
+type ahoCorasick struct {
+	classes    [256]byte
+	numClasses int
+	// delta[state*numClasses+class] is the next state
+	delta []int32
+	// length of the pattern ending in the state, 0 if none
+	length []int32
+	// dict is the nearest state on the fail chain that ends a pattern, 0 if none
+	dict []int32
+}
+
+// newAhoCorasick builds the automaton; patterns must not be empty
+func newAhoCorasick(patterns []string, foldCase bool) *ahoCorasick {
+	ac := &ahoCorasick{numClasses: 1}
+
+	for _, p := range patterns {
+		for i := 0; i < len(p); i++ {
+			c := p[i]
+			if foldCase {
+				c = lower(c)
+			}
+			if ac.classes[c] == 0 {
+				ac.classes[c] = byte(ac.numClasses)
+				ac.numClasses++
+			}
+		}
+	}
+	if foldCase {
+		for c := 'A'; c <= 'Z'; c++ {
+			ac.classes[c] = ac.classes[c+'a'-'A']
+		}
+	}
+
+	// trie of patterns
+	ac.addState()
+	for _, p := range patterns {
+		state := int32(0)
+		for i := 0; i < len(p); i++ {
+			idx := int(state)*ac.numClasses + int(ac.classes[p[i]])
+			if ac.delta[idx] == 0 {
+				ac.delta[idx] = ac.addState()
+			}
+			state = ac.delta[idx]
+		}
+		ac.length[state] = int32(len(p))
+	}
+
+	// breadth-first, so fail links of shorter prefixes are ready first;
+	// missing transitions are replaced with transitions of the fail state
+	fail := make([]int32, len(ac.length))
+	queue := make([]int32, 0, len(ac.length))
+	for c := range ac.numClasses {
+		if child := ac.delta[c]; child != 0 {
+			queue = append(queue, child)
+		}
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		f := fail[state]
+		if ac.length[f] > 0 {
+			ac.dict[state] = f
+		} else {
+			ac.dict[state] = ac.dict[f]
+		}
+
+		row := int(state) * ac.numClasses
+		failRow := int(f) * ac.numClasses
+		for c := range ac.numClasses {
+			child := ac.delta[row+c]
+			if child == 0 {
+				ac.delta[row+c] = ac.delta[failRow+c]
+				continue
+			}
+			fail[child] = ac.delta[failRow+c]
+			queue = append(queue, child)
+		}
+	}
+
+	return ac
+}
+
+func (ac *ahoCorasick) addState() int32 {
+	ac.delta = append(ac.delta, make([]int32, ac.numClasses)...)
+	ac.length = append(ac.length, 0)
+	ac.dict = append(ac.dict, 0)
+	return int32(len(ac.length) - 1)
+}
+
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func (ac *ahoCorasick) find(line string, first bool) []Span {
+	var spans []Span
+	state := int32(0)
+
+	for i := 0; i < len(line); i++ {
+		state = ac.delta[int(state)*ac.numClasses+int(ac.classes[line[i]])]
+
+		out := state
+		if ac.length[out] == 0 {
+			out = ac.dict[out]
+		}
+		for out != 0 {
+			spans = append(spans, Span{Start: i + 1 - int(ac.length[out]), End: i + 1})
+			if first {
+				return spans
+			}
+			out = ac.dict[out]
+		}
+	}
+
+	return spans
+}
@@ -0,0 +1,232 @@
+// Package matcher implements line matching of mygrep.
+//
+// A Matcher is compiled once from the patterns and grep flags and is used
+// both for local search and by servers executing distributed jobs.
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// Span is a matched part of a line, [Start, End) in bytes
+type Span struct {
+	Start int
+	End   int
+}
+
+// engine finds pattern occurrences in a line
+type engine interface {
+	// find returns occurrences leftmost first. With first set it may stop
+	// after the first occurrence. Occurrences may overlap
+	find(line string, first bool) []Span
+}
+
+// Matcher selects lines matching any of the patterns
+type Matcher struct {
+	engine    engine
+	invert    bool
+	wholeWord bool
+	wholeLine bool
+}
+
+// Compile compiles patterns according to flags.
+// Patterns are regular expressions (RE2 syntax) unless flags.Fixed is set.
+// Fixed strings are searched with strings.Index for one pattern
+// and with Aho-Corasick automaton for several patterns
+func Compile(patterns []string, flags model.GrepFlags) (*Matcher, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no pattern specified")
+	}
+
+	m := &Matcher{
+		invert:    flags.InvertMatch,
+		wholeWord: flags.WordRegexp,
+		wholeLine: flags.WholeLine,
+	}
+
+	switch {
+	case flags.Fixed && slices.Contains(patterns, ""):
+		// an empty string matches every line, the automaton can't express it
+		m.engine = mustRegexp(quoteAll(patterns), flags)
+	case flags.Fixed && len(patterns) == 1 && !flags.IgnoreCase:
+		m.engine = indexEngine(patterns[0])
+	case flags.Fixed && (!flags.IgnoreCase || allASCII(patterns)):
+		m.engine = newAhoCorasick(patterns, flags.IgnoreCase)
+	case flags.Fixed:
+		// unicode case folding changes byte lengths, let regexp handle it
+		m.engine = mustRegexp(quoteAll(patterns), flags)
+	default:
+		for _, p := range patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+			}
+		}
+		m.engine = mustRegexp(patterns, flags)
+	}
+
+	return m, nil
+}
+
+// Match reports whether the line is selected, -v taken into account
+func (m *Matcher) Match(line string) bool {
+	return (len(m.spans(line, true)) > 0) != m.invert
+}
+
+// FindAll returns non-overlapping matched parts of the line, leftmost-longest first.
+// An inverted matcher selects lines without matches, so it returns nothing
+func (m *Matcher) FindAll(line string) []Span {
+	if m.invert {
+		return nil
+	}
+	return leftmostLongest(m.spans(line, false))
+}
+
+// spans returns occurrences that satisfy -w and -x
+func (m *Matcher) spans(line string, first bool) []Span {
+	if !m.wholeWord && !m.wholeLine {
+		return m.engine.find(line, first)
+	}
+
+	spans := m.engine.find(line, false)
+	valid := spans[:0]
+	for _, s := range spans {
+		if m.wholeLine && (s.Start != 0 || s.End != len(line)) {
+			continue
+		}
+		if m.wholeWord && !isWord(line, s) {
+			continue
+		}
+		valid = append(valid, s)
+		if first {
+			break
+		}
+	}
+	return valid
+}
+
+// isWord checks that the span is neither preceded nor followed by a word character
+func isWord(line string, s Span) bool {
+	if s.Start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(line[:s.Start])
+		if isWordChar(r) {
+			return false
+		}
+	}
+	if s.End < len(line) {
+		r, _ := utf8.DecodeRuneInString(line[s.End:])
+		if isWordChar(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// leftmostLongest picks non-overlapping spans preferring the leftmost
+// and then the longest one, as grep -o does
+func leftmostLongest(spans []Span) []Span {
+	slices.SortFunc(spans, func(a, b Span) int {
+		if a.Start != b.Start {
+			return a.Start - b.Start
+		}
+		return b.End - a.End
+	})
+
+	var result []Span
+	end := 0
+	for _, s := range spans {
+		// empty matches are not printed by grep -o
+		if s.Start >= end && s.Start < s.End {
+			result = append(result, s)
+			end = s.End
+		}
+	}
+	return result
+}
+
+// regexpEngine searches for any of the regular expressions
+type regexpEngine struct {
+	re *regexp.Regexp
+}
+
+// mustRegexp joins valid patterns into a single alternation
+func mustRegexp(patterns []string, flags model.GrepFlags) regexpEngine {
+	parts := make([]string, len(patterns))
+	for i, p := range patterns {
+		parts[i] = "(?:" + p + ")"
+	}
+
+	expr := strings.Join(parts, "|")
+	if flags.WholeLine {
+		expr = "^(?:" + expr + ")$"
+	}
+	if flags.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+
+	return regexpEngine{re: regexp.MustCompile(expr)}
+}
+
+func (e regexpEngine) find(line string, first bool) []Span {
+	n := -1
+	if first {
+		n = 1
+	}
+
+	locs := e.re.FindAllStringIndex(line, n)
+	spans := make([]Span, len(locs))
+	for i, loc := range locs {
+		spans[i] = Span{Start: loc[0], End: loc[1]}
+	}
+	return spans
+}
+
+// indexEngine searches for a single fixed string
+type indexEngine string
+
+func (e indexEngine) find(line string, first bool) []Span {
+	var spans []Span
+	for offset := 0; offset <= len(line); {
+		i := strings.Index(line[offset:], string(e))
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		spans = append(spans, Span{Start: start, End: start + len(e)})
+		if first {
+			break
+		}
+		// overlapping occurrences are needed for -w and -x
+		offset = start + 1
+	}
+	return spans
+}
+
+func quoteAll(patterns []string) []string {
+	quoted := make([]string, len(patterns))
+	for i, p := range patterns {
+		quoted[i] = regexp.QuoteMeta(p)
+	}
+	return quoted
+}
+
+func allASCII(patterns []string) bool {
+	for _, p := range patterns {
+		for i := 0; i < len(p); i++ {
+			if p[i] >= utf8.RuneSelf {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package matcher
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// BenchmarkManyPatterns compares Aho-Corasick with a regexp alternation
+func BenchmarkManyPatterns(b *testing.B) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	patterns := make([]string, 1000)
+	for i := range patterns {
+		word := make([]byte, 6+rnd.IntN(6))
+		for j := range word {
+			word[j] = byte('a' + rnd.IntN(26))
+		}
+		patterns[i] = string(word)
+	}
+	line := strings.Repeat("some log line without any of the patterns ", 4)
+
+	for _, fixed := range []bool{true, false} {
+		m, err := Compile(patterns, model.GrepFlags{Fixed: fixed})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprintf("fixed=%v", fixed), func(b *testing.B) {
+			for range b.N {
+				m.Match(line)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package matcher
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		flags    model.GrepFlags
+		line     string
+		match    bool
+		found    []string
+	}{
+		{"regexp", []string{"fo+"}, model.GrepFlags{}, "a foo fo", true, []string{"foo", "fo"}},
+		{"regexp alternation", []string{"cat", "dog"}, model.GrepFlags{}, "hot dog", true, []string{"dog"}},
+		{"no match", []string{"cat"}, model.GrepFlags{}, "dog", false, nil},
+		{"ignore case", []string{"привет"}, model.GrepFlags{IgnoreCase: true}, "ПРИВЕТ мир", true, []string{"ПРИВЕТ"}},
+		{"invert", []string{"cat"}, model.GrepFlags{InvertMatch: true}, "dog", true, nil},
+		{"whole line", []string{"a.c"}, model.GrepFlags{WholeLine: true}, "abc", true, []string{"abc"}},
+		{"whole line no match", []string{"a.c"}, model.GrepFlags{WholeLine: true}, "abcd", false, nil},
+		{"word", []string{"foo"}, model.GrepFlags{WordRegexp: true}, "foobar foo_x (foo)", true, []string{"foo"}},
+		{"word unicode", []string{"кот"}, model.GrepFlags{WordRegexp: true}, "котик", false, nil},
+		{"fixed", []string{"a.c"}, model.GrepFlags{Fixed: true}, "abc a.c", true, []string{"a.c"}},
+		{"fixed word overlapping", []string{"aa"}, model.GrepFlags{Fixed: true, WordRegexp: true}, "aaa aa", true, []string{"aa"}},
+		{"aho-corasick", []string{"he", "she", "hers", "his"}, model.GrepFlags{Fixed: true}, "ushers", true, []string{"she"}},
+		{"aho-corasick longest", []string{"ab", "abcd", "bc"}, model.GrepFlags{Fixed: true}, "xabcdbc", true, []string{"abcd", "bc"}},
+		{"aho-corasick ignore case", []string{"ERROR", "warn"}, model.GrepFlags{Fixed: true, IgnoreCase: true}, "Warn: error", true, []string{"Warn", "error"}},
+		{"aho-corasick whole line", []string{"ab", "abc"}, model.GrepFlags{Fixed: true, WholeLine: true}, "abc", true, []string{"abc"}},
+		{"fixed ignore case unicode", []string{"ошибка", "сбой"}, model.GrepFlags{Fixed: true, IgnoreCase: true}, "Ошибка", true, []string{"Ошибка"}},
+		{"fixed empty", []string{"", "x"}, model.GrepFlags{Fixed: true}, "abc", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.patterns, tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := m.Match(tt.line); got != tt.match {
+				t.Errorf("Match = %v, want %v", got, tt.match)
+			}
+
+			var found []string
+			for _, s := range m.FindAll(tt.line) {
+				found = append(found, tt.line[s.Start:s.End])
+			}
+			if !slices.Equal(found, tt.found) {
+				t.Errorf("FindAll = %q, want %q", found, tt.found)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	if _, err := Compile(nil, model.GrepFlags{}); err == nil {
+		t.Error("expected error for no patterns")
+	}
+	if _, err := Compile([]string{"ok", "(unclosed"}, model.GrepFlags{}); err == nil || !strings.Contains(err.Error(), "(unclosed") {
+		t.Errorf("expected invalid pattern error, got %v", err)
+	}
+}
+
+// TestAhoCorasickFindsAll checks the automaton against a naive search
+func TestAhoCorasickFindsAll(t *testing.T) {
+	patterns := []string{"a", "ab", "bab", "bc", "bca", "c", "caa"}
+	ac := newAhoCorasick(patterns, false)
+
+	cmp := func(a, b Span) int {
+		if a.Start != b.Start {
+			return a.Start - b.Start
+		}
+		return a.End - b.End
+	}
+
+	for _, line := range []string{"abccab", "bcabab", "caaab", "xyz", "babcaa"} {
+		var want []Span
+		for _, p := range patterns {
+			for i := 0; i+len(p) <= len(line); i++ {
+				if line[i:i+len(p)] == p {
+					want = append(want, Span{i, i + len(p)})
+				}
+			}
+		}
+
+		got := ac.find(line, false)
+		slices.SortFunc(got, cmp)
+		slices.SortFunc(want, cmp)
+
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", line, got, want)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"net"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,27 +17,43 @@ type Config struct {
 	ServerID      string        `json:"server_id"`
 	Port          string        `json:"port"`
 	Peers         []string      `json:"peers"`
-	Pattern       string        `json:"pattern"`
+	Patterns      []string      `json:"patterns"`
 	Files         []string      `json:"files"`
+	Recursive     bool          `json:"recursive"`
+	Include       []string      `json:"include"`
+	Exclude       []string      `json:"exclude"`
+	ExcludeDir    []string      `json:"exclude_dir"`
 	Input         io.Reader     `json:"-"`
 	Output        io.Writer     `json:"-"`
 	IsDistributed bool          `json:"is_distributed"`
 	Flags         GrepFlags     `json:"flags"`
 	Timeout       time.Duration `json:"timeout"`
+	QuorumTimeout time.Duration `json:"quorum_timeout"`
+	Replicas      int           `json:"replicas"`
+	WriteQuorum   int           `json:"write_quorum"`
+	ChunkSize     int64         `json:"chunk_size"`
+	SharedFS      bool          `json:"shared_fs"`
+	Advertise     string        `json:"advertise"`
+	Heartbeat     time.Duration `json:"heartbeat"`
+	Version       string        `json:"version"`
 	LocalAddress  *net.TCPAddr  `json:"-"`
 }
 
-// GrepResult represents a grep search result
+// GrepResult represents a grep search result.
+// Context results are lines around matches printed with -A, -B and -C
 type GrepResult struct {
 	LineNumber int    `json:"line_number"`
 	Line       string `json:"line"`
 	Match      string `json:"match"`
+	Context    bool   `json:"context,omitempty"`
 }
 
-// JobResult represents job execution result
+// JobResult represents job execution result.
+// Line numbers of matches are local to the job chunk
 type JobResult struct {
 	JobID       string       `json:"job_id"`
 	ServerID    string       `json:"server_id"`
+	Chunk       int          `json:"chunk"`
 	Matches     []GrepResult `json:"matches"`
 	Processed   int          `json:"processed"`
 	Error       string       `json:"error,omitempty"`
@@ -40,18 +61,34 @@ type JobResult struct {
 	CompletedAt time.Time    `json:"completed_at"`
 }
 
-// Job represents a task for processing data chunks
+// Job represents a task for processing data chunks.
+// A job carries either the chunk data itself or a reference
+// to the chunk in a file shared between servers
 type Job struct {
 	ID        string    `json:"id"`
 	ServerID  string    `json:"server_id"`
-	Pattern   string    `json:"pattern"`
-	Data      string    `json:"data"`
-	StartLine int       `json:"start_line"`
-	EndLine   int       `json:"end_line"`
+	Patterns  []string  `json:"patterns"`
+	Chunk     int       `json:"chunk"`
+	Data      string    `json:"data,omitempty"`
+	File      *FileRef  `json:"file,omitempty"`
 	Flags     GrepFlags `json:"flags"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// FileRef points to a byte range of a file
+type FileRef struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+}
+
+// Chunk is a byte range of a file aligned to line boundaries
+type Chunk struct {
+	Index  int   `json:"index"`
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
 // Result represents task processing result
 type Result struct {
 	JobID       string    `json:"job_id"`
@@ -70,31 +107,32 @@ type Match struct {
 	Column     int    `json:"column,omitempty"`
 }
 
+// Member statuses
+const (
+	StatusAlive   = "alive"
+	StatusSuspect = "suspect"
+	StatusDead    = "dead"
+)
+
 // ServerInfo contains server state information
 type ServerInfo struct {
 	ID        string    `json:"id"`
 	Address   string    `json:"address"`
-	Status    string    `json:"status"` // "online", "offline", "processing"
+	Status    string    `json:"status"` // "alive", "suspect", "dead"
 	JobsCount int       `json:"jobs_count"`
+	Version   string    `json:"version"`
+	Protocol  int       `json:"protocol"`
+	Phi       float64   `json:"phi"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// Message represents a network communication message
-type Message struct {
-	Type      string      `json:"type"` // "job_request", "job_response", "status_update", "result"
-	From      string      `json:"from"`
-	To        string      `json:"to,omitempty"`
-	Data      interface{} `json:"data"`
-	Timestamp time.Time   `json:"timestamp"`
-}
-
-// NetworkMessage used for message serialization over network
-type NetworkMessage struct {
-	Type      string      `json:"type"`
-	From      string      `json:"from"`
-	To        string      `json:"to"`
-	Data      interface{} `json:"data"`
-	Timestamp time.Time   `json:"timestamp"`
+// Heartbeat is exchanged between cluster members.
+// It carries the sender state and the members it knows about,
+// so the member list spreads through the cluster
+type Heartbeat struct {
+	From    ServerInfo   `json:"from"`
+	Members []ServerInfo `json:"members"`
+	SentAt  time.Time    `json:"sent_at"`
 }
 
 // GrepFlags contains grep flags
@@ -106,58 +144,60 @@ type GrepFlags struct {
 	LineNumber   bool `json:"line_number"`   // -n
 	Count        bool `json:"count"`         // -c
 	OnlyMatching bool `json:"only_matching"` // -o
+	Fixed        bool `json:"fixed"`         // -F
+	WordRegexp   bool `json:"word_regexp"`   // -w
+	After        int  `json:"after"`         // -A, -C
+	Before       int  `json:"before"`        // -B, -C
 }
 
-// JobRequest structure for job execution request
-type JobRequest struct {
-	Job Job `json:"job"`
-}
-
-// JobResponse structure for result response
-type JobResponse struct {
-	Result Result `json:"result"`
-}
-
-// StatusUpdate structure for status update
-type StatusUpdate struct {
-	ServerInfo ServerInfo `json:"server_info"`
-}
-
-// QuorumStatus tracks quorum status
+// QuorumStatus tracks replica results of a single chunk.
+// A result is accepted when RequiredVotes replicas return the same content
 type QuorumStatus struct {
-	TotalServers  int                   `json:"total_servers"`
-	RequiredVotes int                   `json:"required_votes"` // N/2+1
+	Chunk         int                   `json:"chunk"`
+	Replicas      int                   `json:"replicas"`
+	RequiredVotes int                   `json:"required_votes"`
 	ReceivedVotes int                   `json:"received_votes"`
 	Results       map[string]*JobResult `json:"results"` // serverID -> result
+	Votes         map[string][]string   `json:"votes"`   // content hash -> server IDs
+	Accepted      string                `json:"accepted"`
 	Completed     bool                  `json:"completed"`
 	mu            sync.RWMutex
 }
 
-// NewQuorumStatus creates a new QuorumStatus
-func NewQuorumStatus(totalServers int) *QuorumStatus {
+// NewQuorumStatus creates a new QuorumStatus.
+// If requiredVotes is not positive, majority of replicas is required
+func NewQuorumStatus(chunk, replicas, requiredVotes int) *QuorumStatus {
+	if requiredVotes <= 0 {
+		requiredVotes = replicas/2 + 1
+	}
+
 	return &QuorumStatus{
-		TotalServers:  totalServers,
-		RequiredVotes: totalServers/2 + 1,
+		Chunk:         chunk,
+		Replicas:      replicas,
+		RequiredVotes: requiredVotes,
 		Results:       make(map[string]*JobResult),
+		Votes:         make(map[string][]string),
 		Completed:     false,
 	}
 }
 
-// AddResult adds a result and checks for quorum achievement
+// AddResult adds a replica result and checks for quorum achievement
 func (q *QuorumStatus) AddResult(result *JobResult) bool {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	hash := result.ContentHash()
 	q.Results[result.ServerID] = result
+	q.Votes[hash] = append(q.Votes[hash], result.ServerID)
 	q.ReceivedVotes++
 
 	// Check quorum achievement
-	if q.ReceivedVotes >= q.RequiredVotes {
+	if !q.Completed && len(q.Votes[hash]) >= q.RequiredVotes {
+		q.Accepted = hash
 		q.Completed = true
-		return true
 	}
 
-	return false
+	return q.Completed
 }
 
 // IsCompleted checks if quorum is achieved
@@ -167,6 +207,50 @@ func (q *QuorumStatus) IsCompleted() bool {
 	return q.Completed
 }
 
+// AcceptedResult returns the result agreed by the quorum
+func (q *QuorumStatus) AcceptedResult() (*JobResult, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if !q.Completed {
+		return nil, false
+	}
+	return q.Results[q.Votes[q.Accepted][0]], true
+}
+
+// Divergent returns servers whose results differ from the accepted one
+func (q *QuorumStatus) Divergent() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	var servers []string
+	for hash, ids := range q.Votes {
+		if hash != q.Accepted {
+			servers = append(servers, ids...)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// VotesSummary describes received votes, e.g. "3f2a1b9c: 2 (a, b), 77d0e4aa: 1 (c)"
+func (q *QuorumStatus) VotesSummary() string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	hashes := make([]string, 0, len(q.Votes))
+	for hash := range q.Votes {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	parts := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		parts = append(parts, fmt.Sprintf("%.8s: %d (%s)", hash, len(q.Votes[hash]), strings.Join(q.Votes[hash], ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // GetResults returns all results
 func (q *QuorumStatus) GetResults() map[string]*JobResult {
 	q.mu.RLock()
@@ -179,3 +263,14 @@ func (q *QuorumStatus) GetResults() map[string]*JobResult {
 	}
 	return results
 }
+
+// ContentHash returns SHA-256 of the match list and the number of processed lines.
+// Replicas that processed the same chunk identically have equal hashes
+func (r *JobResult) ContentHash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", r.Processed)
+	for _, m := range r.Matches {
+		fmt.Fprintf(h, "%d %t %d:%s %d:%s\n", m.LineNumber, m.Context, len(m.Line), m.Line, len(m.Match), m.Match)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,150 @@
+// Package protocol implements the mygrep wire protocol.
+//
+// Every message is sent in a frame with a fixed 12-byte header:
+//
+//	magic    [2]byte "MG"
+//	version  uint8   protocol version of the payload
+//	type     uint8   MessageType
+//	stream   uint32  stream the message belongs to, big endian
+//	length   uint32  payload length, big endian
+//
+// followed by length bytes of payload. A connection starts with a Hello
+// exchange on stream 0; after that the dialing side opens a new stream for
+// every request, so many requests share one connection. The Hello and Error
+// payloads are the same in every version, so the handshake frames are read
+// whatever their header version is.
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Protocol versions supported by this build:
+//
+//	1 - initial version
+//	2 - jobs carry several patterns and context flags, results carry context lines
+const (
+	Version    uint8 = 2
+	MinVersion uint8 = 2
+)
+
+const (
+	headerSize = 12
+	// MaxPayloadSize limits a single frame, job data included
+	MaxPayloadSize = 1 << 30
+)
+
+var magic = [2]byte{'M', 'G'}
+
+// ErrIncompatibleVersion is returned when peers have no common protocol version
+var ErrIncompatibleVersion = errors.New("incompatible protocol version")
+
+// Header is the fixed part of a frame
+type Header struct {
+	Version uint8
+	Type    MessageType
+	Stream  uint32
+	Length  uint32
+}
+
+// WriteFrame encodes msg and writes it as a single frame
+func WriteFrame(w io.Writer, stream uint32, msg Message) error {
+	var e encoder
+	e.buf = make([]byte, headerSize, headerSize+64)
+	msg.encode(&e)
+
+	payload := len(e.buf) - headerSize
+	if payload > MaxPayloadSize {
+		return fmt.Errorf("%s payload of %d bytes exceeds limit", msg.Type(), payload)
+	}
+
+	copy(e.buf[0:2], magic[:])
+	e.buf[2] = Version
+	e.buf[3] = byte(msg.Type())
+	binary.BigEndian.PutUint32(e.buf[4:8], stream)
+	binary.BigEndian.PutUint32(e.buf[8:12], uint32(payload))
+
+	_, err := w.Write(e.buf)
+	return err
+}
+
+// ReadFrame reads a frame and decodes its message.
+// Frames of versions outside MinVersion..Version are rejected
+func ReadFrame(r io.Reader) (Header, Message, error) {
+	return readFrame(r, true)
+}
+
+// readFrame reads a frame, checkVersion is false for the handshake frames
+func readFrame(r io.Reader, checkVersion bool) (Header, Message, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return Header{}, nil, err
+	}
+
+	if buf[0] != magic[0] || buf[1] != magic[1] {
+		return Header{}, nil, errors.New("invalid frame magic")
+	}
+
+	h := Header{
+		Version: buf[2],
+		Type:    MessageType(buf[3]),
+		Stream:  binary.BigEndian.Uint32(buf[4:8]),
+		Length:  binary.BigEndian.Uint32(buf[8:12]),
+	}
+
+	if checkVersion && (h.Version < MinVersion || h.Version > Version) {
+		return h, nil, fmt.Errorf("%w: frame version %d", ErrIncompatibleVersion, h.Version)
+	}
+	if h.Length > MaxPayloadSize {
+		return h, nil, fmt.Errorf("frame payload of %d bytes exceeds limit", h.Length)
+	}
+
+	payload := make([]byte, h.Length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return h, nil, err
+	}
+
+	msg := newMessage(h.Type)
+	if msg == nil {
+		return h, nil, fmt.Errorf("unknown message type %d", h.Type)
+	}
+
+	d := decoder{buf: payload}
+	msg.decode(&d)
+	if d.err != nil {
+		return h, nil, fmt.Errorf("invalid %s message: %w", h.Type, d.err)
+	}
+
+	return h, msg, nil
+}
+
+// Handshake sends Hello and checks the Hello of the other side.
+// The side that receives an incompatible Hello answers with Error
+func Handshake(rw io.ReadWriter, serverID string) (*Hello, error) {
+	if err := WriteFrame(rw, 0, &Hello{Version: Version, MinVersion: MinVersion, ServerID: serverID}); err != nil {
+		return nil, err
+	}
+
+	// the version is negotiated from the Hello, not from the frame header
+	h, msg, err := readFrame(rw, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch m := msg.(type) {
+	case *Hello:
+		if m.Version < MinVersion || m.MinVersion > Version {
+			WriteFrame(rw, h.Stream, &Error{Message: fmt.Sprintf("%v: peer supports %d..%d, we support %d..%d",
+				ErrIncompatibleVersion, m.MinVersion, m.Version, MinVersion, Version)})
+			return nil, fmt.Errorf("%w: peer %s supports %d..%d", ErrIncompatibleVersion, m.ServerID, m.MinVersion, m.Version)
+		}
+		return m, nil
+	case *Error:
+		return nil, fmt.Errorf("handshake rejected: %s", m.Message)
+	default:
+		return nil, fmt.Errorf("unexpected %s message during handshake", h.Type)
+	}
+}
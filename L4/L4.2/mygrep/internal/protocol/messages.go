@@ -0,0 +1,321 @@
+package protocol
+
+import (
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// MessageType identifies the payload of a frame
+type MessageType uint8
+
+// Message types
+const (
+	TypeHello MessageType = iota + 1
+	TypeError
+	TypeJobRequest
+	TypeResultChunk
+	TypeJobResult
+	TypeHeartbeat
+	TypeCancel
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case TypeHello:
+		return "hello"
+	case TypeError:
+		return "error"
+	case TypeJobRequest:
+		return "job_request"
+	case TypeResultChunk:
+		return "result_chunk"
+	case TypeJobResult:
+		return "job_result"
+	case TypeHeartbeat:
+		return "heartbeat"
+	case TypeCancel:
+		return "cancel"
+	default:
+		return "unknown"
+	}
+}
+
+// Message is a typed frame payload
+type Message interface {
+	Type() MessageType
+	encode(e *encoder)
+	decode(d *decoder)
+}
+
+// newMessage returns an empty message of the given type
+func newMessage(t MessageType) Message {
+	switch t {
+	case TypeHello:
+		return &Hello{}
+	case TypeError:
+		return &Error{}
+	case TypeJobRequest:
+		return &JobRequest{}
+	case TypeResultChunk:
+		return &ResultChunk{}
+	case TypeJobResult:
+		return &JobResult{}
+	case TypeHeartbeat:
+		return &Heartbeat{}
+	case TypeCancel:
+		return &Cancel{}
+	default:
+		return nil
+	}
+}
+
+// Hello is the first message on a connection in both directions.
+// It carries the protocol version range supported by the sender
+type Hello struct {
+	Version    uint8
+	MinVersion uint8
+	ServerID   string
+}
+
+func (m *Hello) Type() MessageType { return TypeHello }
+
+func (m *Hello) encode(e *encoder) {
+	e.uvarint(uint64(m.Version))
+	e.uvarint(uint64(m.MinVersion))
+	e.string(m.ServerID)
+}
+
+func (m *Hello) decode(d *decoder) {
+	m.Version = uint8(d.uvarint())
+	m.MinVersion = uint8(d.uvarint())
+	m.ServerID = d.string()
+}
+
+// Error reports a failure of a stream or of the whole connection (stream 0)
+type Error struct {
+	Message string
+}
+
+func (m *Error) Type() MessageType { return TypeError }
+
+func (m *Error) encode(e *encoder) {
+	e.string(m.Message)
+}
+
+func (m *Error) decode(d *decoder) {
+	m.Message = d.string()
+}
+
+// JobRequest asks a peer to execute a job
+type JobRequest struct {
+	Job model.Job
+}
+
+func (m *JobRequest) Type() MessageType { return TypeJobRequest }
+
+func (m *JobRequest) encode(e *encoder) {
+	j := m.Job
+	e.string(j.ID)
+	e.string(j.ServerID)
+	e.uvarint(uint64(len(j.Patterns)))
+	for _, p := range j.Patterns {
+		e.string(p)
+	}
+	e.varint(int64(j.Chunk))
+	e.string(j.Data)
+	e.bool(j.File != nil)
+	if j.File != nil {
+		e.string(j.File.Path)
+		e.varint(j.File.Offset)
+		e.varint(j.File.Length)
+	}
+	encodeFlags(e, j.Flags)
+	e.time(j.CreatedAt)
+}
+
+func (m *JobRequest) decode(d *decoder) {
+	j := &m.Job
+	j.ID = d.string()
+	j.ServerID = d.string()
+	n := d.length()
+	for range n {
+		j.Patterns = append(j.Patterns, d.string())
+	}
+	j.Chunk = d.int()
+	j.Data = d.string()
+	if d.bool() {
+		j.File = &model.FileRef{
+			Path:   d.string(),
+			Offset: d.varint(),
+			Length: d.varint(),
+		}
+	}
+	j.Flags = decodeFlags(d)
+	j.CreatedAt = d.time()
+}
+
+// ResultChunk carries a part of the job matches.
+// Matches of a job are streamed in several chunks followed by JobResult
+type ResultChunk struct {
+	Matches []model.GrepResult
+}
+
+func (m *ResultChunk) Type() MessageType { return TypeResultChunk }
+
+func (m *ResultChunk) encode(e *encoder) {
+	e.uvarint(uint64(len(m.Matches)))
+	for _, match := range m.Matches {
+		e.varint(int64(match.LineNumber))
+		e.string(match.Line)
+		e.string(match.Match)
+		e.bool(match.Context)
+	}
+}
+
+func (m *ResultChunk) decode(d *decoder) {
+	n := d.length()
+	m.Matches = make([]model.GrepResult, 0, n)
+	for range n {
+		m.Matches = append(m.Matches, model.GrepResult{
+			LineNumber: d.int(),
+			Line:       d.string(),
+			Match:      d.string(),
+			Context:    d.bool(),
+		})
+	}
+}
+
+// JobResult completes a job stream. Its Matches are always empty,
+// they are sent in the preceding ResultChunk messages
+type JobResult struct {
+	Result model.JobResult
+}
+
+func (m *JobResult) Type() MessageType { return TypeJobResult }
+
+func (m *JobResult) encode(e *encoder) {
+	r := m.Result
+	e.string(r.JobID)
+	e.string(r.ServerID)
+	e.varint(int64(r.Chunk))
+	e.varint(int64(r.Processed))
+	e.string(r.Error)
+	e.bool(r.Success)
+	e.time(r.CompletedAt)
+}
+
+func (m *JobResult) decode(d *decoder) {
+	r := &m.Result
+	r.JobID = d.string()
+	r.ServerID = d.string()
+	r.Chunk = d.int()
+	r.Processed = d.int()
+	r.Error = d.string()
+	r.Success = d.bool()
+	r.CompletedAt = d.time()
+}
+
+// Heartbeat is sent periodically to every cluster member; the member
+// answers with its own heartbeat on the same stream
+type Heartbeat struct {
+	Heartbeat model.Heartbeat
+}
+
+func (m *Heartbeat) Type() MessageType { return TypeHeartbeat }
+
+func (m *Heartbeat) encode(e *encoder) {
+	hb := m.Heartbeat
+	encodeServerInfo(e, hb.From)
+	e.uvarint(uint64(len(hb.Members)))
+	for _, member := range hb.Members {
+		encodeServerInfo(e, member)
+	}
+	e.time(hb.SentAt)
+}
+
+func (m *Heartbeat) decode(d *decoder) {
+	hb := &m.Heartbeat
+	hb.From = decodeServerInfo(d)
+	n := d.length()
+	if n > 0 {
+		hb.Members = make([]model.ServerInfo, 0, n)
+	}
+	for range n {
+		hb.Members = append(hb.Members, decodeServerInfo(d))
+	}
+	hb.SentAt = d.time()
+}
+
+func encodeServerInfo(e *encoder, info model.ServerInfo) {
+	e.string(info.ID)
+	e.string(info.Address)
+	e.string(info.Status)
+	e.varint(int64(info.JobsCount))
+	e.string(info.Version)
+	e.varint(int64(info.Protocol))
+	e.float64(info.Phi)
+	e.time(info.UpdatedAt)
+}
+
+func decodeServerInfo(d *decoder) model.ServerInfo {
+	return model.ServerInfo{
+		ID:        d.string(),
+		Address:   d.string(),
+		Status:    d.string(),
+		JobsCount: d.int(),
+		Version:   d.string(),
+		Protocol:  d.int(),
+		Phi:       d.float64(),
+		UpdatedAt: d.time(),
+	}
+}
+
+// Cancel stops the job running on the stream
+type Cancel struct{}
+
+func (m *Cancel) Type() MessageType { return TypeCancel }
+
+func (m *Cancel) encode(e *encoder) {}
+
+func (m *Cancel) decode(d *decoder) {}
+
+// grep flags are packed into a bit mask
+const (
+	flagColor = 1 << iota
+	flagInvertMatch
+	flagIgnoreCase
+	flagWholeLine
+	flagLineNumber
+	flagCount
+	flagOnlyMatching
+	flagFixed
+	flagWordRegexp
+)
+
+func encodeFlags(e *encoder, f model.GrepFlags) {
+	var mask uint64
+	for bit, set := range []bool{f.Color, f.InvertMatch, f.IgnoreCase, f.WholeLine, f.LineNumber, f.Count, f.OnlyMatching, f.Fixed, f.WordRegexp} {
+		if set {
+			mask |= 1 << bit
+		}
+	}
+	e.uvarint(mask)
+	e.varint(int64(f.After))
+	e.varint(int64(f.Before))
+}
+
+func decodeFlags(d *decoder) model.GrepFlags {
+	mask := d.uvarint()
+	return model.GrepFlags{
+		Color:        mask&flagColor != 0,
+		InvertMatch:  mask&flagInvertMatch != 0,
+		IgnoreCase:   mask&flagIgnoreCase != 0,
+		WholeLine:    mask&flagWholeLine != 0,
+		LineNumber:   mask&flagLineNumber != 0,
+		Count:        mask&flagCount != 0,
+		OnlyMatching: mask&flagOnlyMatching != 0,
+		Fixed:        mask&flagFixed != 0,
+		WordRegexp:   mask&flagWordRegexp != 0,
+		After:        d.int(),
+		Before:       d.int(),
+	}
+}
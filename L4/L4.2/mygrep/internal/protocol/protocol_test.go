@@ -0,0 +1,190 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	created := time.Unix(0, 1700000000123456789)
+
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"hello", &Hello{Version: 1, MinVersion: 1, ServerID: "host:8080"}},
+		{"error", &Error{Message: "boom"}},
+		{"job with data", &JobRequest{Job: model.Job{
+			ID: "j1", ServerID: "a", Patterns: []string{"err", "warn"}, Chunk: 3, Data: "line 1\nline 2\n",
+			Flags:     model.GrepFlags{IgnoreCase: true, Count: true, OnlyMatching: true, Fixed: true, After: 2, Before: 3},
+			CreatedAt: created,
+		}}},
+		{"job with file", &JobRequest{Job: model.Job{
+			ID: "j2", Patterns: []string{"x"}, File: &model.FileRef{Path: "/tmp/f", Offset: 1 << 40, Length: 17},
+			Flags: model.GrepFlags{InvertMatch: true, LineNumber: true, WordRegexp: true},
+		}}},
+		{"result chunk", &ResultChunk{Matches: []model.GrepResult{
+			{LineNumber: 1, Line: "a b", Match: "b"},
+			{LineNumber: 42, Line: "привет", Match: "при"},
+			{LineNumber: 43, Line: "context", Context: true},
+		}}},
+		{"job result", &JobResult{Result: model.JobResult{
+			JobID: "j1", ServerID: "b", Chunk: 3, Processed: 1000, Success: true, CompletedAt: created,
+		}}},
+		{"heartbeat", &Heartbeat{Heartbeat: model.Heartbeat{
+			From: model.ServerInfo{ID: "a", Address: "a:8080", Status: model.StatusAlive, JobsCount: 2, Version: "dev", Protocol: 1},
+			Members: []model.ServerInfo{
+				{ID: "b", Address: "b:8080", Status: model.StatusSuspect, Phi: 9.75, UpdatedAt: created},
+			},
+			SentAt: created,
+		}}},
+		{"heartbeat of observer", &Heartbeat{Heartbeat: model.Heartbeat{SentAt: created}}},
+		{"cancel", &Cancel{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteFrame(&buf, 7, tt.msg); err != nil {
+				t.Fatal(err)
+			}
+
+			h, msg, err := ReadFrame(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Stream != 7 || h.Type != tt.msg.Type() || h.Version != Version {
+				t.Errorf("unexpected header %+v", h)
+			}
+			if !reflect.DeepEqual(msg, tt.msg) {
+				t.Errorf("got %+v, want %+v", msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestReadFrameErrors(t *testing.T) {
+	valid := func() []byte {
+		var buf bytes.Buffer
+		WriteFrame(&buf, 1, &Error{Message: "hello"})
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name   string
+		modify func(b []byte) []byte
+		err    string
+	}{
+		{"bad magic", func(b []byte) []byte { b[0] = 'X'; return b }, "magic"},
+		{"future version", func(b []byte) []byte { b[2] = Version + 1; return b }, "incompatible protocol version"},
+		{"unknown type", func(b []byte) []byte { b[3] = 200; return b }, "unknown message type"},
+		{"truncated payload", func(b []byte) []byte { return b[:len(b)-2] }, "unexpected EOF"},
+		{"short payload", func(b []byte) []byte { b[11] = 0; return b[:headerSize] }, "too short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadFrame(bytes.NewReader(tt.modify(valid())))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+
+	if _, _, err := ReadFrame(bytes.NewReader(nil)); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF on empty input, got %v", err)
+	}
+}
+
+// connPair returns both ends of a loopback TCP connection
+func connPair(t *testing.T) (net.Conn, net.Conn) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	a, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	return a, b
+}
+
+func TestHandshake(t *testing.T) {
+	t.Run("compatible", func(t *testing.T) {
+		a, b := connPair(t)
+
+		go Handshake(b, "b")
+
+		hello, err := Handshake(a, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hello.ServerID != "b" {
+			t.Errorf("peer id %q, want b", hello.ServerID)
+		}
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		a, b := connPair(t)
+
+		// a peer that speaks only a future version of the protocol,
+		// its frames carry the future version in the header
+		reply := make(chan Message, 1)
+		go func() {
+			var buf bytes.Buffer
+			WriteFrame(&buf, 0, &Hello{Version: Version + 2, MinVersion: Version + 1, ServerID: "future"})
+			frame := buf.Bytes()
+			frame[2] = Version + 2
+			b.Write(frame)
+
+			ReadFrame(b) // our Hello
+			_, msg, _ := ReadFrame(b)
+			reply <- msg
+		}()
+
+		_, err := Handshake(a, "a")
+		if !errors.Is(err, ErrIncompatibleVersion) {
+			t.Errorf("expected ErrIncompatibleVersion, got %v", err)
+		}
+
+		select {
+		case msg := <-reply:
+			if _, ok := msg.(*Error); !ok {
+				t.Errorf("peer got %T, want *Error", msg)
+			}
+		case <-time.After(time.Second):
+			t.Error("peer got no reply to its Hello")
+		}
+	})
+
+	t.Run("frame version", func(t *testing.T) {
+		var buf bytes.Buffer
+		WriteFrame(&buf, 1, &Heartbeat{})
+		buf.Bytes()[2] = Version + 1
+
+		if _, _, err := ReadFrame(&buf); !errors.Is(err, ErrIncompatibleVersion) {
+			t.Errorf("expected ErrIncompatibleVersion, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// defaultStreamChunkSize is the chunk size of inputs that are cut into
+// chunks while being decompressed
+const defaultStreamChunkSize = 4 << 20
+
+// planChunks splits size bytes of a file into chunks of about chunkSize bytes.
+// Every chunk except the last one ends right after a newline,
+// so no line is split between two chunks
+func planChunks(file io.ReaderAt, size, chunkSize int64) ([]model.Chunk, error) {
+	var err error
+	if chunkSize <= 0 {
+		chunkSize = size
+	}
+
+	chunks := make([]model.Chunk, 0, size/max(chunkSize, 1)+1)
+	var offset int64
+
+	for offset < size {
+		end := offset + chunkSize
+		if end >= size {
+			end = size
+		} else {
+			end, err = nextLineStart(file, end, size)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		chunks = append(chunks, model.Chunk{
+			Index:  len(chunks),
+			Offset: offset,
+			Length: end - offset,
+		})
+		offset = end
+	}
+
+	return chunks, nil
+}
+
+// nextLineStart returns the offset right after the first newline at or after pos,
+// or size if there is no newline till the end of the file
+func nextLineStart(file io.ReaderAt, pos, size int64) (int64, error) {
+	// a newline right before pos means pos already starts a line
+	r := bufio.NewReader(io.NewSectionReader(file, pos-1, size-pos+1))
+
+	skipped, err := r.ReadSlice('\n')
+	for err == bufio.ErrBufferFull {
+		pos += int64(len(skipped))
+		skipped, err = r.ReadSlice('\n')
+	}
+
+	switch err {
+	case nil:
+		return pos - 1 + int64(len(skipped)), nil
+	case io.EOF:
+		return size, nil
+	default:
+		return 0, fmt.Errorf("cannot find line boundary: %v", err)
+	}
+}
+
+// readChunk reads the chunk content
+func readChunk(file io.ReaderAt, chunk model.Chunk) (string, error) {
+	buf := make([]byte, chunk.Length)
+	if _, err := file.ReadAt(buf, chunk.Offset); err != nil && err != io.EOF {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// splitStream cuts a stream that can't be read at offsets into chunks of
+// about chunkSize bytes on the fly. Like planChunks it ends every chunk
+// except the last one right after a newline. Chunks are passed to yield
+// in order; an error returned by yield stops splitting
+func splitStream(r io.Reader, chunkSize int64, yield func(index int, data string) error) error {
+	br := bufio.NewReader(r)
+	buf := make([]byte, chunkSize)
+
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(br, buf)
+		switch err {
+		case nil:
+		case io.EOF:
+			return nil
+		case io.ErrUnexpectedEOF:
+			return yield(index, string(buf[:n]))
+		default:
+			return err
+		}
+
+		data := buf[:n]
+		if data[n-1] != '\n' {
+			rest, err := br.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			data = append(data, rest...)
+		}
+
+		if err := yield(index, string(data)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanChunks(t *testing.T) {
+	content := "first line\nsecond\n\nfourth line is long\nfifth"
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, chunkSize := range []int64{1, 5, 11, 12, 100} {
+		chunks, err := planChunks(file, int64(len(content)), chunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sb strings.Builder
+		for i, chunk := range chunks {
+			data, err := readChunk(file, chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if chunk.Index != i {
+				t.Errorf("chunk size %d: chunk %d has index %d", chunkSize, i, chunk.Index)
+			}
+			if i < len(chunks)-1 && !strings.HasSuffix(data, "\n") {
+				t.Errorf("chunk size %d: chunk %d %q does not end at a line boundary", chunkSize, i, data)
+			}
+			sb.WriteString(data)
+		}
+
+		if sb.String() != content {
+			t.Errorf("chunk size %d: chunks do not cover the file: %q", chunkSize, sb.String())
+		}
+	}
+}
+
+func TestSplitStream(t *testing.T) {
+	content := "first line\nsecond\n\nfourth line is long\nfifth"
+
+	for _, chunkSize := range []int64{1, 5, 11, 12, 100} {
+		var sb strings.Builder
+		next := 0
+
+		err := splitStream(strings.NewReader(content), chunkSize, func(index int, data string) error {
+			if index != next {
+				t.Errorf("chunk size %d: got chunk %d, want %d", chunkSize, index, next)
+			}
+			next++
+			if data == "" {
+				t.Errorf("chunk size %d: chunk %d is empty", chunkSize, index)
+			}
+			if !strings.HasSuffix(data, "\n") && sb.Len()+len(data) != len(content) {
+				t.Errorf("chunk size %d: chunk %d %q does not end at a line boundary", chunkSize, index, data)
+			}
+			sb.WriteString(data)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sb.String() != content {
+			t.Errorf("chunk size %d: chunks do not cover the stream: %q", chunkSize, sb.String())
+		}
+	}
+}
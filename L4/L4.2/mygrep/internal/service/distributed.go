@@ -0,0 +1,305 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/input"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// processFileDistributed processes a file in distributed mode
+func (s *GrepService) processFileDistributed(filename string) error {
+	fmt.Printf("Processing file %s in distributed mode\n", filename)
+
+	if s.dispatcher == nil {
+		return s.processFile(filename)
+	}
+
+	// Determine number of servers (current + live peers)
+	numServers := s.dispatcher.Servers()
+	if numServers == 1 {
+		// Only one server, process locally
+		return s.processFile(filename)
+	}
+
+	// Split file and distribute jobs
+	return s.distributeAndProcess(filename, numServers)
+}
+
+// distributeAndProcess splits the input into chunks, sends them to servers
+// and prints merged results
+func (s *GrepService) distributeAndProcess(name string, numServers int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// jobs are produced while servers process earlier ones,
+	// so only chunks in flight are kept in memory
+	jobs := make(chan model.Job)
+	produced := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		produced <- s.produceJobs(ctx, name, numServers, jobs)
+	}()
+
+	results, err := s.dispatcher.SendJobsToPeers(ctx, jobs)
+	cancel()
+	if produceErr := <-produced; produceErr != nil && produceErr != context.Canceled {
+		return produceErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.printMergedResults(name, results)
+}
+
+// produceJobs creates a job per chunk of the input. Plain files and URLs
+// supporting range requests are split at offsets: with a shared filesystem
+// or for URLs jobs carry only a reference and servers read their chunks
+// themselves, otherwise the chunk data is sent with the job. Compressed
+// inputs are decompressed here and cut into chunks on the fly
+func (s *GrepService) produceJobs(ctx context.Context, name string, numServers int, jobs chan<- model.Job) error {
+	send := func(job model.Job) error {
+		select {
+		case jobs <- job:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	file, err := input.OpenAt(ctx, name)
+	if errors.Is(err, input.ErrNotSeekable) {
+		return s.produceStreamJobs(ctx, name, send)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot open file %s: %v", name, err)
+	}
+	defer file.Close()
+
+	chunkSize := s.config.ChunkSize
+	if chunkSize <= 0 {
+		// at least one chunk per server
+		chunkSize = file.Size()/int64(numServers) + 1
+	}
+
+	chunks, err := planChunks(file, file.Size(), chunkSize)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("File split into %d chunks for %d servers\n", len(chunks), numServers)
+
+	path := name
+	if !input.IsURL(name) {
+		if path, err = filepath.Abs(name); err != nil {
+			return err
+		}
+	}
+
+	for _, chunk := range chunks {
+		job := s.newJob(name, chunk.Index)
+
+		if s.config.SharedFS || input.IsURL(name) {
+			job.File = &model.FileRef{
+				Path:   path,
+				Offset: chunk.Offset,
+				Length: chunk.Length,
+			}
+		} else {
+			job.Data, err = readChunk(file, chunk)
+			if err != nil {
+				return fmt.Errorf("cannot read chunk %d: %v", chunk.Index, err)
+			}
+		}
+
+		if err := send(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// produceStreamJobs decompresses the input and sends its chunks with jobs.
+// The size of decompressed data is unknown, so without a configured chunk
+// size defaultStreamChunkSize is used
+func (s *GrepService) produceStreamJobs(ctx context.Context, name string, send func(model.Job) error) error {
+	reader, err := input.Open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("cannot open file %s: %v", name, err)
+	}
+	defer reader.Close()
+
+	chunkSize := s.config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultStreamChunkSize
+	}
+
+	fmt.Printf("Streaming %s in chunks of %d bytes\n", name, chunkSize)
+
+	return splitStream(reader, chunkSize, func(index int, data string) error {
+		job := s.newJob(name, index)
+		job.Data = data
+		return send(job)
+	})
+}
+
+// newJob creates a job for a chunk of the input
+func (s *GrepService) newJob(name string, chunk int) model.Job {
+	return model.Job{
+		ID:        fmt.Sprintf("%s-%s-%d", s.config.ServerID, path.Base(filepath.ToSlash(name)), chunk),
+		ServerID:  s.config.ServerID,
+		Patterns:  s.config.Patterns,
+		Chunk:     chunk,
+		Flags:     s.config.Flags,
+		CreatedAt: time.Now(),
+	}
+}
+
+// printMergedResults prints results ordered by chunks, converting line numbers
+// local to a chunk into line numbers of the whole file. Context lines sent
+// by neighbouring chunks complete the context of matches at chunk boundaries
+func (s *GrepService) printMergedResults(filename string, results []*model.JobResult) error {
+	lineOffset := 0
+	matches := 0
+
+	filter := newContextFilter(s.config.Flags, func(result model.GrepResult, separator bool) {
+		s.printResult(filename, &result, separator)
+	})
+
+	for _, result := range results {
+		if !result.Success {
+			return fmt.Errorf("job %s failed on server %s: %s", result.JobID, result.ServerID, result.Error)
+		}
+
+		for _, match := range result.Matches {
+			match.LineNumber += lineOffset
+			if !match.Context {
+				matches++
+			}
+			if s.config.Flags.Count {
+				continue
+			}
+
+			filter.add(match)
+		}
+
+		lineOffset += result.Processed
+	}
+
+	if s.config.Flags.Count {
+		if s.showNames {
+			fmt.Fprintf(s.config.Output, "%s:", filename)
+		}
+		fmt.Fprintf(s.config.Output, "%d\n", matches)
+	}
+
+	return nil
+}
+
+// ExecuteJob executes a job on this server and returns its result.
+// Besides matches and their context the result contains the first -A and
+// the last -B lines of the chunk: they may be context of matches in the
+// neighbouring chunks. The job stops when ctx is canceled
+func (s *GrepService) ExecuteJob(ctx context.Context, job model.Job) *model.JobResult {
+	fmt.Printf("Executing job %s: chunk=%d, patterns=%q\n", job.ID, job.Chunk, job.Patterns)
+
+	s.runningJobs.Add(1)
+	defer s.runningJobs.Add(-1)
+
+	result := &model.JobResult{
+		JobID:    job.ID,
+		ServerID: s.config.ServerID,
+		Chunk:    job.Chunk,
+	}
+	fail := func(err error) *model.JobResult {
+		result.Error = err.Error()
+		result.CompletedAt = time.Now()
+		return result
+	}
+
+	m, err := s.matcherFor(job.Patterns, job.Flags)
+	if err != nil {
+		return fail(err)
+	}
+
+	var reader io.Reader
+	if job.File != nil {
+		rc, err := input.OpenRange(ctx, job.File.Path, job.File.Offset, job.File.Length)
+		if err != nil {
+			return fail(err)
+		}
+		defer rc.Close()
+
+		reader = rc
+	} else {
+		reader = strings.NewReader(job.Data)
+	}
+
+	flags := job.Flags
+	withContext := contextEnabled(flags)
+
+	matches := make([]model.GrepResult, 0)
+	filter := newContextFilter(flags, func(r model.GrepResult, _ bool) {
+		matches = append(matches, r)
+	})
+	var head, tail []model.GrepResult
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		if lineNumber%1024 == 0 && ctx.Err() != nil {
+			return fail(ctx.Err())
+		}
+
+		lineNumber++
+		line := scanner.Text()
+
+		results, selected := selectLine(m, flags, line, lineNumber)
+		for _, r := range results {
+			filter.add(r)
+		}
+		if selected || !withContext {
+			continue
+		}
+
+		r := model.GrepResult{LineNumber: lineNumber, Line: line, Context: true}
+		filter.add(r)
+		if lineNumber <= flags.After {
+			head = append(head, r)
+		}
+		if flags.Before > 0 {
+			if len(tail) == flags.Before {
+				tail = append(tail[:0], tail[1:]...)
+			}
+			tail = append(tail, r)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fail(err)
+	}
+
+	// only the last -B lines of the chunk can be context of the next chunk
+	tail = slices.DeleteFunc(tail, func(r model.GrepResult) bool {
+		return r.LineNumber <= lineNumber-flags.Before
+	})
+
+	result.Matches = mergeContext(matches, head, tail)
+	result.Processed = lineNumber
+	result.CompletedAt = time.Now()
+	result.Success = true
+
+	return result
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/matcher"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// selectLine reports whether the line is selected and returns its results:
+// one per match with -o, one per selected line otherwise
+func selectLine(m *matcher.Matcher, flags model.GrepFlags, line string, lineNumber int) ([]model.GrepResult, bool) {
+	if !m.Match(line) {
+		return nil, false
+	}
+
+	spans := m.FindAll(line)
+
+	if flags.OnlyMatching && !flags.Count && !flags.InvertMatch {
+		results := make([]model.GrepResult, 0, len(spans))
+		for _, span := range spans {
+			match := line[span.Start:span.End]
+			results = append(results, model.GrepResult{LineNumber: lineNumber, Line: match, Match: match})
+		}
+		return results, true
+	}
+
+	match := line
+	if len(spans) > 0 {
+		match = line[spans[0].Start:spans[0].End]
+	}
+	return []model.GrepResult{{LineNumber: lineNumber, Line: line, Match: match}}, true
+}
+
+// contextEnabled reports whether lines around matches are printed
+func contextEnabled(flags model.GrepFlags) bool {
+	return (flags.Before > 0 || flags.After > 0) && !flags.Count && !flags.OnlyMatching
+}
+
+// contextFilter passes matching lines and lines within -B/-A of them.
+// Lines are added in increasing order of line numbers. Some lines may be
+// missing, as long as every line that can be printed as context is added
+type contextFilter struct {
+	before, after int
+	// preceding lines that may become before-context
+	pending []model.GrepResult
+	// last line of after-context of the latest match
+	afterUntil int
+	// last emitted line number, 0 before the first one
+	last int
+	// emit gets selected results; separator is set when there is
+	// a gap between the result and the previous one
+	emit func(result model.GrepResult, separator bool)
+}
+
+func newContextFilter(flags model.GrepFlags, emit func(model.GrepResult, bool)) *contextFilter {
+	f := &contextFilter{emit: emit}
+	if contextEnabled(flags) {
+		f.before = flags.Before
+		f.after = flags.After
+	}
+	return f
+}
+
+// add adds a matching result or, with Context set, a non-matching line
+func (f *contextFilter) add(result model.GrepResult) {
+	if !result.Context {
+		for _, p := range f.pending {
+			if p.LineNumber >= result.LineNumber-f.before && p.LineNumber > f.last {
+				f.output(p)
+			}
+		}
+		f.pending = f.pending[:0]
+
+		f.output(result)
+		f.afterUntil = result.LineNumber + f.after
+		return
+	}
+
+	if result.LineNumber <= f.afterUntil {
+		f.output(result)
+		return
+	}
+
+	if f.before > 0 {
+		if len(f.pending) == f.before {
+			f.pending = append(f.pending[:0], f.pending[1:]...)
+		}
+		f.pending = append(f.pending, result)
+	}
+}
+
+func (f *contextFilter) output(result model.GrepResult) {
+	separator := (f.before > 0 || f.after > 0) && f.last > 0 && result.LineNumber > f.last+1
+	f.last = result.LineNumber
+	f.emit(result, separator)
+}
+
+// mergeContext adds lines the coordinator may need as context of matches
+// in neighbouring chunks to results of a chunk. Results are sorted by line
+func mergeContext(results []model.GrepResult, lines ...[]model.GrepResult) []model.GrepResult {
+	present := make(map[int]bool, len(results))
+	for _, r := range results {
+		present[r.LineNumber] = true
+	}
+
+	for _, group := range lines {
+		for _, r := range group {
+			if !present[r.LineNumber] {
+				present[r.LineNumber] = true
+				results = append(results, r)
+			}
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b model.GrepResult) int {
+		return a.LineNumber - b.LineNumber
+	})
+	return results
+}
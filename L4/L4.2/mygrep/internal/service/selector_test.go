@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// filterLines runs lines through a context filter and returns the output
+// in grep format. Lines starting with '*' are matches, absent lines are skipped
+func filterLines(flags model.GrepFlags, lines map[int]string, last int) string {
+	var sb strings.Builder
+	f := newContextFilter(flags, func(r model.GrepResult, separator bool) {
+		if separator {
+			sb.WriteString("--\n")
+		}
+		delim := ":"
+		if r.Context {
+			delim = "-"
+		}
+		fmt.Fprintf(&sb, "%d%s%s\n", r.LineNumber, delim, r.Line)
+	})
+
+	for n := 1; n <= last; n++ {
+		line, ok := lines[n]
+		if !ok {
+			continue
+		}
+		match := strings.HasPrefix(line, "*")
+		f.add(model.GrepResult{LineNumber: n, Line: line, Context: !match})
+	}
+	return sb.String()
+}
+
+func TestContextFilter(t *testing.T) {
+	all := map[int]string{1: "a", 2: "b", 3: "*c", 4: "d", 5: "e", 6: "f", 7: "g", 8: "*h", 9: "i", 10: "*j"}
+
+	tests := []struct {
+		name     string
+		flags    model.GrepFlags
+		lines    map[int]string
+		expected string
+	}{
+		{
+			name:     "no context",
+			flags:    model.GrepFlags{},
+			lines:    all,
+			expected: "3:*c\n8:*h\n10:*j\n",
+		},
+		{
+			name:     "before and after",
+			flags:    model.GrepFlags{Before: 1, After: 1},
+			lines:    all,
+			expected: "2-b\n3:*c\n4-d\n--\n7-g\n8:*h\n9-i\n10:*j\n",
+		},
+		{
+			name:     "overlapping context is not repeated",
+			flags:    model.GrepFlags{Before: 3, After: 3},
+			lines:    all,
+			expected: "1-a\n2-b\n3:*c\n4-d\n5-e\n6-f\n7-g\n8:*h\n9-i\n10:*j\n",
+		},
+		{
+			// lines far from matches may be missing, as in merged chunk results
+			name:     "missing lines",
+			flags:    model.GrepFlags{Before: 1, After: 1},
+			lines:    map[int]string{2: "b", 3: "*c", 4: "d", 7: "g", 8: "*h", 9: "i", 10: "*j"},
+			expected: "2-b\n3:*c\n4-d\n--\n7-g\n8:*h\n9-i\n10:*j\n",
+		},
+		{
+			name:     "context disabled by count",
+			flags:    model.GrepFlags{Before: 1, Count: true},
+			lines:    all,
+			expected: "3:*c\n8:*h\n10:*j\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterLines(tt.flags, tt.lines, 10); got != tt.expected {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestExecuteJobContextAcrossChunks checks that merged results of chunks
+// print the same context as a search over the whole input
+func TestExecuteJobContextAcrossChunks(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 40; i++ {
+		if i%7 == 0 {
+			lines = append(lines, fmt.Sprintf("hit %d", i))
+		} else {
+			lines = append(lines, fmt.Sprintf("line %d", i))
+		}
+	}
+	input := strings.Join(lines, "\n") + "\n"
+
+	flags := model.GrepFlags{LineNumber: true, Before: 2, After: 3}
+
+	run := func(chunks []string) string {
+		var out strings.Builder
+		s := NewGrepService(&model.Config{Patterns: []string{"hit"}, Flags: flags, Output: &out})
+
+		var results []*model.JobResult
+		for i, data := range chunks {
+			job := model.Job{ID: fmt.Sprint(i), Chunk: i, Patterns: []string{"hit"}, Flags: flags, Data: data}
+			results = append(results, s.ExecuteJob(t.Context(), job))
+		}
+
+		if err := s.printMergedResults("input", results); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	expected := run([]string{input})
+
+	for _, size := range []int{1, 2, 3, 5, 11} {
+		var chunks []string
+		for i := 0; i < len(lines); i += size {
+			chunk := lines[i:min(i+size, len(lines))]
+			chunks = append(chunks, strings.Join(chunk, "\n")+"\n")
+		}
+
+		if got := run(chunks); got != expected {
+			t.Errorf("chunks of %d lines:\n%s\nwant:\n%s", size, got, expected)
+		}
+	}
+}
@@ -1,228 +1,240 @@
 package transport
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"net"
 	"sync"
 	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/protocol"
 )
 
-// Client represents a network client for peer communication
+// defaultJobTimeout is used when the configuration has no timeout
+const defaultJobTimeout = 30 * time.Second
+
+// defaultQuorumTimeout is used when the configuration has no quorum timeout
+const defaultQuorumTimeout = 60 * time.Second
+
+// errConnClosed is returned for requests of a closed peer connection
+var errConnClosed = errors.New("connection closed")
+
+// Client represents a network client for peer communication.
+// It keeps one connection per peer; concurrent requests share it
 type Client struct {
-	config *model.Config
+	config       *model.Config
+	localHandler JobHandler
+	peers        func() []string
+	mu           sync.Mutex
+	conns        map[string]*peerConn
 }
 
-// NewClient creates a new network client
-func NewClient(config *model.Config) *Client {
+// NewClient creates a new network client.
+// Jobs assigned to the current server are executed by localHandler
+func NewClient(config *model.Config, localHandler JobHandler) *Client {
 	return &Client{
-		config: config,
+		config:       config,
+		localHandler: localHandler,
+		peers:        func() []string { return config.Peers },
+		conns:        make(map[string]*peerConn),
 	}
 }
 
+// SetPeerSource sets the function returning peers jobs are sent to.
+// By default the configured peers are used
+func (c *Client) SetPeerSource(peers func() []string) {
+	c.peers = peers
+}
+
+// Servers returns the number of servers jobs are sent to, the current one included
+func (c *Client) Servers() int {
+	return 1 + len(c.peers())
+}
+
 // SendJobToPeer sends a job to a specific peer and returns the result
-func (c *Client) SendJobToPeer(peerAddr string, job map[string]interface{}) (*model.JobResult, error) {
-	conn, err := net.Dial("tcp", peerAddr)
+func (c *Client) SendJobToPeer(ctx context.Context, peerAddr string, job model.Job) (*model.JobResult, error) {
+	pc, err := c.peer(ctx, peerAddr)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
-	// Create job request message
-	msg := model.Message{
-		Type:      "job_request",
-		From:      c.config.ServerID,
-		To:        peerAddr,
-		Data:      job,
-		Timestamp: time.Now(),
-	}
-
-	// Send message
-	encoder := json.NewEncoder(conn)
-	if err := encoder.Encode(msg); err != nil {
+	st, err := pc.openStream()
+	if err != nil {
 		return nil, err
 	}
+	defer pc.closeStream(st)
 
-	// Read response
-	var response model.Message
-	decoder := json.NewDecoder(conn)
-	if err := decoder.Decode(&response); err != nil {
+	if err := pc.write(st.id, &protocol.JobRequest{Job: job}); err != nil {
+		pc.close(err)
 		return nil, err
 	}
 
-	if response.Type != "job_response" {
-		return nil, fmt.Errorf("unexpected response from %s: type=%s", peerAddr, response.Type)
-	}
+	matches := make([]model.GrepResult, 0)
+	for {
+		select {
+		case msg := <-st.messages:
+			switch m := msg.(type) {
+			case *protocol.ResultChunk:
+				matches = append(matches, m.Matches...)
+			case *protocol.JobResult:
+				result := m.Result
+				result.Matches = matches
+				if result.ServerID == "" {
+					result.ServerID = peerAddr
+				}
+				return &result, nil
+			case *protocol.Error:
+				return nil, fmt.Errorf("peer %s: %s", peerAddr, m.Message)
+			default:
+				return nil, fmt.Errorf("unexpected %s message from %s", msg.Type(), peerAddr)
+			}
 
-	// Convert response to JobResult
-	return c.convertToJobResult(response.Data, peerAddr)
-}
+		case <-pc.done:
+			return nil, pc.err
 
-// SendJobsToPeers sends jobs to multiple peers with quorum tracking
-func (c *Client) SendJobsToPeers(jobs []map[string]interface{}) (*QuorumResult, error) {
-	numPeers := len(c.config.Peers)
-	if numPeers == 0 {
-		return nil, fmt.Errorf("no peers configured")
+		case <-ctx.Done():
+			pc.write(st.id, &protocol.Cancel{})
+			return nil, ctx.Err()
+		}
 	}
+}
 
-	// Create quorum (all servers: current + peers)
-	totalServers := numPeers + 1
-	quorum := model.NewQuorumStatus(totalServers)
-
-	fmt.Printf("Starting quorum: %d servers, need %d votes\n",
-		totalServers, quorum.RequiredVotes)
-
-	var wg sync.WaitGroup
-	errors := make(chan error, totalServers)
-
-	// Send jobs to peers in parallel
-	for i, peer := range c.config.Peers {
-		wg.Add(1)
-		go func(peerAddr string, jobIndex int) {
-			defer wg.Done()
-
-			result, err := c.SendJobToPeer(peerAddr, jobs[jobIndex])
-			if err != nil {
-				errors <- fmt.Errorf("error sending job to peer %s: %v", peerAddr, err)
-				return
-			}
-
-			// Add result to quorum
-			if quorum.AddResult(result) {
-				fmt.Println("Quorum achieved!")
-			}
-		}(peer, i)
+// SendHeartbeat sends a heartbeat to the peer and returns its answer
+func (c *Client) SendHeartbeat(ctx context.Context, peerAddr string, hb model.Heartbeat) (*model.Heartbeat, error) {
+	pc, err := c.peer(ctx, peerAddr)
+	if err != nil {
+		return nil, err
 	}
 
-	// Wait for all jobs to complete or quorum to be achieved
-	go func() {
-		wg.Wait()
-		close(errors)
-	}()
-
-	// Wait for quorum or completion
-	return c.waitForQuorum(quorum, errors)
-}
+	st, err := pc.openStream()
+	if err != nil {
+		return nil, err
+	}
+	defer pc.closeStream(st)
 
-// ConnectToPeers connects to all configured peers
-func (c *Client) ConnectToPeers() error {
-	for _, peer := range c.config.Peers {
-		conn, err := net.Dial("tcp", peer)
-		if err != nil {
-			fmt.Printf("Failed to connect to %s: %v\n", peer, err)
-			continue
-		}
+	if err := pc.write(st.id, &protocol.Heartbeat{Heartbeat: hb}); err != nil {
+		pc.close(err)
+		return nil, err
+	}
 
-		// Send status request
-		msg := model.Message{
-			Type:      "status_request",
-			From:      c.config.ServerID,
-			To:        peer,
-			Data:      nil,
-			Timestamp: time.Now(),
+	select {
+	case msg := <-st.messages:
+		reply, ok := msg.(*protocol.Heartbeat)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s message from %s", msg.Type(), peerAddr)
 		}
-
-		json.NewEncoder(conn).Encode(msg)
-		conn.Close()
+		return &reply.Heartbeat, nil
+	case <-pc.done:
+		return nil, pc.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	return nil
 }
 
-// convertToJobResult converts response data to JobResult
-func (c *Client) convertToJobResult(data interface{}, serverID string) (*model.JobResult, error) {
-	dataMap, ok := data.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid result data format")
-	}
-
-	jobID, _ := dataMap["job_id"].(string)
-	processed, _ := dataMap["processed"].(float64)
-	success, _ := dataMap["success"].(bool)
-	completedAt, _ := dataMap["completed_at"].(time.Time)
+// SendJobsToPeers distributes jobs between the current server and peers.
+// Every job is replicated to several servers and its result is accepted
+// only when enough replicas agree on it (see runChunk).
+// Jobs are taken from the channel while servers have capacity, so the
+// sender may produce them lazily; their Chunk fields must be 0, 1, 2...
+// Results are returned in the order of chunks
+func (c *Client) SendJobsToPeers(ctx context.Context, jobs <-chan model.Job) ([]*model.JobResult, error) {
+	servers := newServerPool(c.config.ServerID, c.localHandler, c.peers(), c.SendJobToPeer)
 
-	// Convert matches (placeholder - currently empty array)
-	matches := make([]model.GrepResult, 0)
-
-	result := &model.JobResult{
-		JobID:       jobID,
-		ServerID:    serverID,
-		Matches:     matches,
-		Processed:   int(processed),
-		Success:     success,
-		CompletedAt: completedAt,
+	replicas := max(c.config.Replicas, 1)
+	if replicas > len(servers.servers) {
+		return nil, fmt.Errorf("%d replicas requested, but only %d servers available", replicas, len(servers.servers))
 	}
 
-	if !success {
-		result.Error, _ = dataMap["error"].(string)
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	return result, nil
-}
+	var mu sync.Mutex
+	var results []*model.JobResult
 
-// waitForQuorum waits for quorum achievement or completion
-func (c *Client) waitForQuorum(quorum *model.QuorumStatus, errors <-chan error) (*QuorumResult, error) {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
+	var errOnce sync.Once
+	var runErr error
 
-	for {
-		select {
-		case <-ticker.C:
-			if quorum.IsCompleted() {
-				fmt.Println("Quorum achieved, combining results...")
-				return c.combineResults(quorum.GetResults())
+	// every server handles about one chunk at a time
+	var wg sync.WaitGroup
+	for range len(servers.servers) {
+		wg.Go(func() {
+			for job := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+
+				result, err := c.runChunk(ctx, servers, job, replicas)
+				if err != nil {
+					errOnce.Do(func() {
+						runErr = err
+						cancel()
+					})
+					return
+				}
+
+				mu.Lock()
+				if job.Chunk >= len(results) {
+					results = append(results, make([]*model.JobResult, job.Chunk+1-len(results))...)
+				}
+				results[job.Chunk] = result
+				mu.Unlock()
 			}
-		case err := <-errors:
-			fmt.Printf("Execution error: %v\n", err)
-		case <-time.After(30 * time.Second): // 30 second timeout
-			fmt.Println("Quorum timeout")
-			return c.combineResults(quorum.GetResults())
-		}
+		})
 	}
-}
 
-// combineResults combines results from all servers
-func (c *Client) combineResults(results map[string]*model.JobResult) (*QuorumResult, error) {
-	fmt.Printf("Combining results from %d servers\n", len(results))
+	wg.Wait()
 
-	totalMatches := 0
-	totalProcessed := 0
-	allSuccessful := true
+	if runErr != nil {
+		return nil, runErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result == nil {
+			return nil, fmt.Errorf("no job for chunk %d", i)
+		}
+	}
 
-	for serverID, result := range results {
-		fmt.Printf("Server %s: processed %d lines, found %d matches\n",
-			serverID, result.Processed, len(result.Matches))
+	return results, nil
+}
 
-		totalProcessed += result.Processed
-		totalMatches += len(result.Matches)
+// Close closes all peer connections
+func (c *Client) Close() error {
+	c.mu.Lock()
+	conns := c.conns
+	c.conns = make(map[string]*peerConn)
+	c.mu.Unlock()
 
-		if !result.Success {
-			allSuccessful = false
-			fmt.Printf("Error on server %s: %s\n", serverID, result.Error)
-		}
+	for _, pc := range conns {
+		pc.close(errConnClosed)
 	}
+	return nil
+}
 
-	quorumResult := &QuorumResult{
-		TotalMatches:   totalMatches,
-		TotalProcessed: totalProcessed,
-		AllSuccessful:  allSuccessful,
-		Results:        results,
+// peer returns the connection to the peer, dialing it if there is none
+func (c *Client) peer(ctx context.Context, addr string) (*peerConn, error) {
+	c.mu.Lock()
+	pc, ok := c.conns[addr]
+	c.mu.Unlock()
+	if ok && !pc.closed() {
+		return pc, nil
 	}
 
-	if allSuccessful {
-		fmt.Printf("Success: processed %d lines, found %d matches\n", totalProcessed, totalMatches)
-	} else {
-		fmt.Printf("Partial success: processed %d lines, found %d matches\n", totalProcessed, totalMatches)
+	pc, err := dialPeer(ctx, addr, c.config.ServerID)
+	if err != nil {
+		return nil, err
 	}
 
-	return quorumResult, nil
-}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-// QuorumResult represents the combined result from quorum processing
-type QuorumResult struct {
-	TotalMatches   int
-	TotalProcessed int
-	AllSuccessful  bool
-	Results        map[string]*model.JobResult
+	// another request could have connected meanwhile
+	if current, ok := c.conns[addr]; ok && !current.closed() {
+		pc.close(errConnClosed)
+		return current, nil
+	}
+	c.conns[addr] = pc
+	return pc, nil
 }
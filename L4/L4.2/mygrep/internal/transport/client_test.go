@@ -0,0 +1,229 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/service"
+)
+
+// handlerFunc adapts a function to JobHandler
+type handlerFunc func(ctx context.Context, job model.Job) (*model.JobResult, error)
+
+func (f handlerFunc) HandleJobRequest(ctx context.Context, job model.Job) (*model.JobResult, error) {
+	return f(ctx, job)
+}
+
+// startServer starts a server on a random port and returns its address
+func startServer(t *testing.T, handler JobHandler) string {
+	t.Helper()
+
+	s := NewServer(&model.Config{ServerID: "peer", Port: "0"}, handler)
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Stop() })
+
+	return s.listener.Addr().String()
+}
+
+func TestSendJobToPeer(t *testing.T) {
+	// big enough to be streamed in several result chunks
+	const lines = 5000
+	line := strings.Repeat("x", 100)
+
+	addr := startServer(t, handlerFunc(func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+		result := &model.JobResult{JobID: job.ID, Chunk: job.Chunk, Processed: lines, Success: true}
+		for i := range lines {
+			result.Matches = append(result.Matches, model.GrepResult{LineNumber: i + 1, Line: line, Match: job.Patterns[0]})
+		}
+		return result, nil
+	}))
+
+	client := NewClient(&model.Config{ServerID: "coordinator"}, nil)
+	defer client.Close()
+
+	// concurrent requests share one connection
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Go(func() {
+			job := model.Job{ID: fmt.Sprintf("job-%d", i), Chunk: i, Patterns: []string{"x"}}
+			result, err := client.SendJobToPeer(context.Background(), addr, job)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.JobID != job.ID || result.Chunk != i || len(result.Matches) != lines {
+				t.Errorf("unexpected result %s: chunk %d, %d matches", result.JobID, result.Chunk, len(result.Matches))
+			}
+			if result.Matches[lines-1].LineNumber != lines {
+				t.Errorf("matches are out of order")
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(client.conns) != 1 {
+		t.Errorf("expected 1 connection, got %d", len(client.conns))
+	}
+}
+
+func TestSendJobToPeerCancel(t *testing.T) {
+	canceled := make(chan struct{})
+
+	addr := startServer(t, handlerFunc(func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}))
+
+	client := NewClient(&model.Config{ServerID: "coordinator"}, nil)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.SendJobToPeer(ctx, addr, model.Job{ID: "slow"}); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("job was not canceled on the peer")
+	}
+
+	// the connection is still usable after a canceled request
+	if _, err := client.SendHeartbeat(context.Background(), addr, model.Heartbeat{}); err != nil {
+		t.Errorf("heartbeat after cancel: %v", err)
+	}
+}
+
+// recorder wraps a handler and records chunks it received. Jobs are held
+// until the given number of servers got one, so every server takes part
+type recorder struct {
+	handler JobHandler
+	started *startGate
+	mu      sync.Mutex
+	chunks  []int
+}
+
+func (r *recorder) HandleJobRequest(ctx context.Context, job model.Job) (*model.JobResult, error) {
+	r.mu.Lock()
+	r.chunks = append(r.chunks, job.Chunk)
+	first := len(r.chunks) == 1
+	r.mu.Unlock()
+
+	if first {
+		if err := r.started.arrive(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return r.handler.HandleJobRequest(ctx, job)
+}
+
+func (r *recorder) received() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.chunks)
+}
+
+// startGate is passed when n servers arrived
+type startGate struct {
+	mu      sync.Mutex
+	n       int
+	entered chan struct{}
+}
+
+func newStartGate(n int) *startGate {
+	return &startGate{n: n, entered: make(chan struct{})}
+}
+
+func (g *startGate) arrive(ctx context.Context) error {
+	g.mu.Lock()
+	g.n--
+	if g.n == 0 {
+		close(g.entered)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-g.entered:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// grepHandler returns a handler running jobs with a grep service
+func grepHandler(serverID string) JobHandler {
+	return NewJobHandlerAdapter(service.NewGrepService(&model.Config{ServerID: serverID}))
+}
+
+func TestSendJobsToPeers(t *testing.T) {
+	const chunks = 12
+
+	gate := newStartGate(3)
+	local := &recorder{handler: grepHandler("local"), started: gate}
+	first := &recorder{handler: grepHandler("first"), started: gate}
+	second := &recorder{handler: grepHandler("second"), started: gate}
+
+	config := &model.Config{
+		ServerID: "local",
+		Peers:    []string{startServer(t, first), startServer(t, second)},
+		Timeout:  5 * time.Second,
+	}
+	client := NewClient(config, local)
+	defer client.Close()
+
+	jobs := make(chan model.Job)
+	go func() {
+		defer close(jobs)
+		for i := range chunks {
+			jobs <- model.Job{
+				ID:       fmt.Sprintf("job-%d", i),
+				Chunk:    i,
+				Patterns: []string{"match"},
+				Data:     fmt.Sprintf("skip\nmatch %d\nskip\n", i),
+			}
+		}
+	}()
+
+	results, err := client.SendJobsToPeers(context.Background(), jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != chunks {
+		t.Fatalf("expected %d results, got %d", chunks, len(results))
+	}
+	for i, result := range results {
+		expected := fmt.Sprintf("match %d", i)
+		if result.Chunk != i || result.Processed != 3 || len(result.Matches) != 1 || result.Matches[0].Line != expected {
+			t.Errorf("result %d: chunk %d, %d lines, matches %+v", i, result.Chunk, result.Processed, result.Matches)
+		}
+	}
+
+	// every chunk was run exactly once
+	var received []int
+	for name, r := range map[string]*recorder{"local": local, "first": first, "second": second} {
+		if len(r.received()) == 0 {
+			t.Errorf("server %s got no jobs", name)
+		}
+		received = append(received, r.received()...)
+	}
+	slices.Sort(received)
+	expected := make([]int, chunks)
+	for i := range expected {
+		expected[i] = i
+	}
+	if !slices.Equal(received, expected) {
+		t.Errorf("unexpected chunks received: %v", received)
+	}
+}
@@ -0,0 +1,226 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/service"
+)
+
+// proxy forwards connections to a server. Killing it drops
+// all connections as if the server process died
+type proxy struct {
+	listener net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+	killed   bool
+}
+
+func startProxy(t *testing.T, target string) *proxy {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &proxy{listener: listener}
+	t.Cleanup(p.kill)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			upstream, err := net.Dial("tcp", target)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+
+			p.mu.Lock()
+			if p.killed {
+				conn.Close()
+				upstream.Close()
+			}
+			p.conns = append(p.conns, conn, upstream)
+			p.mu.Unlock()
+
+			go io.Copy(upstream, conn)
+			go io.Copy(conn, upstream)
+		}
+	}()
+
+	return p
+}
+
+func (p *proxy) addr() string {
+	return p.listener.Addr().String()
+}
+
+func (p *proxy) kill() {
+	p.listener.Close()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.killed = true
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+}
+
+// grepFile writes lines to a file and returns the output of a local grep
+func grepFile(t *testing.T, lines []string, config model.Config) (string, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	config.Files = []string{path}
+	config.Output = &out
+	if err := service.NewGrepService(&config).ExecuteGrep(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, out.String()
+}
+
+// distributedGrep runs grep of the file on the local server and peers.
+// Local jobs wait until hold is closed
+func distributedGrep(config model.Config, path string, hold <-chan struct{}) (string, error) {
+	var out bytes.Buffer
+	config.ServerID = "local"
+	config.Files = []string{path}
+	config.Output = &out
+	config.IsDistributed = true
+
+	grep := service.NewGrepService(&config)
+	local := NewJobHandlerAdapter(grep)
+	client := NewClient(&config, handlerFunc(func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+		select {
+		case <-hold:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return local.HandleJobRequest(ctx, job)
+	}))
+	defer client.Close()
+	grep.SetDispatcher(client)
+
+	err := grep.ExecuteDistributedGrep()
+	return out.String(), err
+}
+
+func TestDistributedGrepPeerKilled(t *testing.T) {
+	var lines []string
+	for i := range 200 {
+		if i%7 == 0 {
+			lines = append(lines, fmt.Sprintf("line %d matches", i))
+		} else {
+			lines = append(lines, fmt.Sprintf("line %d", i))
+		}
+	}
+
+	config := model.Config{
+		Patterns:      []string{"matches"},
+		Flags:         model.GrepFlags{LineNumber: true},
+		ChunkSize:     100,
+		Timeout:       5 * time.Second,
+		QuorumTimeout: 5 * time.Second,
+	}
+	path, expected := grepFile(t, lines, config)
+	if strings.Count(expected, "\n") != 29 {
+		t.Fatalf("unexpected local output:\n%s", expected)
+	}
+
+	// the killed peer dies as soon as it gets its first job;
+	// until then the local server is busy, so the peer surely gets one
+	var once sync.Once
+	received := make(chan struct{})
+	killed := startProxy(t, startServer(t, handlerFunc(func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+		once.Do(func() { close(received) })
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})))
+	go func() {
+		<-received
+		killed.kill()
+	}()
+
+	// early chunks of the other peer finish last, so results arrive out of order
+	peer := grepHandler("peer")
+	alive := startServer(t, handlerFunc(func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+		if job.Chunk < 3 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return peer.HandleJobRequest(ctx, job)
+	}))
+
+	config.Peers = []string{killed.addr(), alive}
+	out, err := distributedGrep(config, path, received)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-received:
+	default:
+		t.Fatal("killed peer got no job")
+	}
+	if out != expected {
+		t.Errorf("distributed output differs from local grep\ngot:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+func TestDistributedGrepQuorumNotReached(t *testing.T) {
+	lines := []string{"alpha", "beta", "alpha beta", "gamma"}
+	config := model.Config{
+		Patterns:  []string{"alpha"},
+		ChunkSize: 100,
+		Timeout:   5 * time.Second,
+		Replicas:  2,
+	}
+	path, _ := grepFile(t, lines, config)
+
+	// the only working peer disagrees with the local server, the other one is down
+	divergent := startServer(t, handlerFunc(func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+		return &model.JobResult{
+			JobID:     job.ID,
+			Chunk:     job.Chunk,
+			Matches:   []model.GrepResult{{LineNumber: 1, Line: "forged", Match: "alpha"}},
+			Processed: 1,
+			Success:   true,
+		}, nil
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := listener.Addr().String()
+	listener.Close()
+
+	config.Peers = []string{divergent, down}
+	ready := make(chan struct{})
+	close(ready)
+	out, err := distributedGrep(config, path, ready)
+	if err == nil || !strings.Contains(err.Error(), "quorum not reached for chunk 0") {
+		t.Fatalf("expected quorum error, got %v", err)
+	}
+	if out != "" {
+		t.Errorf("nothing should be printed without quorum, got %q", out)
+	}
+}
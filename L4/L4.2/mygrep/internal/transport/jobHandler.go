@@ -1,6 +1,8 @@
 package transport
 
 import (
+	"context"
+
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/service"
 )
@@ -18,6 +20,6 @@ func NewJobHandlerAdapter(grepService *service.GrepService) JobHandler {
 }
 
 // HandleJobRequest implements JobHandler interface
-func (a *JobHandlerAdapter) HandleJobRequest(job map[string]interface{}) (*model.JobResult, error) {
-	return a.grepService.ExecuteJobLocally(job)
+func (a *JobHandlerAdapter) HandleJobRequest(ctx context.Context, job model.Job) (*model.JobResult, error) {
+	return a.grepService.ExecuteJob(ctx, job), nil
 }
@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/protocol"
+)
+
+// peerConn is a persistent connection to a peer.
+// Every request gets its own stream, messages of the stream are
+// delivered to it by the read loop
+type peerConn struct {
+	addr    string
+	conn    net.Conn
+	writeMu sync.Mutex
+
+	mu         sync.Mutex
+	nextStream uint32
+	streams    map[uint32]*stream
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// stream receives messages of a single request
+type stream struct {
+	id       uint32
+	messages chan protocol.Message
+	done     chan struct{}
+}
+
+// dialPeer connects to the peer and performs the version handshake
+func dialPeer(ctx context.Context, addr, serverID string) (*peerConn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(handshakeTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := protocol.Handshake(conn, serverID); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake with %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	pc := &peerConn{
+		addr:    addr,
+		conn:    conn,
+		streams: make(map[uint32]*stream),
+		done:    make(chan struct{}),
+	}
+	go pc.readLoop()
+
+	return pc, nil
+}
+
+// readLoop dispatches incoming messages to streams until the connection fails
+func (pc *peerConn) readLoop() {
+	reader := bufio.NewReader(pc.conn)
+	for {
+		h, msg, err := protocol.ReadFrame(reader)
+		if err != nil {
+			pc.close(fmt.Errorf("connection to %s lost: %w", pc.addr, err))
+			return
+		}
+
+		if h.Stream == 0 {
+			if m, ok := msg.(*protocol.Error); ok {
+				pc.close(fmt.Errorf("peer %s closed connection: %s", pc.addr, m.Message))
+				return
+			}
+			continue
+		}
+
+		pc.mu.Lock()
+		st, ok := pc.streams[h.Stream]
+		pc.mu.Unlock()
+		if !ok {
+			// the request was canceled
+			continue
+		}
+
+		select {
+		case st.messages <- msg:
+		case <-st.done:
+		}
+	}
+}
+
+// openStream registers a new stream
+func (pc *peerConn) openStream() (*stream, error) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if pc.closed() {
+		return nil, pc.err
+	}
+
+	pc.nextStream++
+	st := &stream{
+		id:       pc.nextStream,
+		messages: make(chan protocol.Message, 16),
+		done:     make(chan struct{}),
+	}
+	pc.streams[st.id] = st
+	return st, nil
+}
+
+// closeStream unregisters the stream; its remaining messages are dropped
+func (pc *peerConn) closeStream(st *stream) {
+	pc.mu.Lock()
+	delete(pc.streams, st.id)
+	pc.mu.Unlock()
+
+	close(st.done)
+}
+
+// write sends a frame; frames of different streams are never interleaved
+func (pc *peerConn) write(stream uint32, msg protocol.Message) error {
+	pc.writeMu.Lock()
+	defer pc.writeMu.Unlock()
+
+	pc.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return protocol.WriteFrame(pc.conn, stream, msg)
+}
+
+// close closes the connection and fails all its requests with err
+func (pc *peerConn) close(err error) {
+	pc.closeOnce.Do(func() {
+		pc.err = err
+		close(pc.done)
+		pc.conn.Close()
+	})
+}
+
+// closed reports whether the connection is closed
+func (pc *peerConn) closed() bool {
+	select {
+	case <-pc.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,209 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+// executeFunc runs a job on a single server
+type executeFunc func(ctx context.Context, job model.Job) (*model.JobResult, error)
+
+// poolServer is a server jobs can be sent to
+type poolServer struct {
+	id       string
+	execute  executeFunc
+	inFlight int
+	failed   bool
+}
+
+// serverPool keeps track of server load and health during a run
+type serverPool struct {
+	mu      sync.Mutex
+	servers []*poolServer
+}
+
+// newServerPool creates a pool of the current server and its peers
+func newServerPool(localID string, local JobHandler, peers []string, send func(context.Context, string, model.Job) (*model.JobResult, error)) *serverPool {
+	pool := &serverPool{}
+
+	pool.servers = append(pool.servers, &poolServer{
+		id: localID,
+		execute: func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+			return local.HandleJobRequest(ctx, job)
+		},
+	})
+
+	for _, peer := range peers {
+		pool.servers = append(pool.servers, &poolServer{
+			id: peer,
+			execute: func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+				return send(ctx, peer, job)
+			},
+		})
+	}
+
+	return pool
+}
+
+// acquire returns the least loaded healthy server not in exclude
+func (p *serverPool) acquire(exclude map[string]bool) *poolServer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *poolServer
+	for _, s := range p.servers {
+		if s.failed || exclude[s.id] {
+			continue
+		}
+		if best == nil || s.inFlight < best.inFlight {
+			best = s
+		}
+	}
+
+	if best != nil {
+		best.inFlight++
+	}
+	return best
+}
+
+// release returns a server to the pool. A failed server gets no more jobs
+func (p *serverPool) release(s *poolServer, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s.inFlight--
+	if failed {
+		s.failed = true
+	}
+}
+
+// replicaReply is the outcome of a job replica
+type replicaReply struct {
+	serverID string
+	result   *model.JobResult
+	err      error
+}
+
+// runChunk sends the job to replicas servers and waits until
+// the required number of them return results with equal content hashes.
+// A failed replica is replaced by another server. Divergent and slow
+// replicas are reported; if quorum can't be reached, an error is returned
+func (c *Client) runChunk(ctx context.Context, pool *serverPool, job model.Job, replicas int) (*model.JobResult, error) {
+	jobTimeout := c.config.Timeout
+	if jobTimeout <= 0 {
+		jobTimeout = defaultJobTimeout
+	}
+	quorumTimeout := c.config.QuorumTimeout
+	if quorumTimeout <= 0 {
+		quorumTimeout = defaultQuorumTimeout
+	}
+
+	quorum := model.NewQuorumStatus(job.Chunk, replicas, c.config.WriteQuorum)
+	if quorum.RequiredVotes > replicas {
+		return nil, fmt.Errorf("write quorum %d is greater than replicas %d", quorum.RequiredVotes, replicas)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, quorumTimeout)
+	defer cancel()
+
+	// every server gets at most one replica, so the buffer never blocks senders
+	replies := make(chan replicaReply, len(pool.servers))
+	used := make(map[string]bool)
+	running := make(map[string]bool)
+	var failed []string
+
+	start := func() bool {
+		server := pool.acquire(used)
+		if server == nil {
+			return false
+		}
+		used[server.id] = true
+		running[server.id] = true
+
+		go func() {
+			jobCtx, jobCancel := context.WithTimeout(ctx, jobTimeout)
+			defer jobCancel()
+
+			result, err := server.execute(jobCtx, job)
+			if err == nil && !result.Success {
+				err = errors.New(result.Error)
+			}
+			if err == nil {
+				result.ServerID = server.id
+			}
+
+			// a replica canceled after quorum is not a server failure
+			pool.release(server, err != nil && ctx.Err() == nil)
+			replies <- replicaReply{serverID: server.id, result: result, err: err}
+		}()
+
+		return true
+	}
+
+	for range replicas {
+		if !start() {
+			break
+		}
+	}
+
+	for len(running) > 0 {
+		select {
+		case reply := <-replies:
+			delete(running, reply.serverID)
+
+			if reply.err != nil {
+				fmt.Printf("Replica of chunk %d failed on server %s: %v\n", job.Chunk, reply.serverID, reply.err)
+				failed = append(failed, reply.serverID)
+				start()
+				continue
+			}
+
+			if !quorum.AddResult(reply.result) {
+				continue
+			}
+
+			result, _ := quorum.AcceptedResult()
+			c.reportQuorum(quorum, running, failed)
+			return result, nil
+
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				c.reportQuorum(quorum, running, failed)
+				return nil, fmt.Errorf("quorum not reached for chunk %d: timeout %v, votes: [%s], slow servers: [%s]",
+					job.Chunk, quorumTimeout, quorum.VotesSummary(), strings.Join(sortedKeys(running), ", "))
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("quorum not reached for chunk %d: %d of %d matching replicas required, votes: [%s], failed servers: [%s]",
+		job.Chunk, quorum.RequiredVotes, replicas, quorum.VotesSummary(), strings.Join(failed, ", "))
+}
+
+// reportQuorum prints replicas that disagreed with the quorum, were too slow or failed
+func (c *Client) reportQuorum(quorum *model.QuorumStatus, running map[string]bool, failed []string) {
+	if divergent := quorum.Divergent(); len(divergent) > 0 {
+		fmt.Printf("Chunk %d: divergent replicas: %s (votes: %s)\n", quorum.Chunk, strings.Join(divergent, ", "), quorum.VotesSummary())
+	}
+	if len(running) > 0 {
+		fmt.Printf("Chunk %d: slow replicas: %s\n", quorum.Chunk, strings.Join(sortedKeys(running), ", "))
+	}
+	if len(failed) > 0 {
+		fmt.Printf("Chunk %d: failed replicas: %s\n", quorum.Chunk, strings.Join(failed, ", "))
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+)
+
+func fakeServer(id string, execute executeFunc) *poolServer {
+	return &poolServer{id: id, execute: execute}
+}
+
+func answer(line string) executeFunc {
+	return func(ctx context.Context, job model.Job) (*model.JobResult, error) {
+		return &model.JobResult{
+			JobID:     job.ID,
+			Chunk:     job.Chunk,
+			Matches:   []model.GrepResult{{LineNumber: 1, Line: line, Match: line}},
+			Processed: 1,
+			Success:   true,
+		}, nil
+	}
+}
+
+func fail(ctx context.Context, job model.Job) (*model.JobResult, error) {
+	return nil, errors.New("connection refused")
+}
+
+func hang(ctx context.Context, job model.Job) (*model.JobResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRunChunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		servers  []*poolServer
+		replicas int
+		quorum   int
+		expected string
+		err      string
+	}{
+		{
+			name:     "all agree",
+			servers:  []*poolServer{fakeServer("a", answer("x")), fakeServer("b", answer("x")), fakeServer("c", answer("x"))},
+			replicas: 3,
+			expected: "x",
+		},
+		{
+			name:     "one divergent",
+			servers:  []*poolServer{fakeServer("a", answer("x")), fakeServer("b", answer("y")), fakeServer("c", answer("x"))},
+			replicas: 3,
+			expected: "x",
+		},
+		{
+			name:     "failed replica replaced",
+			servers:  []*poolServer{fakeServer("a", fail), fakeServer("b", answer("x")), fakeServer("c", answer("x"))},
+			replicas: 2,
+			quorum:   2,
+			expected: "x",
+		},
+		{
+			name:     "no agreement",
+			servers:  []*poolServer{fakeServer("a", answer("x")), fakeServer("b", answer("y"))},
+			replicas: 2,
+			quorum:   2,
+			err:      "quorum not reached for chunk 7",
+		},
+		{
+			name:     "slow replicas",
+			servers:  []*poolServer{fakeServer("a", answer("x")), fakeServer("b", hang), fakeServer("c", hang)},
+			replicas: 3,
+			err:      "quorum not reached for chunk 7: timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{config: &model.Config{
+				Timeout:       time.Second,
+				QuorumTimeout: 50 * time.Millisecond,
+				WriteQuorum:   tt.quorum,
+			}}
+			pool := &serverPool{servers: tt.servers}
+
+			result, err := c.runChunk(context.Background(), pool, model.Job{ID: "job", Chunk: 7}, tt.replicas)
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Matches[0].Line != tt.expected {
+				t.Errorf("accepted %q, want %q", result.Matches[0].Line, tt.expected)
+			}
+		})
+	}
+}
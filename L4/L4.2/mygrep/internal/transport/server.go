@@ -1,13 +1,24 @@
 package transport
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
 	"fmt"
 	"net"
 	"sync"
 	"time"
 
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/model"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.2/mygrep/internal/protocol"
+)
+
+const (
+	// resultChunkSize is the approximate size of matches sent in one ResultChunk
+	resultChunkSize = 64 * 1024
+	// handshakeTimeout limits the Hello exchange on a new connection
+	handshakeTimeout = 5 * time.Second
+	// writeTimeout limits writing of a single frame
+	writeTimeout = 10 * time.Second
 )
 
 // Server represents a TCP server for distributed communication
@@ -18,11 +29,17 @@ type Server struct {
 	serverMutex sync.RWMutex
 	quit        chan bool
 	jobHandler  JobHandler
+	heartbeats  HeartbeatHandler
 }
 
 // JobHandler interface for handling job requests
 type JobHandler interface {
-	HandleJobRequest(job map[string]interface{}) (*model.JobResult, error)
+	HandleJobRequest(ctx context.Context, job model.Job) (*model.JobResult, error)
+}
+
+// HeartbeatHandler interface for handling heartbeats of cluster members
+type HeartbeatHandler interface {
+	HandleHeartbeat(hb model.Heartbeat) model.Heartbeat
 }
 
 // NewServer creates a new TCP server
@@ -35,6 +52,12 @@ func NewServer(config *model.Config, jobHandler JobHandler) *Server {
 	}
 }
 
+// SetHeartbeatHandler sets the handler that answers heartbeats.
+// Without it the server answers only with its ID
+func (s *Server) SetHeartbeatHandler(handler HeartbeatHandler) {
+	s.heartbeats = handler
+}
+
 // Start starts the TCP server
 func (s *Server) Start() error {
 	listener, err := net.Listen("tcp", ":"+s.config.Port)
@@ -76,93 +99,157 @@ func (s *Server) acceptConnections() {
 	}
 }
 
-// handleConnection handles a single TCP connection
+// session is a connection of a peer. Jobs of the peer are run
+// concurrently, each in its own stream
+type session struct {
+	server  *Server
+	conn    net.Conn
+	peer    string
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	streams map[uint32]context.CancelFunc
+}
+
+// handleConnection checks the protocol version of the peer
+// and serves its requests until the connection is closed
 func (s *Server) handleConnection(conn net.Conn) {
 	defer conn.Close()
 
-	var msg model.Message
-	decoder := json.NewDecoder(conn)
-	if err := decoder.Decode(&msg); err != nil {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	hello, err := protocol.Handshake(conn, s.config.ServerID)
+	if err != nil {
+		fmt.Printf("Handshake with %s failed: %v\n", conn.RemoteAddr(), err)
 		return
 	}
+	conn.SetDeadline(time.Time{})
 
-	switch msg.Type {
-	case "status_request":
-		s.sendStatusResponse(conn, msg.From)
-	case "job_request":
-		s.handleJobRequest(conn, msg)
-	default:
-		fmt.Printf("Received unknown message type: %s\n", msg.Type)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sess := &session{
+		server:  s,
+		conn:    conn,
+		peer:    hello.ServerID,
+		streams: make(map[uint32]context.CancelFunc),
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		h, msg, err := protocol.ReadFrame(reader)
+		if err != nil {
+			return
+		}
+
+		switch m := msg.(type) {
+		case *protocol.JobRequest:
+			sess.startJob(ctx, h.Stream, m.Job)
+		case *protocol.Cancel:
+			sess.cancelJob(h.Stream)
+		case *protocol.Heartbeat:
+			sess.write(h.Stream, &protocol.Heartbeat{Heartbeat: s.handleHeartbeat(m.Heartbeat)})
+		default:
+			sess.write(h.Stream, &protocol.Error{Message: fmt.Sprintf("unexpected %s message", h.Type)})
+		}
 	}
 }
 
-// sendStatusResponse sends status response to client
-func (s *Server) sendStatusResponse(conn net.Conn, from string) {
-	status := map[string]interface{}{
-		"server_id": s.config.ServerID,
-		"status":    "online",
-		"timestamp": time.Now(),
+// handleHeartbeat returns the answer to a heartbeat
+func (s *Server) handleHeartbeat(hb model.Heartbeat) model.Heartbeat {
+	if s.heartbeats != nil {
+		return s.heartbeats.HandleHeartbeat(hb)
 	}
 
-	response := model.Message{
-		Type:      "status_response",
-		From:      s.config.ServerID,
-		To:        from,
-		Data:      status,
-		Timestamp: time.Now(),
+	return model.Heartbeat{
+		From: model.ServerInfo{
+			ID:       s.config.ServerID,
+			Status:   model.StatusAlive,
+			Version:  s.config.Version,
+			Protocol: int(protocol.Version),
+		},
+		SentAt: time.Now(),
 	}
+}
 
-	json.NewEncoder(conn).Encode(response)
+// write sends a frame; frames of different streams are never interleaved
+func (sess *session) write(stream uint32, msg protocol.Message) error {
+	sess.writeMu.Lock()
+	defer sess.writeMu.Unlock()
+
+	sess.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return protocol.WriteFrame(sess.conn, stream, msg)
 }
 
-// handleJobRequest handles job request from client
-func (s *Server) handleJobRequest(conn net.Conn, msg model.Message) {
-	fmt.Printf("Received job request from %s\n", msg.From)
+// startJob runs the job in the background and streams its result
+func (sess *session) startJob(ctx context.Context, stream uint32, job model.Job) {
+	ctx, cancel := context.WithCancel(ctx)
 
-	jobData, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		fmt.Println("Invalid job data format")
-		s.sendErrorResponse(conn, msg.From, "Invalid job data format")
+	sess.mu.Lock()
+	if _, ok := sess.streams[stream]; ok {
+		sess.mu.Unlock()
+		cancel()
+		sess.write(stream, &protocol.Error{Message: fmt.Sprintf("stream %d is already in use", stream)})
 		return
 	}
+	sess.streams[stream] = cancel
+	sess.mu.Unlock()
 
-	// Execute job using the job handler
-	result, err := s.jobHandler.HandleJobRequest(jobData)
-	if err != nil {
-		fmt.Printf("Job execution error: %v\n", err)
-		s.sendErrorResponse(conn, msg.From, err.Error())
-		return
-	}
+	fmt.Printf("Received job request %s from %s\n", job.ID, sess.peer)
+
+	go func() {
+		defer sess.cancelJob(stream)
+
+		result, err := sess.server.jobHandler.HandleJobRequest(ctx, job)
+		if ctx.Err() != nil {
+			// the peer doesn't wait for the result anymore
+			return
+		}
+		if err != nil {
+			fmt.Printf("Job execution error: %v\n", err)
+			sess.write(stream, &protocol.Error{Message: err.Error()})
+			return
+		}
 
-	// Send result back
-	s.sendJobResponse(conn, msg.From, result)
+		if err := sess.sendResult(stream, result); err != nil {
+			fmt.Printf("Error sending result of job %s: %v\n", job.ID, err)
+		}
+	}()
 }
 
-// sendJobResponse sends job result response
-func (s *Server) sendJobResponse(conn net.Conn, to string, result *model.JobResult) {
-	response := model.Message{
-		Type:      "job_response",
-		From:      s.config.ServerID,
-		To:        to,
-		Data:      result,
-		Timestamp: time.Now(),
-	}
+// cancelJob stops the job of the stream
+func (sess *session) cancelJob(stream uint32) {
+	sess.mu.Lock()
+	cancel, ok := sess.streams[stream]
+	delete(sess.streams, stream)
+	sess.mu.Unlock()
 
-	if err := json.NewEncoder(conn).Encode(response); err != nil {
-		fmt.Printf("Error sending response: %v\n", err)
+	if ok {
+		cancel()
 	}
 }
 
-// sendErrorResponse sends error response
-func (s *Server) sendErrorResponse(conn net.Conn, to string, errorMsg string) {
-	result := &model.JobResult{
-		JobID:       "",
-		ServerID:    s.config.ServerID,
-		Processed:   0,
-		Success:     false,
-		Error:       errorMsg,
-		CompletedAt: time.Now(),
+// sendResult streams matches in chunks of about resultChunkSize bytes
+// and completes the stream with the result summary
+func (sess *session) sendResult(stream uint32, result *model.JobResult) error {
+	start, size := 0, 0
+	for i, match := range result.Matches {
+		size += len(match.Line) + len(match.Match) + 8
+		if size < resultChunkSize {
+			continue
+		}
+
+		if err := sess.write(stream, &protocol.ResultChunk{Matches: result.Matches[start : i+1]}); err != nil {
+			return err
+		}
+		start, size = i+1, 0
+	}
+
+	if start < len(result.Matches) {
+		if err := sess.write(stream, &protocol.ResultChunk{Matches: result.Matches[start:]}); err != nil {
+			return err
+		}
 	}
 
-	s.sendJobResponse(conn, to, result)
+	summary := *result
+	summary.Matches = nil
+	return sess.write(stream, &protocol.JobResult{Result: summary})
 }
@@ -33,7 +33,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := app.Run(cancel); err != nil {
+	if err := app.Run(ctx, cancel); err != nil {
 		lg.Error().Err(err).Msgf("%s application stopped with error", pkgConst.Error)
 		// wait()
 		os.Exit(1)
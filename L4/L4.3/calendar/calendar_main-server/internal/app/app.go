@@ -12,6 +12,9 @@ type App struct {
 	lg   *zlog.Zerolog
 	deps *dependencies
 	rm   *resourceManager
+
+	// pollDone is closed when the telegram polling stops
+	pollDone chan struct{}
 }
 
 func New(env string) (*App, error) {
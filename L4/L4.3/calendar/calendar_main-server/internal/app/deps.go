@@ -122,6 +122,7 @@ func (b *dependencyBuilder) initService() {
 	sv := service.New(
 		b.cfg.sv,
 		b.deps.rp,
+		b.cfg.tg,
 		b.deps.tg,
 		b.deps.em,
 		b.deps.rs,
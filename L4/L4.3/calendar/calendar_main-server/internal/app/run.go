@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.3/calendar/calendar_main-server/internal/pkg/pkgConst"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.3/calendar/calendar_main-server/internal/pkg/pkgErrors"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.3/calendar/calendar_main-server/internal/pkg/pkgTelegram"
 )
 
-func (a *App) Run(cancel context.CancelFunc) error {
+func (a *App) Run(ctx context.Context, cancel context.CancelFunc) error {
 	a.deps.tr.HTTP.RunServer(cancel)
 	time.Sleep(500 * time.Millisecond)
 
@@ -14,5 +18,23 @@ func (a *App) Run(cancel context.CancelFunc) error {
 		return fmt.Errorf("failed to start http server: %w", err)
 	}
 
+	switch a.cfg.tg.Mode {
+	case pkgTelegram.ModeWebhook:
+		if err := a.deps.tg.SetWebhook(fmt.Sprintf("%s/telegram/webhook", a.cfg.tr.TrHTTP.PublicHost)); err != nil {
+			return fmt.Errorf("failed to set telegram webhook: %w", err)
+		}
+	case pkgTelegram.ModePolling:
+		a.pollDone = make(chan struct{})
+		go func() {
+			defer close(a.pollDone)
+			if err := a.deps.sv.Telegram.RunPolling(ctx); err != nil {
+				a.lg.Error().Err(err).Msgf("%s telegram polling failed", pkgConst.Error)
+				cancel()
+			}
+		}()
+	default:
+		return fmt.Errorf("%w, mode: %s", pkgErrors.ErrUnknownTelegramMode, a.cfg.tg.Mode)
+	}
+
 	return nil
 }
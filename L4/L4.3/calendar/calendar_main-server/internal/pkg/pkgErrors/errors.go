@@ -16,4 +16,6 @@ var (
 	ErrNoticeNotFound = errors.New("notice not found")
 	ErrUnauthorized   = errors.New("unauthorized")
 	ErrInvalidID      = errors.New("invalid id")
+
+	ErrUnknownTelegramMode = errors.New("telegram mode must be webhook or polling")
 )
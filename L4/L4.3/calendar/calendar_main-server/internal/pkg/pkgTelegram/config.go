@@ -2,18 +2,44 @@ package pkgTelegram
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/wb-go/wbf/config"
 )
 
+const (
+	// ModeWebhook receives the updates on /telegram/webhook
+	ModeWebhook = "webhook"
+	// ModePolling receives the updates with getUpdates long polling,
+	// no public address is needed
+	ModePolling = "polling"
+)
+
 type Config struct {
 	Token string
+	// APIEndpoint is the Bot API endpoint with the token and method placeholders,
+	// the Telegram one if empty
+	APIEndpoint string
+	// Mode is ModeWebhook or ModePolling
+	Mode string
+	// PollTimeout is the long polling timeout of getUpdates
+	PollTimeout time.Duration
 }
 
 func NewConfig(cfg *config.Config) *Config {
-	return &Config{
-		Token: cfg.GetString("telegram.token"),
+	c := &Config{
+		Token:       cfg.GetString("telegram.token"),
+		APIEndpoint: cfg.GetString("telegram.api_endpoint"),
+		Mode:        cfg.GetString("telegram.mode"),
+		PollTimeout: cfg.GetDuration("telegram.poll_timeout"),
+	}
+	if c.Mode == "" {
+		c.Mode = ModeWebhook
+	}
+	if c.PollTimeout <= 0 {
+		c.PollTimeout = 30 * time.Second
 	}
+	return c
 }
 
 func (c Config) String() string {
@@ -22,6 +48,12 @@ func (c Config) String() string {
 		token = "***hidden***"
 	}
 	return fmt.Sprintf(`telegram:
-  %s: %s`,
-		"token", token)
+  %s: %s
+  %s: %s
+  %s: %s
+  %s: %v`,
+		"token", token,
+		"api_endpoint", c.APIEndpoint,
+		"mode", c.Mode,
+		"poll_timeout", c.PollTimeout)
 }
@@ -307,3 +307,42 @@ func (rp *RpPostgres) GetDayEvents(date string) ([]model.Event, error) {
 
 	return events, nil
 }
+
+// LoadTelegramOffset returns the saved Telegram update offset, 0 if it is not saved
+func (rp *RpPostgres) LoadTelegramOffset(ctx context.Context) (int, error) {
+	query := `
+		SELECT
+			update_offset
+		FROM
+			telegram_offset
+		WHERE
+			id = 1
+	`
+
+	var offset int
+	// the offset is read from the master, a replica may return an outdated one
+	err := rp.db.DB.Master.QueryRowContext(ctx, query).Scan(&offset)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("get telegram offset: %w", err)
+	}
+
+	return offset, nil
+}
+
+// SaveTelegramOffset saves the offset of the next Telegram update to receive
+func (rp *RpPostgres) SaveTelegramOffset(ctx context.Context, offset int) error {
+	query := `
+		INSERT INTO telegram_offset (id, update_offset)
+		VALUES (1, $1)
+		ON CONFLICT (id) DO UPDATE SET update_offset = EXCLUDED.update_offset
+	`
+
+	if _, err := rp.db.DB.ExecContext(ctx, query, offset); err != nil {
+		return fmt.Errorf("save telegram offset: %w", err)
+	}
+
+	return nil
+}
@@ -41,12 +41,14 @@ type LogEntry struct {
 type Service struct {
 	Calendar *CalendarService
 	Notice   *NoticeService
+	Telegram *TelegramHandler
 }
 
 // New creates a new Service structure
 func New(
 	cfg *Config,
 	rp *repository.Repository,
+	tgCfg *pkgTelegram.Config,
 	tgClient *pkgTelegram.Client,
 	emailClient *pkgEmail.Client,
 	rs *pkgRetry.Retry,
@@ -72,12 +74,13 @@ func New(
 	return &Service{
 		Calendar: calendarService,
 		Notice:   noticeService,
+		Telegram: NewTelegramHandler(&lg, tgCfg, tgClient, rp),
 	}
 }
 
-// TelegramService returns the telegram service (for compatibility)
+// TelegramService returns the telegram service, it handles the webhook and the polled updates
 func (sv *Service) TelegramService() telegramHandler.ISvForTelegramHandler {
-	return &TelegramHandler{tg: nil}
+	return sv.Telegram
 }
 
 // Start implements the telegramHandler.ISvForTelegramHandler interface
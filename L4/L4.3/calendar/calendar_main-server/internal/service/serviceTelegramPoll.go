@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// telegramPollRetryDelay is the pause after a failed getUpdates request
+const telegramPollRetryDelay = time.Second
+
+// ITelegramOffsetRepository stores the offset of the next Telegram update
+type ITelegramOffsetRepository interface {
+	LoadTelegramOffset(ctx context.Context) (int, error)
+	SaveTelegramOffset(ctx context.Context, offset int) error
+}
+
+// RunPolling receives the updates with getUpdates long polling instead of the webhook
+// and handles them with HandleUpdate until ctx is done. The offset of the next update
+// is saved after each handled one, so a restarted server does not handle it again.
+// The update being handled when ctx is done is handled to the end
+func (th *TelegramHandler) RunPolling(ctx context.Context) error {
+	lg := th.lg.With().Str("worker", "telegram_poll").Logger()
+
+	// getUpdates fails while the webhook is set
+	if err := th.tg.DeleteWebhook(); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+
+	offset, err := th.rp.LoadTelegramOffset(ctx)
+	if err != nil {
+		return fmt.Errorf("load update offset: %w", err)
+	}
+
+	lg.Info().Int("offset", offset).Msg("Telegram poll worker started")
+	defer lg.Info().Msg("Telegram poll worker stopped")
+
+	for ctx.Err() == nil {
+		updates, err := th.tg.GetUpdates(ctx, offset, th.cfg.PollTimeout)
+		if err != nil && ctx.Err() == nil {
+			lg.Error().Err(err).Int("offset", offset).Msg("Failed to get Telegram updates")
+			select {
+			case <-ctx.Done():
+			case <-time.After(telegramPollRetryDelay):
+			}
+			continue
+		}
+
+		// the updates left on shutdown are received again after the restart
+		for i := 0; i < len(updates) && ctx.Err() == nil; i++ {
+			update := updates[i]
+			// failed updates are not received again, as the webhook ones
+			if err := th.HandleUpdate(context.WithoutCancel(ctx), update); err != nil {
+				lg.Warn().Err(err).Int("updateID", update.UpdateID).Msg("Failed to handle Telegram update")
+			}
+
+			offset = update.UpdateID + 1
+			if err := th.rp.SaveTelegramOffset(context.WithoutCancel(ctx), offset); err != nil {
+				lg.Error().Err(err).Int("offset", offset).Msg("Failed to save Telegram update offset")
+			}
+		}
+	}
+
+	return nil
+}
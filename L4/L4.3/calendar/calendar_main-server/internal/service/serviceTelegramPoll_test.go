@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.3/calendar/calendar_main-server/internal/pkg/pkgTelegram"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// fakeBotAPI serves getUpdates with long polling
+type fakeBotAPI struct {
+	mu             sync.Mutex
+	updates        []tgbotapi.Update
+	added          chan struct{}
+	offsets        []int
+	webhookDeleted bool
+}
+
+func newFakeBotAPI() *fakeBotAPI {
+	return &fakeBotAPI{added: make(chan struct{})}
+}
+
+func (f *fakeBotAPI) push(ids ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range ids {
+		f.updates = append(f.updates, tgbotapi.Update{UpdateID: id})
+	}
+	close(f.added)
+	f.added = make(chan struct{})
+}
+
+func (f *fakeBotAPI) pending(offset int) ([]tgbotapi.Update, chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var pending []tgbotapi.Update
+	for _, u := range f.updates {
+		if u.UpdateID >= offset {
+			pending = append(pending, u)
+		}
+	}
+	return pending, f.added
+}
+
+func (f *fakeBotAPI) firstOffset() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.offsets[0]
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result any = true
+	switch path.Base(r.URL.Path) {
+	case "getMe":
+		result = map[string]any{"id": 1, "is_bot": true, "username": "calendar_bot"}
+	case "deleteWebhook":
+		f.mu.Lock()
+		f.webhookDeleted = true
+		f.mu.Unlock()
+	case "getUpdates":
+		offset, _ := strconv.Atoi(r.PostForm.Get("offset"))
+		timeout, _ := strconv.Atoi(r.PostForm.Get("timeout"))
+		f.mu.Lock()
+		f.offsets = append(f.offsets, offset)
+		f.mu.Unlock()
+
+		updates, added := f.pending(offset)
+		if len(updates) == 0 {
+			select {
+			case <-added:
+				updates, _ = f.pending(offset)
+			case <-time.After(time.Duration(timeout) * time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		result = append([]tgbotapi.Update{}, updates...)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// memOffsetRepository keeps the offset in memory. If release is set, an offset
+// is saved only after release is closed, as if the update were still being handled
+type memOffsetRepository struct {
+	mu      sync.Mutex
+	offset  int
+	saved   []int
+	started chan int
+	release chan struct{}
+}
+
+func (r *memOffsetRepository) LoadTelegramOffset(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.offset, nil
+}
+
+func (r *memOffsetRepository) SaveTelegramOffset(ctx context.Context, offset int) error {
+	if r.release != nil {
+		r.started <- offset
+		<-r.release
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offset = offset
+	r.saved = append(r.saved, offset)
+	return nil
+}
+
+func (r *memOffsetRepository) list() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.saved...)
+}
+
+func newTestTelegramHandler(t *testing.T, api *fakeBotAPI, rp ITelegramOffsetRepository) *TelegramHandler {
+	t.Helper()
+
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	cfg := &pkgTelegram.Config{Token: "test", APIEndpoint: srv.URL + "/bot%s/%s", PollTimeout: 5 * time.Second}
+	tg, err := pkgTelegram.New(cfg)
+	require.NoError(t, err)
+
+	lg := zlog.Logger
+	return NewTelegramHandler(&lg, cfg, tg, rp)
+}
+
+// runPolling runs the polling and returns the function stopping it and waiting for it to return
+func runPolling(t *testing.T, th *TelegramHandler) (stop func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- th.RunPolling(ctx) }()
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			cancel()
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(2 * time.Second):
+				t.Error("polling did not stop")
+			}
+		})
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestRunPolling_ResumesFromSavedOffset(t *testing.T) {
+	api := newFakeBotAPI()
+	rp := &memOffsetRepository{offset: 21}
+	th := newTestTelegramHandler(t, api, rp)
+
+	// update 20 was handled before the restart
+	api.push(20, 21, 22)
+	stop := runPolling(t, th)
+
+	require.Eventually(t, func() bool { return len(rp.list()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int{22, 23}, rp.list())
+	require.Equal(t, 21, api.firstOffset())
+
+	// the long poll request waiting for updates is cancelled on shutdown
+	started := time.Now()
+	stop()
+	require.Less(t, time.Since(started), time.Second)
+	require.True(t, api.webhookDeleted)
+
+	offset, err := rp.LoadTelegramOffset(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 23, offset)
+}
+
+func TestRunPolling_FinishesUpdateOnShutdown(t *testing.T) {
+	api := newFakeBotAPI()
+	rp := &memOffsetRepository{started: make(chan int), release: make(chan struct{})}
+	th := newTestTelegramHandler(t, api, rp)
+
+	api.push(30, 31)
+	stop := runPolling(t, th)
+	require.Equal(t, 31, <-rp.started)
+
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("polling must wait for the update being handled")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(rp.release)
+	<-stopped
+
+	require.Equal(t, []int{31}, rp.list(), "the update is handled with a live context, the rest are left")
+	offset, err := rp.LoadTelegramOffset(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 31, offset)
+}
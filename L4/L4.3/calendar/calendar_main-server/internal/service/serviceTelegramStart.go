@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/golovanevvs/wbtech-school-go/tree/main/L4/L4.3/calendar/calendar_main-server/internal/pkg/pkgTelegram"
+	"github.com/wb-go/wbf/zlog"
 )
 
 // ITelegramHandler interface for telegram bot handlers
@@ -13,17 +15,58 @@ type ITelegramHandler interface {
 }
 
 // TelegramHandler handles basic Telegram bot commands for calendar
+// received with the webhook or long polling
 type TelegramHandler struct {
-	tg *pkgTelegram.Client
+	lg  *zlog.Zerolog
+	cfg *pkgTelegram.Config
+	tg  *pkgTelegram.Client
+	rp  ITelegramOffsetRepository
 }
 
 // NewTelegramHandler creates a new TelegramHandler
-func NewTelegramHandler(tg *pkgTelegram.Client) *TelegramHandler {
+func NewTelegramHandler(
+	parentLg *zlog.Zerolog,
+	cfg *pkgTelegram.Config,
+	tg *pkgTelegram.Client,
+	rp ITelegramOffsetRepository,
+) *TelegramHandler {
+	lg := parentLg.With().Str("component", "TelegramHandler").Logger()
 	return &TelegramHandler{
-		tg: tg,
+		lg:  &lg,
+		cfg: cfg,
+		tg:  tg,
+		rp:  rp,
 	}
 }
 
+// HandleUpdate handles an update received with the webhook or long polling,
+// only the /start command is handled
+func (th *TelegramHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) error {
+	if update.Message == nil || update.Message.From == nil {
+		return nil
+	}
+
+	username := update.Message.From.UserName
+	chatID := update.Message.Chat.ID
+	message := update.Message.Text
+
+	if username == "" {
+		th.lg.Warn().Int64("chatID", chatID).Msg("Username is empty, cannot process /start command")
+		return nil
+	}
+
+	if update.Message.IsCommand() && update.Message.Command() == "start" {
+		th.lg.Debug().Str("username", username).Int64("chatID", chatID).Msg("Processing /start command")
+		if err := th.Start(ctx, username, chatID, message); err != nil {
+			return fmt.Errorf("handle /start command: %w", err)
+		}
+		return nil
+	}
+
+	th.lg.Debug().Str("username", username).Int64("chatID", chatID).Str("message_body", message).Msg("Telegram message skipped")
+	return nil
+}
+
 // HandleCommand handles incoming Telegram commands
 func (th *TelegramHandler) HandleCommand(ctx context.Context, chatID int64, command, message string) error {
 	switch command {
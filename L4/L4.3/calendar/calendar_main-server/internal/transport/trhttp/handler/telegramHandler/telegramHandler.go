@@ -15,7 +15,7 @@ import (
 
 // ISvForTelegramHandler interface for telegram handler service
 type ISvForTelegramHandler interface {
-	Start(ctx context.Context, username string, chatID int64, message string) error
+	HandleUpdate(ctx context.Context, update tgbotapi.Update) error
 }
 
 // TelegramHandler handles Telegram webhook requests
@@ -57,35 +57,12 @@ func (hd *TelegramHandler) WebHookHandler(c *gin.Context) {
 		return
 	}
 
-	if update.Message == nil {
-		c.Status(http.StatusOK)
+	if err := hd.sv.HandleUpdate(c.Request.Context(), update); err != nil {
+		lg.Warn().Int("updateID", update.UpdateID).Err(err).Msgf("%s failed to handle update", pkgConst.Warn)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	username := update.Message.From.UserName
-	if username == "" {
-		lg.Warn().Int64("chatID", update.Message.Chat.ID).Msg("Username is empty, cannot process /start command")
-		c.Status(http.StatusOK)
-		return
-	}
-
-	chatID := update.Message.Chat.ID
-	message := update.Message.Text
-
-	if update.Message.IsCommand() && update.Message.Command() == "start" {
-		lg.Debug().Str("username", username).Int64("chatID", chatID).Msg("Processing /start command")
-
-		if err := hd.sv.Start(c.Request.Context(), username, chatID, message); err != nil {
-			lg.Warn().Str("username", username).Int64("chatID", chatID).Err(err).Msgf("%s failed to handle /start command", pkgConst.Warn)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		lg.Debug().Str("username", username).Int64("chatID", chatID).Msgf("%s Telegram /start command processed successfully", pkgConst.OpSuccess)
-		c.Status(http.StatusOK)
-		return
-	}
-
-	lg.Debug().Str("username", username).Int64("chatID", chatID).Str("message_body", message).Msgf("%s Telegram message processed", pkgConst.OpSuccess)
+	lg.Debug().Int("updateID", update.UpdateID).Msgf("%s Telegram update processed", pkgConst.OpSuccess)
 	c.Status(http.StatusOK)
 }
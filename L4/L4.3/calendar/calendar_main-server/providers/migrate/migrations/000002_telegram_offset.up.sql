@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS telegram_offset (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    update_offset BIGINT NOT NULL
+);
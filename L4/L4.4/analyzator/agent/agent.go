@@ -0,0 +1,372 @@
+// Package agent встраивает в сервис метрики, историю памяти, управление GC
+// и pprof анализатора.
+//
+// Сервис на net/http или gin подключает агента одним вызовом:
+//
+//	agent.Register(mux, agent.Options{})
+//	agent.RegisterGin(router, agent.Options{})
+//
+// или поднимает его на отдельном порту (режим sidecar):
+//
+//	go agent.ListenAndServe(ctx, ":6060", agent.Options{})
+//
+// pprof и принудительный запуск GC подключаются только явно через
+// Options.Endpoints и закрываются токеном или basic auth
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"analyzator/internal/pkg/pkgMemHistory"
+	"analyzator/internal/pkg/pkgRuntimeMetrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Endpoint группа маршрутов агента
+type Endpoint uint
+
+const (
+	// EndpointMetrics GET <MetricsPath> - метрики Prometheus
+	EndpointMetrics Endpoint = 1 << iota
+	// EndpointMemory GET <APIPrefix>/memory/history и /memory/alerts
+	EndpointMemory
+	// EndpointDescribe GET <APIPrefix>/metrics/describe - список метрик runtime
+	EndpointDescribe
+	// EndpointGCStatus GET <APIPrefix>/gc/status
+	EndpointGCStatus
+	// EndpointGCTrigger POST <APIPrefix>/gc/trigger, защищен
+	EndpointGCTrigger
+	// EndpointPprof GET <PprofPath>/*, защищен
+	EndpointPprof
+)
+
+const (
+	// DefaultEndpoints маршруты, которые только читают состояние
+	DefaultEndpoints = EndpointMetrics | EndpointMemory | EndpointDescribe | EndpointGCStatus
+	// AllEndpoints все маршруты
+	AllEndpoints = DefaultEndpoints | EndpointGCTrigger | EndpointPprof
+	// protectedEndpoints маршруты, которые влияют на работу сервиса или раскрывают его код
+	protectedEndpoints = EndpointGCTrigger | EndpointPprof
+)
+
+// Значения Options по умолчанию
+const (
+	DefaultMetricsPath    = "/metrics"
+	DefaultAPIPrefix      = "/agent"
+	DefaultPprofPath      = "/debug/pprof"
+	DefaultSampleInterval = 5 * time.Second
+	// DefaultHistorySize час истории при снимке раз в 5 секунд
+	DefaultHistorySize = 720
+)
+
+// ErrUnprotected защищенные маршруты запрошены без учетных данных
+var ErrUnprotected = errors.New("для pprof и GC trigger требуется Auth или AllowUnprotected")
+
+// Auth учетные данные защищенных маршрутов. Запрос проходит, если совпал
+// токен (Authorization: Bearer <Token>) или логин и пароль basic auth
+type Auth struct {
+	Token    string
+	Username string
+	Password string
+}
+
+func (a Auth) empty() bool {
+	return a.Token == "" && a.Username == ""
+}
+
+// Options настройки агента, нулевые поля заменяются значениями по умолчанию
+type Options struct {
+	// Endpoints подключаемые маршруты, по умолчанию DefaultEndpoints
+	Endpoints Endpoint
+	// MetricsPath путь метрик Prometheus
+	MetricsPath string
+	// APIPrefix префикс JSON маршрутов
+	APIPrefix string
+	// PprofPath префикс маршрутов pprof
+	PprofPath string
+
+	// Auth защищает pprof и GC trigger
+	Auth Auth
+	// AllowUnprotected разрешает pprof и GC trigger без Auth
+	AllowUnprotected bool
+
+	// Registry реестр для метрик агента, по умолчанию новый
+	Registry *prometheus.Registry
+	// Gatherer дополнительные метрики сервиса для MetricsPath,
+	// например prometheus.DefaultGatherer. Метрики агента, которые сервис
+	// уже экспортирует (go_* стандартного GoCollector), пропускаются
+	Gatherer prometheus.Gatherer
+
+	// SampleInterval интервал снятия MemStats
+	SampleInterval time.Duration
+	// HistorySize количество хранимых снимков MemStats
+	HistorySize int
+	// MaxBuckets количество границ гистограмм runtime/metrics
+	MaxBuckets int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Endpoints == 0 {
+		o.Endpoints = DefaultEndpoints
+	}
+	if o.MetricsPath == "" {
+		o.MetricsPath = DefaultMetricsPath
+	}
+	if o.APIPrefix == "" {
+		o.APIPrefix = DefaultAPIPrefix
+	}
+	if o.PprofPath == "" {
+		o.PprofPath = DefaultPprofPath
+	}
+	o.APIPrefix = strings.TrimSuffix(o.APIPrefix, "/")
+	o.PprofPath = strings.TrimSuffix(o.PprofPath, "/")
+	if o.Registry == nil {
+		o.Registry = prometheus.NewRegistry()
+	}
+	if o.SampleInterval <= 0 {
+		o.SampleInterval = DefaultSampleInterval
+	}
+	if o.HistorySize <= 0 {
+		o.HistorySize = DefaultHistorySize
+	}
+	if o.MaxBuckets <= 0 {
+		o.MaxBuckets = pkgRuntimeMetrics.DefaultMaxBuckets
+	}
+	return o
+}
+
+// Agent собирает метрики памяти и runtime и обслуживает маршруты
+type Agent struct {
+	opts           Options
+	history        *pkgMemHistory.History
+	runtimeMetrics *pkgRuntimeMetrics.Collector
+	memory         *memoryMetrics
+	routes         []route
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// route маршрут агента. Маршрут с prefix обслуживает все пути под path
+type route struct {
+	method    string
+	path      string
+	prefix    bool
+	protected bool
+	handler   http.Handler
+}
+
+// New регистрирует метрики агента в Options.Registry и запускает
+// снятие MemStats. Остановить его можно через Close
+func New(opts Options) (*Agent, error) {
+	opts = opts.withDefaults()
+
+	if opts.Endpoints&^AllEndpoints != 0 {
+		return nil, fmt.Errorf("неизвестные маршруты агента: %b", opts.Endpoints&^AllEndpoints)
+	}
+	if opts.Endpoints&protectedEndpoints != 0 && opts.Auth.empty() && !opts.AllowUnprotected {
+		return nil, ErrUnprotected
+	}
+
+	a := &Agent{
+		opts:           opts,
+		history:        pkgMemHistory.New(opts.HistorySize, pkgMemHistory.DefaultDetectorConfig()),
+		runtimeMetrics: pkgRuntimeMetrics.New(opts.MaxBuckets),
+		memory:         newMemoryMetrics(),
+		stop:           make(chan struct{}),
+	}
+
+	// метрики процесса process_* агент не регистрирует: их экспортирует сервис
+	err := opts.Registry.Register(a.memory)
+	if err == nil {
+		err = opts.Registry.Register(a.runtimeMetrics)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось зарегистрировать метрики агента: %w", err)
+	}
+
+	a.routes = a.buildRoutes()
+
+	a.sample()
+	go a.collect()
+
+	return a, nil
+}
+
+// Close останавливает снятие MemStats
+func (a *Agent) Close() {
+	a.stopOnce.Do(func() { close(a.stop) })
+}
+
+// History возвращает историю MemStats
+func (a *Agent) History() *pkgMemHistory.History {
+	return a.history
+}
+
+// Registry возвращает реестр метрик агента
+func (a *Agent) Registry() *prometheus.Registry {
+	return a.opts.Registry
+}
+
+func (a *Agent) collect() {
+	ticker := time.NewTicker(a.opts.SampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			a.sample()
+		}
+	}
+}
+
+func (a *Agent) sample() {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	a.history.Record(&memStats)
+	a.memory.update(&memStats, a.history.Alerts())
+}
+
+func (a *Agent) buildRoutes() []route {
+	var routes []route
+	add := func(endpoint Endpoint, r route) {
+		if a.opts.Endpoints&endpoint == 0 {
+			return
+		}
+		r.protected = endpoint&protectedEndpoints != 0
+		if r.protected && !a.opts.Auth.empty() {
+			r.handler = authHandler(a.opts.Auth, r.handler)
+		}
+		routes = append(routes, r)
+	}
+
+	var gatherer prometheus.Gatherer = a.opts.Registry
+	if a.opts.Gatherer != nil {
+		gatherer = mergedGatherer{agent: a.opts.Registry, service: a.opts.Gatherer}
+	}
+
+	api := a.opts.APIPrefix
+	add(EndpointMetrics, route{method: http.MethodGet, path: a.opts.MetricsPath,
+		handler: promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})})
+	add(EndpointMemory, route{method: http.MethodGet, path: api + "/memory/history",
+		handler: http.HandlerFunc(a.memoryHistoryHandler)})
+	add(EndpointMemory, route{method: http.MethodGet, path: api + "/memory/alerts",
+		handler: http.HandlerFunc(a.memoryAlertsHandler)})
+	add(EndpointDescribe, route{method: http.MethodGet, path: api + "/metrics/describe",
+		handler: http.HandlerFunc(a.describeMetricsHandler)})
+	add(EndpointGCStatus, route{method: http.MethodGet, path: api + "/gc/status",
+		handler: http.HandlerFunc(a.gcStatusHandler)})
+	add(EndpointGCTrigger, route{method: http.MethodPost, path: api + "/gc/trigger",
+		handler: http.HandlerFunc(a.triggerGCHandler)})
+	add(EndpointPprof, route{method: http.MethodGet, path: a.opts.PprofPath, prefix: true,
+		handler: pprofHandler(a.opts.PprofPath)})
+
+	return routes
+}
+
+// mergedGatherer объединяет метрики агента и сервиса. Семейства агента с
+// именами, которые уже есть у сервиса, пропускаются: например,
+// go_gc_gogc_percent стандартного GoCollector с другим описанием, из-за
+// которого prometheus.Gatherers вернул бы ошибку
+type mergedGatherer struct {
+	agent   prometheus.Gatherer
+	service prometheus.Gatherer
+}
+
+// Gather реализует prometheus.Gatherer
+func (g mergedGatherer) Gather() ([]*dto.MetricFamily, error) {
+	var errs prometheus.MultiError
+
+	families, err := g.service.Gather()
+	errs.Append(err)
+
+	names := make(map[string]struct{}, len(families))
+	for _, mf := range families {
+		names[mf.GetName()] = struct{}{}
+	}
+
+	agentFamilies, err := g.agent.Gather()
+	errs.Append(err)
+	for _, mf := range agentFamilies {
+		if _, ok := names[mf.GetName()]; !ok {
+			families = append(families, mf)
+		}
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+	return families, errs.MaybeUnwrap()
+}
+
+// Mux маршрутизатор net/http с шаблонами "METHOD /path", например *http.ServeMux
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// Register подключает маршруты агента к mux
+func (a *Agent) Register(mux Mux) {
+	for _, r := range a.routes {
+		path := r.path
+		if r.prefix {
+			path += "/"
+		}
+		mux.Handle(r.method+" "+path, r.handler)
+	}
+	a.logRoutes()
+}
+
+// RegisterGin подключает маршруты агента к gin
+func (a *Agent) RegisterGin(router gin.IRouter) {
+	for _, r := range a.routes {
+		path := r.path
+		if r.prefix {
+			path += "/*path"
+		}
+		router.Handle(r.method, path, gin.WrapH(r.handler))
+	}
+	a.logRoutes()
+}
+
+func (a *Agent) logRoutes() {
+	for _, r := range a.routes {
+		if r.protected && a.opts.Auth.empty() {
+			log.Printf("Агент: маршрут %s %s доступен без авторизации", r.method, r.path)
+		}
+	}
+}
+
+// Register создает агента и подключает его маршруты к mux
+func Register(mux Mux, opts Options) (*Agent, error) {
+	a, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	a.Register(mux)
+	return a, nil
+}
+
+// RegisterGin создает агента и подключает его маршруты к gin
+func RegisterGin(router gin.IRouter, opts Options) (*Agent, error) {
+	a, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	a.RegisterGin(router)
+	return a, nil
+}
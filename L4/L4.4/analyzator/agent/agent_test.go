@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func do(t *testing.T, h http.Handler, method, path string, setup func(r *http.Request)) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, path, nil)
+	if setup != nil {
+		setup(r)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRegisterDefaultEndpoints(t *testing.T) {
+	mux := http.NewServeMux()
+	a, err := Register(mux, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	for _, path := range []string{"/metrics", "/agent/memory/history", "/agent/memory/alerts", "/agent/metrics/describe", "/agent/gc/status"} {
+		if w := do(t, mux, http.MethodGet, path, nil); w.Code != http.StatusOK {
+			t.Errorf("GET %s: %d, ожидался 200", path, w.Code)
+		}
+	}
+
+	// защищенные маршруты подключаются только явно
+	if w := do(t, mux, http.MethodPost, "/agent/gc/trigger", nil); w.Code != http.StatusNotFound {
+		t.Errorf("POST /agent/gc/trigger: %d, ожидался 404", w.Code)
+	}
+	if w := do(t, mux, http.MethodGet, "/debug/pprof/", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET /debug/pprof/: %d, ожидался 404", w.Code)
+	}
+
+	body := do(t, mux, http.MethodGet, "/metrics", nil).Body.String()
+	for _, name := range []string{"go_memory_alloc_bytes", "go_sched_goroutines_goroutines"} {
+		if !strings.Contains(body, name) {
+			t.Errorf("в метриках нет %s", name)
+		}
+	}
+
+	if w := do(t, mux, http.MethodGet, "/agent/memory/history?window=bad", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("некорректное окно: %d, ожидался 400", w.Code)
+	}
+}
+
+func TestDefaultGatherer(t *testing.T) {
+	mux := http.NewServeMux()
+	a, err := Register(mux, Options{Gatherer: prometheus.DefaultGatherer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	w := do(t, mux, http.MethodGet, "/metrics", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics: %d, ожидался 200: %s", w.Code, w.Body.String())
+	}
+
+	// go_gc_gogc_percent экспортируют и GoCollector, и агент,
+	// process_* и go_memstats_* - только сервис
+	body := w.Body.String()
+	for _, name := range []string{"go_gc_gogc_percent", "go_memstats_alloc_bytes", "process_cpu_seconds_total", "go_memory_alloc_bytes", "go_gc_pauses_seconds"} {
+		if n := strings.Count(body, "# TYPE "+name+" "); n != 1 {
+			t.Errorf("%s описан %d раз, ожидался 1", name, n)
+		}
+	}
+}
+
+func TestProtectedEndpoints(t *testing.T) {
+	if _, err := New(Options{Endpoints: EndpointPprof}); !errors.Is(err, ErrUnprotected) {
+		t.Fatalf("pprof без авторизации: %v, ожидалась ErrUnprotected", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	a, err := RegisterGin(router, Options{
+		Endpoints: AllEndpoints,
+		APIPrefix: "/api/v1/",
+		PprofPath: "/internal/pprof",
+		Auth:      Auth{Token: "secret", Username: "admin", Password: "pass"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		setup  func(r *http.Request)
+		want   int
+	}{
+		{"без авторизации", http.MethodPost, "/api/v1/gc/trigger", nil, http.StatusUnauthorized},
+		{"неверный токен", http.MethodPost, "/api/v1/gc/trigger", func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer wrong")
+		}, http.StatusUnauthorized},
+		{"токен", http.MethodPost, "/api/v1/gc/trigger", func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer secret")
+		}, http.StatusOK},
+		{"неверный пароль", http.MethodGet, "/internal/pprof/", func(r *http.Request) {
+			r.SetBasicAuth("admin", "wrong")
+		}, http.StatusUnauthorized},
+		{"basic auth", http.MethodGet, "/internal/pprof/", func(r *http.Request) {
+			r.SetBasicAuth("admin", "pass")
+		}, http.StatusOK},
+		{"профиль под своим префиксом", http.MethodGet, "/internal/pprof/goroutine?debug=1", func(r *http.Request) {
+			r.SetBasicAuth("admin", "pass")
+		}, http.StatusOK},
+		{"открытый маршрут", http.MethodGet, "/api/v1/gc/status", nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(t, router, tt.method, tt.path, tt.setup); w.Code != tt.want {
+				t.Errorf("%s %s: %d, ожидался %d", tt.method, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+
+	w := do(t, router, http.MethodGet, "/internal/pprof/goroutine?debug=1", func(r *http.Request) {
+		r.SetBasicAuth("admin", "pass")
+	})
+	if !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Errorf("вместо профиля goroutine получено %q", w.Body.String()[:min(w.Body.Len(), 100)])
+	}
+}
+
+func TestListenAndServe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ListenAndServe(ctx, addr, Options{}) }()
+
+	var resp *http.Response
+	for range 50 {
+		if resp, err = http.Get("http://" + addr + "/metrics"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /metrics: %d, ожидался 200", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("ListenAndServe завершился с ошибкой %v", err)
+	}
+}
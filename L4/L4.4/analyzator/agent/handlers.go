@@ -0,0 +1,188 @@
+package agent
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"analyzator/internal/pkg/pkgMemHistory"
+)
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+type jsonObject = map[string]any
+
+// authHandler пропускает запросы с токеном Authorization: Bearer <token>
+// или с логином и паролем basic auth
+func authHandler(auth Auth, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authorized(auth, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if auth.Username != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="agent"`)
+		}
+		writeJSON(w, http.StatusUnauthorized, jsonObject{
+			"error": "требуется авторизация",
+		})
+	})
+}
+
+func authorized(auth Auth, r *http.Request) bool {
+	if auth.Token != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && equal(token, auth.Token) {
+			return true
+		}
+	}
+	if auth.Username != "" {
+		// пароль сравнивается и при неверном логине, чтобы время ответа не выдавало логин
+		username, password, ok := r.BasicAuth()
+		userOK := equal(username, auth.Username)
+		passwordOK := equal(password, auth.Password)
+		if ok && userOK && passwordOK {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// memoryHistoryHandler отдает историю MemStats за окно ?window= (например, 15m).
+// Без параметра возвращается вся история
+func (a *Agent) memoryHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	var window time.Duration
+	if value := r.URL.Query().Get("window"); value != "" {
+		var err error
+		window, err = time.ParseDuration(value)
+		if err != nil || window < 0 {
+			writeJSON(w, http.StatusBadRequest, jsonObject{
+				"error": "некорректное окно: ожидается длительность, например 15m",
+			})
+			return
+		}
+	}
+
+	samples := a.history.Window(window)
+
+	var pauses []time.Duration
+	var lost uint32
+	for _, s := range samples {
+		for _, p := range s.Pauses {
+			pauses = append(pauses, p.Duration)
+		}
+		lost += s.LostPauses
+	}
+
+	writeJSON(w, http.StatusOK, jsonObject{
+		"window":   window.String(),
+		"capacity": a.history.Capacity(),
+		"count":    len(samples),
+		"samples":  samples,
+		"pauses": jsonObject{
+			"count":  len(pauses),
+			"lost":   lost,
+			"p50_ns": pkgMemHistory.Percentile(pauses, 0.5),
+			"p99_ns": pkgMemHistory.Percentile(pauses, 0.99),
+			"max_ns": pkgMemHistory.Percentile(pauses, 1),
+		},
+	})
+}
+
+// memoryAlertsHandler отдает активные оповещения об аномалиях памяти и GC
+func (a *Agent) memoryAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jsonObject{
+		"alerts": a.history.Alerts(),
+	})
+}
+
+// describeMetricsHandler отдает список метрик runtime/metrics, экспортируемых в MetricsPath
+func (a *Agent) describeMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	infos := a.runtimeMetrics.Metrics()
+	writeJSON(w, http.StatusOK, jsonObject{
+		"count":   len(infos),
+		"metrics": infos,
+	})
+}
+
+// gcStatusHandler отдает настройки и счетчики GC и подключенные маршруты агента.
+// Значения читаются через runtime/metrics без остановки программы
+func (a *Agent) gcStatusHandler(w http.ResponseWriter, r *http.Request) {
+	samples := []metrics.Sample{
+		{Name: "/gc/gogc:percent"},
+		{Name: "/gc/gomemlimit:bytes"},
+		{Name: "/gc/cycles/total:gc-cycles"},
+		{Name: "/gc/cycles/forced:gc-cycles"},
+	}
+	metrics.Read(samples)
+
+	endpoints := make(jsonObject, len(a.routes))
+	for _, rt := range a.routes {
+		path := rt.path
+		if rt.prefix {
+			path += "/*"
+		}
+		endpoints[path] = rt.method
+	}
+
+	// при GOGC=off runtime отдает -1, приведенное к uint64
+	gogc := int64(samples[0].Value.Uint64())
+	writeJSON(w, http.StatusOK, jsonObject{
+		"gc_status": jsonObject{
+			"enabled":      gogc >= 0,
+			"gogc":         gogc,
+			"memory_limit": samples[1].Value.Uint64(),
+			"cycles":       samples[2].Value.Uint64(),
+			"forced":       samples[3].Value.Uint64(),
+		},
+		"endpoints": endpoints,
+	})
+}
+
+// triggerGCHandler принудительно запускает сборку мусора
+func (a *Agent) triggerGCHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	runtime.GC()
+
+	writeJSON(w, http.StatusOK, jsonObject{
+		"message":     "Выполнена принудительная сборка мусора",
+		"duration_ns": time.Since(start),
+	})
+}
+
+// pprofHandler обслуживает pprof под префиксом prefix.
+// pprof.Index находит профили только под /debug/pprof/, поэтому
+// имя профиля выделяется из пути здесь
+func pprofHandler(prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+		switch name {
+		case "":
+			pprof.Index(w, r)
+		case "cmdline":
+			pprof.Cmdline(w, r)
+		case "profile":
+			pprof.Profile(w, r)
+		case "symbol":
+			pprof.Symbol(w, r)
+		case "trace":
+			pprof.Trace(w, r)
+		default:
+			pprof.Handler(name).ServeHTTP(w, r)
+		}
+	})
+}
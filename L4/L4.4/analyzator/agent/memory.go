@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"runtime"
+
+	"analyzator/internal/pkg/pkgMemHistory"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// memoryMetrics метрики последнего снимка MemStats и оповещений об аномалиях
+type memoryMetrics struct {
+	allocBytes  *prometheus.GaugeVec
+	sysBytes    *prometheus.GaugeVec
+	alertActive *prometheus.GaugeVec
+}
+
+func newMemoryMetrics() *memoryMetrics {
+	m := &memoryMetrics{
+		allocBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "go_memory_alloc_bytes",
+				Help: "Number of bytes currently allocated",
+			},
+			[]string{"type"},
+		),
+		sysBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "go_memory_sys_bytes",
+				Help: "Number of bytes obtained from system",
+			},
+			[]string{"type"},
+		),
+		alertActive: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "go_memory_alert_active",
+				Help: "Whether a memory or GC anomaly is detected (1) or not (0)",
+			},
+			[]string{"type"},
+		),
+	}
+
+	for _, alertType := range pkgMemHistory.AlertTypes {
+		m.alertActive.WithLabelValues(alertType).Set(0)
+	}
+
+	return m
+}
+
+func (m *memoryMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.allocBytes.Describe(ch)
+	m.sysBytes.Describe(ch)
+	m.alertActive.Describe(ch)
+}
+
+func (m *memoryMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.allocBytes.Collect(ch)
+	m.sysBytes.Collect(ch)
+	m.alertActive.Collect(ch)
+}
+
+func (m *memoryMetrics) update(memStats *runtime.MemStats, alerts []pkgMemHistory.Alert) {
+	m.allocBytes.WithLabelValues("heap").Set(float64(memStats.HeapAlloc))
+	m.allocBytes.WithLabelValues("stack").Set(float64(memStats.StackInuse))
+	m.allocBytes.WithLabelValues("other").Set(float64(memStats.MSpanInuse + memStats.MCacheInuse))
+
+	m.sysBytes.WithLabelValues("heap").Set(float64(memStats.HeapSys))
+	m.sysBytes.WithLabelValues("stack").Set(float64(memStats.StackSys))
+	m.sysBytes.WithLabelValues("other").Set(float64(memStats.MSpanSys + memStats.MCacheSys))
+
+	for _, alertType := range pkgMemHistory.AlertTypes {
+		active := 0.0
+		for _, alert := range alerts {
+			if alert.Type == alertType {
+				active = 1
+			}
+		}
+		m.alertActive.WithLabelValues(alertType).Set(active)
+	}
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// shutdownTimeout время на завершение запросов при остановке sidecar
+const shutdownTimeout = 5 * time.Second
+
+// ListenAndServe запускает агента на отдельном адресе (режим sidecar),
+// чтобы отладочные маршруты не попадали на публичный порт сервиса.
+// Блокируется до отмены ctx или ошибки сервера
+func ListenAndServe(ctx context.Context, addr string, opts Options) error {
+	mux := http.NewServeMux()
+	a, err := Register(mux, opts)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Агент запущен на %s", addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
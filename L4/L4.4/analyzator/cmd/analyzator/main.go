@@ -4,10 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"analyzator/internal/app"
+	"analyzator/internal/pkg/pkgProfiler"
 )
 
 func main() {
@@ -17,6 +20,18 @@ func main() {
 	readTimeout := flag.Duration("read-timeout", 10*time.Second, "Таймаут чтения (по умолчанию: 10s)")
 	writeTimeout := flag.Duration("write-timeout", 10*time.Second, "Таймаут записи (по умолчанию: 10s)")
 	idleTimeout := flag.Duration("idle-timeout", 60*time.Second, "Таймаут простоя (по умолчанию: 60s)")
+	sampleInterval := flag.Duration("sample-interval", 5*time.Second, "Интервал снятия статистики памяти (по умолчанию: 5s)")
+	historySize := flag.Int("history-size", 720, "Количество хранимых снимков памяти (по умолчанию: 720)")
+	adminToken := flag.String("admin-token", os.Getenv("ANALYZATOR_ADMIN_TOKEN"), "Токен admin API (по умолчанию: $ANALYZATOR_ADMIN_TOKEN, пустой отключает admin API)")
+
+	defaults := app.DefaultConfig().Profile
+	profileDir := flag.String("profile-dir", defaults.Dir, "Каталог хранения профилей, обязателен при --profile-interval > 0")
+	profileInterval := flag.Duration("profile-interval", defaults.Interval, "Интервал снятия профилей, 0 отключает профилирование (по умолчанию: 0)")
+	profileCPUDuration := flag.Duration("profile-cpu-duration", defaults.CPUDuration, "Длительность CPU профиля (по умолчанию: 10s)")
+	profileTypes := flag.String("profile-types", strings.Join(defaults.Types, ","), "Снимаемые типы профилей через запятую (по умолчанию: cpu,heap,goroutine,mutex,block)")
+	profileMaxCount := flag.Int("profile-max-count", defaults.Retention.MaxCount, "Максимальное количество хранимых профилей, 0 без ограничения (по умолчанию: 2000)")
+	profileMaxBytes := flag.Int64("profile-max-bytes", defaults.Retention.MaxBytes, "Максимальный объем хранимых профилей в байтах, 0 без ограничения (по умолчанию: 256 МБ)")
+	profileMaxAge := flag.Duration("profile-max-age", defaults.Retention.MaxAge, "Максимальный возраст хранимых профилей, 0 без ограничения (по умолчанию: 6h)")
 
 	flag.Parse()
 
@@ -31,6 +46,20 @@ func main() {
 		log.Fatalf("Недопустимое значение GC процента: %d (должно быть >= -1)", gcPercentInt)
 	}
 
+	if *sampleInterval <= 0 {
+		log.Fatalf("Недопустимый интервал снятия статистики: %v (должен быть > 0)", *sampleInterval)
+	}
+	if *historySize < 1 {
+		log.Fatalf("Недопустимый размер истории: %d (должен быть >= 1)", *historySize)
+	}
+
+	if *profileInterval < 0 || *profileCPUDuration < 0 || *profileMaxCount < 0 || *profileMaxBytes < 0 || *profileMaxAge < 0 {
+		log.Fatalf("Параметры профилирования не могут быть отрицательными")
+	}
+	if *profileInterval > 0 && *profileDir == "" {
+		log.Fatalf("Для профилирования нужно задать --profile-dir")
+	}
+
 	// Создание конфигурации
 	config := app.Config{
 		Port:         *port,
@@ -38,6 +67,22 @@ func main() {
 		ReadTimeout:  *readTimeout,
 		WriteTimeout: *writeTimeout,
 		IdleTimeout:  *idleTimeout,
+
+		SampleInterval: *sampleInterval,
+		HistorySize:    *historySize,
+		AdminToken:     *adminToken,
+
+		Profile: app.ProfileConfig{
+			Dir:         *profileDir,
+			Interval:    *profileInterval,
+			CPUDuration: *profileCPUDuration,
+			Types:       strings.Split(*profileTypes, ","),
+			Retention: pkgProfiler.Retention{
+				MaxCount: *profileMaxCount,
+				MaxBytes: *profileMaxBytes,
+				MaxAge:   *profileMaxAge,
+			},
+		},
 	}
 
 	// Вывод информации о запуске
@@ -50,6 +95,17 @@ func main() {
 	}
 	fmt.Printf("Таймауты: чтение=%v, запись=%v, простой=%v\n",
 		config.ReadTimeout, config.WriteTimeout, config.IdleTimeout)
+	fmt.Printf("История памяти: %d снимков раз в %v\n", config.HistorySize, config.SampleInterval)
+	if config.Profile.Interval > 0 {
+		fmt.Printf("Профилирование: %s раз в %v\n", *profileTypes, config.Profile.Interval)
+	} else {
+		fmt.Println("Профилирование: отключено")
+	}
+	if config.AdminToken != "" {
+		fmt.Println("Admin API: включен")
+	} else {
+		fmt.Println("Admin API: отключен")
+	}
 	fmt.Println("==============================")
 
 	// Создание и запуск приложения
@@ -11,7 +11,10 @@ import (
 	"syscall"
 	"time"
 
+	"analyzator/agent"
+	"analyzator/internal/pkg/pkgProfiler"
 	pkgPrometheus "analyzator/internal/pkg/pkgPrometheus"
+	"analyzator/internal/pkg/pkgTuning"
 	"analyzator/internal/transport"
 )
 
@@ -22,12 +25,39 @@ type Config struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// SampleInterval интервал снятия MemStats
+	SampleInterval time.Duration
+	// HistorySize количество хранимых снимков MemStats
+	HistorySize int
+	// AdminToken токен доступа к admin API, пустой отключает его
+	AdminToken string
+	// Profile настройки непрерывного профилирования
+	Profile ProfileConfig
+}
+
+// ProfileConfig настройки непрерывного профилирования
+type ProfileConfig struct {
+	// Dir каталог хранения профилей, обязателен при Interval > 0
+	Dir string
+	// Interval интервал снятия профилей, 0 отключает профилирование.
+	// Пока снимается CPU профиль, /debug/pprof/profile возвращает ошибку
+	Interval time.Duration
+	// CPUDuration длительность CPU профиля
+	CPUDuration time.Duration
+	// Types снимаемые типы профилей
+	Types []string
+	// Retention ограничения хранилища профилей
+	Retention pkgProfiler.Retention
 }
 
 // App структура приложения
 type App struct {
-	server *http.Server
-	config Config
+	server   *http.Server
+	config   Config
+	agent    *agent.Agent
+	profiler *pkgProfiler.Profiler
+	// stopProfiler останавливает снятие профилей
+	stopProfiler context.CancelFunc
 }
 
 // New создает новое приложение
@@ -47,10 +77,54 @@ func (a *App) Setup() error {
 
 	// Инициализация Prometheus метрик
 	pkgPrometheus.Init()
+
+	// Агент собирает историю MemStats и все метрики runtime/metrics
+	// и обслуживает метрики, память, GC и pprof. Маршруты pprof и
+	// GC trigger анализатора всегда были открыты
+	var err error
+	a.agent, err = agent.New(agent.Options{
+		Endpoints:        agent.AllEndpoints,
+		APIPrefix:        "/api/v1",
+		AllowUnprotected: true,
+		Registry:         pkgPrometheus.Registry,
+		SampleInterval:   a.config.SampleInterval,
+		HistorySize:      a.config.HistorySize,
+	})
+	if err != nil {
+		return err
+	}
 	log.Println("Prometheus метрики инициализированы")
 
+	// Непрерывное профилирование
+	var profiles *pkgProfiler.Store
+	if a.config.Profile.Interval > 0 {
+		if a.config.Profile.Dir == "" {
+			return fmt.Errorf("каталог профилей не задан")
+		}
+		store, err := pkgProfiler.NewStore(a.config.Profile.Dir, a.config.Profile.Retention)
+		if err != nil {
+			return err
+		}
+		a.profiler, err = pkgProfiler.New(pkgProfiler.Config{
+			Interval:    a.config.Profile.Interval,
+			CPUDuration: a.config.Profile.CPUDuration,
+			Types:       a.config.Profile.Types,
+		}, store)
+		if err != nil {
+			return err
+		}
+		profiles = store
+		log.Printf("Непрерывное профилирование: %v раз в %v в каталог %s",
+			a.config.Profile.Types, a.config.Profile.Interval, a.config.Profile.Dir)
+	}
+
 	// Создание HTTP сервера
-	router := transport.NewRouter()
+	router := transport.NewRouter(transport.Dependencies{
+		Agent:      a.agent,
+		Tuner:      pkgTuning.New(),
+		Profiles:   profiles,
+		AdminToken: a.config.AdminToken,
+	})
 
 	a.server = &http.Server{
 		Addr:         ":" + a.config.Port,
@@ -73,12 +147,22 @@ func (a *App) Start() error {
 		}
 	}()
 
+	if a.profiler != nil {
+		var ctx context.Context
+		ctx, a.stopProfiler = context.WithCancel(context.Background())
+		go a.profiler.Run(ctx)
+	}
+
 	return nil
 }
 
 // Stop останавливает сервер
 func (a *App) Stop(ctx context.Context) error {
 	log.Println("Остановка сервера...")
+	if a.stopProfiler != nil {
+		a.stopProfiler()
+	}
+	a.agent.Close()
 	return a.server.Shutdown(ctx)
 }
 
@@ -121,5 +205,20 @@ func DefaultConfig() Config {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		// час истории при снимке раз в 5 секунд
+		SampleInterval: 5 * time.Second,
+		HistorySize:    720,
+		// профилирование отключено: оно пишет на диск и занимает CPU профиль,
+		// нужный /debug/pprof/profile. Ограничения хранилища действуют после
+		// включения через Profile.Interval и Profile.Dir
+		Profile: ProfileConfig{
+			CPUDuration: 10 * time.Second,
+			Types:       pkgProfiler.Types,
+			Retention: pkgProfiler.Retention{
+				MaxCount: 2000,
+				MaxBytes: 256 << 20,
+				MaxAge:   6 * time.Hour,
+			},
+		},
 	}
 }
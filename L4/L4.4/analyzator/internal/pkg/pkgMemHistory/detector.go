@@ -0,0 +1,225 @@
+package pkgMemHistory
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Типы оповещений
+const (
+	AlertHeapGrowth  = "heap_growth"
+	AlertGCFrequency = "gc_frequency"
+	AlertPauseP99    = "gc_pause_p99"
+)
+
+// AlertTypes все типы оповещений
+var AlertTypes = []string{AlertHeapGrowth, AlertGCFrequency, AlertPauseP99}
+
+// Alert оповещение об аномалии
+type Alert struct {
+	Type      string    `json:"type"`
+	Message   string    `json:"message"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Since     time.Time `json:"since"`
+}
+
+// DetectorConfig пороги детектора аномалий.
+// Частота GC и p99 пауз сравниваются между первой и второй половиной окна
+type DetectorConfig struct {
+	// Window окно анализа
+	Window time.Duration
+	// MinSamples минимальное количество снимков в окне для анализа
+	MinSamples int
+	// HeapGrowthPerMinute относительный рост heap в минуту по линейному тренду
+	HeapGrowthPerMinute float64
+	// MinHeapGrowthR2 минимальный коэффициент детерминации тренда,
+	// чтобы пилообразный heap между сборками не считался ростом
+	MinHeapGrowthR2 float64
+	// GCRateFactor во сколько раз должна вырасти частота GC
+	GCRateFactor float64
+	// MinGCRate минимальная частота GC в минуту во второй половине окна
+	MinGCRate float64
+	// PauseP99Factor во сколько раз должен вырасти p99 пауз GC
+	PauseP99Factor float64
+	// MinPauseP99 минимальный p99 пауз во второй половине окна
+	MinPauseP99 time.Duration
+	// MinPauses минимальное количество пауз в каждой половине окна
+	MinPauses int
+}
+
+// DefaultDetectorConfig возвращает пороги по умолчанию
+func DefaultDetectorConfig() DetectorConfig {
+	return DetectorConfig{
+		Window:              10 * time.Minute,
+		MinSamples:          12,
+		HeapGrowthPerMinute: 0.05,
+		MinHeapGrowthR2:     0.8,
+		GCRateFactor:        2,
+		MinGCRate:           6,
+		PauseP99Factor:      2,
+		MinPauseP99:         time.Millisecond,
+		MinPauses:           20,
+	}
+}
+
+// detect ищет аномалии в снимках, упорядоченных по времени
+func detect(samples []Sample, config DetectorConfig) []Alert {
+	if len(samples) < max(config.MinSamples, 2) {
+		return nil
+	}
+
+	var alerts []Alert
+
+	if alert, ok := detectHeapGrowth(samples, config); ok {
+		alerts = append(alerts, alert)
+	}
+
+	half := len(samples) / 2
+	baseline, recent := samples[:half+1], samples[half:]
+
+	if alert, ok := detectGCFrequency(baseline, recent, config); ok {
+		alerts = append(alerts, alert)
+	}
+	if alert, ok := detectPauseP99(baseline, recent, config); ok {
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+// detectHeapGrowth ищет устойчивый линейный рост HeapAlloc
+func detectHeapGrowth(samples []Sample, config DetectorConfig) (Alert, bool) {
+	start := samples[0].Time
+	xs := make([]float64, len(samples))
+	ys := make([]float64, len(samples))
+	for i, s := range samples {
+		xs[i] = s.Time.Sub(start).Minutes()
+		ys[i] = float64(s.HeapAlloc)
+	}
+
+	slope, r2, mean := linearTrend(xs, ys)
+	if mean == 0 || r2 < config.MinHeapGrowthR2 {
+		return Alert{}, false
+	}
+
+	growth := slope / mean
+	if growth < config.HeapGrowthPerMinute {
+		return Alert{}, false
+	}
+
+	return Alert{
+		Type: AlertHeapGrowth,
+		Message: fmt.Sprintf("heap растет на %.1f%% в минуту (%.0f байт/мин, R²=%.2f)",
+			growth*100, slope, r2),
+		Value:     growth,
+		Threshold: config.HeapGrowthPerMinute,
+	}, true
+}
+
+// linearTrend возвращает наклон линейной регрессии y по x,
+// коэффициент детерминации и среднее y
+func linearTrend(xs, ys []float64) (slope, r2, mean float64) {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, 0, meanY
+	}
+
+	slope = sxy / sxx
+	r2 = sxy * sxy / (sxx * syy)
+	return slope, r2, meanY
+}
+
+// detectGCFrequency сравнивает частоту GC в двух половинах окна
+func detectGCFrequency(baseline, recent []Sample, config DetectorConfig) (Alert, bool) {
+	baseRate := gcRate(baseline)
+	recentRate := gcRate(recent)
+
+	if recentRate < config.MinGCRate || recentRate < baseRate*config.GCRateFactor {
+		return Alert{}, false
+	}
+
+	return Alert{
+		Type: AlertGCFrequency,
+		Message: fmt.Sprintf("частота GC выросла с %.1f до %.1f сборок в минуту",
+			baseRate, recentRate),
+		Value:     recentRate,
+		Threshold: max(baseRate*config.GCRateFactor, config.MinGCRate),
+	}, true
+}
+
+// gcRate возвращает количество сборок в минуту между первым и последним снимком.
+// Принудительные сборки (runtime.GC) не учитываются
+func gcRate(samples []Sample) float64 {
+	first, last := samples[0], samples[len(samples)-1]
+	minutes := last.Time.Sub(first.Time).Minutes()
+	if minutes <= 0 {
+		return 0
+	}
+	collections := (last.NumGC - last.NumForcedGC) - (first.NumGC - first.NumForcedGC)
+	return float64(collections) / minutes
+}
+
+// detectPauseP99 сравнивает p99 пауз GC в двух половинах окна.
+// Паузы первого снимка половины относятся к периоду до нее
+func detectPauseP99(baseline, recent []Sample, config DetectorConfig) (Alert, bool) {
+	basePauses := pauseDurations(baseline[1:])
+	recentPauses := pauseDurations(recent[1:])
+	if len(basePauses) < config.MinPauses || len(recentPauses) < config.MinPauses {
+		return Alert{}, false
+	}
+
+	baseP99 := Percentile(basePauses, 0.99)
+	recentP99 := Percentile(recentPauses, 0.99)
+
+	threshold := time.Duration(float64(baseP99) * config.PauseP99Factor)
+	if recentP99 < config.MinPauseP99 || recentP99 < threshold {
+		return Alert{}, false
+	}
+
+	return Alert{
+		Type:      AlertPauseP99,
+		Message:   fmt.Sprintf("p99 пауз GC вырос с %v до %v", baseP99, recentP99),
+		Value:     recentP99.Seconds(),
+		Threshold: max(threshold, config.MinPauseP99).Seconds(),
+	}, true
+}
+
+func pauseDurations(samples []Sample) []time.Duration {
+	var pauses []time.Duration
+	for _, s := range samples {
+		for _, p := range s.Pauses {
+			pauses = append(pauses, p.Duration)
+		}
+	}
+	return pauses
+}
+
+// Percentile возвращает перцентиль q (от 0 до 1) длительностей методом nearest-rank
+func Percentile(durations []time.Duration, q float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
@@ -0,0 +1,200 @@
+// Package pkgMemHistory хранит историю runtime.MemStats в кольцевом буфере
+// и ищет в ней аномалии: рост heap, учащение GC и рост пауз GC
+package pkgMemHistory
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// pauseBufferSize размер циклических буферов PauseNs и PauseEnd в runtime.MemStats
+const pauseBufferSize = uint32(len(runtime.MemStats{}.PauseNs))
+
+// Pause пауза одной сборки мусора
+type Pause struct {
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Sample снимок основных полей MemStats.
+// Pauses содержит все паузы GC, завершившиеся после предыдущего снимка
+type Sample struct {
+	Time          time.Time `json:"time"`
+	HeapAlloc     uint64    `json:"heap_alloc"`
+	HeapInuse     uint64    `json:"heap_inuse"`
+	HeapIdle      uint64    `json:"heap_idle"`
+	HeapReleased  uint64    `json:"heap_released"`
+	HeapSys       uint64    `json:"heap_sys"`
+	HeapObjects   uint64    `json:"heap_objects"`
+	StackInuse    uint64    `json:"stack_inuse"`
+	StackSys      uint64    `json:"stack_sys"`
+	Sys           uint64    `json:"sys"`
+	TotalAlloc    uint64    `json:"total_alloc"`
+	Mallocs       uint64    `json:"mallocs"`
+	Frees         uint64    `json:"frees"`
+	NextGC        uint64    `json:"next_gc"`
+	NumGC         uint32    `json:"num_gc"`
+	NumForcedGC   uint32    `json:"num_forced_gc"`
+	GCCPUFraction float64   `json:"gc_cpu_fraction"`
+	PauseTotalNs  uint64    `json:"pause_total_ns"`
+	Pauses        []Pause   `json:"pauses"`
+	// LostPauses количество пауз, вытесненных из буферов MemStats до чтения
+	LostPauses uint32 `json:"lost_pauses,omitempty"`
+}
+
+// History кольцевой буфер снимков памяти с детектором аномалий
+type History struct {
+	mu        sync.RWMutex
+	samples   []Sample
+	next      int
+	size      int
+	lastNumGC uint32
+	config    DetectorConfig
+	alerts    []Alert
+}
+
+// New создает историю на capacity снимков
+func New(capacity int, config DetectorConfig) *History {
+	return &History{
+		samples: make([]Sample, capacity),
+		config:  config,
+	}
+}
+
+// Record сохраняет снимок memStats, проверяет историю на аномалии
+// и возвращает сохраненный снимок
+func (h *History) Record(memStats *runtime.MemStats) Sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sample := Sample{
+		Time:          time.Now(),
+		HeapAlloc:     memStats.HeapAlloc,
+		HeapInuse:     memStats.HeapInuse,
+		HeapIdle:      memStats.HeapIdle,
+		HeapReleased:  memStats.HeapReleased,
+		HeapSys:       memStats.HeapSys,
+		HeapObjects:   memStats.HeapObjects,
+		StackInuse:    memStats.StackInuse,
+		StackSys:      memStats.StackSys,
+		Sys:           memStats.Sys,
+		TotalAlloc:    memStats.TotalAlloc,
+		Mallocs:       memStats.Mallocs,
+		Frees:         memStats.Frees,
+		NextGC:        memStats.NextGC,
+		NumGC:         memStats.NumGC,
+		NumForcedGC:   memStats.NumForcedGC,
+		GCCPUFraction: memStats.GCCPUFraction,
+		PauseTotalNs:  memStats.PauseTotalNs,
+	}
+	sample.Pauses, sample.LostPauses = pausesSince(memStats, h.lastNumGC)
+	h.lastNumGC = memStats.NumGC
+
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % len(h.samples)
+	h.size = min(h.size+1, len(h.samples))
+
+	h.updateAlerts(sample.Time)
+
+	return sample
+}
+
+// pausesSince возвращает паузы сборок с номерами после lastNumGC.
+// Буферы PauseNs и PauseEnd хранят только последние 256 пауз,
+// более старые считаются потерянными
+func pausesSince(memStats *runtime.MemStats, lastNumGC uint32) ([]Pause, uint32) {
+	count := memStats.NumGC - lastNumGC
+	var lost uint32
+	if count > pauseBufferSize {
+		lost = count - pauseBufferSize
+		count = pauseBufferSize
+	}
+
+	pauses := make([]Pause, 0, count)
+	for gc := memStats.NumGC - count + 1; gc <= memStats.NumGC; gc++ {
+		// пауза сборки с номером gc лежит в элементе (gc+255)%256
+		i := (gc + pauseBufferSize - 1) % pauseBufferSize
+		pauses = append(pauses, Pause{
+			End:      time.Unix(0, int64(memStats.PauseEnd[i])),
+			Duration: time.Duration(memStats.PauseNs[i]),
+		})
+	}
+
+	return pauses, lost
+}
+
+// Window возвращает снимки за последний период window от старых к новым.
+// При window <= 0 возвращается вся история
+func (h *History) Window(window time.Duration) []Sample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.window(window, time.Now())
+}
+
+func (h *History) window(window time.Duration, now time.Time) []Sample {
+	samples := make([]Sample, 0, h.size)
+	start := (h.next - h.size + len(h.samples)) % len(h.samples)
+
+	for i := range h.size {
+		sample := h.samples[(start+i)%len(h.samples)]
+		if window > 0 && now.Sub(sample.Time) > window {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples
+}
+
+// Capacity возвращает максимальное количество снимков в истории
+func (h *History) Capacity() int {
+	return len(h.samples)
+}
+
+// Alerts возвращает активные оповещения об аномалиях
+func (h *History) Alerts() []Alert {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	alerts := make([]Alert, len(h.alerts))
+	copy(alerts, h.alerts)
+	return alerts
+}
+
+// updateAlerts пересчитывает оповещения по окну детектора.
+// Для продолжающихся аномалий сохраняется время обнаружения
+func (h *History) updateAlerts(now time.Time) {
+	alerts := detect(h.window(h.config.Window, now), h.config)
+
+	for i := range alerts {
+		alerts[i].Since = now
+		for _, prev := range h.alerts {
+			if prev.Type == alerts[i].Type {
+				alerts[i].Since = prev.Since
+			}
+		}
+		if alerts[i].Since == now {
+			log.Printf("Обнаружена аномалия %s: %s", alerts[i].Type, alerts[i].Message)
+		}
+	}
+
+	for _, prev := range h.alerts {
+		if !hasAlert(alerts, prev.Type) {
+			log.Printf("Аномалия %s больше не наблюдается", prev.Type)
+		}
+	}
+
+	h.alerts = alerts
+}
+
+func hasAlert(alerts []Alert, alertType string) bool {
+	for _, a := range alerts {
+		if a.Type == alertType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package pkgMemHistory
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// memStatsWithPauses заполняет циклические буферы пауз как runtime:
+// пауза сборки n лежит в элементе (n+255)%256 и длится n микросекунд
+func memStatsWithPauses(numGC uint32) *runtime.MemStats {
+	var ms runtime.MemStats
+	ms.NumGC = numGC
+	start := max(int64(numGC)-int64(pauseBufferSize)+1, 1)
+	for gc := start; gc <= int64(numGC); gc++ {
+		i := (gc + int64(pauseBufferSize) - 1) % int64(pauseBufferSize)
+		ms.PauseNs[i] = uint64(gc) * 1000
+		ms.PauseEnd[i] = uint64(gc) * 1e9
+	}
+	return &ms
+}
+
+func TestPausesSince(t *testing.T) {
+	tests := []struct {
+		name      string
+		numGC     uint32
+		lastNumGC uint32
+		first     uint32
+		count     int
+		lost      uint32
+	}{
+		{"без новых сборок", 10, 10, 0, 0, 0},
+		{"несколько сборок", 10, 7, 8, 3, 0},
+		{"переход через конец буфера", 300, 250, 251, 50, 0},
+		{"буфер переполнен", 600, 100, 345, 256, 244},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pauses, lost := pausesSince(memStatsWithPauses(tt.numGC), tt.lastNumGC)
+			if len(pauses) != tt.count || lost != tt.lost {
+				t.Fatalf("получено %d пауз и %d потерянных, ожидалось %d и %d", len(pauses), lost, tt.count, tt.lost)
+			}
+			for i, p := range pauses {
+				gc := tt.first + uint32(i)
+				if p.Duration != time.Duration(gc)*time.Microsecond || !p.End.Equal(time.Unix(int64(gc), 0)) {
+					t.Errorf("пауза %d: %v в %v, ожидалась сборка %d", i, p.Duration, p.End, gc)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryWindow(t *testing.T) {
+	h := New(3, DefaultDetectorConfig())
+	for i := range 5 {
+		h.Record(memStatsWithPauses(uint32(i)))
+	}
+
+	samples := h.Window(0)
+	if len(samples) != 3 {
+		t.Fatalf("в истории %d снимков, ожидалось 3", len(samples))
+	}
+	for i, s := range samples {
+		if s.NumGC != uint32(i+2) {
+			t.Errorf("снимок %d: NumGC=%d, ожидалось %d", i, s.NumGC, i+2)
+		}
+	}
+
+	if got := h.window(time.Minute, samples[2].Time.Add(2*time.Minute)); len(got) != 0 {
+		t.Errorf("в окне минуту назад %d снимков, ожидалось 0", len(got))
+	}
+}
+
+// series строит снимки раз в 5 секунд
+func series(n int, fill func(i int, s *Sample)) []Sample {
+	start := time.Unix(1700000000, 0)
+	samples := make([]Sample, n)
+	for i := range samples {
+		samples[i].Time = start.Add(time.Duration(i) * 5 * time.Second)
+		fill(i, &samples[i])
+	}
+	return samples
+}
+
+func alertTypes(alerts []Alert) map[string]bool {
+	types := make(map[string]bool)
+	for _, a := range alerts {
+		types[a.Type] = true
+	}
+	return types
+}
+
+func TestDetect(t *testing.T) {
+	config := DefaultDetectorConfig()
+	const mb = 1 << 20
+
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    []string
+	}{
+		{
+			name: "стабильная нагрузка",
+			samples: series(60, func(i int, s *Sample) {
+				// пила между сборками без тренда
+				s.HeapAlloc = uint64(50*mb + (i%4)*5*mb)
+				s.NumGC = uint32(i)
+				s.Pauses = []Pause{{Duration: time.Duration(100+i%3*10) * time.Microsecond}}
+			}),
+		},
+		{
+			name: "утечка",
+			samples: series(60, func(i int, s *Sample) {
+				s.HeapAlloc = uint64(50*mb + i*mb)
+				s.NumGC = uint32(i)
+			}),
+			want: []string{AlertHeapGrowth},
+		},
+		{
+			name: "учащение GC",
+			samples: series(60, func(i int, s *Sample) {
+				s.HeapAlloc = 50 * mb
+				if i < 30 {
+					s.NumGC = uint32(i)
+				} else {
+					s.NumGC = uint32(30 + (i-30)*5)
+				}
+			}),
+			want: []string{AlertGCFrequency},
+		},
+		{
+			name: "рост p99 пауз",
+			samples: series(60, func(i int, s *Sample) {
+				s.HeapAlloc = 50 * mb
+				s.NumGC = uint32(i)
+				pause := 200 * time.Microsecond
+				if i > 30 && i%3 == 0 {
+					pause = 5 * time.Millisecond
+				}
+				s.Pauses = []Pause{{Duration: pause}}
+			}),
+			want: []string{AlertPauseP99},
+		},
+		{
+			name: "мало снимков",
+			samples: series(5, func(i int, s *Sample) {
+				s.HeapAlloc = uint64(i * 100 * mb)
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := alertTypes(detect(tt.samples, config))
+			if len(got) != len(tt.want) {
+				t.Fatalf("оповещения %v, ожидались %v", got, tt.want)
+			}
+			for _, w := range tt.want {
+				if !got[w] {
+					t.Errorf("нет оповещения %s, получены %v", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i))
+	}
+
+	for q, want := range map[float64]time.Duration{0.5: 50, 0.99: 99, 1: 100, 0: 1} {
+		if got := Percentile(durations, q); got != want {
+			t.Errorf("Percentile(%v) = %v, ожидалось %v", q, got, want)
+		}
+	}
+	if Percentile(nil, 0.99) != 0 {
+		t.Errorf("перцентиль пустого списка не равен 0")
+	}
+}
@@ -0,0 +1,205 @@
+package pkgProfiler
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/pprof/profile"
+)
+
+// ErrIncompatible профили нельзя сравнить
+var ErrIncompatible = errors.New("профили несовместимы")
+
+// DefaultTop количество функций в результате сравнения по умолчанию
+const DefaultTop = 20
+
+// FunctionDelta изменение значения функции между профилями.
+// Flat учитывает только выборки, где функция на вершине стека,
+// Cum все выборки, где функция есть в стеке
+type FunctionDelta struct {
+	Function   string `json:"function"`
+	File       string `json:"file,omitempty"`
+	BaseFlat   int64  `json:"base_flat"`
+	TargetFlat int64  `json:"target_flat"`
+	DeltaFlat  int64  `json:"delta_flat"`
+	BaseCum    int64  `json:"base_cum"`
+	TargetCum  int64  `json:"target_cum"`
+	DeltaCum   int64  `json:"delta_cum"`
+}
+
+// DiffResult результат сравнения двух профилей
+type DiffResult struct {
+	Base        Profile `json:"base"`
+	Target      Profile `json:"target"`
+	SampleType  string  `json:"sample_type"`
+	Unit        string  `json:"unit"`
+	BaseTotal   int64   `json:"base_total"`
+	TargetTotal int64   `json:"target_total"`
+	// Regressions функции с наибольшим ростом flat значения
+	Regressions []FunctionDelta `json:"regressions"`
+}
+
+// defaultSampleTypes тип значения по умолчанию для сравнения
+var defaultSampleTypes = map[string]string{
+	TypeCPU:  "cpu",
+	TypeHeap: "inuse_space",
+}
+
+// Diff сравнивает профили baseID и targetID одного типа (cpu или heap)
+// по значению sampleType (по умолчанию cpu и inuse_space) и возвращает
+// top функций с наибольшим ростом
+func (s *Store) Diff(baseID, targetID, sampleType string, top int) (DiffResult, error) {
+	base, baseProf, err := s.parse(baseID)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	target, targetProf, err := s.parse(targetID)
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	if base.Type != target.Type {
+		return DiffResult{}, fmt.Errorf("%w: %s и %s", ErrIncompatible, base.Type, target.Type)
+	}
+	if _, ok := defaultSampleTypes[base.Type]; !ok {
+		return DiffResult{}, fmt.Errorf("%w: сравниваются только профили cpu и heap", ErrIncompatible)
+	}
+	if sampleType == "" {
+		sampleType = defaultSampleTypes[base.Type]
+	}
+	if top <= 0 {
+		top = DefaultTop
+	}
+
+	baseIndex, unit, err := sampleIndex(baseProf, sampleType)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	targetIndex, targetUnit, err := sampleIndex(targetProf, sampleType)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	if unit != targetUnit {
+		return DiffResult{}, fmt.Errorf("%w: единицы %s и %s", ErrIncompatible, unit, targetUnit)
+	}
+
+	result := DiffResult{Base: base, Target: target, SampleType: sampleType, Unit: unit}
+
+	deltas := make(map[functionKey]*FunctionDelta)
+	result.BaseTotal = aggregate(baseProf, baseIndex, deltas, func(d *FunctionDelta, flat, cum int64) {
+		d.BaseFlat += flat
+		d.BaseCum += cum
+	})
+	result.TargetTotal = aggregate(targetProf, targetIndex, deltas, func(d *FunctionDelta, flat, cum int64) {
+		d.TargetFlat += flat
+		d.TargetCum += cum
+	})
+
+	result.Regressions = make([]FunctionDelta, 0, len(deltas))
+	for _, d := range deltas {
+		d.DeltaFlat = d.TargetFlat - d.BaseFlat
+		d.DeltaCum = d.TargetCum - d.BaseCum
+		if d.DeltaFlat > 0 || d.DeltaCum > 0 {
+			result.Regressions = append(result.Regressions, *d)
+		}
+	}
+	slices.SortFunc(result.Regressions, func(a, b FunctionDelta) int {
+		return cmp.Or(
+			cmp.Compare(b.DeltaFlat, a.DeltaFlat),
+			cmp.Compare(b.DeltaCum, a.DeltaCum),
+			cmp.Compare(a.Function, b.Function),
+		)
+	})
+	if len(result.Regressions) > top {
+		result.Regressions = result.Regressions[:top]
+	}
+
+	return result, nil
+}
+
+// parse читает и разбирает профиль
+func (s *Store) parse(id string) (Profile, *profile.Profile, error) {
+	p, data, err := s.Read(id)
+	if err != nil {
+		return Profile{}, nil, err
+	}
+	prof, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return Profile{}, nil, fmt.Errorf("не удалось разобрать профиль %s: %w", id, err)
+	}
+	return p, prof, nil
+}
+
+// sampleIndex возвращает индекс и единицу значения sampleType в профиле
+func sampleIndex(prof *profile.Profile, sampleType string) (int, string, error) {
+	for i, st := range prof.SampleType {
+		if st.Type == sampleType {
+			return i, st.Unit, nil
+		}
+	}
+
+	types := make([]string, len(prof.SampleType))
+	for i, st := range prof.SampleType {
+		types[i] = st.Type
+	}
+	return 0, "", fmt.Errorf("%w: в профиле нет значения %s, доступны %v", ErrIncompatible, sampleType, types)
+}
+
+type functionKey struct {
+	name string
+	file string
+}
+
+// aggregate суммирует значения выборок по функциям и возвращает общую сумму.
+// Рекурсивная функция учитывается в cum один раз на выборку
+func aggregate(prof *profile.Profile, index int, deltas map[functionKey]*FunctionDelta, add func(d *FunctionDelta, flat, cum int64)) int64 {
+	var total int64
+	seen := make(map[functionKey]bool)
+
+	for _, sample := range prof.Sample {
+		value := sample.Value[index]
+		if value == 0 {
+			continue
+		}
+		total += value
+		clear(seen)
+
+		leaf := true
+		for _, loc := range sample.Location {
+			lines := loc.Line
+			if len(lines) == 0 {
+				// адрес без символов
+				lines = []profile.Line{{}}
+			}
+			// встроенные функции идут в Line от внутренней к внешней
+			for _, line := range lines {
+				key := functionKey{name: "?"}
+				if line.Function != nil {
+					key = functionKey{name: line.Function.Name, file: line.Function.Filename}
+				}
+
+				d, ok := deltas[key]
+				if !ok {
+					d = &FunctionDelta{Function: key.name, File: key.file}
+					deltas[key] = d
+				}
+
+				var flat, cum int64
+				if leaf {
+					flat = value
+					leaf = false
+				}
+				if !seen[key] {
+					seen[key] = true
+					cum = value
+				}
+				add(d, flat, cum)
+			}
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,118 @@
+// Package pkgProfiler периодически снимает профили CPU, heap, goroutine,
+// mutex и block и хранит их на диске с ограничением по количеству,
+// объему и возрасту. Два профиля одного типа можно сравнить (Diff)
+package pkgProfiler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"runtime/pprof"
+	"slices"
+	"time"
+)
+
+// Типы профилей
+const (
+	TypeCPU       = "cpu"
+	TypeHeap      = "heap"
+	TypeGoroutine = "goroutine"
+	TypeMutex     = "mutex"
+	TypeBlock     = "block"
+)
+
+// Types все типы профилей
+var Types = []string{TypeCPU, TypeHeap, TypeGoroutine, TypeMutex, TypeBlock}
+
+var logf = log.Printf
+
+// Config настройки профилировщика
+type Config struct {
+	// Interval интервал между снятиями профилей
+	Interval time.Duration
+	// CPUDuration длительность CPU профиля, не больше Interval
+	CPUDuration time.Duration
+	// Types снимаемые типы профилей
+	Types []string
+}
+
+// Profiler снимает профили по расписанию и сохраняет их в Store
+type Profiler struct {
+	config Config
+	store  *Store
+}
+
+// New создает профилировщик
+func New(config Config, store *Store) (*Profiler, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("интервал профилирования должен быть > 0")
+	}
+	for _, t := range config.Types {
+		if !slices.Contains(Types, t) {
+			return nil, fmt.Errorf("неизвестный тип профиля: %s", t)
+		}
+	}
+	config.CPUDuration = min(config.CPUDuration, config.Interval)
+
+	return &Profiler{config: config, store: store}, nil
+}
+
+// Store возвращает хранилище профилей
+func (p *Profiler) Store() *Store {
+	return p.store
+}
+
+// Run снимает профили каждые Interval до отмены ctx
+func (p *Profiler) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, t := range p.config.Types {
+			if _, err := p.Capture(ctx, t); err != nil && ctx.Err() == nil {
+				logf("Не удалось снять профиль %s: %v", t, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Capture снимает и сохраняет профиль типа profileType.
+// CPU профиль снимается CPUDuration и не может сниматься одновременно
+// с другим CPU профилем, например запрошенным через /debug/pprof/profile
+func (p *Profiler) Capture(ctx context.Context, profileType string) (Profile, error) {
+	var buf bytes.Buffer
+	at := time.Now()
+
+	switch profileType {
+	case TypeCPU:
+		if p.config.CPUDuration <= 0 {
+			return Profile{}, fmt.Errorf("длительность CPU профиля не задана")
+		}
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return Profile{}, err
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.config.CPUDuration):
+		}
+		pprof.StopCPUProfile()
+		if err := ctx.Err(); err != nil {
+			return Profile{}, err
+		}
+	case TypeHeap, TypeGoroutine, TypeMutex, TypeBlock:
+		if err := pprof.Lookup(profileType).WriteTo(&buf, 0); err != nil {
+			return Profile{}, err
+		}
+	default:
+		return Profile{}, fmt.Errorf("неизвестный тип профиля: %s", profileType)
+	}
+
+	return p.store.Save(profileType, at, buf.Bytes())
+}
@@ -0,0 +1,176 @@
+package pkgProfiler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+func TestStoreRetention(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, Retention{MaxCount: 3, MaxBytes: 250})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i := range 5 {
+		if _, err := store.Save(TypeHeap, now.Add(time.Duration(i)*time.Second), make([]byte, 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 3 профиля по 100 байт превышают 250 байт
+	profiles := store.List("")
+	if len(profiles) != 2 {
+		t.Fatalf("в хранилище %d профилей, ожидалось 2", len(profiles))
+	}
+	if !profiles[0].Time.After(profiles[1].Time) {
+		t.Errorf("профили не упорядочены от новых к старым")
+	}
+
+	// список восстанавливается из каталога, новое ограничение по возрасту применяется сразу
+	reopened, err := NewStore(dir, Retention{MaxAge: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.List(TypeHeap); len(got) != 2 || got[0].ID != profiles[0].ID {
+		t.Fatalf("после перезапуска %v, ожидалось %v", got, profiles)
+	}
+	if _, err := reopened.Save(TypeHeap, now.Add(-time.Hour), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.List(""); len(got) != 2 {
+		t.Errorf("устаревший профиль не удален: %v", got)
+	}
+
+	if _, _, err := reopened.Read("heap-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ошибка для неизвестного профиля %v, ожидалась ErrNotFound", err)
+	}
+}
+
+// heapProfile строит heap профиль, в котором функции из allocs
+// выделили указанное количество байт со стеком leaf <- main
+func heapProfile(allocs map[string]int64) []byte {
+	mainFn := &profile.Function{ID: 1, Name: "main.main"}
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		Function: []*profile.Function{mainFn},
+		Location: []*profile.Location{{ID: 1, Line: []profile.Line{{Function: mainFn}}}},
+	}
+
+	for name, bytes := range allocs {
+		id := uint64(len(prof.Function) + 1)
+		fn := &profile.Function{ID: id, Name: name}
+		loc := &profile.Location{ID: id, Line: []profile.Line{{Function: fn}}}
+		prof.Function = append(prof.Function, fn)
+		prof.Location = append(prof.Location, loc)
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: []*profile.Location{loc, prof.Location[0]},
+			Value:    []int64{1, bytes},
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := prof.Write(&buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDiff(t *testing.T) {
+	store, err := NewStore(t.TempDir(), Retention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	base, _ := store.Save(TypeHeap, now, heapProfile(map[string]int64{
+		"pkg.cache": 1000, "pkg.buffer": 500, "pkg.stable": 300,
+	}))
+	target, _ := store.Save(TypeHeap, now.Add(time.Minute), heapProfile(map[string]int64{
+		"pkg.cache": 5000, "pkg.buffer": 200, "pkg.stable": 300, "pkg.leak": 2000,
+	}))
+
+	result, err := store.Diff(base.ID, target.ID, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SampleType != "inuse_space" || result.Unit != "bytes" {
+		t.Errorf("значение %s в %s, ожидалось inuse_space в bytes", result.SampleType, result.Unit)
+	}
+	if result.BaseTotal != 1800 || result.TargetTotal != 7500 {
+		t.Errorf("суммы %d и %d, ожидалось 1800 и 7500", result.BaseTotal, result.TargetTotal)
+	}
+
+	want := []FunctionDelta{
+		{Function: "pkg.cache", BaseFlat: 1000, TargetFlat: 5000, DeltaFlat: 4000, BaseCum: 1000, TargetCum: 5000, DeltaCum: 4000},
+		{Function: "pkg.leak", TargetFlat: 2000, DeltaFlat: 2000, TargetCum: 2000, DeltaCum: 2000},
+	}
+	if len(result.Regressions) != len(want) {
+		t.Fatalf("регрессии %+v, ожидались %+v", result.Regressions, want)
+	}
+	for i := range want {
+		if result.Regressions[i] != want[i] {
+			t.Errorf("регрессия %d: %+v, ожидалась %+v", i, result.Regressions[i], want[i])
+		}
+	}
+
+	// main.main есть в стеке каждой выборки, но сам ничего не выделяет
+	all, err := store.Diff(base.ID, target.ID, "inuse_space", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range all.Regressions {
+		if d.Function == "main.main" && (d.DeltaFlat != 0 || d.DeltaCum != 5700) {
+			t.Errorf("main.main: %+v, ожидался только рост cum на 5700", d)
+		}
+		if d.Function == "pkg.stable" || d.Function == "pkg.buffer" {
+			t.Errorf("%s не вырос, но попал в регрессии", d.Function)
+		}
+	}
+
+	if _, err := store.Diff(base.ID, target.ID, "cpu", 0); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("ошибка для отсутствующего значения %v, ожидалась ErrIncompatible", err)
+	}
+	goroutines, _ := store.Save(TypeGoroutine, now, heapProfile(nil))
+	if _, err := store.Diff(base.ID, goroutines.ID, "", 0); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("ошибка для профилей разных типов %v, ожидалась ErrIncompatible", err)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	store, err := NewStore(t.TempDir(), Retention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiler, err := New(Config{Interval: time.Minute, CPUDuration: 50 * time.Millisecond, Types: Types}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, profileType := range Types {
+		p, err := profiler.Capture(context.Background(), profileType)
+		if err != nil {
+			t.Fatalf("профиль %s: %v", profileType, err)
+		}
+		_, data, err := store.Read(p.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := profile.Parse(bytes.NewReader(data)); err != nil {
+			t.Errorf("профиль %s не разбирается: %v", profileType, err)
+		}
+	}
+
+	if _, err := New(Config{Interval: time.Minute, Types: []string{"threadcreate"}}, store); err == nil {
+		t.Errorf("неизвестный тип профиля принят")
+	}
+}
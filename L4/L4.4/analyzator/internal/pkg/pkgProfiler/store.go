@@ -0,0 +1,204 @@
+package pkgProfiler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// profileExt расширение файлов профилей: gzip-сжатый protobuf
+const profileExt = ".pb.gz"
+
+// ErrNotFound профиль не найден
+var ErrNotFound = errors.New("профиль не найден")
+
+// idPattern идентификатор профиля: <тип>-<время в наносекундах>
+var idPattern = regexp.MustCompile(`^([a-z]+)-(\d+)$`)
+
+// Profile описание сохраненного профиля
+type Profile struct {
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// Retention ограничения хранилища, нулевое значение снимает ограничение
+type Retention struct {
+	MaxCount int
+	MaxBytes int64
+	MaxAge   time.Duration
+}
+
+// Store хранит профили в каталоге. Список профилей восстанавливается
+// из имен файлов, поэтому профили переживают перезапуск
+type Store struct {
+	dir       string
+	retention Retention
+
+	mu       sync.Mutex
+	profiles []Profile // от старых к новым
+}
+
+// NewStore открывает хранилище в каталоге dir, создавая его при необходимости
+func NewStore(dir string, retention Retention) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог профилей: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать каталог профилей: %w", err)
+	}
+
+	s := &Store{dir: dir, retention: retention}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), profileExt)
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		p, ok := parseID(id)
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		p.Size = info.Size()
+		s.profiles = append(s.profiles, p)
+	}
+	slices.SortFunc(s.profiles, compareProfiles)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enforceRetention(time.Now())
+
+	return s, nil
+}
+
+func parseID(id string) (Profile, bool) {
+	m := idPattern.FindStringSubmatch(id)
+	if m == nil {
+		return Profile{}, false
+	}
+	nanos, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return Profile{}, false
+	}
+	return Profile{ID: id, Type: m[1], Time: time.Unix(0, nanos)}, true
+}
+
+func compareProfiles(a, b Profile) int {
+	return a.Time.Compare(b.Time)
+}
+
+// Save сохраняет профиль типа profileType, снятый в момент at,
+// и удаляет профили, выходящие за ограничения хранилища
+func (s *Store) Save(profileType string, at time.Time, data []byte) (Profile, error) {
+	p := Profile{
+		ID:   fmt.Sprintf("%s-%d", profileType, at.UnixNano()),
+		Type: profileType,
+		Time: at,
+		Size: int64(len(data)),
+	}
+
+	// запись во временный файл и переименование, чтобы в списке
+	// никогда не оказался недописанный профиль
+	tmp := filepath.Join(s.dir, "."+p.ID+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return Profile{}, fmt.Errorf("не удалось записать профиль: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(p.ID)); err != nil {
+		os.Remove(tmp)
+		return Profile{}, fmt.Errorf("не удалось записать профиль: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.profiles = append(s.profiles, p)
+	slices.SortFunc(s.profiles, compareProfiles)
+	s.enforceRetention(time.Now())
+
+	return p, nil
+}
+
+// enforceRetention удаляет старые профили; вызывается под s.mu
+func (s *Store) enforceRetention(now time.Time) {
+	var total int64
+	for _, p := range s.profiles {
+		total += p.Size
+	}
+
+	for len(s.profiles) > 0 {
+		oldest := s.profiles[0]
+		expired := s.retention.MaxAge > 0 && now.Sub(oldest.Time) > s.retention.MaxAge
+		tooMany := s.retention.MaxCount > 0 && len(s.profiles) > s.retention.MaxCount
+		tooLarge := s.retention.MaxBytes > 0 && total > s.retention.MaxBytes
+		if !expired && !tooMany && !tooLarge {
+			return
+		}
+
+		if err := os.Remove(s.path(oldest.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			// профиль остается в списке, чтобы повторить удаление позже
+			logf("Не удалось удалить профиль %s: %v", oldest.ID, err)
+			return
+		}
+		total -= oldest.Size
+		s.profiles = s.profiles[1:]
+	}
+}
+
+// List возвращает профили типа profileType (всех типов, если пустой)
+// от новых к старым
+func (s *Store) List(profileType string) []Profile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profiles := []Profile{}
+	for i := len(s.profiles) - 1; i >= 0; i-- {
+		if profileType == "" || s.profiles[i].Type == profileType {
+			profiles = append(profiles, s.profiles[i])
+		}
+	}
+	return profiles
+}
+
+// Get возвращает описание профиля
+func (s *Store) Get(id string) (Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.profiles {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// Read возвращает содержимое профиля
+func (s *Store) Read(id string) (Profile, []byte, error) {
+	p, err := s.Get(id)
+	if err != nil {
+		return Profile{}, nil, err
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Profile{}, nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return p, data, err
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+profileExt)
+}
@@ -0,0 +1,246 @@
+// Package pkgRuntimeMetrics экспортирует в Prometheus все метрики runtime/metrics.
+//
+// В отличие от runtime.ReadMemStats чтение runtime/metrics не останавливает
+// программу. Скалярные метрики экспортируются как gauge или counter
+// (накопительные), распределения - как гистограммы Prometheus.
+// Имена строятся по схеме Name и не меняются между запусками
+package pkgRuntimeMetrics
+
+import (
+	"math"
+	"regexp"
+	"runtime/metrics"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace префикс имен метрик
+const Namespace = "go"
+
+// DefaultMaxBuckets количество границ гистограмм по умолчанию.
+// В runtime гистограммы содержат сотни бакетов
+const DefaultMaxBuckets = 32
+
+// Типы метрик Prometheus
+const (
+	TypeGauge     = "gauge"
+	TypeCounter   = "counter"
+	TypeHistogram = "histogram"
+)
+
+// Info описание экспортируемой метрики
+type Info struct {
+	RuntimeName string `json:"runtime_name"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Unit        string `json:"unit"`
+	Cumulative  bool   `json:"cumulative"`
+	Description string `json:"description"`
+	// Buckets количество границ гистограммы после прореживания
+	Buckets int `json:"buckets,omitempty"`
+}
+
+// Collector собирает метрики runtime/metrics для Prometheus
+type Collector struct {
+	maxBuckets int
+	infos      []Info
+	descs      []*prometheus.Desc
+
+	// samples переиспользуются между сборами, чтение защищено мьютексом
+	mu      sync.Mutex
+	samples []metrics.Sample
+}
+
+// New создает коллектор всех поддерживаемых метрик.
+// Гистограммы прореживаются до maxBuckets границ
+func New(maxBuckets int) *Collector {
+	c := &Collector{maxBuckets: max(maxBuckets, 1)}
+
+	for _, d := range metrics.All() {
+		typ := metricType(d)
+		if typ == "" {
+			continue
+		}
+
+		_, unit, _ := strings.Cut(d.Name, ":")
+		info := Info{
+			RuntimeName: d.Name,
+			Name:        Name(d.Name, typ == TypeCounter),
+			Type:        typ,
+			Unit:        unit,
+			Cumulative:  d.Cumulative,
+			Description: d.Description,
+		}
+
+		c.infos = append(c.infos, info)
+		c.descs = append(c.descs, prometheus.NewDesc(info.Name, d.Description, nil, nil))
+		c.samples = append(c.samples, metrics.Sample{Name: d.Name})
+	}
+
+	// количество бакетов известно только после первого чтения,
+	// границы гистограмм runtime не меняются
+	metrics.Read(c.samples)
+	for i, s := range c.samples {
+		if s.Value.Kind() == metrics.KindFloat64Histogram {
+			bounds, _ := c.histogram(s.Value.Float64Histogram())
+			c.infos[i].Buckets = len(bounds)
+		}
+	}
+
+	return c
+}
+
+func metricType(d metrics.Description) string {
+	switch d.Kind {
+	case metrics.KindUint64, metrics.KindFloat64:
+		if d.Cumulative {
+			return TypeCounter
+		}
+		return TypeGauge
+	case metrics.KindFloat64Histogram:
+		return TypeHistogram
+	default:
+		// метрики новых видов пропускаются, пока их не научатся экспортировать
+		return ""
+	}
+}
+
+var invalidChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// Name возвращает имя метрики Prometheus для имени runtime/metrics:
+// путь и единица измерения через "_", "/" в единице - "_per_",
+// остальные недопустимые символы - "_"; к счетчикам добавляется "_total".
+// Например, /gc/heap/allocs:bytes - go_gc_heap_allocs_bytes_total,
+// /gc/pauses:seconds - go_gc_pauses_seconds
+func Name(runtimeName string, counter bool) string {
+	path, unit, _ := strings.Cut(runtimeName, ":")
+	unit = strings.ReplaceAll(unit, "/", "_per_")
+
+	name := Namespace + "_" + strings.Trim(path, "/") + "_" + unit
+	name = strings.Trim(invalidChars.ReplaceAllString(name, "_"), "_")
+
+	if counter && !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	return name
+}
+
+// Metrics возвращает описания экспортируемых метрик
+func (c *Collector) Metrics() []Info {
+	infos := make([]Info, len(c.infos))
+	copy(infos, c.infos)
+	return infos
+}
+
+// Describe реализует prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.descs {
+		ch <- desc
+	}
+}
+
+// Collect реализует prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics.Read(c.samples)
+
+	for i, s := range c.samples {
+		desc := c.descs[i]
+
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			ch <- prometheus.MustNewConstMetric(desc, c.valueType(i), float64(s.Value.Uint64()))
+		case metrics.KindFloat64:
+			ch <- prometheus.MustNewConstMetric(desc, c.valueType(i), s.Value.Float64())
+		case metrics.KindFloat64Histogram:
+			bounds, counts := c.histogram(s.Value.Float64Histogram())
+			total, sum := histogramTotals(s.Value.Float64Histogram())
+
+			buckets := make(map[float64]uint64, len(bounds))
+			for j, bound := range bounds {
+				buckets[bound] = counts[j]
+			}
+			ch <- prometheus.MustNewConstHistogram(desc, total, sum, buckets)
+		}
+	}
+}
+
+func (c *Collector) valueType(i int) prometheus.ValueType {
+	if c.infos[i].Type == TypeCounter {
+		return prometheus.CounterValue
+	}
+	return prometheus.GaugeValue
+}
+
+// histogram переводит гистограмму runtime в конечные верхние границы
+// бакетов и накопленные количества Prometheus. Если границ больше
+// maxBuckets, остается каждая k-я: границы runtime растут экспоненциально,
+// поэтому прореженные тоже
+func (c *Collector) histogram(h *metrics.Float64Histogram) ([]float64, []uint64) {
+	var bounds []float64
+	var counts []uint64
+	var cumulative uint64
+
+	// бакет i - полуинтервал [Buckets[i], Buckets[i+1])
+	for i, count := range h.Counts {
+		cumulative += count
+		upper := h.Buckets[i+1]
+		if math.IsInf(upper, 1) {
+			break
+		}
+		bounds = append(bounds, upper)
+		counts = append(counts, cumulative)
+	}
+
+	if len(bounds) <= c.maxBuckets {
+		return bounds, counts
+	}
+
+	step := (len(bounds) + c.maxBuckets - 1) / c.maxBuckets
+	reducedBounds := make([]float64, 0, c.maxBuckets)
+	reducedCounts := make([]uint64, 0, c.maxBuckets)
+	// последняя граница сохраняется, чтобы не терять хвост распределения
+	for i := len(bounds) - 1; i >= 0; i -= step {
+		reducedBounds = append(reducedBounds, bounds[i])
+		reducedCounts = append(reducedCounts, counts[i])
+	}
+	slices.Reverse(reducedBounds)
+	slices.Reverse(reducedCounts)
+
+	return reducedBounds, reducedCounts
+}
+
+// histogramTotals возвращает количество наблюдений и оценку их суммы.
+// runtime не хранит сумму, поэтому наблюдение считается равным середине
+// своего бакета, для бесконечных бакетов - конечной границе
+func histogramTotals(h *metrics.Float64Histogram) (uint64, float64) {
+	var total uint64
+	var sum float64
+
+	for i, count := range h.Counts {
+		if count == 0 {
+			continue
+		}
+		lower, upper := h.Buckets[i], h.Buckets[i+1]
+
+		var value float64
+		switch {
+		case math.IsInf(lower, -1):
+			value = upper
+		case math.IsInf(upper, 1):
+			value = lower
+		default:
+			value = (lower + upper) / 2
+		}
+
+		total += count
+		sum += value * float64(count)
+	}
+
+	return total, sum
+}
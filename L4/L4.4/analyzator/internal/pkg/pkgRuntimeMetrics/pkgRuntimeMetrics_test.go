@@ -0,0 +1,93 @@
+package pkgRuntimeMetrics
+
+import (
+	"math"
+	"runtime/metrics"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		runtimeName string
+		counter     bool
+		want        string
+	}{
+		{"/gc/heap/allocs:bytes", true, "go_gc_heap_allocs_bytes_total"},
+		{"/gc/pauses:seconds", false, "go_gc_pauses_seconds"},
+		{"/sched/goroutines:goroutines", false, "go_sched_goroutines_goroutines"},
+		{"/cpu/classes/gc/mark/assist:cpu-seconds", true, "go_cpu_classes_gc_mark_assist_cpu_seconds_total"},
+		{"/memory/classes/heap/free:bytes", false, "go_memory_classes_heap_free_bytes"},
+		{"/godebug/non-default-behavior/x509sha1:events", true, "go_godebug_non_default_behavior_x509sha1_events_total"},
+		{"/test/rate:bytes/second", false, "go_test_rate_bytes_per_second"},
+	}
+
+	for _, tt := range tests {
+		if got := Name(tt.runtimeName, tt.counter); got != tt.want {
+			t.Errorf("Name(%q) = %q, ожидалось %q", tt.runtimeName, got, tt.want)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := &metrics.Float64Histogram{
+		Buckets: []float64{math.Inf(-1), 1, 2, 4, 8, 16, 32, math.Inf(1)},
+		Counts:  []uint64{1, 2, 3, 4, 5, 6, 7},
+	}
+
+	bounds, counts := (&Collector{maxBuckets: 10}).histogram(h)
+	if !slices.Equal(bounds, []float64{1, 2, 4, 8, 16, 32}) || !slices.Equal(counts, []uint64{1, 3, 6, 10, 15, 21}) {
+		t.Errorf("границы %v, количества %v", bounds, counts)
+	}
+
+	bounds, counts = (&Collector{maxBuckets: 3}).histogram(h)
+	if !slices.Equal(bounds, []float64{2, 8, 32}) || !slices.Equal(counts, []uint64{3, 10, 21}) {
+		t.Errorf("после прореживания границы %v, количества %v", bounds, counts)
+	}
+
+	total, sum := histogramTotals(h)
+	// середины бакетов, для бесконечных - конечные границы
+	wantSum := 1*1 + 2*1.5 + 3*3 + 4*6 + 5*12 + 6*24 + 7*32.0
+	if total != 28 || sum != wantSum {
+		t.Errorf("всего %d, сумма %v, ожидалось 28 и %v", total, sum, wantSum)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := New(DefaultMaxBuckets)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := make(map[string]string)
+	for _, f := range families {
+		types[f.GetName()] = f.GetType().String()
+	}
+
+	for _, info := range c.Metrics() {
+		if _, ok := types[info.Name]; !ok {
+			t.Errorf("метрика %s (%s) не экспортирована", info.Name, info.RuntimeName)
+		}
+		if info.Type == TypeHistogram && (info.Buckets == 0 || info.Buckets > DefaultMaxBuckets) {
+			t.Errorf("гистограмма %s: %d границ", info.Name, info.Buckets)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"go_gc_pauses_seconds":           "HISTOGRAM",
+		"go_sched_latencies_seconds":     "HISTOGRAM",
+		"go_gc_heap_allocs_bytes_total":  "COUNTER",
+		"go_sched_goroutines_goroutines": "GAUGE",
+	} {
+		if types[name] != want {
+			t.Errorf("%s: тип %q, ожидался %s", name, types[name], want)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+// Package pkgTuning изменяет параметры runtime во время работы: GOGC,
+// мягкий лимит памяти, частоту block и mutex профилирования.
+//
+// Каждое изменение действует ограниченное время (TTL) и затем
+// откатывается. Все изменения и откаты пишутся в журнал аудита
+package pkgTuning
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Параметры runtime
+const (
+	GCPercent            = "gc_percent"
+	MemoryLimit          = "memory_limit"
+	BlockProfileRate     = "block_profile_rate"
+	MutexProfileFraction = "mutex_profile_fraction"
+)
+
+// Parameters все параметры в порядке вывода
+var Parameters = []string{GCPercent, MemoryLimit, BlockProfileRate, MutexProfileFraction}
+
+// Действия журнала аудита
+const (
+	ActionSet     = "set"
+	ActionRevert  = "revert"
+	ActionExpire  = "expire"
+	ActionWhatIf  = "what_if"
+	ActionRestore = "what_if_restore"
+)
+
+const (
+	// DefaultTTL время действия изменения по умолчанию
+	DefaultTTL = 10 * time.Minute
+	// MaxTTL максимальное время действия изменения
+	MaxTTL = 24 * time.Hour
+	// auditSize количество хранимых записей аудита
+	auditSize = 1000
+)
+
+var (
+	// ErrUnknownParameter неизвестный параметр
+	ErrUnknownParameter = errors.New("неизвестный параметр")
+	// ErrOverrideNotFound изменение не найдено или уже откатилось
+	ErrOverrideNotFound = errors.New("изменение не найдено")
+)
+
+// Override действующее изменение параметра.
+// Previous - значение, которое будет восстановлено при откате
+type Override struct {
+	ID        int64     `json:"id"`
+	Parameter string    `json:"parameter"`
+	Value     int64     `json:"value"`
+	Previous  int64     `json:"previous"`
+	SetAt     time.Time `json:"set_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason,omitempty"`
+
+	timer *time.Timer
+}
+
+// AuditEntry запись журнала аудита
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	Parameter  string    `json:"parameter"`
+	OverrideID int64     `json:"override_id,omitempty"`
+	Old        int64     `json:"old"`
+	New        int64     `json:"new"`
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	Actor      string    `json:"actor,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// Change запрос на изменение параметров
+type Change struct {
+	Values map[string]int64
+	TTL    time.Duration
+	Actor  string
+	Reason string
+}
+
+// parameter параметр runtime со стеком действующих изменений.
+// Верхнее изменение определяет текущее значение
+type parameter struct {
+	get       func() int64
+	set       func(int64)
+	validate  func(int64) error
+	overrides []*Override
+}
+
+// Tuner изменяет параметры runtime с автоматическим откатом
+type Tuner struct {
+	mu     sync.Mutex
+	params map[string]*parameter
+	nextID int64
+	audit  []AuditEntry
+
+	// blockProfileRate у runtime нет функции чтения
+	blockProfileRate int64
+
+	whatIfMu sync.Mutex
+	// whatIf параметры, измененные выполняемой нагрузкой; изменяется под mu
+	whatIf map[string]*whatIfValue
+}
+
+// New создает Tuner. Текущие значения параметров считаются исходными
+func New() *Tuner {
+	t := &Tuner{}
+
+	t.params = map[string]*parameter{
+		GCPercent: {
+			get: func() int64 {
+				sample := []metrics.Sample{{Name: "/gc/gogc:percent"}}
+				metrics.Read(sample)
+				// -1 (GC отключен) читается как максимальный uint64
+				return int64(sample[0].Value.Uint64())
+			},
+			set: func(v int64) { debug.SetGCPercent(int(v)) },
+			validate: func(v int64) error {
+				if v < -1 || v > math.MaxInt32 {
+					return fmt.Errorf("%s должен быть >= -1 (-1 отключает GC)", GCPercent)
+				}
+				return nil
+			},
+		},
+		MemoryLimit: {
+			// отрицательное значение только читает лимит
+			get: func() int64 { return debug.SetMemoryLimit(-1) },
+			set: func(v int64) { debug.SetMemoryLimit(v) },
+			validate: func(v int64) error {
+				if v <= 0 {
+					return fmt.Errorf("%s должен быть > 0 (без лимита - %d)", MemoryLimit, int64(math.MaxInt64))
+				}
+				return nil
+			},
+		},
+		BlockProfileRate: {
+			get: func() int64 { return t.blockProfileRate },
+			set: func(v int64) {
+				runtime.SetBlockProfileRate(int(v))
+				t.blockProfileRate = v
+			},
+			validate: func(v int64) error {
+				if v < 0 || v > math.MaxInt32 {
+					return fmt.Errorf("%s должен быть >= 0 (0 отключает профилирование)", BlockProfileRate)
+				}
+				return nil
+			},
+		},
+		MutexProfileFraction: {
+			// отрицательное значение только читает частоту
+			get: func() int64 { return int64(runtime.SetMutexProfileFraction(-1)) },
+			set: func(v int64) { runtime.SetMutexProfileFraction(int(v)) },
+			validate: func(v int64) error {
+				if v < 0 || v > math.MaxInt32 {
+					return fmt.Errorf("%s должен быть >= 0 (0 отключает профилирование)", MutexProfileFraction)
+				}
+				return nil
+			},
+		},
+	}
+
+	return t
+}
+
+// Current возвращает текущие значения параметров
+func (t *Tuner) Current() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	values := make(map[string]int64, len(t.params))
+	for name, p := range t.params {
+		values[name] = p.get()
+	}
+	return values
+}
+
+// Overrides возвращает действующие изменения в порядке применения
+func (t *Tuner) Overrides() []Override {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	overrides := []Override{}
+	for _, name := range Parameters {
+		for _, o := range t.params[name].overrides {
+			overrides = append(overrides, *o)
+		}
+	}
+	slices.SortFunc(overrides, func(a, b Override) int {
+		return int(a.ID - b.ID)
+	})
+	return overrides
+}
+
+// Audit возвращает журнал аудита от старых записей к новым
+func (t *Tuner) Audit() []AuditEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.audit)
+}
+
+// Apply проверяет и применяет все значения change. Изменения
+// откатываются через change.TTL (DefaultTTL, если не задан)
+func (t *Tuner) Apply(change Change) ([]Override, error) {
+	ttl := change.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		return nil, fmt.Errorf("ttl должен быть от 0 до %v", MaxTTL)
+	}
+	if len(change.Values) == 0 {
+		return nil, errors.New("не задано ни одного параметра")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, value := range change.Values {
+		p, ok := t.params[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownParameter, name)
+		}
+		if err := p.validate(value); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	var applied []Override
+
+	for _, name := range Parameters {
+		value, ok := change.Values[name]
+		if !ok {
+			continue
+		}
+		p := t.params[name]
+
+		previous := p.get()
+		if w, ok := t.whatIf[name]; ok {
+			// изменение заменяет значение нагрузки и после нее не восстанавливается
+			previous = w.previous
+			delete(t.whatIf, name)
+		}
+
+		t.nextID++
+		o := &Override{
+			ID:        t.nextID,
+			Parameter: name,
+			Value:     value,
+			Previous:  previous,
+			SetAt:     now,
+			ExpiresAt: now.Add(ttl),
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+		}
+		id := o.ID
+		o.timer = time.AfterFunc(ttl, func() {
+			t.revert(name, id, ActionExpire, "")
+		})
+
+		p.set(value)
+		p.overrides = append(p.overrides, o)
+
+		t.record(AuditEntry{
+			Time:       now,
+			Action:     ActionSet,
+			Parameter:  name,
+			OverrideID: o.ID,
+			Old:        o.Previous,
+			New:        value,
+			ExpiresAt:  o.ExpiresAt,
+			Actor:      change.Actor,
+			Reason:     change.Reason,
+		})
+		applied = append(applied, *o)
+	}
+
+	return applied, nil
+}
+
+// Revert досрочно откатывает изменение с идентификатором id
+func (t *Tuner) Revert(id int64, actor string) error {
+	t.mu.Lock()
+	name := ""
+	for n, p := range t.params {
+		for _, o := range p.overrides {
+			if o.ID == id {
+				name = n
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	if name == "" || !t.revert(name, id, ActionRevert, actor) {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
+
+// revert убирает изменение из стека параметра. Для верхнего изменения
+// восстанавливается предыдущее значение; для перекрытого - предыдущее
+// значение переходит к изменению над ним, текущее значение не меняется
+func (t *Tuner) revert(name string, id int64, action, actor string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := t.params[name]
+	i := slices.IndexFunc(p.overrides, func(o *Override) bool { return o.ID == id })
+	if i < 0 {
+		return false
+	}
+
+	o := p.overrides[i]
+	o.timer.Stop()
+	p.overrides = slices.Delete(p.overrides, i, i+1)
+
+	current := p.get()
+	restored := current
+	if w, ok := t.whatIf[name]; ok && i == len(p.overrides) {
+		// значение нагрузки действует до ее окончания, затем восстанавливается o.Previous
+		w.previous = o.Previous
+		restored = o.Previous
+	} else if i == len(p.overrides) {
+		p.set(o.Previous)
+		restored = o.Previous
+	} else {
+		p.overrides[i].Previous = o.Previous
+	}
+
+	t.record(AuditEntry{
+		Time:       time.Now(),
+		Action:     action,
+		Parameter:  name,
+		OverrideID: id,
+		Old:        current,
+		New:        restored,
+		Actor:      actor,
+	})
+	return true
+}
+
+// record добавляет запись аудита; вызывается под t.mu
+func (t *Tuner) record(entry AuditEntry) {
+	if len(t.audit) == auditSize {
+		t.audit = slices.Delete(t.audit, 0, 1)
+	}
+	t.audit = append(t.audit, entry)
+
+	log.Printf("Аудит runtime: %s %s %d -> %d (изменение %d, инициатор %q)",
+		entry.Action, entry.Parameter, entry.Old, entry.New, entry.OverrideID, entry.Actor)
+}
@@ -0,0 +1,179 @@
+package pkgTuning
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestApplyAndRevert(t *testing.T) {
+	tuner := New()
+	initial := tuner.Current()[GCPercent]
+	defer debug.SetGCPercent(int(initial))
+
+	first, err := tuner.Apply(Change{Values: map[string]int64{GCPercent: 50}, TTL: time.Hour, Actor: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := tuner.Apply(Change{Values: map[string]int64{GCPercent: 200}, TTL: time.Hour, Actor: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tuner.Current()[GCPercent]; got != 200 {
+		t.Fatalf("GOGC %d, ожидалось 200", got)
+	}
+
+	// откат перекрытого изменения не меняет текущее значение
+	if err := tuner.Revert(first[0].ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := tuner.Current()[GCPercent]; got != 200 {
+		t.Errorf("после отката перекрытого изменения GOGC %d, ожидалось 200", got)
+	}
+
+	// откат верхнего изменения восстанавливает значение до первого
+	if err := tuner.Revert(second[0].ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := tuner.Current()[GCPercent]; got != initial {
+		t.Errorf("после отката GOGC %d, ожидалось %d", got, initial)
+	}
+
+	if err := tuner.Revert(second[0].ID, "test"); !errors.Is(err, ErrOverrideNotFound) {
+		t.Errorf("повторный откат: %v", err)
+	}
+
+	var actions []string
+	for _, e := range tuner.Audit() {
+		actions = append(actions, e.Action)
+	}
+	if len(actions) != 4 || actions[0] != ActionSet || actions[3] != ActionRevert {
+		t.Errorf("журнал аудита %v", actions)
+	}
+}
+
+func TestApplyExpires(t *testing.T) {
+	tuner := New()
+
+	if _, err := tuner.Apply(Change{Values: map[string]int64{MutexProfileFraction: 5, BlockProfileRate: 1000}, TTL: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if got := tuner.Current(); got[MutexProfileFraction] != 5 || got[BlockProfileRate] != 1000 {
+		t.Fatalf("параметры не применены: %v", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(tuner.Overrides()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := tuner.Current(); got[MutexProfileFraction] != 0 || got[BlockProfileRate] != 0 {
+		t.Errorf("параметры не откатились по TTL: %v", got)
+	}
+	for _, e := range tuner.Audit()[2:] {
+		if e.Action != ActionExpire {
+			t.Errorf("запись %+v, ожидался откат по TTL", e)
+		}
+	}
+}
+
+func TestApplyValidation(t *testing.T) {
+	tuner := New()
+
+	for _, change := range []Change{
+		{},
+		{Values: map[string]int64{"gomaxprocs": 2}},
+		{Values: map[string]int64{GCPercent: -2}},
+		{Values: map[string]int64{MemoryLimit: 0}},
+		{Values: map[string]int64{GCPercent: 100}, TTL: 48 * time.Hour},
+	} {
+		if _, err := tuner.Apply(change); err == nil {
+			t.Errorf("изменение %+v принято", change)
+		}
+	}
+	if len(tuner.Audit()) != 0 {
+		t.Errorf("отклоненные изменения попали в аудит")
+	}
+}
+
+func TestWhatIf(t *testing.T) {
+	tuner := New()
+	before := tuner.Current()
+
+	workload := Workload{Duration: 200 * time.Millisecond, AllocSize: 1024, LiveBytes: 4 << 20, Goroutines: 2}
+	result, err := tuner.WhatIf(context.Background(), map[string]int64{GCPercent: 10}, workload, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Allocated == 0 || result.GCCount == 0 || result.PeakHeap == 0 {
+		t.Errorf("пустой результат %+v", result)
+	}
+	if got := tuner.Current()[GCPercent]; got != before[GCPercent] {
+		t.Errorf("GOGC после нагрузки %d, ожидалось %d", got, before[GCPercent])
+	}
+
+	if _, err := tuner.WhatIf(context.Background(), map[string]int64{BlockProfileRate: 1}, workload, "test"); !errors.Is(err, ErrUnknownParameter) {
+		t.Errorf("нагрузка с %s: %v", BlockProfileRate, err)
+	}
+}
+
+func TestWhatIfConcurrentChanges(t *testing.T) {
+	tuner := New()
+	before := tuner.Current()
+
+	// GOGC до нагрузки задан изменением, которое откатывается во время нее
+	reverted, err := tuner.Apply(Change{Values: map[string]int64{GCPercent: 150}, Actor: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := map[string]int64{GCPercent: 10, MemoryLimit: 1 << 40}
+	workload := Workload{Duration: 500 * time.Millisecond, AllocSize: 1024, LiveBytes: 1 << 20, Goroutines: 1}
+	done := make(chan error, 1)
+	go func() {
+		_, err := tuner.WhatIf(context.Background(), settings, workload, "test")
+		done <- err
+	}()
+
+	// параметры читаются, пока нагрузка выполняется
+	deadline := time.Now().Add(workload.Duration / 2)
+	for tuner.Current()[GCPercent] != 10 {
+		if time.Now().After(deadline) {
+			t.Fatal("нагрузка не применила GOGC")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := tuner.Revert(reverted[0].ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := tuner.Current()[GCPercent]; got != 10 {
+		t.Errorf("GOGC во время нагрузки после отката %d, ожидалось 10", got)
+	}
+	applied, err := tuner.Apply(Change{Values: map[string]int64{MemoryLimit: 1 << 41}, Actor: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	current := tuner.Current()
+	if current[GCPercent] != before[GCPercent] {
+		t.Errorf("GOGC после нагрузки %d, ожидалось %d", current[GCPercent], before[GCPercent])
+	}
+	if current[MemoryLimit] != 1<<41 {
+		t.Errorf("лимит памяти после нагрузки %d, изменение во время нагрузки потеряно", current[MemoryLimit])
+	}
+
+	if err := tuner.Revert(applied[0].ID, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := tuner.Current()[MemoryLimit]; got != before[MemoryLimit] {
+		t.Errorf("лимит памяти после отката %d, ожидалось %d", got, before[MemoryLimit])
+	}
+}
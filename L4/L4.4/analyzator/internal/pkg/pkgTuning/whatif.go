@@ -0,0 +1,228 @@
+package pkgTuning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Ограничения синтетической нагрузки
+const (
+	maxWhatIfDuration   = 30 * time.Second
+	maxWhatIfLiveBytes  = 1 << 30
+	maxWhatIfAllocSize  = 16 << 20
+	maxWhatIfGoroutines = 64
+	// peakInterval интервал измерения размера heap во время нагрузки
+	peakInterval = 5 * time.Millisecond
+)
+
+// ErrWhatIfBusy возвращается, пока выполняется другая нагрузка
+var ErrWhatIfBusy = errors.New("другая нагрузка уже выполняется")
+
+// Workload синтетическая нагрузка: горутины в течение Duration выделяют
+// блоки по AllocSize байт и держат последние LiveBytes байт живыми
+type Workload struct {
+	Duration   time.Duration `json:"duration"`
+	AllocSize  int           `json:"alloc_size"`
+	LiveBytes  int64         `json:"live_bytes"`
+	Goroutines int           `json:"goroutines"`
+}
+
+// DefaultWorkload возвращает нагрузку по умолчанию
+func DefaultWorkload() Workload {
+	return Workload{
+		Duration:   2 * time.Second,
+		AllocSize:  4 << 10,
+		LiveBytes:  64 << 20,
+		Goroutines: 4,
+	}
+}
+
+// Validate проверяет ограничения нагрузки
+func (w Workload) Validate() error {
+	switch {
+	case w.Duration <= 0 || w.Duration > maxWhatIfDuration:
+		return fmt.Errorf("длительность нагрузки должна быть от 0 до %v", maxWhatIfDuration)
+	case w.AllocSize < 16 || w.AllocSize > maxWhatIfAllocSize:
+		return fmt.Errorf("размер аллокации должен быть от 16 до %d байт", maxWhatIfAllocSize)
+	case w.LiveBytes < 0 || w.LiveBytes > maxWhatIfLiveBytes:
+		return fmt.Errorf("объем живых данных должен быть от 0 до %d байт", maxWhatIfLiveBytes)
+	case w.Goroutines < 1 || w.Goroutines > maxWhatIfGoroutines:
+		return fmt.Errorf("количество горутин должно быть от 1 до %d", maxWhatIfGoroutines)
+	}
+	return nil
+}
+
+// WhatIfResult результат нагрузки. GC и паузы учитываются для всего
+// процесса, поэтому в них попадает и работа самого сервиса
+type WhatIfResult struct {
+	Settings   map[string]int64 `json:"settings"`
+	Workload   Workload         `json:"workload"`
+	Elapsed    time.Duration    `json:"elapsed_ns"`
+	Allocated  uint64           `json:"allocated_bytes"`
+	GCCount    uint32           `json:"gc_count"`
+	PauseTotal time.Duration    `json:"pause_total_ns"`
+	PauseMax   time.Duration    `json:"pause_max_ns"`
+	PeakHeap   uint64           `json:"peak_heap_bytes"`
+}
+
+// whatIfValue значение параметра на время нагрузки и значение,
+// которое восстанавливается после нее
+type whatIfValue struct {
+	value    int64
+	previous int64
+}
+
+// WhatIf выполняет нагрузку с заданными GOGC и лимитом памяти и возвращает
+// статистику GC. Настройки действуют на весь процесс только на время
+// нагрузки. Изменение того же параметра через Apply во время нагрузки
+// заменяет ее значение и после нагрузки сохраняется, а откат изменения
+// во время нагрузки откладывается до ее окончания
+func (t *Tuner) WhatIf(ctx context.Context, settings map[string]int64, workload Workload, actor string) (WhatIfResult, error) {
+	if err := workload.Validate(); err != nil {
+		return WhatIfResult{}, err
+	}
+	for name := range settings {
+		if name != GCPercent && name != MemoryLimit {
+			return WhatIfResult{}, fmt.Errorf("%w для нагрузки: %s (допустимы %s и %s)", ErrUnknownParameter, name, GCPercent, MemoryLimit)
+		}
+	}
+
+	if !t.whatIfMu.TryLock() {
+		return WhatIfResult{}, ErrWhatIfBusy
+	}
+	defer t.whatIfMu.Unlock()
+
+	if err := t.applyWhatIf(settings, actor); err != nil {
+		return WhatIfResult{}, err
+	}
+	// mu не удерживается во время нагрузки, чтобы чтение параметров,
+	// изменения и их откаты по TTL не ждали ее окончания
+	defer t.restoreWhatIf(actor)
+
+	result := runWorkload(ctx, workload)
+	result.Settings = settings
+	return result, nil
+}
+
+// applyWhatIf применяет настройки нагрузки в обход стека изменений
+func (t *Tuner) applyWhatIf(settings map[string]int64, actor string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, value := range settings {
+		if err := t.params[name].validate(value); err != nil {
+			return err
+		}
+	}
+
+	t.whatIf = make(map[string]*whatIfValue, len(settings))
+	for name, value := range settings {
+		p := t.params[name]
+		previous := p.get()
+		p.set(value)
+		t.whatIf[name] = &whatIfValue{value: value, previous: previous}
+		t.record(AuditEntry{Time: time.Now(), Action: ActionWhatIf, Parameter: name, Old: previous, New: value, Actor: actor})
+	}
+	return nil
+}
+
+// restoreWhatIf восстанавливает параметры, которые не были изменены во время нагрузки
+func (t *Tuner) restoreWhatIf(actor string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, w := range t.whatIf {
+		t.params[name].set(w.previous)
+		t.record(AuditEntry{Time: time.Now(), Action: ActionRestore, Parameter: name, Old: w.value, New: w.previous, Actor: actor})
+	}
+	t.whatIf = nil
+}
+
+// runWorkload выполняет нагрузку и собирает статистику GC по MemStats
+func runWorkload(ctx context.Context, w Workload) WhatIfResult {
+	ctx, cancel := context.WithTimeout(ctx, w.Duration)
+	defer cancel()
+
+	// мусор, накопленный до нагрузки, не должен попасть в результат
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	var allocated atomic.Uint64
+
+	peak := make(chan uint64, 1)
+	go func() {
+		peak <- measurePeakHeap(ctx)
+	}()
+
+	slots := max(int(w.LiveBytes/int64(w.AllocSize)/int64(w.Goroutines)), 1)
+
+	var wg sync.WaitGroup
+	for range w.Goroutines {
+		wg.Go(func() {
+			live := make([][]byte, slots)
+			for i := 0; ctx.Err() == nil; i++ {
+				buf := make([]byte, w.AllocSize)
+				buf[0] = byte(i)
+				live[i%slots] = buf
+				allocated.Add(uint64(w.AllocSize))
+			}
+		})
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	peakHeap := <-peak
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+
+	return WhatIfResult{
+		Workload:   w,
+		Elapsed:    elapsed,
+		Allocated:  allocated.Load(),
+		GCCount:    after.NumGC - before.NumGC,
+		PauseTotal: time.Duration(after.PauseTotalNs - before.PauseTotalNs),
+		PauseMax:   maxPause(&after, before.NumGC),
+		PeakHeap:   peakHeap,
+	}
+}
+
+// measurePeakHeap возвращает максимальный размер объектов heap до отмены ctx
+func measurePeakHeap(ctx context.Context) uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	ticker := time.NewTicker(peakInterval)
+	defer ticker.Stop()
+
+	var peak uint64
+	for {
+		metrics.Read(sample)
+		peak = max(peak, sample[0].Value.Uint64())
+
+		select {
+		case <-ctx.Done():
+			return peak
+		case <-ticker.C:
+		}
+	}
+}
+
+// maxPause возвращает максимальную паузу сборок после sinceNumGC.
+// MemStats хранит только последние 256 пауз
+func maxPause(ms *runtime.MemStats, sinceNumGC uint32) time.Duration {
+	size := uint32(len(ms.PauseNs))
+	count := min(ms.NumGC-sinceNumGC, size)
+
+	var longest time.Duration
+	for gc := ms.NumGC - count + 1; gc <= ms.NumGC; gc++ {
+		longest = max(longest, time.Duration(ms.PauseNs[(gc+size-1)%size]))
+	}
+	return longest
+}
@@ -0,0 +1,194 @@
+package transport
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"analyzator/internal/pkg/pkgTuning"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminAuth пропускает запросы с заголовком Authorization: Bearer <token>
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "требуется заголовок Authorization: Bearer <токен>",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// runtimeSettingsRequest тело запроса на изменение параметров runtime.
+// Незаданные параметры не меняются
+type runtimeSettingsRequest struct {
+	GCPercent            *int64 `json:"gc_percent"`
+	MemoryLimit          *int64 `json:"memory_limit"`
+	BlockProfileRate     *int64 `json:"block_profile_rate"`
+	MutexProfileFraction *int64 `json:"mutex_profile_fraction"`
+	TTL                  string `json:"ttl"`
+	Reason               string `json:"reason"`
+}
+
+func (r runtimeSettingsRequest) values() map[string]int64 {
+	values := make(map[string]int64)
+	for name, v := range map[string]*int64{
+		pkgTuning.GCPercent:            r.GCPercent,
+		pkgTuning.MemoryLimit:          r.MemoryLimit,
+		pkgTuning.BlockProfileRate:     r.BlockProfileRate,
+		pkgTuning.MutexProfileFraction: r.MutexProfileFraction,
+	} {
+		if v != nil {
+			values[name] = *v
+		}
+	}
+	return values
+}
+
+// runtimeSettingsHandler возвращает текущие параметры runtime и действующие изменения
+func runtimeSettingsHandler(tuner *pkgTuning.Tuner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"settings":  tuner.Current(),
+			"overrides": tuner.Overrides(),
+		})
+	}
+}
+
+// updateRuntimeSettingsHandler изменяет параметры runtime на время ttl
+func updateRuntimeSettingsHandler(tuner *pkgTuning.Tuner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req runtimeSettingsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректное тело запроса: " + err.Error()})
+			return
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(req.TTL); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный ttl: ожидается длительность, например 10m"})
+				return
+			}
+		}
+
+		applied, err := tuner.Apply(pkgTuning.Change{
+			Values: req.values(),
+			TTL:    ttl,
+			Actor:  c.ClientIP(),
+			Reason: req.Reason,
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"applied":  applied,
+			"settings": tuner.Current(),
+		})
+	}
+}
+
+// revertOverrideHandler досрочно откатывает изменение
+func revertOverrideHandler(tuner *pkgTuning.Tuner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор изменения"})
+			return
+		}
+
+		if err := tuner.Revert(id, c.ClientIP()); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, pkgTuning.ErrOverrideNotFound) {
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"settings": tuner.Current(),
+		})
+	}
+}
+
+// auditHandler возвращает журнал изменений параметров runtime
+func auditHandler(tuner *pkgTuning.Tuner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"entries": tuner.Audit(),
+		})
+	}
+}
+
+// whatIfRequest тело запроса синтетической нагрузки.
+// Незаданные поля нагрузки берутся из pkgTuning.DefaultWorkload
+type whatIfRequest struct {
+	GCPercent   *int64 `json:"gc_percent"`
+	MemoryLimit *int64 `json:"memory_limit"`
+	Duration    string `json:"duration"`
+	AllocSize   int    `json:"alloc_size"`
+	LiveBytes   int64  `json:"live_bytes"`
+	Goroutines  int    `json:"goroutines"`
+}
+
+// whatIfHandler выполняет синтетическую нагрузку с заданными GOGC и лимитом памяти
+func whatIfHandler(tuner *pkgTuning.Tuner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req whatIfRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректное тело запроса: " + err.Error()})
+			return
+		}
+
+		workload := pkgTuning.DefaultWorkload()
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "некорректная длительность: ожидается, например, 5s"})
+				return
+			}
+			workload.Duration = d
+		}
+		if req.AllocSize != 0 {
+			workload.AllocSize = req.AllocSize
+		}
+		if req.LiveBytes != 0 {
+			workload.LiveBytes = req.LiveBytes
+		}
+		if req.Goroutines != 0 {
+			workload.Goroutines = req.Goroutines
+		}
+
+		settings := make(map[string]int64)
+		if req.GCPercent != nil {
+			settings[pkgTuning.GCPercent] = *req.GCPercent
+		}
+		if req.MemoryLimit != nil {
+			settings[pkgTuning.MemoryLimit] = *req.MemoryLimit
+		}
+
+		result, err := tuner.WhatIf(c.Request.Context(), settings, workload, c.ClientIP())
+		switch {
+		case errors.Is(err, pkgTuning.ErrWhatIfBusy):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
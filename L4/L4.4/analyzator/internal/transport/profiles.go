@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"analyzator/internal/pkg/pkgProfiler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listProfilesHandler возвращает сохраненные профили, ?type= отбирает профили одного типа
+func listProfilesHandler(store *pkgProfiler.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		profiles := store.List(c.Query("type"))
+		c.JSON(http.StatusOK, gin.H{
+			"count":    len(profiles),
+			"profiles": profiles,
+		})
+	}
+}
+
+// downloadProfileHandler отдает профиль в формате pprof
+func downloadProfileHandler(store *pkgProfiler.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, data, err := store.Read(c.Param("id"))
+		if err != nil {
+			c.JSON(profileErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="`+p.ID+`.pb.gz"`)
+		c.Data(http.StatusOK, "application/octet-stream", data)
+	}
+}
+
+// diffProfilesHandler сравнивает профили ?base= и ?target= и возвращает
+// ?top= функций с наибольшим ростом значения ?sample=
+func diffProfilesHandler(store *pkgProfiler.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		base, target := c.Query("base"), c.Query("target")
+		if base == "" || target == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "требуются параметры base и target"})
+			return
+		}
+
+		top := pkgProfiler.DefaultTop
+		if value := c.Query("top"); value != "" {
+			var err error
+			if top, err = strconv.Atoi(value); err != nil || top < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный top: ожидается число >= 1"})
+				return
+			}
+		}
+
+		result, err := store.Diff(base, target, c.Query("sample"), top)
+		if err != nil {
+			c.JSON(profileErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func profileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, pkgProfiler.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, pkgProfiler.ErrIncompatible):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
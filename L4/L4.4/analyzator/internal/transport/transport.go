@@ -3,16 +3,29 @@ package transport
 import (
 	"log"
 	"net/http"
-	"net/http/pprof"
-	"runtime"
 
+	"analyzator/agent"
+	"analyzator/internal/pkg/pkgProfiler"
 	pkgPrometheus "analyzator/internal/pkg/pkgPrometheus"
+	"analyzator/internal/pkg/pkgTuning"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Dependencies зависимости обработчиков
+type Dependencies struct {
+	// Agent обслуживает /metrics, /debug/pprof, память и GC
+	Agent *agent.Agent
+	// Tuner изменяет параметры runtime через /api/v1/admin
+	Tuner *pkgTuning.Tuner
+	// Profiles отдаются через /api/v1/profiles, без хранилища API отключен
+	Profiles *pkgProfiler.Store
+	// AdminToken токен доступа к /api/v1/admin, без токена admin API отключен
+	AdminToken string
+}
+
 // NewRouter создает новый маршрутизатор
-func NewRouter() *gin.Engine {
+func NewRouter(deps Dependencies) *gin.Engine {
 	router := gin.New()
 
 	// Используем middleware для логирования
@@ -35,30 +48,29 @@ func NewRouter() *gin.Engine {
 	// Health check endpoint
 	api.GET("/health", healthHandler)
 
-	// Memory и GC endpoints
+	// Memory endpoint
 	api.GET("/memory", memoryHandler)
-	api.POST("/gc/trigger", triggerGCHandler)
-	api.GET("/gc/status", gcStatusHandler)
 
-	// Группа для Prometheus метрик
-	metrics := router.Group("/metrics")
-	{
-		metrics.GET("", prometheusHandler)
+	// Метрики, история памяти, GC и pprof
+	deps.Agent.RegisterGin(router)
+
+	// Профили, снятые непрерывным профилированием
+	if deps.Profiles != nil {
+		api.GET("/profiles", listProfilesHandler(deps.Profiles))
+		api.GET("/profiles/diff", diffProfilesHandler(deps.Profiles))
+		api.GET("/profiles/:id", downloadProfileHandler(deps.Profiles))
 	}
 
-	// Группа для профилирования pprof
-	pprofGroup := router.Group("/debug/pprof")
-	{
-		pprofGroup.GET("/", pprofIndexHandler)
-		pprofGroup.GET("/cmdline", pprofCmdlineHandler)
-		pprofGroup.GET("/profile", pprofProfileHandler)
-		pprofGroup.GET("/symbol", pprofSymbolHandler)
-		pprofGroup.GET("/trace", pprofTraceHandler)
-		pprofGroup.GET("/goroutine", pprofGoroutineHandler)
-		pprofGroup.GET("/heap", pprofHeapHandler)
-		pprofGroup.GET("/allocs", pprofAllocsHandler)
-		pprofGroup.GET("/block", pprofBlockHandler)
-		pprofGroup.GET("/mutex", pprofMutexHandler)
+	// Изменение параметров runtime доступно только с токеном
+	if deps.AdminToken != "" {
+		admin := api.Group("/admin", adminAuth(deps.AdminToken))
+		admin.GET("/runtime", runtimeSettingsHandler(deps.Tuner))
+		admin.PUT("/runtime", updateRuntimeSettingsHandler(deps.Tuner))
+		admin.DELETE("/runtime/overrides/:id", revertOverrideHandler(deps.Tuner))
+		admin.GET("/audit", auditHandler(deps.Tuner))
+		admin.POST("/whatif", whatIfHandler(deps.Tuner))
+	} else {
+		log.Println("Admin API отключен: не задан токен --admin-token")
 	}
 
 	return router
@@ -106,84 +118,3 @@ func memoryHandler(c *gin.Context) {
 		"description": "Получите подробную информацию о памяти и GC через /metrics",
 	})
 }
-
-// triggerGCHandler обработчик для принудительного запуска GC
-func triggerGCHandler(c *gin.Context) {
-	// Запускаем принудительную сборку мусора
-	runtime.GC()
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Запущена принудительная сборка мусора",
-		"timestamp": gin.H{
-			"unix": gin.H{
-				"sec": gin.H{
-					"int":   0,
-					"int64": 0,
-				},
-			},
-		},
-	})
-}
-
-// gcStatusHandler обработчик для получения статуса GC
-func gcStatusHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"gc_status": gin.H{
-			"enabled":     true,
-			"description": "GC включен, используйте /metrics для получения детальной статистики",
-		},
-		"endpoints": gin.H{
-			"metrics":    "/metrics - все метрики в формате Prometheus",
-			"memory":     "/api/v1/memory - информация о памяти",
-			"gc_trigger": "/api/v1/gc/trigger - принудительный запуск GC (POST)",
-			"gc_status":  "/api/v1/gc/status - статус GC",
-			"pprof":      "/debug/pprof/* - профилирование",
-		},
-	})
-}
-
-// prometheusHandler обработчик для метрик Prometheus
-func prometheusHandler(c *gin.Context) {
-	pkgPrometheus.Handler().ServeHTTP(c.Writer, c.Request)
-}
-
-// pprof handlers для профилирования
-func pprofIndexHandler(c *gin.Context) {
-	pprof.Index(c.Writer, c.Request)
-}
-
-func pprofCmdlineHandler(c *gin.Context) {
-	pprof.Cmdline(c.Writer, c.Request)
-}
-
-func pprofProfileHandler(c *gin.Context) {
-	pprof.Profile(c.Writer, c.Request)
-}
-
-func pprofSymbolHandler(c *gin.Context) {
-	pprof.Symbol(c.Writer, c.Request)
-}
-
-func pprofTraceHandler(c *gin.Context) {
-	pprof.Trace(c.Writer, c.Request)
-}
-
-func pprofGoroutineHandler(c *gin.Context) {
-	pprof.Handler("goroutine").ServeHTTP(c.Writer, c.Request)
-}
-
-func pprofHeapHandler(c *gin.Context) {
-	pprof.Handler("heap").ServeHTTP(c.Writer, c.Request)
-}
-
-func pprofAllocsHandler(c *gin.Context) {
-	pprof.Handler("allocs").ServeHTTP(c.Writer, c.Request)
-}
-
-func pprofBlockHandler(c *gin.Context) {
-	pprof.Handler("block").ServeHTTP(c.Writer, c.Request)
-}
-
-func pprofMutexHandler(c *gin.Context) {
-	pprof.Handler("mutex").ServeHTTP(c.Writer, c.Request)
-}